@@ -0,0 +1,357 @@
+// Command loadtest opens many Socket.IO clients against a running server,
+// joins them to a session and plays a few rounds, measuring how long
+// broadcasts take to reach every client and how many never arrive. Run it
+// against a staging instance before an event:
+//
+//	go run ./cmd/loadtest --url https://staging.example.org --clients 500
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/kiliankoe/gptdash/internal/sioclient"
+)
+
+func main() {
+	var (
+		url      = flag.String("url", "http://localhost:8080", "Server to test")
+		clients  = flag.Int("clients", 200, "Number of players to connect")
+		rounds   = flag.Int("rounds", 3, "Rounds to play")
+		ramp     = flag.Int("ramp", 50, "Connections opened per second")
+		buffer   = flag.Int("buffer", 256, "Events buffered per client before they count as dropped")
+		provider = flag.String("provider", "mock", "AI provider of the session")
+		think    = flag.Duration("think", 2*time.Second, "Longest pause before a player answers or votes")
+		wait     = flag.Duration("wait", 10*time.Second, "How long a broadcast may take to reach every client")
+	)
+	flag.Parse()
+	if *clients < 1 || *rounds < 1 || *ramp < 1 {
+		fmt.Fprintln(os.Stderr, "need at least one client, one round and a ramp of one connection per second")
+		os.Exit(2)
+	}
+
+	lt := &loadTest{url: *url, buffer: *buffer, think: *think, wait: *wait}
+	err := lt.run(*clients, *rounds, *ramp, game.SessionConfig{Provider: *provider, Model: *provider, RoundCount: *rounds})
+	lt.report(os.Stdout)
+	lt.close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load test aborted: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+type loadTest struct {
+	url    string
+	buffer int
+	think  time.Duration
+	wait   time.Duration
+
+	host    *sioclient.Client
+	players []*player
+	probe   atomic.Pointer[probe] // the broadcast players are waiting for
+	closing atomic.Bool
+
+	mu       sync.Mutex
+	results  []*probe
+	acks     map[string][]time.Duration // event -> ack latencies
+	failures map[string]int             // what went wrong -> how often
+	connect  []time.Duration
+}
+
+type player struct {
+	ix  int
+	c   *sioclient.Client
+	sub string // submission of the current round
+}
+
+// probe is one broadcast the host triggered, with when each player got it.
+type probe struct {
+	name  string
+	match func(sioclient.Event) bool
+	start time.Time
+
+	mu   sync.Mutex
+	lat  map[int]time.Duration // player -> latency
+	all  chan struct{}         // closed once every player got it
+	want int
+}
+
+func (p *probe) record(ix int, d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.lat[ix]; ok {
+		return
+	}
+	p.lat[ix] = d
+	if len(p.lat) == p.want {
+		close(p.all)
+	}
+}
+
+func (lt *loadTest) fail(what string) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	lt.failures[what]++
+}
+
+func (lt *loadTest) run(clients, rounds, ramp int, cfg game.SessionConfig) error {
+	lt.acks = map[string][]time.Duration{}
+	lt.failures = map[string]int{}
+	ctx := context.Background()
+
+	// the host hears about every answer and vote but only reads the events
+	// it waits for
+	host, err := sioclient.Dial(ctx, lt.url, 4*clients+1024)
+	if err != nil {
+		return fmt.Errorf("host could not connect: %w", err)
+	}
+	lt.host = host
+	ack, err := host.Emit(ctx, "game:create", map[string]any{"config": cfg})
+	if err != nil || ack["error"] != nil {
+		return fmt.Errorf("could not create a session: %v %v", ack, err)
+	}
+	code, _ := ack["sessionCode"].(string)
+	fmt.Printf("session %s, connecting %d clients at %d/s\n", code, clients, ramp)
+
+	lt.connectAll(ctx, code, clients, ramp)
+	if len(lt.players) == 0 {
+		return fmt.Errorf("no client could join")
+	}
+	fmt.Printf("%d clients joined, playing %d rounds\n", len(lt.players), rounds)
+
+	for round := 1; round <= rounds; round++ {
+		if err := lt.broadcast(ctx, "game:setPrompt", map[string]any{"prompt": fmt.Sprintf("Load test round %d", round)}, "game:state", phase(game.PhaseAnswering)); err != nil {
+			return err
+		}
+		if cfg.Provider != game.ProviderManual {
+			if _, err := host.WaitFor(ctx, "game:aiAnswer", nil); err != nil {
+				return err
+			}
+		}
+		lt.each(func(p *player) {
+			ack := lt.emit(ctx, p, "game:submit", map[string]any{"text": fmt.Sprintf("Answer %d of client %d", round, p.ix)})
+			p.sub, _ = ack["submissionId"].(string)
+		})
+		if err := lt.broadcast(ctx, "game:advance", nil, "game:voting", nil); err != nil {
+			return err
+		}
+		options := lt.votingOptions(ctx)
+		lt.each(func(p *player) {
+			var targets []string
+			for _, id := range options {
+				if id != p.sub {
+					targets = append(targets, id)
+				}
+			}
+			if len(targets) > 0 {
+				lt.emit(ctx, p, "game:vote", map[string]any{"submissionId": targets[rand.Intn(len(targets))]})
+			}
+		})
+		if err := lt.broadcast(ctx, "game:advance", nil, "game:results", nil); err != nil {
+			return err
+		}
+	}
+	return lt.broadcast(ctx, "game:advance", nil, "game:summary", nil)
+}
+
+// connectAll connects and joins clients, starting ramp of them per second.
+func (lt *loadTest) connectAll(ctx context.Context, code string, clients, ramp int) {
+	tick := time.NewTicker(time.Second / time.Duration(ramp))
+	defer tick.Stop()
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		<-tick.C
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			c, err := sioclient.Dial(ctx, lt.url, lt.buffer)
+			if err != nil {
+				lt.fail("connect")
+				return
+			}
+			ack, err := c.Emit(ctx, "game:join", map[string]any{"sessionCode": code, "name": fmt.Sprintf("Load %d", i+1)})
+			if err != nil || ack["error"] != nil {
+				lt.fail("join")
+				c.Close()
+				return
+			}
+			lt.mu.Lock()
+			defer lt.mu.Unlock()
+			lt.connect = append(lt.connect, time.Since(start))
+			p := &player{ix: len(lt.players), c: c}
+			lt.players = append(lt.players, p)
+			go lt.listen(p)
+		}()
+	}
+	wg.Wait()
+}
+
+// listen records when p receives the current probe's event.
+func (lt *loadTest) listen(p *player) {
+	for {
+		select {
+		case ev := <-p.c.Events():
+			pr := lt.probe.Load()
+			if pr != nil && pr.name == ev.Name && (pr.match == nil || pr.match(ev)) {
+				pr.record(p.ix, ev.At.Sub(pr.start))
+			}
+		case <-p.c.Done():
+			if !lt.closing.Load() {
+				lt.fail("disconnect")
+			}
+			return
+		}
+	}
+}
+
+// broadcast has the host emit an event and waits until every player got the
+// event called name it triggers, or lt.wait passed.
+func (lt *loadTest) broadcast(ctx context.Context, event string, payload any, name string, match func(sioclient.Event) bool) error {
+	pr := &probe{name: name, match: match, start: time.Now(), lat: map[int]time.Duration{}, all: make(chan struct{}), want: len(lt.players)}
+	lt.probe.Store(pr)
+	ack, err := lt.host.Emit(ctx, event, payload)
+	if err != nil || ack["error"] != nil {
+		return fmt.Errorf("%s failed: %v %v", event, ack, err)
+	}
+	select {
+	case <-pr.all:
+	case <-time.After(lt.wait):
+	}
+	lt.probe.Store(nil)
+	lt.mu.Lock()
+	lt.results = append(lt.results, pr)
+	lt.mu.Unlock()
+	return nil
+}
+
+// votingOptions reads the answers up for vote from the host's copy of the
+// voting list.
+func (lt *loadTest) votingOptions(ctx context.Context) []string {
+	ctx, cancel := context.WithTimeout(ctx, lt.wait)
+	defer cancel()
+	ev, err := lt.host.WaitFor(ctx, "game:voting", nil)
+	if err != nil {
+		return nil
+	}
+	var voting struct {
+		Submissions []struct {
+			ID string `json:"id"`
+		} `json:"submissions"`
+	}
+	ev.Decode(&voting)
+	ids := make([]string, 0, len(voting.Submissions))
+	for _, s := range voting.Submissions {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+// each runs fn for every player at once, each after a random think time.
+func (lt *loadTest) each(fn func(*player)) {
+	var wg sync.WaitGroup
+	for _, p := range lt.players {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if lt.think > 0 {
+				time.Sleep(time.Duration(rand.Int63n(int64(lt.think))))
+			}
+			fn(p)
+		}()
+	}
+	wg.Wait()
+}
+
+// emit sends a player's event and records how long the ack took.
+func (lt *loadTest) emit(ctx context.Context, p *player, event string, payload any) map[string]any {
+	ctx, cancel := context.WithTimeout(ctx, lt.wait)
+	defer cancel()
+	start := time.Now()
+	ack, err := p.c.Emit(ctx, event, payload)
+	if err != nil || ack["error"] != nil {
+		lt.fail(event)
+		return nil
+	}
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	lt.acks[event] = append(lt.acks[event], time.Since(start))
+	return ack
+}
+
+func (lt *loadTest) close() {
+	lt.closing.Store(true)
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	for _, p := range lt.players {
+		p.c.Close()
+	}
+	if lt.host != nil {
+		lt.host.Close()
+	}
+}
+
+func phase(ph game.Phase) func(sioclient.Event) bool {
+	return func(ev sioclient.Event) bool {
+		var st struct {
+			Phase game.Phase `json:"phase"`
+		}
+		return json.Unmarshal(ev.Data, &st) == nil && st.Phase == ph
+	}
+}
+
+func (lt *loadTest) report(out *os.File) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "\t\treceived\tmissed\tp50\tp95\tp99\tmax\t")
+	row := func(kind, name string, got, missed int, lat []time.Duration) {
+		sort.Slice(lat, func(i, j int) bool { return lat[i] < lat[j] })
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t\n", kind, name, got, missed,
+			percentile(lat, 0.5), percentile(lat, 0.95), percentile(lat, 0.99), percentile(lat, 1))
+	}
+	row("connect", "", len(lt.connect), lt.failures["connect"]+lt.failures["join"], lt.connect)
+	for _, pr := range lt.results {
+		pr.mu.Lock()
+		lat := make([]time.Duration, 0, len(pr.lat))
+		for _, d := range pr.lat {
+			lat = append(lat, d)
+		}
+		pr.mu.Unlock()
+		row("broadcast", pr.name, len(lat), pr.want-len(lat), lat)
+	}
+	events := make([]string, 0, len(lt.acks))
+	for event := range lt.acks {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+	for _, event := range events {
+		row("ack", event, len(lt.acks[event]), lt.failures[event], lt.acks[event])
+	}
+	w.Flush()
+
+	var dropped int64
+	for _, p := range lt.players {
+		dropped += p.c.Dropped()
+	}
+	fmt.Fprintf(out, "\nevents dropped by full client buffers: %d\ndisconnects: %d\n", dropped, lt.failures["disconnect"])
+}
+
+func percentile(sorted []time.Duration, q float64) string {
+	if len(sorted) == 0 {
+		return "-"
+	}
+	i := int(math.Ceil(q*float64(len(sorted)))) - 1
+	return sorted[max(i, 0)].Round(time.Millisecond).String()
+}
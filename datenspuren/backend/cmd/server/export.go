@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+// exportCmd re-renders a session from its write-ahead log, e.g. one that was
+// exported with different settings or not at all.
+func exportCmd(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	var (
+		session   = fs.String("session", "", "Write-ahead log of the session (required)")
+		format    = fs.String("format", "text", `Output format: "text" (the export file), "json" or "csv"`)
+		out       = fs.String("out", "-", `Output file, "-" for stdout`)
+		anonymize = fs.Bool("anonymize", false, "Replace player names with pseudonyms")
+		redact    = fs.String("redact", "", "Comma-separated terms; answers containing one are omitted")
+		timezone  = fs.String("timezone", "", "Time zone of timestamps unless the session set its own (default: local time)")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s export --session FILE [options]\n\nOptions:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *session == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	s, err := game.LoadWAL(*session)
+	if err != nil {
+		fatalf("could not read %s: %v", *session, err)
+	}
+	opts := game.ExportOptions{Anonymize: *anonymize}
+	for _, term := range strings.Split(*redact, ",") {
+		if term = strings.TrimSpace(term); term != "" {
+			opts.Sensitive = append(opts.Sensitive, term)
+		}
+	}
+	if *timezone != "" {
+		if opts.Location, err = time.LoadLocation(*timezone); err != nil {
+			fatalf("invalid time zone %q: %v", *timezone, err)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fatalf("could not create %s: %v", *out, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	switch *format {
+	case "text":
+		var text string
+		if text, err = game.ExportText(s, opts); err == nil {
+			_, err = io.WriteString(w, text)
+		}
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(map[string]any{
+			"sessionCode": s.Code,
+			"config":      s.Config,
+			"summary":     s.RedactSummary(s.Summary(), opts),
+		})
+	case "csv":
+		err = game.ExportCSV(s, w, opts)
+	default:
+		fatalf("unknown format %q", *format)
+	}
+	if err != nil {
+		fatalf("could not export %s: %v", *session, err)
+	}
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -1,81 +1,194 @@
 package main
 
 import (
+    "context"
+    "errors"
     "flag"
     "fmt"
     "log"
+    "net"
     "net/http"
     "os"
+    "os/signal"
     "strings"
+    "syscall"
     "time"
+    _ "time/tzdata" // time zones for exports, even in minimal containers
 
     "github.com/gin-gonic/gin"
+    "github.com/kiliankoe/gptdash/internal/ai"
+    "github.com/kiliankoe/gptdash/internal/ai/deepl"
+    "github.com/kiliankoe/gptdash/internal/ai/mock"
     "github.com/kiliankoe/gptdash/internal/ai/openai"
+    "github.com/kiliankoe/gptdash/internal/buildinfo"
+    "github.com/kiliankoe/gptdash/internal/certs"
+    "github.com/kiliankoe/gptdash/internal/collector"
     "github.com/kiliankoe/gptdash/internal/ai/ollama"
     "github.com/kiliankoe/gptdash/internal/config"
     "github.com/kiliankoe/gptdash/internal/game"
+    "github.com/kiliankoe/gptdash/internal/moderation"
+    "github.com/kiliankoe/gptdash/internal/ratelimit"
+    "github.com/kiliankoe/gptdash/internal/routing"
+    "github.com/kiliankoe/gptdash/internal/store"
+    "github.com/kiliankoe/gptdash/internal/tracing"
+    "github.com/kiliankoe/gptdash/internal/tts"
     "github.com/kiliankoe/gptdash/internal/ws"
     staticserver "github.com/kiliankoe/gptdash/static"
     "github.com/rs/zerolog"
     zerologlog "github.com/rs/zerolog/log"
 )
 
-var version = "dev" // Set at build time via -ldflags
-
 func main() {
+    args := os.Args[1:]
+    cmd := "serve"
+    if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+        cmd, args = args[0], args[1:]
+    }
+    switch cmd {
+    case "serve":
+        serve(args)
+    case "export":
+        exportCmd(args)
+    case "simulate":
+        simulateCmd(args)
+    default:
+        fmt.Fprintf(os.Stderr, "unknown command %q, see %s --help\n", cmd, os.Args[0])
+        os.Exit(2)
+    }
+}
+
+// serve runs the game server until it is interrupted.
+func serve(args []string) {
+    fs := flag.NewFlagSet("serve", flag.ExitOnError)
     var (
-        showHelp    = flag.Bool("help", false, "Show help message")
-        showVersion = flag.Bool("version", false, "Show version information")
-        portFlag    = flag.String("port", "", "Port to listen on (overrides PORT env var)")
+        showHelp    = fs.Bool("help", false, "Show help message")
+        showVersion = fs.Bool("version", false, "Show version information")
+        portFlag    = fs.String("port", "", "Port to listen on (overrides PORT env var)")
+        demo        = fs.Bool("demo", false, "Play a scripted demo game with bots and a mock AI")
+        configFile  = fs.String("config", "", "YAML or TOML config file (overrides CONFIG_FILE env var)")
+        bots        = fs.Int("bots", 0, "Bot players joining every game hosts create, for testing")
     )
-    flag.BoolVar(showHelp, "h", false, "Show help message (shorthand)")
-    flag.BoolVar(showVersion, "v", false, "Show version information (shorthand)")
-    flag.Parse()
+    fs.BoolVar(showHelp, "h", false, "Show help message (shorthand)")
+    fs.BoolVar(showVersion, "v", false, "Show version information (shorthand)")
+    fs.Parse(args)
 
     if *showHelp {
         fmt.Printf(`GPTdash - Real-time AI party game
 
-Usage: %s [options]
+Usage: %s [serve] [options]
+       %s export --session FILE [options]
+       %s simulate [options]
+
+Commands:
+  serve           Run the game server (default)
+  export          Render a session's write-ahead log as text, JSON or CSV;
+                  see "export --help"
+  simulate        Play scripted games with bots against a running server;
+                  see "simulate --help"
 
 Options:
   -h, --help      Show this help message
   -v, --version   Show version information
   --port PORT     Port to listen on (default: 8080 or PORT env var)
+  --config FILE   YAML or TOML config file; environment variables override it
+  --demo          Play demo games with bots and a mock AI, no configuration
+                  or API key needed; WAL and exports are turned off
+  --bots N        Add N bot players to every game hosts create; they answer
+                  and vote at random, for testing phase logic
 
 Environment Variables:
+  CONFIG_FILE         YAML or TOML config file, see README (optional)
   PORT                Port to listen on (default: 8080)
-  DEFAULT_PROVIDER    AI provider: "openai" or "ollama" (default: openai)
+  TLS_CERT, TLS_KEY   Serve HTTPS with this certificate and key (optional)
+  ACME_DOMAINS        Serve HTTPS with Let's Encrypt certificates for these comma-separated hosts (optional)
+  ACME_EMAIL          Contact address for the ACME account (optional)
+  ACME_CACHE_DIR      Where ACME accounts and certificates are kept (default: ./gptdash-certs)
+  ACME_DIRECTORY_URL  ACME directory, e.g. Let's Encrypt staging (default: Let's Encrypt production)
+  HTTP_PORT           With TLS, plain HTTP port redirecting to HTTPS and answering ACME challenges (optional)
+  DEFAULT_PROVIDER    AI provider: "openai", "ollama", "mock" or "manual" (default: openai)
   DEFAULT_MODEL       AI model to use (default: gpt-3.5-turbo)
   OPENAI_API_KEY      OpenAI API key (required for OpenAI provider)
   OPENAI_BASE_URL     Custom OpenAI API base URL (optional)
   OLLAMA_HOST         Ollama host URL (default: http://localhost:11434)
+  AI_FALLBACK         Providers to fall back to when a session's one fails, e.g. "openai,ollama:llama3.1" (optional)
+  AI_RETRIES          Extra attempts per provider, with exponential backoff (default: 2)
+  AI_TIMEOUT          Seconds per attempt before trying again (default: 20)
   GM_USER             GM interface username for basic auth
   GM_PASS             GM interface password for basic auth
-  SINGLE_SESSION      Allow only one active session (default: true)
+  SINGLE_SESSION      Offer the latest hosted game on the join page; false runs several side by side, joined by code (default: true)
   EXPORT_ENABLED      Export game results to file (default: true)
-  EXPORT_FILE         Path to export game results (default: ./gptdash-results.txt)
+  EXPORT_DIR          Directory for per-session result files (default: ./gptdash-results)
+  EXPORT_FILE         Deprecated: without EXPORT_DIR, results go to this file's directory
+  EXPORT_STREAM_URL   POST a JSON document per completed round to this URL (optional)
+  EXPORT_STREAM_FILE  Append a JSON line per completed round to this file (optional)
+  EXPORT_ANONYMIZE    Replace player names with pseudonyms in exports (default: false)
+  EXPORT_REDACT_TERMS Comma-separated terms; answers containing one are omitted from exports
+  EXPORT_TIMEZONE     Time zone of export timestamps, e.g. Europe/Berlin (default: server local time)
+  PUBLIC_URL          Public frontend URL, used for join links on signage (optional)
+  SIGNAGE_WEBHOOK_URL POST the running game's signage info here when it changes (optional)
+  WEBHOOK_URL         POST signed JSON here when a round is scored and when a game ends (optional)
+  WEBHOOK_SECRET      Shared secret for the webhook's HMAC-SHA256 signature (required with WEBHOOK_URL)
+  PROFILES_FILE       Path to store player profiles (default: ./gptdash-profiles.json)
+  PROMPTS_FILE        Path to store imported prompt decks (default: ./gptdash-prompts.json)
+  PROMPT_STATS_FILE   Path to store per-prompt stats across exported sessions (default: EXPORT_DIR/prompt-stats.json)
+  ANSWER_POOL_FILE    JSON or YAML file of pre-written AI answers the host can pick from (optional)
+  WAL_ENABLED         Journal exported sessions to disk and recover them on startup (default: true)
+  WAL_DIR             Directory for session write-ahead logs (default: ./gptdash-wal)
+  SESSION_DB          Keep sessions in this SQLite database instead of the WAL and recover them on startup (optional)
+  INSTANCES           All instances of a multi-instance deployment: "id=url,..." (optional)
+  INSTANCE_ID         This instance's id in INSTANCES
+  ROUTING_MODE        Hand foreign sessions to their owner: "forward" or "redirect" (default: forward)
+  MAX_SESSIONS        Maximum number of running sessions (default: 0, unlimited)
+  MAX_CONNECTIONS     Maximum number of open sockets (default: 0, unlimited)
+  MAX_CONNECTIONS_PER_SESSION  Maximum sockets joined to one session (default: 0, unlimited)
+  EVENT_RATE_LIMIT    Joins, answers and votes per second a single connection may send; 0 = unlimited (default: 5)
+  EVENT_BURST         Such events a connection may send at once before the rate applies (default: 10)
+  JOIN_RATE_LIMIT     Join attempts per minute from one IP; 0 = unlimited (default: 120)
+  JOIN_BLOCK          How long an IP trying to join at twice that rate is blocked, e.g. 30m (default: 10m)
+  TRUSTED_PROXIES     Comma-separated IPs or CIDRs of reverse proxies whose X-Forwarded-For is trusted (default: none)
+  TOKEN_SECRET        Hand out host and player tokens as JWTs signed with this secret (default: bare tokens)
+  TOKEN_TTL           How long a signed token is valid; clients refresh theirs on reconnect (default: 24h)
+  OTEL_EXPORTER_OTLP_ENDPOINT  Send OpenTelemetry traces of socket events and AI calls to this OTLP/HTTP collector (optional)
+  SESSION_TTL         Close sessions without activity or connected clients for this long, e.g. 90m; 0 keeps them (default: 12h)
+  MAX_SUBMISSIONS_PER_ROUND    Answers stored per round, further ones are rejected (default: 1000)
+  MAX_VOTES_PER_ROUND          Votes stored per round, further ones are rejected (default: 1000)
+  MAX_QUEUED_PROMPTS           Prompts waiting in a session's queue (default: 200)
+  MAX_NOTES_PER_ROUND          Host notes kept per round, oldest dropped (default: 100)
+  MAX_CHEAT_LOG                Cheat audit entries kept per session, oldest dropped (default: 1000)
+  MAX_ROUND_HISTORY            Rounds kept in full; older rounds keep only vote tallies (default: 200)
+  POINTS_PER_VOTE     Points for every vote an answer draws (default: 2)
+  FOUND_AI_POINTS     Points for voting for the AI answer (default: 1)
+  FOOLED_BONUS        Points for drawing more votes than the AI, unless a session sets its own (default: 0)
+  CUE_THRESHOLDS      Seconds left on answer/vote timers that trigger sound/vibration cues (default: 30,10,0)
+  TRANSLATOR          Translate prompts/answers: "deepl", "openai" or "ollama" (default: off)
+  TRANSLATOR_MODEL    Model used by AI translators (default: DEFAULT_MODEL)
+  DEEPL_API_KEY       DeepL API key (required for the DeepL translator)
+  DEEPL_BASE_URL      Custom DeepL API base URL (default: https://api-free.deepl.com)
+  MODERATOR           Reject flagged prompts: "openai" (moderation endpoint) or "ollama" (default: off)
+  MODERATOR_MODEL     Model used by the ollama moderator (default: DEFAULT_MODEL)
+  WORDLIST_FILE       Blocked words for player names and answers, one per line (default: off)
+  WORDLIST_MODE       "replace" masks blocked words, "reject" refuses the name or answer (default: replace)
 
 Examples:
   %s                  Start server with default settings
   %s --port 3000      Start server on port 3000
+  %s --demo           Watch or join a demo game without any setup
+  %s export --session gptdash-wal/ABCD.wal --format csv
+                      Write a finished game's answers and votes as CSV
+  %s simulate --players 8 --rounds 3
+                      Play three rounds with eight bots against localhost:8080
   
 Visit http://localhost:8080 after starting the server.
-`, os.Args[0], os.Args[0], os.Args[0])
+`, os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
         return
     }
 
     if *showVersion {
-        fmt.Printf("GPTdash %s\n", version)
+        fmt.Printf("GPTdash %s\n", buildinfo.Get())
         return
     }
 
-    port := *portFlag
-    if port == "" {
-        port = os.Getenv("PORT")
-    }
-    if port == "" {
-        port = "8080"
-    }
+    startedAt := time.Now()
 
     zerolog.TimeFieldFormat = time.RFC3339
     cw := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
@@ -96,21 +209,275 @@ Visit http://localhost:8080 after starting the server.
         zerologlog.Info().Str("path", path).Int("status", status).Dur("dur", dur).Msg("http")
     })
 
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"ok": true, "time": time.Now().UTC()})
-	})
-
-    cfg := config.FromEnv()
+    if *configFile == "" {
+        *configFile = os.Getenv("CONFIG_FILE")
+    }
+    cfg, err := config.Load(*configFile)
+    if err != nil {
+        log.Fatal(err)
+    }
+    // only believe X-Forwarded-For from our own proxies, the same way the
+    // socket handlers do, see ws.Server.clientIP
+    r.RemoteIPHeaders = []string{"X-Forwarded-For"}
+    if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+        log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+    }
+    if cfg.ExportFile != "" {
+        zerologlog.Warn().Str("EXPORT_FILE", cfg.ExportFile).Str("EXPORT_DIR", cfg.ExportDir).Msg("EXPORT_FILE is deprecated, results are written to one file per session in EXPORT_DIR")
+    }
+    port := *portFlag
+    if port == "" {
+        port = cfg.Port
+    }
+    if *demo {
+        cfg.DefaultProvider = ws.DemoProvider
+        cfg.DefaultModel = ws.DemoProvider
+        cfg.WALEnabled = false
+        cfg.SessionDB = ""
+        cfg.ExportEnabled = false
+    }
+    traceShutdown := func(context.Context) error { return nil }
+    if cfg.Tracing {
+        if traceShutdown, err = tracing.Setup(context.Background(), buildinfo.Version); err != nil {
+            log.Fatal(err)
+        }
+    }
+    tlsConfig, plainHandler, err := certs.Setup(certs.Options{
+        CertFile:     cfg.TLSCert,
+        KeyFile:      cfg.TLSKey,
+        Domains:      cfg.ACMEDomains,
+        Email:        cfg.ACMEEmail,
+        CacheDir:     cfg.ACMECacheDir,
+        DirectoryURL: cfg.ACMEDirectory,
+    }, port)
+    if err != nil {
+        log.Fatal(err)
+    }
 
     rm := game.NewRoomManager()
+    rm.SetMaxSessions(cfg.MaxSessions)
+    rm.SetSingleSession(cfg.SingleSession)
+    rm.SetExportDefault(cfg.ExportEnabled)
+    rm.SetLimits(game.Limits{
+        Submissions:   cfg.MaxSubmissions,
+        Votes:         cfg.MaxVotes,
+        QueuedPrompts: cfg.MaxQueuedPrompts,
+        Notes:         cfg.MaxNotes,
+        CheatLog:      cfg.MaxCheatLog,
+        History:       cfg.MaxRoundHistory,
+    })
+    rm.SetScoring(game.Scoring{PerVote: cfg.PointsPerVote, FoundAI: cfg.FoundAIPoints}, cfg.FooledBonus)
+    if cfg.WordlistFile != "" {
+        filter, err := moderation.Load(cfg.WordlistFile, moderation.Mode(cfg.WordlistMode))
+        if err != nil {
+            log.Fatalf("invalid WORDLIST_FILE: %v", err)
+        }
+        rm.SetWordFilter(filter)
+    }
+    if cfg.Instances != "" {
+        ring, err := routing.Parse(cfg.InstanceID, cfg.Instances)
+        if err != nil {
+            log.Fatal(err)
+        }
+        if cfg.RoutingMode != routing.ModeForward && cfg.RoutingMode != routing.ModeRedirect {
+            log.Fatalf("invalid ROUTING_MODE %q", cfg.RoutingMode)
+        }
+        rm.SetCodeFilter(ring.Local)
+        r.Use(ring.Middleware(cfg.RoutingMode))
+        r.GET("/api/route/:code", ring.Handler())
+        zerologlog.Info().Str("instance", ring.Self().ID).Str("mode", cfg.RoutingMode).Msg("routing sessions across instances")
+    }
+    if cfg.SessionDB != "" {
+        st, err := store.Open(cfg.SessionDB)
+        if err != nil {
+            log.Fatal(err)
+        }
+        defer st.Close()
+        rm.EnableStore(st, func(code string, err error) {
+            zerologlog.Error().Err(err).Str("code", code).Msg("failed to save session")
+        })
+        codes, err := rm.RecoverStore()
+        if err != nil {
+            zerologlog.Error().Err(err).Msg("failed to recover some sessions from the session database")
+        }
+        if len(codes) > 0 {
+            zerologlog.Info().Strs("codes", codes).Msg("recovered sessions from the session database")
+        }
+    } else if cfg.WALEnabled {
+        if err := rm.EnableWAL(cfg.WALDir, func(code string, err error) {
+            zerologlog.Error().Err(err).Str("code", code).Msg("failed to write WAL")
+        }); err != nil {
+            log.Fatal(err)
+        }
+        codes, err := rm.RecoverWAL()
+        if err != nil {
+            zerologlog.Error().Err(err).Msg("failed to recover some sessions from WAL")
+        }
+        if len(codes) > 0 {
+            zerologlog.Info().Strs("codes", codes).Msg("recovered sessions from WAL")
+        }
+    }
     sock := ws.New(rm, cfg)
     oa := openai.New(cfg.OpenAIKey, cfg.OpenAIBaseURL)
     ol := ollama.New(cfg.OllamaHost)
-    sock.SetProvider(oa) // default fallback
-    sock.SetProviders(map[string]ws.AIProvider{"openai": oa, "ollama": ol})
-    sock.SetSystemPrompt(cfg.SystemPrompt)
+    backends := map[string]ai.Provider{"openai": oa, "ollama": ol}
+    chain, err := ai.ParseChain(cfg.AIFallback, backends)
+    if err != nil {
+        log.Fatal(err)
+    }
+    retry := ai.Fallback{Retries: cfg.AIRetries, Backoff: 500 * time.Millisecond, Timeout: time.Duration(cfg.AITimeout) * time.Second, OnFailure: func(link string, attempt int, err error) {
+        zerologlog.Warn().Err(err).Str("provider", link).Int("attempt", attempt).Msg("AI call failed")
+    }}
+    providers := map[string]ws.AIProvider{ws.DemoProvider: mock.New()}
+    for name, p := range backends {
+        providers[name] = ai.Chain(name, p, chain, retry)
+    }
+    // providers games use by default, pinged by /health?ai=1
+    aiHealth := &ai.Health{Providers: map[string]ai.Pinger{}, TTL: 30 * time.Second, Timeout: 5 * time.Second}
+    for _, l := range append([]ai.Link{{Name: strings.ToLower(cfg.DefaultProvider)}}, chain...) {
+        if p, ok := backends[l.Name].(ai.Pinger); ok {
+            aiHealth.Providers[l.Name] = p
+        }
+    }
+    sock.SetProvider(providers["openai"]) // default fallback
+    sock.SetProviders(providers)
+    autoBots := ws.Bots{Count: *bots, Delay: 5 * time.Second}
+    if *bots > 0 {
+        sock.SetAutoBots(autoBots)
+    }
+    var dl *deepl.Client
+    switch cfg.Translator {
+    case "":
+    case "deepl":
+        dl = deepl.New(cfg.DeepLKey, cfg.DeepLBaseURL)
+        sock.SetTranslator(dl)
+    case "openai":
+        sock.SetTranslator(ai.ProviderTranslator{Provider: oa, Model: cfg.TranslatorModel})
+    case "ollama":
+        sock.SetTranslator(ai.ProviderTranslator{Provider: ol, Model: cfg.TranslatorModel})
+    default:
+        log.Fatalf("unknown TRANSLATOR %q", cfg.Translator)
+    }
+    switch cfg.Moderator {
+    case "":
+    case "openai":
+        sock.SetModerator(oa)
+    case "ollama":
+        sock.SetModerator(ai.ProviderModerator{Provider: ol, Model: cfg.ModeratorModel})
+    default:
+        log.Fatalf("unknown MODERATOR %q", cfg.Moderator)
+    }
+    switch cfg.ImageProvider {
+    case "":
+    case "openai":
+        sock.SetImageProvider(oa, cfg.ImageModel)
+    default:
+        log.Fatalf("unknown IMAGE_PROVIDER %q", cfg.ImageProvider)
+    }
+    switch cfg.TTS {
+    case "":
+    case "openai":
+        sock.SetSpeaker(tts.NewCache(tts.NewOpenAI(cfg.OpenAIKey, cfg.OpenAIBaseURL, cfg.TTSModel, cfg.TTSVoice), 64))
+    case "command":
+        cmd, err := tts.ParseCommand(cfg.TTSCommand)
+        if err != nil {
+            log.Fatalf("invalid TTS_COMMAND: %v", err)
+        }
+        sock.SetSpeaker(tts.NewCache(cmd, 64))
+    default:
+        log.Fatalf("unknown TTS %q", cfg.TTS)
+    }
+    if cfg.ExportTimeZone != "" {
+        if _, err := time.LoadLocation(cfg.ExportTimeZone); err != nil {
+            log.Fatalf("invalid EXPORT_TIMEZONE %q: %v", cfg.ExportTimeZone, err)
+        }
+    }
+    var collectors collector.Multi
+    if cfg.StreamURL != "" {
+        collectors = append(collectors, collector.NewHTTP(cfg.StreamURL))
+    }
+    if cfg.StreamFile != "" {
+        collectors = append(collectors, collector.NewNDJSON(cfg.StreamFile))
+    }
+    if len(collectors) > 0 {
+        sock.SetCollector(collectors)
+    }
+    if cfg.SignageWebhook != "" {
+        sock.SetSignageHook(collector.NewHTTP(cfg.SignageWebhook))
+    }
+    var hook *game.Webhook
+    if cfg.WebhookURL != "" {
+        if cfg.WebhookSecret == "" {
+            log.Fatal("WEBHOOK_URL needs a WEBHOOK_SECRET to sign deliveries with")
+        }
+        hook = game.NewWebhook(cfg.WebhookURL, cfg.WebhookSecret)
+        hook.OnError = func(code string, err error) {
+            zerologlog.Error().Err(err).Str("code", code).Msg("failed to deliver webhook")
+        }
+        sock.SetWebhook(hook)
+    }
+    profiles, err := game.LoadProfiles(cfg.ProfilesFile)
+    if err != nil {
+        log.Fatal(err)
+    }
+    sock.SetProfiles(profiles)
+    library, err := game.LoadPromptLibrary(cfg.PromptsFile)
+    if err != nil {
+        log.Fatal(err)
+    }
+    sock.SetPromptLibrary(library)
+    promptStats, err := game.LoadPromptStats(cfg.PromptStatsFile)
+    if err != nil {
+        log.Fatal(err)
+    }
+    sock.SetPromptStats(promptStats)
+    if cfg.AnswerPoolFile != "" {
+        pool, err := game.LoadAnswerPool(cfg.AnswerPoolFile)
+        if err != nil {
+            log.Fatal(err)
+        }
+        sock.SetAnswerPool(pool)
+        zerologlog.Info().Int("answers", pool.Len()).Msg("loaded answer pool")
+    }
     io := sock.Mount(r)
     defer io.Close()
+    // recovered sessions may be mid-phase, their timers keep running
+    sock.ResumeTimers()
+
+	r.GET("/health", func(c *gin.Context) {
+		total, running := rm.SessionCount()
+		status := http.StatusOK
+		body := gin.H{
+			"ok":             true,
+			"time":           time.Now().UTC(),
+			"version":        buildinfo.Version,
+			"uptimeSeconds":  int(time.Since(startedAt).Seconds()),
+			"sessions":       total,
+			"activeSessions": running,
+			"clients":        io.Count(),
+			"provider":       cfg.DefaultProvider,
+			"model":          cfg.DefaultModel,
+			"translator":     cfg.Translator,
+		}
+		// ?ai=1 also checks that the AI providers are reachable and
+		// accept their keys, failing the check if one isn't
+		if c.Query("ai") != "" {
+			results, ok := aiHealth.Check(c.Request.Context())
+			errs := gin.H{}
+			for name, err := range results {
+				if err != nil {
+					errs[name] = err.Error()
+				}
+			}
+			body["ai"] = "ok"
+			if !ok {
+				body["ai"] = "degraded"
+				body["aiErrors"] = errs
+				status = http.StatusServiceUnavailable
+			}
+		}
+		c.JSON(status, body)
+	})
 
     // Host-protected routes (serves the SPA index behind basic auth)
     if cfg.GMUser != "" && cfg.GMPass != "" {
@@ -123,14 +490,78 @@ Visit http://localhost:8080 after starting the server.
         })
     }
 
-    // Minimal API for active session and GM create
+    // Minimal API for the session the join page offers and GM create
     r.GET("/api/session/active", func(c *gin.Context) {
         if code, sess := rm.Active(); sess != nil {
-            c.JSON(http.StatusOK, gin.H{"sessionCode": code})
+            c.JSON(http.StatusOK, gin.H{"sessionCode": code, "joinPin": sess.JoinPin})
             return
         }
         c.Status(http.StatusNotFound)
     })
+    // Read-only state for overlays and info screens (no socket needed)
+    r.GET("/api/session/:code/state", func(c *gin.Context) {
+        sess, err := rm.Lookup(c.Param("code"))
+        if err != nil {
+            c.JSON(http.StatusNotFound, gin.H{"error": "session_not_found"})
+            return
+        }
+        c.JSON(http.StatusOK, sess.PublicState())
+    })
+    // Pre-join lobby info, polled by the join page
+    lobbyLimit := ratelimit.New(60, time.Minute)
+    r.GET("/api/session/:code/lobby", sock.JoinGuard(), func(c *gin.Context) {
+        if !lobbyLimit.Allow(c.ClientIP()) {
+            c.JSON(http.StatusTooManyRequests, gin.H{"error": "rate_limited"})
+            return
+        }
+        sess, err := rm.Lookup(c.Param("code"))
+        if err != nil {
+            c.JSON(http.StatusNotFound, gin.H{"error": "session_not_found"})
+            return
+        }
+        c.JSON(http.StatusOK, sock.Lobby(sess))
+    })
+    // Join link as QR code for the projector and host screens
+    r.GET("/api/session/:code/qr.png", sock.QRHandler())
+    // Short join links for QR codes and saying out loud
+    r.GET("/j/:code", sock.JoinHandler())
+    // Generated pictures of image rounds
+    r.GET("/api/session/:code/images/:roundId", sock.ImageHandler())
+    // Answers read aloud for the host view, see TTS
+    r.GET("/api/session/:code/tts/:submissionId", sock.TTSHandler())
+    // Running game info for venue signage, also pushed to SIGNAGE_WEBHOOK_URL
+    r.GET("/api/signage", sock.SignageHandler())
+    // Minimal host remote for phones, backed by the API below
+    r.GET("/host/simple", sock.SimpleHostHandler())
+    // Token-authenticated SSE feed for stream overlays
+    r.GET("/api/session/:code/overlay", sock.OverlayHandler())
+    // Game events over SSE with actions as plain POSTs, for venues whose
+    // proxies break Socket.IO
+    r.GET("/api/session/:code/events", sock.JoinGuard(), sock.EventsHandler())
+    r.POST("/api/session/:code/events/:sid/:event", sock.ActionHandler())
+    // Connect/gRPC API for companion tools; cleartext HTTP/2 for gRPC clients
+    // is scoped to it, see ws.ScopeH2C
+    sock.MountAPI(r)
+    // Public session browser (only sessions created with "public": true)
+    browseLimit := ratelimit.New(10, time.Minute)
+    r.GET("/api/sessions/public", func(c *gin.Context) {
+        if !browseLimit.Allow(c.ClientIP()) {
+            c.JSON(http.StatusTooManyRequests, gin.H{"error": "rate_limited"})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{"sessions": rm.PublicSessions()})
+    })
+    r.GET("/api/version", func(c *gin.Context) {
+        c.JSON(http.StatusOK, buildinfo.Get())
+    })
+    r.GET("/api/profiles/:name", func(c *gin.Context) {
+        p, err := profiles.Get(c.Param("name"))
+        if err != nil {
+            c.JSON(http.StatusNotFound, gin.H{"error": "profile_not_found"})
+            return
+        }
+        c.JSON(http.StatusOK, p)
+    })
     if cfg.GMUser != "" && cfg.GMPass != "" {
         auth := gin.BasicAuth(gin.Accounts{cfg.GMUser: cfg.GMPass})
         type createReq struct{ Config game.SessionConfig `json:"config"` }
@@ -140,9 +571,39 @@ Visit http://localhost:8080 after starting the server.
                 c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_config"})
                 return
             }
-            code, hostToken, _ := rm.CreateSession(req.Config)
-            c.JSON(http.StatusOK, gin.H{"sessionCode": code, "hostToken": hostToken})
+            code, hostToken, err := rm.CreateSession(req.Config)
+            if errors.Is(err, game.ErrTooManySessions) {
+                c.Header("Retry-After", "60")
+                c.JSON(http.StatusServiceUnavailable, gin.H{"error": "server_full"})
+                return
+            } else if errors.Is(err, game.ErrInvalidTimeZone) || errors.Is(err, game.ErrInvalidTrigger) {
+                c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_config", "message": err.Error()})
+                return
+            } else if err != nil {
+                c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+                return
+            }
+            if *bots > 0 {
+                sock.AddBots(code, autoBots)
+            }
+            sess, _ := rm.Get(code)
+            c.JSON(http.StatusOK, gin.H{"sessionCode": code, "joinPin": sess.JoinPin, "hostToken": sock.ClientToken(code, "host", "", hostToken), "overlayToken": sess.OverlayToken, "seed": sess.ShuffleSeed()})
         })
+        // All sessions of this instance with their phase, players and sockets
+        r.GET("/api/sessions", auth, sock.SessionsHandler())
+        // Inspecting and force-closing sessions at runtime
+        r.GET("/api/admin/sessions", auth, sock.SessionsHandler())
+        r.GET("/api/admin/sessions/:code", auth, sock.AdminSessionHandler())
+        r.DELETE("/api/admin/sessions/:code", auth, sock.CloseSessionHandler())
+        // Active sockets per session, and force-disconnecting ghost connections
+        r.GET("/api/host/sessions/:code/connections", auth, sock.ConnectionsHandler())
+        r.DELETE("/api/host/sessions/:code/connections/:sid", auth, sock.DisconnectHandler())
+        // Prompt decks (CSV or JSON) for the library or, with ?session=CODE, one session's queue
+        r.POST("/api/gm/prompts/import", auth, sock.PromptImportHandler())
+        // How each prompt fared so far, to retire the ones players have figured out
+        r.GET("/api/gm/prompts/stats", auth, sock.PromptStatsHandler())
+        // Bot players for a session, to test a setup or demo without a crowd
+        r.POST("/api/debug/bots", auth, sock.BotsHandler())
     }
 
     // Serve frontend (if embedded build is present) for all other routes
@@ -150,8 +611,106 @@ Visit http://localhost:8080 after starting the server.
         staticserver.Handler().ServeHTTP(c.Writer, c.Request)
     })
 
-    log.Printf("listening on :%s", port)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatal(err)
-	}
+    if *demo {
+        go sock.RunDemo(context.Background(), ws.Demo{Rounds: 3, AnswerTime: 30 * time.Second, VoteTime: 20 * time.Second, Pause: 8 * time.Second})
+        zerologlog.Info().Msgf("demo mode: join the game at http://localhost:%s", port)
+    }
+    // On SIGINT/SIGTERM tell clients, stop taking requests, then export and
+    // snapshot the games still running so a redeploy mid-event doesn't lose
+    // their results
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+    if cfg.SessionTTL > 0 {
+        rm.StartReaper(ctx, cfg.SessionTTL, sock.ClientCount, sock.CloseExpired)
+    }
+    // Requests that stay open, like event streams and overlays, end with this
+    streams, closeStreams := context.WithCancel(context.Background())
+    server := &http.Server{Addr: ":" + port, Handler: ws.ScopeH2C(r.Handler()), TLSConfig: tlsConfig, BaseContext: func(net.Listener) context.Context { return streams }}
+    go func() {
+        var err error
+        if tlsConfig != nil {
+            log.Printf("listening on :%s (HTTPS)", port)
+            err = server.ListenAndServeTLS("", "")
+        } else {
+            log.Printf("listening on :%s", port)
+            err = server.ListenAndServe()
+        }
+        if err != nil && !errors.Is(err, http.ErrServerClosed) {
+            log.Fatal(err)
+        }
+    }()
+    // SIGHUP reloads provider keys, the system prompt, the prompt library and
+    // the wordlist; running sessions carry on
+    reload := func() error {
+        next, err := config.Load(*configFile)
+        if err != nil {
+            return err
+        }
+        var filter *moderation.Filter
+        if next.WordlistFile != "" {
+            if filter, err = moderation.Load(next.WordlistFile, moderation.Mode(next.WordlistMode)); err != nil {
+                return fmt.Errorf("invalid WORDLIST_FILE: %w", err)
+            }
+        }
+        if err := library.Reload(); err != nil {
+            return err
+        }
+        rm.SetWordFilter(filter)
+        rm.SetExportDefault(next.ExportEnabled)
+        oa.SetCredentials(next.OpenAIKey, next.OpenAIBaseURL)
+        ol.SetHost(next.OllamaHost)
+        if dl != nil {
+            dl.SetCredentials(next.DeepLKey, next.DeepLBaseURL)
+        }
+        sock.Reload(next)
+        return nil
+    }
+    hup := make(chan os.Signal, 1)
+    signal.Notify(hup, syscall.SIGHUP)
+    go func() {
+        for range hup {
+            if *demo {
+                zerologlog.Warn().Msg("demo mode doesn't reload its configuration")
+            } else if err := reload(); err != nil {
+                zerologlog.Error().Err(err).Msg("failed to reload configuration, keeping the current one")
+            } else {
+                zerologlog.Info().Msg("reloaded configuration")
+            }
+        }
+    }()
+    var plain *http.Server
+    if tlsConfig != nil && cfg.HTTPPort != "" {
+        plain = &http.Server{Addr: ":" + cfg.HTTPPort, Handler: plainHandler}
+        go func() {
+            log.Printf("redirecting :%s to HTTPS", cfg.HTTPPort)
+            if err := plain.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+                log.Fatal(err)
+            }
+        }()
+    }
+    <-ctx.Done()
+    stop()
+    zerologlog.Info().Msg("shutting down")
+    shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+    defer cancel()
+    sock.Shutdown()
+    closeStreams()
+    if err := server.Shutdown(shutdownCtx); err != nil {
+        zerologlog.Error().Err(err).Msg("failed to shut down HTTP server cleanly")
+    }
+    if plain != nil {
+        plain.Shutdown(shutdownCtx)
+    }
+    sock.ExportRunning(shutdownCtx)
+    if hook != nil {
+        if err := hook.Close(shutdownCtx); err != nil {
+            zerologlog.Error().Err(err).Msg("failed to deliver all queued webhooks")
+        }
+    }
+    rm.Checkpoint()
+    // the session database closes when main returns, save what's queued first
+    rm.FlushStore()
+    if err := traceShutdown(shutdownCtx); err != nil {
+        zerologlog.Error().Err(err).Msg("failed to flush traces")
+    }
 }
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/kiliankoe/gptdash/internal/sioclient"
+)
+
+var simPrompts = []string{
+	"Warum ist der Himmel blau?",
+	"Was ist das Geheimnis eines guten Kuchens?",
+	"Wie überzeugt man eine Katze, vom Tisch zu gehen?",
+	"Was macht ein Pinguin in seiner Freizeit?",
+	"Warum gibt es Montage?",
+}
+
+// simulateCmd plays scripted games against a running server over the same
+// socket protocol as the browser clients: a host and a number of players who
+// answer and vote at random. It exits non-zero as soon as a step fails, which
+// makes it a smoke test for deployments.
+func simulateCmd(args []string) {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	var (
+		url      = fs.String("url", "http://localhost:8080", "Server to play against")
+		players  = fs.Int("players", 4, "Number of simulated players")
+		rounds   = fs.Int("rounds", 3, "Rounds to play")
+		provider = fs.String("provider", "mock", `AI provider of the session; "manual" skips the AI answer`)
+		model    = fs.String("model", "", "AI model of the session (default: the provider's)")
+		think    = fs.Duration("think", 500*time.Millisecond, "Longest pause before a player answers or votes")
+		timeout  = fs.Duration("timeout", 2*time.Minute, "Give up when the game takes longer than this")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s simulate [options]\n\nOptions:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *players < 1 || *rounds < 1 {
+		fatalf("need at least one player and one round")
+	}
+	if *model == "" {
+		*model = *provider
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	sim := &simulation{url: *url, think: *think}
+	start := time.Now()
+	if err := sim.play(ctx, *players, *rounds, game.SessionConfig{Provider: *provider, Model: *model, RoundCount: *rounds}); err != nil {
+		fatalf("simulation failed: %v", err)
+	}
+	fmt.Printf("played %d rounds with %d players in %s\n", *rounds, *players, time.Since(start).Round(time.Millisecond))
+}
+
+type simulation struct {
+	url   string
+	think time.Duration
+}
+
+type simPlayer struct {
+	name string
+	c    *sioclient.Client
+	sub  string // submission of the current round
+}
+
+func (sim *simulation) play(ctx context.Context, players, rounds int, cfg game.SessionConfig) error {
+	host, err := sioclient.Dial(ctx, sim.url, 1024)
+	if err != nil {
+		return fmt.Errorf("host could not connect: %w", err)
+	}
+	defer host.Close()
+	ack, err := emit(ctx, host, "game:create", map[string]any{"config": cfg})
+	if err != nil {
+		return err
+	}
+	code, _ := ack["sessionCode"].(string)
+	fmt.Printf("created session %s\n", code)
+
+	ps := make([]*simPlayer, players)
+	for i := range ps {
+		p := &simPlayer{name: fmt.Sprintf("Bot %d", i+1)}
+		if p.c, err = sioclient.Dial(ctx, sim.url, 1024); err != nil {
+			return fmt.Errorf("%s could not connect: %w", p.name, err)
+		}
+		defer p.c.Close()
+		if _, err := emit(ctx, p.c, "game:join", map[string]any{"sessionCode": code, "name": p.name}); err != nil {
+			return fmt.Errorf("%s: %w", p.name, err)
+		}
+		ps[i] = p
+	}
+
+	for round := 1; round <= rounds; round++ {
+		prompt := simPrompts[(round-1)%len(simPrompts)]
+		if _, err := emit(ctx, host, "game:setPrompt", map[string]any{"prompt": prompt}); err != nil {
+			return fmt.Errorf("round %d: %w", round, err)
+		}
+		if cfg.Provider != "manual" {
+			if _, err := host.WaitFor(ctx, "game:aiAnswer", nil); err != nil {
+				return fmt.Errorf("round %d: %w", round, err)
+			}
+		}
+
+		err := eachPlayer(ctx, ps, func(ctx context.Context, p *simPlayer) error {
+			sim.pause(ctx)
+			ack, err := emit(ctx, p.c, "game:submit", map[string]any{"text": fmt.Sprintf("%s sagt: %s", p.name, prompt)})
+			if err != nil {
+				return fmt.Errorf("%s: %w", p.name, err)
+			}
+			p.sub, _ = ack["submissionId"].(string)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("round %d: %w", round, err)
+		}
+
+		if _, err := emit(ctx, host, "game:advance", nil); err != nil {
+			return fmt.Errorf("round %d: %w", round, err)
+		}
+		err = eachPlayer(ctx, ps, func(ctx context.Context, p *simPlayer) error {
+			ev, err := p.c.WaitFor(ctx, "game:voting", nil)
+			if err != nil {
+				return fmt.Errorf("%s: %w", p.name, err)
+			}
+			var voting struct {
+				Submissions []struct {
+					ID        string   `json:"id"`
+					MergedIDs []string `json:"mergedIds"`
+				} `json:"submissions"`
+			}
+			if err := ev.Decode(&voting); err != nil {
+				return err
+			}
+			var options []string
+			for _, s := range voting.Submissions {
+				own := s.ID == p.sub
+				for _, id := range s.MergedIDs {
+					own = own || id == p.sub
+				}
+				if !own {
+					options = append(options, s.ID)
+				}
+			}
+			if len(options) == 0 {
+				return nil
+			}
+			sim.pause(ctx)
+			if _, err := emit(ctx, p.c, "game:vote", map[string]any{"submissionId": options[rand.Intn(len(options))]}); err != nil {
+				return fmt.Errorf("%s: %w", p.name, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("round %d: %w", round, err)
+		}
+
+		if _, err := emit(ctx, host, "game:advance", nil); err != nil {
+			return fmt.Errorf("round %d: %w", round, err)
+		}
+		ev, err := host.WaitFor(ctx, "game:results", nil)
+		if err != nil {
+			return fmt.Errorf("round %d: %w", round, err)
+		}
+		var results struct {
+			AISubmissionID string `json:"aiSubmissionId"`
+		}
+		ev.Decode(&results)
+		fmt.Printf("round %d scored (AI answer %s)\n", round, results.AISubmissionID)
+	}
+
+	if _, err := emit(ctx, host, "game:advance", nil); err != nil {
+		return err
+	}
+	ev, err := host.WaitFor(ctx, "game:summary", nil)
+	if err != nil {
+		return err
+	}
+	var summary game.GameSummary
+	if err := ev.Decode(&summary); err != nil {
+		return err
+	}
+	fmt.Printf("%d votes, %d found the AI\n", summary.TotalVotes, summary.AIVotes)
+	return nil
+}
+
+// eachPlayer runs fn for all players at once and returns the first error;
+// the others are canceled then.
+func eachPlayer(ctx context.Context, ps []*simPlayer, fn func(context.Context, *simPlayer) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for _, p := range ps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(ctx, p); err != nil {
+				once.Do(func() { firstErr = err; cancel() })
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// pause waits a random time up to sim.think, so players don't act in
+// lockstep.
+func (sim *simulation) pause(ctx context.Context) {
+	if sim.think <= 0 {
+		return
+	}
+	select {
+	case <-time.After(time.Duration(rand.Int63n(int64(sim.think)))):
+	case <-ctx.Done():
+	}
+}
+
+// emit sends an event and turns an error ack into an error.
+func emit(ctx context.Context, c *sioclient.Client, name string, payload any) (map[string]any, error) {
+	ack, err := c.Emit(ctx, name, payload)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if ack["error"] != nil {
+		b, _ := json.Marshal(ack["error"])
+		return nil, fmt.Errorf("%s: %s", name, b)
+	}
+	return ack, nil
+}
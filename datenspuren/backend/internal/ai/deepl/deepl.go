@@ -0,0 +1,74 @@
+package deepl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Client struct {
+	APIKey  string
+	BaseURL string
+	http    *http.Client
+	mu      sync.RWMutex // guards APIKey and BaseURL, see SetCredentials
+}
+
+func New(apiKey, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = "https://api-free.deepl.com"
+	}
+	return &Client{APIKey: apiKey, BaseURL: strings.TrimRight(baseURL, "/"), http: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// SetCredentials swaps the API key and base URL, e.g. on a config reload.
+func (c *Client) SetCredentials(apiKey, baseURL string) {
+	if baseURL == "" {
+		baseURL = "https://api-free.deepl.com"
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.APIKey, c.BaseURL = apiKey, strings.TrimRight(baseURL, "/")
+}
+
+func (c *Client) Translate(ctx context.Context, text string, targetLang string) (string, error) {
+	c.mu.RLock()
+	apiKey, baseURL := c.APIKey, c.BaseURL
+	c.mu.RUnlock()
+	if apiKey == "" {
+		return "", errors.New("missing DEEPL_API_KEY")
+	}
+	payload := map[string]any{
+		"text":        []string{text},
+		"target_lang": strings.ToUpper(targetLang),
+	}
+	b, _ := json.Marshal(payload)
+	req, _ := http.NewRequestWithContext(ctx, "POST", baseURL+"/v2/translate", bytes.NewReader(b))
+	req.Header.Set("Authorization", "DeepL-Auth-Key "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("deepl status %d", resp.StatusCode)
+	}
+	var out struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	if len(out.Translations) == 0 {
+		return "", errors.New("no translations")
+	}
+	return strings.TrimSpace(out.Translations[0].Text), nil
+}
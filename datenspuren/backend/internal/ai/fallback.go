@@ -0,0 +1,158 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Link is one provider of a fallback chain. Model replaces the requested
+// model for this provider, e.g. an Ollama model when falling back from
+// OpenAI; empty keeps the requested one.
+type Link struct {
+	Name     string
+	Provider Provider
+	Model    string
+}
+
+// Fallback tries its links in order until one answers. Each link gets
+// Retries more attempts after the first, waiting Backoff before the first
+// retry and twice as long before each further one. An attempt fails on an
+// error, an empty answer or after Timeout.
+type Fallback struct {
+	Links   []Link
+	Retries int
+	Backoff time.Duration
+	Timeout time.Duration // per attempt, 0 = only the caller's context
+
+	// OnFailure is told about every failed attempt, e.g. for logging.
+	OnFailure func(link string, attempt int, err error)
+}
+
+// ParseChain reads a fallback order like "openai,ollama:llama3.1" into
+// links to the named providers.
+func ParseChain(spec string, providers map[string]Provider) ([]Link, error) {
+	var links []Link
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, model, _ := strings.Cut(part, ":")
+		name = strings.ToLower(name)
+		p := providers[name]
+		if p == nil {
+			return nil, fmt.Errorf("unknown AI provider %q in fallback chain", name)
+		}
+		links = append(links, Link{Name: name, Provider: p, Model: model})
+	}
+	return links, nil
+}
+
+// Chain returns a copy of opts that tries p with the requested model first
+// and then the links of chain that aren't p.
+func Chain(name string, p Provider, chain []Link, opts Fallback) *Fallback {
+	f := opts
+	f.Links = []Link{{Name: name, Provider: p}}
+	for _, l := range chain {
+		if l.Name != name {
+			f.Links = append(f.Links, l)
+		}
+	}
+	return &f
+}
+
+func (f *Fallback) Complete(ctx context.Context, model string, prompt string) (string, error) {
+	return f.CompleteWithSystem(ctx, model, "", prompt)
+}
+
+func (f *Fallback) CompleteWithSystem(ctx context.Context, model string, systemPrompt string, prompt string) (string, error) {
+	out, err := f.CompleteDetailed(ctx, model, systemPrompt, prompt)
+	return out.Text, err
+}
+
+// CompleteDetailed returns the first answer along the chain, with Provider
+// set to the link that gave it.
+func (f *Fallback) CompleteDetailed(ctx context.Context, model string, systemPrompt string, prompt string) (Completion, error) {
+	var errs []error
+	for _, l := range f.Links {
+		m := model
+		if l.Model != "" {
+			m = l.Model
+		}
+		wait := f.Backoff
+		for attempt := 1; attempt <= f.Retries+1; attempt++ {
+			if attempt > 1 && !sleep(ctx, wait) {
+				return Completion{}, ctx.Err()
+			}
+			if attempt > 1 {
+				wait *= 2
+			}
+			out, err := f.attempt(ctx, l, attempt, m, systemPrompt, prompt)
+			if err == nil {
+				if out.Provider == "" {
+					out.Provider = l.Name
+				}
+				if out.Model == "" {
+					out.Model = m
+				}
+				return out, nil
+			}
+			if ctx.Err() != nil {
+				// the caller gave up, e.g. the round was reset
+				return Completion{}, ctx.Err()
+			}
+			if f.OnFailure != nil {
+				f.OnFailure(l.Name, attempt, err)
+			}
+			errs = append(errs, fmt.Errorf("%s attempt %d: %w", l.Name, attempt, err))
+		}
+	}
+	if len(errs) == 0 {
+		return Completion{}, errors.New("no AI provider configured")
+	}
+	return Completion{}, fmt.Errorf("all AI providers failed: %w", errors.Join(errs...))
+}
+
+// attempt asks a link once, traced as a span of its own so retries and
+// fallbacks show up with their timing.
+func (f *Fallback) attempt(ctx context.Context, l Link, n int, model, systemPrompt, prompt string) (Completion, error) {
+	if f.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.Timeout)
+		defer cancel()
+	}
+	ctx, span := tracer.Start(ctx, "ai.attempt", trace.WithAttributes(
+		attribute.String("ai.provider", l.Name),
+		attribute.String("ai.model", model),
+		attribute.Int("ai.attempt", n),
+	))
+	defer span.End()
+	out, err := l.Provider.CompleteDetailed(ctx, model, systemPrompt, prompt)
+	if err == nil && out.Text == "" {
+		err = errors.New("empty answer")
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return out, err
+}
+
+// sleep waits for d and reports whether ctx is still alive afterwards.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
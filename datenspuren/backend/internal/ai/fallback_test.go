@@ -0,0 +1,92 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// flaky fails its first failures calls, then answers.
+type flaky struct {
+	failures int
+	calls    int
+	models   []string
+}
+
+func (f *flaky) Complete(ctx context.Context, model string, prompt string) (string, error) {
+	return f.CompleteWithSystem(ctx, model, "", prompt)
+}
+
+func (f *flaky) CompleteWithSystem(ctx context.Context, model string, systemPrompt string, prompt string) (string, error) {
+	out, err := f.CompleteDetailed(ctx, model, systemPrompt, prompt)
+	return out.Text, err
+}
+
+func (f *flaky) CompleteDetailed(ctx context.Context, model string, systemPrompt string, prompt string) (Completion, error) {
+	f.calls++
+	f.models = append(f.models, model)
+	if f.calls <= f.failures {
+		return Completion{}, errors.New("unavailable")
+	}
+	return Completion{Text: "answer from " + model}, nil
+}
+
+// hanging never answers until the context is done.
+type hanging struct{ flaky }
+
+func (h *hanging) CompleteDetailed(ctx context.Context, model string, systemPrompt string, prompt string) (Completion, error) {
+	h.calls++
+	<-ctx.Done()
+	return Completion{}, ctx.Err()
+}
+
+func TestFallback(t *testing.T) {
+	primary := &flaky{failures: 1}
+	secondary := &flaky{}
+	chain, err := ParseChain("openai, ollama:llama3.1", map[string]Provider{"openai": primary, "ollama": secondary})
+	if err != nil {
+		t.Fatalf("should be able to parse the chain: %v", err)
+	}
+	if _, err := ParseChain("openai,bogus", map[string]Provider{"openai": primary}); err == nil {
+		t.Fatal("expected an error for an unknown provider")
+	}
+	opts := Fallback{Retries: 1, Backoff: time.Millisecond}
+
+	out, err := Chain("openai", primary, chain, opts).CompleteDetailed(context.Background(), "gpt-4o", "", "Q?")
+	if err != nil || out.Provider != "openai" || out.Text != "answer from gpt-4o" {
+		t.Fatalf("expected the retry to succeed with openai, got %+v (%v)", out, err)
+	}
+	if primary.calls != 2 || secondary.calls != 0 {
+		t.Fatalf("expected 2 calls to openai and none to ollama, got %d and %d", primary.calls, secondary.calls)
+	}
+
+	primary = &flaky{failures: 10}
+	var failures []string
+	opts.OnFailure = func(link string, attempt int, err error) { failures = append(failures, link) }
+	out, err = Chain("openai", primary, []Link{{Name: "openai", Provider: primary}, {Name: "ollama", Provider: secondary, Model: "llama3.1"}}, opts).
+		CompleteDetailed(context.Background(), "gpt-4o", "", "Q?")
+	if err != nil || out.Provider != "ollama" || out.Model != "llama3.1" {
+		t.Fatalf("expected ollama to answer with its own model, got %+v (%v)", out, err)
+	}
+	if primary.calls != 2 || len(failures) != 2 {
+		t.Fatalf("expected openai to be tried twice and only once in the chain, got %d calls and failures %v", primary.calls, failures)
+	}
+
+	slow := &hanging{}
+	opts = Fallback{Retries: 0, Timeout: 20 * time.Millisecond}
+	if _, err := Chain("ollama", slow, nil, opts).CompleteDetailed(context.Background(), "m", "", "Q?"); err == nil {
+		t.Fatal("expected a timeout to fail the chain")
+	}
+
+	// a cancelled caller stops the chain right away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	slow = &hanging{}
+	if _, err := Chain("ollama", slow, chain, Fallback{Retries: 3}).CompleteDetailed(ctx, "m", "", "Q?"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if slow.calls != 1 {
+		t.Fatalf("expected no retries after cancellation, got %d calls", slow.calls)
+	}
+}
@@ -0,0 +1,68 @@
+package ai
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Pinger is implemented by providers that can cheaply tell whether they are
+// reachable and accept their credentials, e.g. by listing their models.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Health pings providers for the health endpoint. Results are kept for TTL,
+// so monitoring that polls every few seconds doesn't turn into API traffic
+// of its own.
+type Health struct {
+	Providers map[string]Pinger
+	TTL       time.Duration
+	Timeout   time.Duration // per ping
+
+	mu      sync.Mutex
+	checked time.Time
+	last    map[string]error
+}
+
+// Check pings every provider at once, or returns the previous results if
+// they are recent enough. The map has an entry per provider, nil for the
+// reachable ones; ok is whether all of them are.
+func (h *Health) Check(ctx context.Context) (results map[string]error, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.last == nil || time.Since(h.checked) >= h.TTL {
+		h.last = h.ping(ctx)
+		h.checked = time.Now()
+	}
+	ok = true
+	for _, err := range h.last {
+		ok = ok && err == nil
+	}
+	return h.last, ok
+}
+
+func (h *Health) ping(ctx context.Context) map[string]error {
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		out = make(map[string]error, len(h.Providers))
+	)
+	for name, p := range h.Providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := p.Ping(ctx)
+			mu.Lock()
+			out[name] = err
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return out
+}
@@ -0,0 +1,38 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type pinger struct {
+	err   error
+	calls int
+}
+
+func (p *pinger) Ping(ctx context.Context) error {
+	p.calls++
+	return p.err
+}
+
+func TestHealth(t *testing.T) {
+	up, down := &pinger{}, &pinger{err: errors.New("openai status 401")}
+	h := &Health{Providers: map[string]Pinger{"ollama": up, "openai": down}, TTL: time.Minute}
+
+	results, ok := h.Check(context.Background())
+	if ok || results["ollama"] != nil || results["openai"] == nil {
+		t.Fatalf("expected openai to be reported down, got %v", results)
+	}
+	h.Check(context.Background())
+	if up.calls != 1 || down.calls != 1 {
+		t.Fatalf("expected a second check within the TTL to reuse the results, got %d and %d pings", up.calls, down.calls)
+	}
+
+	down.err = nil
+	h.checked = time.Now().Add(-time.Minute)
+	if _, ok := h.Check(context.Background()); !ok {
+		t.Fatal("expected every provider to be reachable once the TTL passed")
+	}
+}
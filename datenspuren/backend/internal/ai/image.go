@@ -0,0 +1,15 @@
+package ai
+
+import "context"
+
+// Image is a generated picture, see ImageProvider.
+type Image struct {
+	Data        []byte
+	ContentType string
+}
+
+// ImageProvider generates an image from a prompt, for image rounds. Model
+// is provider specific, e.g. "dall-e-3".
+type ImageProvider interface {
+	GenerateImage(ctx context.Context, model string, prompt string) (Image, error)
+}
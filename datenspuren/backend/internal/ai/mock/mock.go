@@ -0,0 +1,59 @@
+// Package mock is an AI provider that needs no API key or model: it answers
+// every prompt with a canned reply after a short, realistic-looking delay.
+// It backs the --demo mode and is handy for frontend work.
+package mock
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+
+	"github.com/kiliankoe/gptdash/internal/ai"
+)
+
+// answers are picked by prompt so the same prompt always gets the same reply.
+var answers = []string{
+	"Das hängt stark vom Kontext ab, aber meistens ist es eine Frage der Perspektive.",
+	"Statistisch gesehen ist die Antwort 42, zumindest an Dienstagen.",
+	"Eine Kombination aus Neugier, Kaffee und sehr viel Geduld.",
+	"Vermutlich ein gut gemeinter Zufall, der sich verselbstständigt hat.",
+	"Wissenschaftler sind sich uneinig, aber die meisten tippen auf Katzen.",
+	"Es begann als Missverständnis und wurde dann zur Tradition.",
+	"Kurz gesagt: ja. Lang gesagt: es ist kompliziert.",
+	"Die Antwort liegt irgendwo zwischen Mittagspause und Feierabend.",
+}
+
+type Client struct {
+	Delay time.Duration // how long a "generation" takes
+}
+
+func New() *Client {
+	return &Client{Delay: 1500 * time.Millisecond}
+}
+
+func (c *Client) Complete(ctx context.Context, model string, prompt string) (string, error) {
+	return c.CompleteWithSystem(ctx, model, "", prompt)
+}
+
+func (c *Client) CompleteWithSystem(ctx context.Context, model string, systemPrompt string, prompt string) (string, error) {
+	out, err := c.CompleteDetailed(ctx, model, systemPrompt, prompt)
+	return out.Text, err
+}
+
+func (c *Client) CompleteDetailed(ctx context.Context, model string, systemPrompt string, prompt string) (ai.Completion, error) {
+	select {
+	case <-time.After(c.Delay):
+	case <-ctx.Done():
+		return ai.Completion{}, ctx.Err()
+	}
+	h := fnv.New32a()
+	h.Write([]byte(prompt))
+	text := answers[h.Sum32()%uint32(len(answers))]
+	return ai.Completion{
+		Text:             text,
+		Model:            "mock",
+		PromptTokens:     len(prompt) / 4,
+		CompletionTokens: len(text) / 4,
+		FinishReason:     "stop",
+	}, nil
+}
@@ -0,0 +1,47 @@
+package ai
+
+import (
+	"context"
+	"strings"
+)
+
+// Moderator screens text players and hosts put on the projector.
+type Moderator interface {
+	Moderate(ctx context.Context, text string) (Verdict, error)
+}
+
+// Verdict is a moderation result. Categories name why text was flagged,
+// e.g. "harassment", as far as the moderator tells.
+type Verdict struct {
+	Flagged    bool
+	Categories []string
+}
+
+// ProviderModerator classifies text by prompting a completion provider, for
+// setups without a dedicated moderation endpoint, e.g. a local model.
+type ProviderModerator struct {
+	Provider Provider
+	Model    string
+}
+
+const moderationSystemPrompt = "You moderate a party quiz shown on a projector at a public event. " +
+	"Reply with OK if the user's message is fine to show. Otherwise reply with FLAGGED: followed by " +
+	"comma-separated categories, e.g. FLAGGED: hate, sexual. Reply with nothing else."
+
+func (m ProviderModerator) Moderate(ctx context.Context, text string) (Verdict, error) {
+	reply, err := m.Provider.CompleteWithSystem(ctx, m.Model, moderationSystemPrompt, text)
+	if err != nil {
+		return Verdict{}, err
+	}
+	rest, found := strings.CutPrefix(strings.ToLower(strings.TrimSpace(reply)), "flagged")
+	if !found {
+		return Verdict{}, nil
+	}
+	v := Verdict{Flagged: true}
+	for _, c := range strings.Split(strings.TrimPrefix(strings.TrimSpace(rest), ":"), ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			v.Categories = append(v.Categories, c)
+		}
+	}
+	return v, nil
+}
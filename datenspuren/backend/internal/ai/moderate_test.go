@@ -0,0 +1,33 @@
+package ai
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// canned replies with text regardless of the prompt.
+type canned string
+
+func (c canned) Complete(ctx context.Context, model string, prompt string) (string, error) {
+	return string(c), nil
+}
+
+func (c canned) CompleteWithSystem(ctx context.Context, model string, systemPrompt string, prompt string) (string, error) {
+	return string(c), nil
+}
+
+func (c canned) CompleteDetailed(ctx context.Context, model string, systemPrompt string, prompt string) (Completion, error) {
+	return Completion{Text: string(c)}, nil
+}
+
+func TestProviderModerator(t *testing.T) {
+	v, err := ProviderModerator{Provider: canned(" OK\n")}.Moderate(context.Background(), "Why is the sky blue?")
+	if err != nil || v.Flagged {
+		t.Fatalf("expected the prompt to pass, got %+v %v", v, err)
+	}
+	v, _ = ProviderModerator{Provider: canned("Flagged: Hate, sexual")}.Moderate(context.Background(), "...")
+	if !v.Flagged || !slices.Equal(v.Categories, []string{"hate", "sexual"}) {
+		t.Fatalf("expected the prompt to be flagged as hate and sexual, got %+v", v)
+	}
+}
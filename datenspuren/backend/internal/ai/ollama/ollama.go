@@ -7,12 +7,16 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/kiliankoe/gptdash/internal/ai"
 )
 
 type Client struct {
 	Host string
 	http *http.Client
+	mu   sync.RWMutex // guards Host, see SetHost
 }
 
 func New(host string) *Client {
@@ -22,11 +26,32 @@ func New(host string) *Client {
 	return &Client{Host: strings.TrimRight(host, "/"), http: &http.Client{Timeout: 20 * time.Second}}
 }
 
+// SetHost points the client at another Ollama server, e.g. on a config
+// reload.
+func (c *Client) SetHost(host string) {
+	if host == "" {
+		host = "http://localhost:11434"
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Host = strings.TrimRight(host, "/")
+}
+
 func (c *Client) Complete(ctx context.Context, model string, prompt string) (string, error) {
 	return c.CompleteWithSystem(ctx, model, "", prompt)
 }
 
 func (c *Client) CompleteWithSystem(ctx context.Context, model string, systemPrompt string, prompt string) (string, error) {
+	out, err := c.CompleteDetailed(ctx, model, systemPrompt, prompt)
+	return out.Text, err
+}
+
+func (c *Client) CompleteDetailed(ctx context.Context, model string, systemPrompt string, prompt string) (completion ai.Completion, err error) {
+	ctx, span := ai.StartCall(ctx, "ollama", model)
+	defer func() { ai.EndCall(span, completion, err) }()
+	c.mu.RLock()
+	host := c.Host
+	c.mu.RUnlock()
 	if systemPrompt == "" {
 		systemPrompt = "You are a concise AI. Answer briefly in 1-2 sentences."
 	}
@@ -39,23 +64,50 @@ func (c *Client) CompleteWithSystem(ctx context.Context, model string, systemPro
 		"stream": false,
 	}
 	b, _ := json.Marshal(payload)
-	req, _ := http.NewRequestWithContext(ctx, "POST", c.Host+"/api/chat", bytes.NewReader(b))
+	req, _ := http.NewRequestWithContext(ctx, "POST", host+"/api/chat", bytes.NewReader(b))
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return "", err
+		return ai.Completion{}, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode/100 != 2 {
-		return "", fmt.Errorf("ollama status %d", resp.StatusCode)
+		return ai.Completion{}, fmt.Errorf("ollama status %d", resp.StatusCode)
 	}
 	var out struct {
+		Model   string `json:"model"`
 		Message struct {
 			Content string `json:"content"`
 		} `json:"message"`
+		PromptEvalCount int    `json:"prompt_eval_count"`
+		EvalCount       int    `json:"eval_count"`
+		DoneReason      string `json:"done_reason"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return "", err
+		return ai.Completion{}, err
+	}
+	return ai.Completion{
+		Text:             strings.TrimSpace(out.Message.Content),
+		Model:            out.Model,
+		PromptTokens:     out.PromptEvalCount,
+		CompletionTokens: out.EvalCount,
+		FinishReason:     out.DoneReason,
+	}, nil
+}
+
+// Ping lists the locally available models to check that Ollama is up.
+func (c *Client) Ping(ctx context.Context) error {
+	c.mu.RLock()
+	host := c.Host
+	c.mu.RUnlock()
+	req, _ := http.NewRequestWithContext(ctx, "GET", host+"/api/tags", nil)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("ollama status %d", resp.StatusCode)
 	}
-	return strings.TrimSpace(out.Message.Content), nil
+	return nil
 }
@@ -3,18 +3,25 @@ package openai
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/kiliankoe/gptdash/internal/ai"
+	"github.com/kiliankoe/gptdash/internal/i18n"
 )
 
 type Client struct {
 	APIKey  string
 	BaseURL string
 	http    *http.Client
+	mu      sync.RWMutex // guards APIKey and BaseURL, see SetCredentials
 }
 
 func New(apiKey, baseURL string) *Client {
@@ -24,16 +31,40 @@ func New(apiKey, baseURL string) *Client {
 	return &Client{APIKey: apiKey, BaseURL: strings.TrimRight(baseURL, "/"), http: &http.Client{Timeout: 20 * time.Second}}
 }
 
+// SetCredentials swaps the API key and base URL, e.g. on a config reload.
+// Requests already sent finish with the old ones.
+func (c *Client) SetCredentials(apiKey, baseURL string) {
+	if baseURL == "" {
+		baseURL = "https://api.openai.com"
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.APIKey, c.BaseURL = apiKey, strings.TrimRight(baseURL, "/")
+}
+
+func (c *Client) credentials() (apiKey, baseURL string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.APIKey, c.BaseURL
+}
+
 func (c *Client) Complete(ctx context.Context, model string, prompt string) (string, error) {
 	return c.CompleteWithSystem(ctx, model, "", prompt)
 }
 
 func (c *Client) CompleteWithSystem(ctx context.Context, model string, systemPrompt string, prompt string) (string, error) {
-	if c.APIKey == "" {
-		return "", errors.New("missing OPENAI_API_KEY")
+	out, err := c.CompleteDetailed(ctx, model, systemPrompt, prompt)
+	return out.Text, err
+}
+
+func (c *Client) CompleteDetailed(ctx context.Context, model string, systemPrompt string, prompt string) (out ai.Completion, err error) {
+	ctx, span := ai.StartCall(ctx, "openai", model)
+	defer func() { ai.EndCall(span, out, err) }()
+	if apiKey, _ := c.credentials(); apiKey == "" {
+		return ai.Completion{}, errors.New("missing OPENAI_API_KEY")
 	}
 	if systemPrompt == "" {
-		systemPrompt = "Du bist eine prägnante, sich kurzfassende KI. Antworte knapp in 1-2 Sätzen."
+		systemPrompt = i18n.T(i18n.Default, i18n.SystemPrompt)
 	}
 	if strings.Contains(model, "gpt") {
 		return c.chatCompleteWithSystem(ctx, model, systemPrompt, prompt)
@@ -41,7 +72,13 @@ func (c *Client) CompleteWithSystem(ctx context.Context, model string, systemPro
 	return c.textComplete(ctx, model, prompt)
 }
 
-func (c *Client) chatCompleteWithSystem(ctx context.Context, model string, systemPrompt string, prompt string) (string, error) {
+type usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+func (c *Client) chatCompleteWithSystem(ctx context.Context, model string, systemPrompt string, prompt string) (ai.Completion, error) {
+	apiKey, baseURL := c.credentials()
 	payload := map[string]any{
 		"model": model,
 		"messages": []map[string]string{
@@ -52,34 +89,44 @@ func (c *Client) chatCompleteWithSystem(ctx context.Context, model string, syste
 		"max_tokens":  200,
 	}
 	b, _ := json.Marshal(payload)
-	req, _ := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/v1/chat/completions", bytes.NewReader(b))
-	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req, _ := http.NewRequestWithContext(ctx, "POST", baseURL+"/v1/chat/completions", bytes.NewReader(b))
+	req.Header.Set("Authorization", "Bearer "+apiKey)
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return "", err
+		return ai.Completion{}, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode/100 != 2 {
-		return "", fmt.Errorf("openai status %d", resp.StatusCode)
+		return ai.Completion{}, fmt.Errorf("openai status %d", resp.StatusCode)
 	}
 	var out struct {
+		Model   string `json:"model"`
+		Usage   usage  `json:"usage"`
 		Choices []struct {
 			Message struct {
 				Content string `json:"content"`
 			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
 		} `json:"choices"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return "", err
+		return ai.Completion{}, err
 	}
 	if len(out.Choices) == 0 {
-		return "", errors.New("no choices")
+		return ai.Completion{}, errors.New("no choices")
 	}
-	return strings.TrimSpace(out.Choices[0].Message.Content), nil
+	return ai.Completion{
+		Text:             strings.TrimSpace(out.Choices[0].Message.Content),
+		Model:            out.Model,
+		PromptTokens:     out.Usage.PromptTokens,
+		CompletionTokens: out.Usage.CompletionTokens,
+		FinishReason:     out.Choices[0].FinishReason,
+	}, nil
 }
 
-func (c *Client) textComplete(ctx context.Context, model string, prompt string) (string, error) {
+func (c *Client) textComplete(ctx context.Context, model string, prompt string) (ai.Completion, error) {
+	apiKey, baseURL := c.credentials()
 	payload := map[string]any{
 		"model":       model,
 		"prompt":      prompt,
@@ -87,27 +134,134 @@ func (c *Client) textComplete(ctx context.Context, model string, prompt string)
 		"max_tokens":  200,
 	}
 	b, _ := json.Marshal(payload)
-	req, _ := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/v1/completions", bytes.NewReader(b))
-	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req, _ := http.NewRequestWithContext(ctx, "POST", baseURL+"/v1/completions", bytes.NewReader(b))
+	req.Header.Set("Authorization", "Bearer "+apiKey)
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return "", err
+		return ai.Completion{}, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode/100 != 2 {
-		return "", fmt.Errorf("openai status %d", resp.StatusCode)
+		return ai.Completion{}, fmt.Errorf("openai status %d", resp.StatusCode)
 	}
 	var out struct {
+		Model   string `json:"model"`
+		Usage   usage  `json:"usage"`
 		Choices []struct {
-			Text string `json:"text"`
+			Text         string `json:"text"`
+			FinishReason string `json:"finish_reason"`
 		} `json:"choices"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return "", err
+		return ai.Completion{}, err
 	}
 	if len(out.Choices) == 0 {
-		return "", errors.New("no choices")
+		return ai.Completion{}, errors.New("no choices")
+	}
+	return ai.Completion{
+		Text:             strings.TrimSpace(out.Choices[0].Text),
+		Model:            out.Model,
+		PromptTokens:     out.Usage.PromptTokens,
+		CompletionTokens: out.Usage.CompletionTokens,
+		FinishReason:     out.Choices[0].FinishReason,
+	}, nil
+}
+
+// GenerateImage creates a square image with OpenAI's image endpoint. The
+// image comes back inline, the URLs the endpoint offers expire after an hour.
+func (c *Client) GenerateImage(ctx context.Context, model string, prompt string) (ai.Image, error) {
+	apiKey, baseURL := c.credentials()
+	if apiKey == "" {
+		return ai.Image{}, errors.New("missing OPENAI_API_KEY")
+	}
+	b, _ := json.Marshal(map[string]any{"model": model, "prompt": prompt, "n": 1, "size": "1024x1024", "response_format": "b64_json"})
+	req, _ := http.NewRequestWithContext(ctx, "POST", baseURL+"/v1/images/generations", bytes.NewReader(b))
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	// image generation takes longer than the client's text timeout
+	resp, err := (&http.Client{Timeout: 2 * time.Minute}).Do(req)
+	if err != nil {
+		return ai.Image{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return ai.Image{}, fmt.Errorf("openai status %d", resp.StatusCode)
+	}
+	var out struct {
+		Data []struct {
+			B64JSON string `json:"b64_json"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return ai.Image{}, err
+	}
+	if len(out.Data) == 0 {
+		return ai.Image{}, errors.New("no image generated")
+	}
+	data, err := base64.StdEncoding.DecodeString(out.Data[0].B64JSON)
+	if err != nil {
+		return ai.Image{}, err
+	}
+	return ai.Image{Data: data, ContentType: "image/png"}, nil
+}
+
+// Moderate checks text against OpenAI's moderation endpoint.
+func (c *Client) Moderate(ctx context.Context, text string) (ai.Verdict, error) {
+	apiKey, baseURL := c.credentials()
+	if apiKey == "" {
+		return ai.Verdict{}, errors.New("missing OPENAI_API_KEY")
+	}
+	b, _ := json.Marshal(map[string]any{"model": "omni-moderation-latest", "input": text})
+	req, _ := http.NewRequestWithContext(ctx, "POST", baseURL+"/v1/moderations", bytes.NewReader(b))
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return ai.Verdict{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return ai.Verdict{}, fmt.Errorf("openai status %d", resp.StatusCode)
+	}
+	var out struct {
+		Results []struct {
+			Flagged    bool            `json:"flagged"`
+			Categories map[string]bool `json:"categories"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return ai.Verdict{}, err
+	}
+	if len(out.Results) == 0 {
+		return ai.Verdict{}, errors.New("no moderation results")
+	}
+	v := ai.Verdict{Flagged: out.Results[0].Flagged}
+	for name, hit := range out.Results[0].Categories {
+		if hit {
+			v.Categories = append(v.Categories, name)
+		}
+	}
+	sort.Strings(v.Categories)
+	return v, nil
+}
+
+// Ping lists the available models, which checks that the API is reachable
+// and the key is accepted without generating anything.
+func (c *Client) Ping(ctx context.Context) error {
+	apiKey, baseURL := c.credentials()
+	if apiKey == "" {
+		return errors.New("missing OPENAI_API_KEY")
+	}
+	req, _ := http.NewRequestWithContext(ctx, "GET", baseURL+"/v1/models", nil)
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("openai status %d", resp.StatusCode)
 	}
-	return strings.TrimSpace(out.Choices[0].Text), nil
+	return nil
 }
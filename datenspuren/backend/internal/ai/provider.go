@@ -5,6 +5,18 @@ import "context"
 type Provider interface {
 	Complete(ctx context.Context, model string, prompt string) (string, error)
 	CompleteWithSystem(ctx context.Context, model string, systemPrompt string, prompt string) (string, error)
+	CompleteDetailed(ctx context.Context, model string, systemPrompt string, prompt string) (Completion, error)
+}
+
+// Completion is a generated answer together with what the provider reported
+// about generating it.
+type Completion struct {
+	Text             string
+	Provider         string // set by Fallback to the provider that answered
+	Model            string // as reported by the provider, may differ from the requested one
+	PromptTokens     int
+	CompletionTokens int
+	FinishReason     string
 }
 
 type Config struct {
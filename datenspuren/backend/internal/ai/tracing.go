@@ -0,0 +1,40 @@
+package ai
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/kiliankoe/gptdash/internal/ai")
+
+// StartCall starts the span of a request to a provider's API, named after
+// the provider, e.g. "openai". EndCall finishes it.
+func StartCall(ctx context.Context, system, model string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, system+".complete", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("gen_ai.system", system),
+		attribute.String("gen_ai.request.model", model),
+	))
+}
+
+// EndCall finishes a span from StartCall with the usage the provider
+// reported, or the error.
+func EndCall(span trace.Span, out Completion, err error) {
+	defer span.End()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	span.SetAttributes(
+		attribute.String("gen_ai.response.model", out.Model),
+		attribute.Int("gen_ai.usage.input_tokens", out.PromptTokens),
+		attribute.Int("gen_ai.usage.output_tokens", out.CompletionTokens),
+	)
+	if out.FinishReason != "" {
+		span.SetAttributes(attribute.StringSlice("gen_ai.response.finish_reasons", []string{out.FinishReason}))
+	}
+}
@@ -0,0 +1,22 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+)
+
+type Translator interface {
+	Translate(ctx context.Context, text string, targetLang string) (string, error)
+}
+
+// ProviderTranslator translates by prompting a completion provider, for
+// setups without a dedicated translation service.
+type ProviderTranslator struct {
+	Provider Provider
+	Model    string
+}
+
+func (t ProviderTranslator) Translate(ctx context.Context, text string, targetLang string) (string, error) {
+	system := fmt.Sprintf("Translate the user's message into the language with ISO 639-1 code %q. Reply with the translation only, no quotes or explanations.", targetLang)
+	return t.Provider.CompleteWithSystem(ctx, t.Model, system, text)
+}
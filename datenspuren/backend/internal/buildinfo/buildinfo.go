@@ -0,0 +1,49 @@
+// Package buildinfo identifies the running binary. The variables are set at
+// build time via -ldflags "-X github.com/kiliankoe/gptdash/internal/buildinfo.Version=...";
+// Commit and Date fall back to the VCS stamp Go embeds in module builds.
+package buildinfo
+
+import "runtime/debug"
+
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+type Info struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Date    string `json:"date"`
+}
+
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, Date: Date}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.Date == "":
+				info.Date = s.Value
+			}
+		}
+	}
+	return info
+}
+
+func (i Info) String() string {
+	s := i.Version
+	if i.Commit != "" {
+		c := i.Commit
+		if len(c) > 7 {
+			c = c[:7]
+		}
+		s += " (" + c
+		if i.Date != "" {
+			s += ", " + i.Date
+		}
+		s += ")"
+	}
+	return s
+}
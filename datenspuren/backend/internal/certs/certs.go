@@ -0,0 +1,76 @@
+// Package certs lets the server speak HTTPS itself, for events without a
+// reverse proxy in front: either with a certificate and key from files or
+// with certificates it obtains and renews via ACME (Let's Encrypt).
+package certs
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Options configures TLS. With neither files nor domains set TLS is off.
+type Options struct {
+	CertFile string
+	KeyFile  string
+	Domains  []string // ACME mode: certificates are issued for exactly these hosts
+	Email    string   // ACME account contact, optional
+	CacheDir string   // where ACME keeps accounts and certificates across restarts
+	// ACME directory, Let's Encrypt's production one when empty. Their
+	// staging directory helps while trying things out.
+	DirectoryURL string
+}
+
+// Setup returns the TLS config for o, nil when TLS is off, and the handler
+// for the plain HTTP port: it redirects to HTTPS on httpsPort and, in ACME
+// mode, answers HTTP-01 challenges.
+func Setup(o Options, httpsPort string) (*tls.Config, http.Handler, error) {
+	files := o.CertFile != "" || o.KeyFile != ""
+	switch {
+	case files && len(o.Domains) > 0:
+		return nil, nil, errors.New("set either TLS_CERT/TLS_KEY or ACME_DOMAINS, not both")
+	case files && (o.CertFile == "" || o.KeyFile == ""):
+		return nil, nil, errors.New("TLS_CERT and TLS_KEY go together")
+	case files:
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, Redirect(httpsPort), nil
+	case len(o.Domains) > 0:
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(o.Domains...),
+			Email:      o.Email,
+		}
+		if o.CacheDir != "" {
+			m.Cache = autocert.DirCache(o.CacheDir)
+		}
+		if o.DirectoryURL != "" {
+			m.Client = &acme.Client{DirectoryURL: o.DirectoryURL}
+		}
+		cfg := m.TLSConfig() // answers TLS-ALPN-01 challenges on the HTTPS port
+		cfg.MinVersion = tls.VersionTLS12
+		return cfg, m.HTTPHandler(Redirect(httpsPort)), nil
+	}
+	return nil, nil, nil
+}
+
+// Redirect sends plain HTTP requests to the same URL on HTTPS.
+func Redirect(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
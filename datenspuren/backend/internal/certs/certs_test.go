@@ -0,0 +1,76 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func writeSelfSigned(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("should be able to generate a key: %v", err)
+	}
+	tmpl := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "localhost"}, NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour), DNSNames: []string{"localhost"}}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("should be able to create a certificate: %v", err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile
+}
+
+func TestSetup(t *testing.T) {
+	if cfg, h, err := Setup(Options{}, "443"); cfg != nil || h != nil || err != nil {
+		t.Fatalf("expected TLS to be off without options, got %v %v %v", cfg, h, err)
+	}
+	certFile, keyFile := writeSelfSigned(t)
+	if _, _, err := Setup(Options{CertFile: certFile}, "443"); err == nil {
+		t.Fatal("expected a certificate without key to be rejected")
+	}
+	if _, _, err := Setup(Options{CertFile: certFile, KeyFile: keyFile, Domains: []string{"example.org"}}, "443"); err == nil {
+		t.Fatal("expected files and ACME together to be rejected")
+	}
+
+	cfg, _, err := Setup(Options{CertFile: certFile, KeyFile: keyFile}, "443")
+	if err != nil || len(cfg.Certificates) != 1 {
+		t.Fatalf("should be able to load the certificate: %v", err)
+	}
+	if cert, err := x509.ParseCertificate(cfg.Certificates[0].Certificate[0]); err != nil || cert.Subject.CommonName != "localhost" {
+		t.Fatalf("expected the loaded certificate, got %v", err)
+	}
+
+	cfg, _, err = Setup(Options{Domains: []string{"example.org"}, CacheDir: t.TempDir()}, "443")
+	if err != nil || cfg.GetCertificate == nil || !slices.Contains(cfg.NextProtos, "acme-tls/1") {
+		t.Fatalf("expected an ACME config answering TLS-ALPN challenges, got %v", err)
+	}
+}
+
+func TestRedirect(t *testing.T) {
+	for port, want := range map[string]string{
+		"443":  "https://example.org/play?code=ABCDE",
+		"8443": "https://example.org:8443/play?code=ABCDE",
+	} {
+		w := httptest.NewRecorder()
+		Redirect(port).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://example.org:8080/play?code=ABCDE", nil))
+		if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != want {
+			t.Fatalf("expected a redirect to %s, got %d %s", want, w.Code, w.Header().Get("Location"))
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Package collector streams game documents to external sinks while a game is
+// running, as opposed to the per-session text export.
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type Collector interface {
+	Send(ctx context.Context, doc any) error
+}
+
+// HTTP POSTs each document as JSON to a collector endpoint.
+type HTTP struct {
+	URL  string
+	http *http.Client
+}
+
+func NewHTTP(url string) *HTTP {
+	return &HTTP{URL: url, http: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *HTTP) Send(ctx context.Context, doc any) error {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.URL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// NDJSON appends each document as one JSON line to a file.
+type NDJSON struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func NewNDJSON(path string) *NDJSON {
+	return &NDJSON{Path: path}
+}
+
+func (c *NDJSON) Send(_ context.Context, doc any) error {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	f, err := os.OpenFile(c.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// Multi sends to every collector and joins their errors.
+type Multi []Collector
+
+func (m Multi) Send(ctx context.Context, doc any) error {
+	var errs []error
+	for _, c := range m {
+		if err := c.Send(ctx, doc); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
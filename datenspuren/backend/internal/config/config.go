@@ -1,42 +1,230 @@
 package config
 
-import "os"
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type Config struct {
 	Port            string
 	DefaultProvider string
 	DefaultModel    string
-	SystemPrompt    string
+	SystemPrompt    string // overrides the per-language default, see i18n
 	OpenAIKey       string
 	OpenAIBaseURL   string
 	OllamaHost      string
+	AIFallback      string // "provider[:model],..." tried in order when a session's provider fails
+	AIRetries       int    // extra attempts per provider
+	AITimeout       int    // seconds per attempt
 	GMUser          string
 	GMPass          string
 	SingleSession   bool
 	ExportEnabled   bool
-	ExportFile      string
+	ExportDir       string
+	ExportFile      string // EXPORT_FILE from before per-session exports, only its directory is used
+	StreamURL       string // HTTP collector receiving round documents
+	StreamFile      string // NDJSON file receiving round documents
+	PublicURL       string // where players open the frontend, for join links on signage
+	TLSCert         string // serve HTTPS with this certificate and TLSKey
+	TLSKey          string
+	ACMEDomains     []string // serve HTTPS with certificates from ACMEDirectory for these hosts
+	ACMEEmail       string
+	ACMECacheDir    string
+	ACMEDirectory   string   // Let's Encrypt when empty
+	HTTPPort        string   // with TLS, plain HTTP port redirecting to HTTPS and answering ACME challenges
+	SignageWebhook  string   // receives the running game's signage info when it changes
+	WebhookURL      string   // receives signed deliveries of finished rounds and games
+	WebhookSecret   string   // signs webhook deliveries
+	ExportAnonymize bool     // pseudonymize player names in every export
+	ExportRedact    []string // answers containing any of these are omitted from exports
+	ExportTimeZone  string   // IANA zone of export timestamps unless a session sets its own
+	ProfilesFile    string
+	PromptsFile     string // prompt library imported via the GM API
+	PromptStatsFile string // how each prompt fared across sessions
+	AnswerPoolFile  string // pre-written AI answers, JSON or YAML
+	WALEnabled      bool
+	WALDir          string
+	SessionDB       string // SQLite database for sessions, replaces the WAL when set
+	InstanceID      string // this instance in Instances
+	Instances       string // "id=url,..." of all instances sharing the load
+	RoutingMode     string // "forward" or "redirect"
+	Translator      string // "", "deepl" or an AI provider name
+	TranslatorModel string
+	DeepLKey        string
+	DeepLBaseURL    string
+	Moderator       string // "", "openai" (moderation endpoint) or "ollama"
+	ModeratorModel  string
+	TTS             string // "", "openai" or "command"
+	TTSModel        string
+	TTSVoice        string
+	TTSCommand      string // local engine writing WAV, see tts.Command
+	ImageProvider   string // "" or "openai", enables image rounds
+	ImageModel      string
+	WordlistFile    string        // blocked words for player names and answers
+	WordlistMode    string        // "replace" or "reject"
+	MaxSessions     int           // running sessions, 0 = unlimited
+	MaxConnections  int           // open sockets, 0 = unlimited
+	MaxSessionConns int           // sockets joined to one session, 0 = unlimited
+	SessionTTL      time.Duration // idle sessions are closed after this long, 0 = never
+	EventRate       int           // joins, answers and votes per second and connection, 0 = unlimited
+	EventBurst      int           // events a connection may send at once before EventRate applies
+	JoinRate        int           // join attempts per minute and IP, 0 = unlimited
+	JoinBlock       time.Duration // how long an IP flooding joins at twice JoinRate is blocked
+	TrustedProxies  []string      // IPs or CIDRs whose X-Forwarded-For is believed, none when empty
+	TokenSecret     string        // signs host and player tokens as JWTs, bare tokens when empty
+	TokenTTL        time.Duration // how long a signed token is valid
+	Tracing         bool          // export OpenTelemetry traces, set by OTEL_EXPORTER_OTLP_ENDPOINT
+
+	// per-session storage caps, 0 = unlimited
+	MaxSubmissions   int // answers per round
+	MaxVotes         int // votes per round
+	MaxQueuedPrompts int
+	MaxNotes         int // host notes per round, oldest dropped
+	MaxCheatLog      int // cheat audit entries, oldest dropped
+	MaxRoundHistory  int // rounds archived in full, older ones keep only tallies
+
+	// scoring of sessions that don't set their own
+	PointsPerVote int // for every vote an answer draws
+	FoundAIPoints int // for voting for the AI answer
+	FooledBonus   int // for drawing more votes than the AI, 0 = none
+
+	CueThresholds []int // seconds left on a phase timer at which clients get a cue
 }
 
+// FromEnv reads the configuration from environment variables.
 func FromEnv() Config {
+	return load(os.Getenv)
+}
+
+// load builds the configuration from get, which looks up a variable by its
+// environment name and returns "" for unset ones.
+func load(get func(string) string) Config {
+	getenv := func(k, def string) string {
+		if v := get(k); v != "" {
+			return v
+		}
+		return def
+	}
+	getint := func(k string, def int) int {
+		if n, err := strconv.Atoi(get(k)); err == nil && n >= 0 {
+			return n
+		}
+		return def
+	}
 	c := Config{}
 	c.Port = getenv("PORT", "8080")
 	c.DefaultProvider = getenv("DEFAULT_PROVIDER", "openai")
 	c.DefaultModel = getenv("DEFAULT_MODEL", "gpt-3.5-turbo")
-	c.SystemPrompt = getenv("SYSTEM_PROMPT", "Du bist eine prägnante, sich kurzfassende KI. Antworte knapp in 1-2 Sätzen.")
-	c.OpenAIKey = os.Getenv("OPENAI_API_KEY")
-	c.OpenAIBaseURL = os.Getenv("OPENAI_BASE_URL")
+	c.SystemPrompt = get("SYSTEM_PROMPT")
+	c.OpenAIKey = get("OPENAI_API_KEY")
+	c.OpenAIBaseURL = get("OPENAI_BASE_URL")
 	c.OllamaHost = getenv("OLLAMA_HOST", "http://localhost:11434")
-	c.GMUser = os.Getenv("GM_USER")
-	c.GMPass = os.Getenv("GM_PASS")
+	c.AIFallback = get("AI_FALLBACK")
+	c.AIRetries = getint("AI_RETRIES", 2)
+	c.AITimeout = getint("AI_TIMEOUT", 20)
+	c.GMUser = get("GM_USER")
+	c.GMPass = get("GM_PASS")
 	c.SingleSession = getenv("SINGLE_SESSION", "true") == "true"
 	c.ExportEnabled = getenv("EXPORT_ENABLED", "true") == "true"
-	c.ExportFile = getenv("EXPORT_FILE", "./gptdash-results.txt")
-	return c
-}
-
-func getenv(k, def string) string {
-	if v := os.Getenv(k); v != "" {
-		return v
+	c.ExportFile = get("EXPORT_FILE")
+	c.ExportDir = get("EXPORT_DIR")
+	if c.ExportDir == "" && c.ExportFile != "" {
+		// keep results next to where older setups expect them
+		c.ExportDir = filepath.Dir(c.ExportFile)
 	}
-	return def
+	if c.ExportDir == "" {
+		c.ExportDir = "./gptdash-results"
+	}
+	c.StreamURL = get("EXPORT_STREAM_URL")
+	c.StreamFile = get("EXPORT_STREAM_FILE")
+	c.PublicURL = get("PUBLIC_URL")
+	c.TLSCert = get("TLS_CERT")
+	c.TLSKey = get("TLS_KEY")
+	for _, d := range strings.Split(get("ACME_DOMAINS"), ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			c.ACMEDomains = append(c.ACMEDomains, d)
+		}
+	}
+	c.ACMEEmail = get("ACME_EMAIL")
+	c.ACMECacheDir = getenv("ACME_CACHE_DIR", "./gptdash-certs")
+	c.ACMEDirectory = get("ACME_DIRECTORY_URL")
+	c.HTTPPort = get("HTTP_PORT")
+	c.SignageWebhook = get("SIGNAGE_WEBHOOK_URL")
+	c.WebhookURL = get("WEBHOOK_URL")
+	c.WebhookSecret = get("WEBHOOK_SECRET")
+	c.ExportAnonymize = getenv("EXPORT_ANONYMIZE", "false") == "true"
+	for _, term := range strings.Split(get("EXPORT_REDACT_TERMS"), ",") {
+		if term = strings.TrimSpace(term); term != "" {
+			c.ExportRedact = append(c.ExportRedact, term)
+		}
+	}
+	c.ExportTimeZone = get("EXPORT_TIMEZONE")
+	c.ProfilesFile = getenv("PROFILES_FILE", "./gptdash-profiles.json")
+	c.PromptsFile = getenv("PROMPTS_FILE", "./gptdash-prompts.json")
+	// stats come from exported sessions only, so they live next to the results
+	c.PromptStatsFile = getenv("PROMPT_STATS_FILE", filepath.Join(c.ExportDir, "prompt-stats.json"))
+	c.AnswerPoolFile = get("ANSWER_POOL_FILE")
+	c.WALEnabled = getenv("WAL_ENABLED", "true") == "true"
+	c.WALDir = getenv("WAL_DIR", "./gptdash-wal")
+	c.SessionDB = get("SESSION_DB")
+	c.InstanceID = get("INSTANCE_ID")
+	c.Instances = get("INSTANCES")
+	c.RoutingMode = getenv("ROUTING_MODE", "forward")
+	c.Translator = get("TRANSLATOR")
+	c.TranslatorModel = getenv("TRANSLATOR_MODEL", c.DefaultModel)
+	c.DeepLKey = get("DEEPL_API_KEY")
+	c.DeepLBaseURL = get("DEEPL_BASE_URL")
+	c.Moderator = get("MODERATOR")
+	c.ModeratorModel = getenv("MODERATOR_MODEL", c.DefaultModel)
+	c.TTS = get("TTS")
+	c.TTSModel = get("TTS_MODEL")
+	c.TTSVoice = get("TTS_VOICE")
+	c.TTSCommand = getenv("TTS_COMMAND", "espeak-ng -v {lang} --stdin --stdout")
+	c.ImageProvider = get("IMAGE_PROVIDER")
+	c.ImageModel = getenv("IMAGE_MODEL", "dall-e-3")
+	c.WordlistFile = get("WORDLIST_FILE")
+	c.WordlistMode = getenv("WORDLIST_MODE", "replace")
+	c.MaxSessions = getint("MAX_SESSIONS", 0)
+	c.MaxConnections = getint("MAX_CONNECTIONS", 0)
+	c.MaxSessionConns = getint("MAX_CONNECTIONS_PER_SESSION", 0)
+	c.EventRate = getint("EVENT_RATE_LIMIT", 5)
+	c.EventBurst = getint("EVENT_BURST", 10)
+	c.JoinRate = getint("JOIN_RATE_LIMIT", 120)
+	c.JoinBlock = 10 * time.Minute
+	if d, err := time.ParseDuration(get("JOIN_BLOCK")); err == nil && d >= 0 {
+		c.JoinBlock = d
+	}
+	for _, p := range strings.Split(get("TRUSTED_PROXIES"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			c.TrustedProxies = append(c.TrustedProxies, p)
+		}
+	}
+	c.TokenSecret = get("TOKEN_SECRET")
+	c.TokenTTL = 24 * time.Hour
+	if d, err := time.ParseDuration(get("TOKEN_TTL")); err == nil && d > 0 {
+		c.TokenTTL = d
+	}
+	c.Tracing = get("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || get("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+	c.SessionTTL = 12 * time.Hour
+	if d, err := time.ParseDuration(get("SESSION_TTL")); err == nil && d >= 0 {
+		c.SessionTTL = d
+	}
+	c.MaxSubmissions = getint("MAX_SUBMISSIONS_PER_ROUND", 1000)
+	c.MaxVotes = getint("MAX_VOTES_PER_ROUND", 1000)
+	c.MaxQueuedPrompts = getint("MAX_QUEUED_PROMPTS", 200)
+	c.MaxNotes = getint("MAX_NOTES_PER_ROUND", 100)
+	c.MaxCheatLog = getint("MAX_CHEAT_LOG", 1000)
+	c.MaxRoundHistory = getint("MAX_ROUND_HISTORY", 200)
+	c.PointsPerVote = getint("POINTS_PER_VOTE", 2)
+	c.FoundAIPoints = getint("FOUND_AI_POINTS", 1)
+	c.FooledBonus = getint("FOOLED_BONUS", 0)
+	for _, v := range strings.Split(getenv("CUE_THRESHOLDS", "30,10,0"), ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && n >= 0 {
+			c.CueThresholds = append(c.CueThresholds, n)
+		}
+	}
+	return c
 }
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	yamlFile := filepath.Join(dir, "event.yaml")
+	os.WriteFile(yamlFile, []byte(`
+ai:
+  model: gpt-4o-mini
+openai:
+  apiKey: from-file
+prompts:
+  file: ./event-prompts.json
+scoring:
+  perVote: 3
+  foundAI: 0
+export:
+  enabled: false
+  redactTerms: [foo, bar]
+gm:
+  user: gm
+  pass: secret
+`), 0600)
+	t.Setenv("OPENAI_API_KEY", "from-env")
+
+	c, err := Load(yamlFile)
+	if err != nil {
+		t.Fatalf("should be able to load a YAML config: %v", err)
+	}
+	if c.DefaultModel != "gpt-4o-mini" || c.PromptsFile != "./event-prompts.json" || c.GMUser != "gm" || c.GMPass != "secret" {
+		t.Fatalf("expected the file's values, got %+v", c)
+	}
+	if c.OpenAIKey != "from-env" {
+		t.Fatalf("expected the environment to win over the file, got %q", c.OpenAIKey)
+	}
+	if c.PointsPerVote != 3 || c.FoundAIPoints != 0 || c.ExportEnabled || !slices.Equal(c.ExportRedact, []string{"foo", "bar"}) {
+		t.Fatalf("expected scoring and export settings from the file, got %+v", c)
+	}
+	if c.DefaultProvider != "openai" || c.AIRetries != 2 {
+		t.Fatalf("expected defaults for what the file leaves out, got %q %d", c.DefaultProvider, c.AIRetries)
+	}
+
+	tomlFile := filepath.Join(dir, "event.toml")
+	os.WriteFile(tomlFile, []byte(`
+port = "9090"
+
+[scoring]
+fooled = 2
+
+[export]
+dir = "./results"
+`), 0600)
+	if c, err = Load(tomlFile); err != nil {
+		t.Fatalf("should be able to load a TOML config: %v", err)
+	}
+	if c.Port != "9090" || c.FooledBonus != 2 || c.ExportDir != "./results" || c.PointsPerVote != 2 {
+		t.Fatalf("expected the TOML file's values, got %+v", c)
+	}
+
+	iniFile := filepath.Join(dir, "event.ini")
+	os.WriteFile(iniFile, []byte("port=9090"), 0600)
+	if _, err := Load(iniFile); err == nil {
+		t.Fatal("expected an unknown format to be rejected")
+	}
+	os.WriteFile(yamlFile, []byte("scoring: [1, 2]"), 0600)
+	if _, err := Load(yamlFile); err == nil {
+		t.Fatal("expected an invalid file to be rejected")
+	}
+}
+
+func TestLegacyExportFile(t *testing.T) {
+	env := map[string]string{"EXPORT_FILE": "/srv/gptdash/results.txt"}
+	if c := load(func(k string) string { return env[k] }); c.ExportDir != "/srv/gptdash" {
+		t.Fatalf("expected EXPORT_FILE's directory without EXPORT_DIR, got %q", c.ExportDir)
+	}
+	env["EXPORT_DIR"] = "/srv/results"
+	if c := load(func(k string) string { return env[k] }); c.ExportDir != "/srv/results" {
+		t.Fatalf("expected EXPORT_DIR to win over EXPORT_FILE, got %q", c.ExportDir)
+	}
+}
+
+func TestPromptStatsNextToResults(t *testing.T) {
+	env := map[string]string{"EXPORT_DIR": "/srv/results"}
+	if c := load(func(k string) string { return env[k] }); c.PromptStatsFile != filepath.Join("/srv/results", "prompt-stats.json") {
+		t.Fatalf("expected prompt stats in EXPORT_DIR, got %q", c.PromptStatsFile)
+	}
+	env["PROMPT_STATS_FILE"] = "/srv/stats.json"
+	if c := load(func(k string) string { return env[k] }); c.PromptStatsFile != "/srv/stats.json" {
+		t.Fatalf("expected PROMPT_STATS_FILE to win, got %q", c.PromptStatsFile)
+	}
+}
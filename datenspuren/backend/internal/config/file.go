@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the optional config file, YAML or TOML by its extension. Every
+// value has an environment variable, which wins over the file when set, so
+// an event's file can be versioned while secrets stay in the environment.
+type File struct {
+	Port string `yaml:"port" toml:"port"`
+	AI   struct {
+		Provider     string `yaml:"provider" toml:"provider"`         // DEFAULT_PROVIDER
+		Model        string `yaml:"model" toml:"model"`               // DEFAULT_MODEL
+		SystemPrompt string `yaml:"systemPrompt" toml:"systemPrompt"` // SYSTEM_PROMPT
+		Fallback     string `yaml:"fallback" toml:"fallback"`         // AI_FALLBACK
+		Retries      *int   `yaml:"retries" toml:"retries"`           // AI_RETRIES
+		Timeout      *int   `yaml:"timeout" toml:"timeout"`           // AI_TIMEOUT, seconds
+	} `yaml:"ai" toml:"ai"`
+	OpenAI struct {
+		APIKey  string `yaml:"apiKey" toml:"apiKey"`   // OPENAI_API_KEY
+		BaseURL string `yaml:"baseURL" toml:"baseURL"` // OPENAI_BASE_URL
+	} `yaml:"openai" toml:"openai"`
+	Ollama struct {
+		Host string `yaml:"host" toml:"host"` // OLLAMA_HOST
+	} `yaml:"ollama" toml:"ollama"`
+	DeepL struct {
+		APIKey  string `yaml:"apiKey" toml:"apiKey"`   // DEEPL_API_KEY
+		BaseURL string `yaml:"baseURL" toml:"baseURL"` // DEEPL_BASE_URL
+	} `yaml:"deepl" toml:"deepl"`
+	Prompts struct {
+		File       string `yaml:"file" toml:"file"`             // PROMPTS_FILE
+		StatsFile  string `yaml:"statsFile" toml:"statsFile"`   // PROMPT_STATS_FILE
+		AnswerPool string `yaml:"answerPool" toml:"answerPool"` // ANSWER_POOL_FILE
+	} `yaml:"prompts" toml:"prompts"`
+	Scoring struct {
+		PerVote *int `yaml:"perVote" toml:"perVote"` // POINTS_PER_VOTE
+		FoundAI *int `yaml:"foundAI" toml:"foundAI"` // FOUND_AI_POINTS
+		Fooled  *int `yaml:"fooled" toml:"fooled"`   // FOOLED_BONUS
+	} `yaml:"scoring" toml:"scoring"`
+	Export struct {
+		Enabled     *bool    `yaml:"enabled" toml:"enabled"`         // EXPORT_ENABLED
+		Dir         string   `yaml:"dir" toml:"dir"`                 // EXPORT_DIR
+		StreamURL   string   `yaml:"streamURL" toml:"streamURL"`     // EXPORT_STREAM_URL
+		StreamFile  string   `yaml:"streamFile" toml:"streamFile"`   // EXPORT_STREAM_FILE
+		Anonymize   *bool    `yaml:"anonymize" toml:"anonymize"`     // EXPORT_ANONYMIZE
+		RedactTerms []string `yaml:"redactTerms" toml:"redactTerms"` // EXPORT_REDACT_TERMS
+		TimeZone    string   `yaml:"timeZone" toml:"timeZone"`       // EXPORT_TIMEZONE
+	} `yaml:"export" toml:"export"`
+	GM struct {
+		User string `yaml:"user" toml:"user"` // GM_USER
+		Pass string `yaml:"pass" toml:"pass"` // GM_PASS
+	} `yaml:"gm" toml:"gm"`
+}
+
+// vars returns the file's values by the name of their environment variable.
+func (f File) vars() map[string]string {
+	return map[string]string{
+		"PORT":                f.Port,
+		"DEFAULT_PROVIDER":    f.AI.Provider,
+		"DEFAULT_MODEL":       f.AI.Model,
+		"SYSTEM_PROMPT":       f.AI.SystemPrompt,
+		"AI_FALLBACK":         f.AI.Fallback,
+		"AI_RETRIES":          itoa(f.AI.Retries),
+		"AI_TIMEOUT":          itoa(f.AI.Timeout),
+		"OPENAI_API_KEY":      f.OpenAI.APIKey,
+		"OPENAI_BASE_URL":     f.OpenAI.BaseURL,
+		"OLLAMA_HOST":         f.Ollama.Host,
+		"DEEPL_API_KEY":       f.DeepL.APIKey,
+		"DEEPL_BASE_URL":      f.DeepL.BaseURL,
+		"PROMPTS_FILE":        f.Prompts.File,
+		"PROMPT_STATS_FILE":   f.Prompts.StatsFile,
+		"ANSWER_POOL_FILE":    f.Prompts.AnswerPool,
+		"POINTS_PER_VOTE":     itoa(f.Scoring.PerVote),
+		"FOUND_AI_POINTS":     itoa(f.Scoring.FoundAI),
+		"FOOLED_BONUS":        itoa(f.Scoring.Fooled),
+		"EXPORT_ENABLED":      btoa(f.Export.Enabled),
+		"EXPORT_DIR":          f.Export.Dir,
+		"EXPORT_STREAM_URL":   f.Export.StreamURL,
+		"EXPORT_STREAM_FILE":  f.Export.StreamFile,
+		"EXPORT_ANONYMIZE":    btoa(f.Export.Anonymize),
+		"EXPORT_REDACT_TERMS": strings.Join(f.Export.RedactTerms, ","),
+		"EXPORT_TIMEZONE":     f.Export.TimeZone,
+		"GM_USER":             f.GM.User,
+		"GM_PASS":             f.GM.Pass,
+	}
+}
+
+func itoa(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+func btoa(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b)
+}
+
+// Load reads the config file at path, if any, and the environment on top of
+// it. Settings the file doesn't cover come from the environment only.
+func Load(path string) (Config, error) {
+	if path == "" {
+		return FromEnv(), nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var f File
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &f)
+	case ".toml":
+		err = toml.Unmarshal(b, &f)
+	default:
+		return Config{}, fmt.Errorf("config file %s: unknown format, use .yaml or .toml", path)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	vars := f.vars()
+	return load(func(k string) string {
+		if v := os.Getenv(k); v != "" {
+			return v
+		}
+		return vars[k]
+	}), nil
+}
@@ -0,0 +1,150 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OmittedAnswer replaces answers caught by the sensitivity filter in exports.
+const OmittedAnswer = "[answer omitted]"
+
+// ExportOptions controls what of a session leaves the server in exports and
+// the collector stream, e.g. when publishing results from a public event.
+type ExportOptions struct {
+	// Anonymize replaces player names with pseudonyms ("Player 3") that stay
+	// the same for the whole session.
+	Anonymize bool
+	// Sensitive lists terms (case-insensitive) that get an answer omitted
+	// from the export, e.g. names of people or places.
+	Sensitive []string
+	// Terminated marks an export made because the server shut down before
+	// the game ended; it then includes the unfinished round, if any.
+	Terminated time.Time
+	// Location is the time zone of the export's timestamps unless the
+	// session configured its own; nil means the server's local time.
+	Location *time.Location
+}
+
+func (o ExportOptions) zero() bool {
+	return !o.Anonymize && len(o.Sensitive) == 0
+}
+
+func (o ExportOptions) sensitive(text string) bool {
+	lower := strings.ToLower(text)
+	for _, term := range o.Sensitive {
+		if term = strings.ToLower(strings.TrimSpace(term)); term != "" && strings.Contains(lower, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinOrder lists the players in the order they joined. Callers must hold
+// s.mu.
+func (s *SessionCtx) joinOrder() []*Player {
+	players := make([]*Player, 0, len(s.PlayersByID))
+	for _, p := range s.PlayersByID {
+		players = append(players, p)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		if !players[i].JoinedAt.Equal(players[j].JoinedAt) {
+			return players[i].JoinedAt.Before(players[j].JoinedAt)
+		}
+		return players[i].ID < players[j].ID
+	})
+	return players
+}
+
+// pseudonyms numbers the players in join order, so a player keeps their
+// pseudonym when the export is rewritten after later players joined.
+// Callers must hold s.mu.
+func (s *SessionCtx) pseudonyms() map[string]string {
+	players := s.joinOrder()
+	out := make(map[string]string, len(players))
+	for i, p := range players {
+		out[p.ID] = fmt.Sprintf("Player %d", i+1)
+	}
+	return out
+}
+
+// redactRound applies opts to an archived round without touching the
+// archived copy. Callers must hold s.mu.
+func (s *SessionCtx) redactRound(rs RoundSummary, opts ExportOptions, names map[string]string) RoundSummary {
+	if opts.zero() {
+		return rs
+	}
+	subs := make([]SubmissionResult, len(rs.Submissions))
+	for i, sub := range rs.Submissions {
+		if opts.sensitive(sub.Text) {
+			sub.Text = OmittedAnswer
+		}
+		if opts.Anonymize {
+			if !sub.IsAI {
+				sub.AuthorName = names[sub.AuthorID]
+			}
+			voters := make([]string, len(sub.VoterIDs))
+			for j, id := range sub.VoterIDs {
+				voters[j] = names[id]
+			}
+			sort.Strings(voters)
+			sub.Voters = voters
+		}
+		subs[i] = sub
+	}
+	rs.Submissions = subs
+	if opts.Anonymize {
+		scores := make([]ScoreEntry, len(rs.Scores))
+		for i, e := range rs.Scores {
+			e.Name = names[e.PlayerID]
+			scores[i] = e
+		}
+		rs.Scores = scores
+		awards := make([]RoundAward, len(rs.Awards))
+		for i, a := range rs.Awards {
+			a.PlayerName = names[a.PlayerID]
+			awards[i] = a
+		}
+		rs.Awards = awards
+	}
+	return rs
+}
+
+// RedactRound returns rs with opts applied.
+func (s *SessionCtx) RedactRound(rs RoundSummary, opts ExportOptions) RoundSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.redactRound(rs, opts, s.pseudonyms())
+}
+
+// RedactSummary returns gs with opts applied to every round and the best
+// answer.
+func (s *SessionCtx) RedactSummary(gs GameSummary, opts ExportOptions) GameSummary {
+	if opts.zero() {
+		return gs
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := s.pseudonyms()
+	rounds := make([]RoundSummary, len(gs.Rounds))
+	for i, rs := range gs.Rounds {
+		rounds[i] = s.redactRound(rs, opts, names)
+	}
+	gs.Rounds = rounds
+	if gs.BestAnswer != nil {
+		best := *gs.BestAnswer
+		wrapped := s.redactRound(RoundSummary{Submissions: []SubmissionResult{best.Submission}}, opts, names)
+		best.Submission = wrapped.Submissions[0]
+		gs.BestAnswer = &best
+	}
+	if opts.Anonymize {
+		awards := make([]Award, len(gs.Awards))
+		for i, a := range gs.Awards {
+			a.PlayerName = names[a.PlayerID]
+			awards[i] = a
+		}
+		gs.Awards = awards
+	}
+	return gs
+}
@@ -0,0 +1,93 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrPoolAnswerGone = errors.New("answer not in pool")
+
+// PoolAnswer is a pre-written AI answer the host can pick instead of, or on
+// top of, a generated one. Answers without a prompt fit any round.
+type PoolAnswer struct {
+	ID     string `json:"id"`
+	Prompt string `json:"prompt,omitempty"`
+	Text   string `json:"text"`
+}
+
+// AnswerPool holds the pre-written answers loaded from a file. It's read
+// only after loading, so it needs no locking.
+type AnswerPool struct {
+	byPrompt map[string][]PoolAnswer // normalized prompt -> answers
+	generic  []PoolAnswer
+	byID     map[string]PoolAnswer
+}
+
+// LoadAnswerPool reads a pool file, JSON or YAML by its extension, holding a
+// list of entries with an optional "prompt" and its "answers".
+func LoadAnswerPool(filename string) (*AnswerPool, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answer pool: %w", err)
+	}
+	var entries []struct {
+		Prompt  string   `json:"prompt" yaml:"prompt"`
+		Answers []string `json:"answers" yaml:"answers"`
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &entries)
+	default:
+		err = json.Unmarshal(b, &entries)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse answer pool: %w", err)
+	}
+	p := &AnswerPool{byPrompt: map[string][]PoolAnswer{}, byID: map[string]PoolAnswer{}}
+	for i, e := range entries {
+		prompt := strings.TrimSpace(e.Prompt)
+		for j, text := range e.Answers {
+			text = strings.TrimSpace(text)
+			if text == "" {
+				continue
+			}
+			// IDs are positions in the file, stable across restarts as long
+			// as the file doesn't change
+			a := PoolAnswer{ID: fmt.Sprintf("%d.%d", i+1, j+1), Prompt: prompt, Text: text}
+			p.byID[a.ID] = a
+			if prompt == "" {
+				p.generic = append(p.generic, a)
+			} else {
+				key := normalizePrompt(prompt)
+				p.byPrompt[key] = append(p.byPrompt[key], a)
+			}
+		}
+	}
+	return p, nil
+}
+
+// For returns the answers written for prompt, followed by the ones that fit
+// any round.
+func (p *AnswerPool) For(prompt string) []PoolAnswer {
+	out := append([]PoolAnswer{}, p.byPrompt[normalizePrompt(prompt)]...)
+	return append(out, p.generic...)
+}
+
+func (p *AnswerPool) Get(id string) (PoolAnswer, error) {
+	a, ok := p.byID[id]
+	if !ok {
+		return PoolAnswer{}, ErrPoolAnswerGone
+	}
+	return a, nil
+}
+
+// Len is the number of answers in the pool.
+func (p *AnswerPool) Len() int {
+	return len(p.byID)
+}
@@ -0,0 +1,51 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAnswerPool(t *testing.T) {
+	dir := t.TempDir()
+	yamlFile := filepath.Join(dir, "answers.yaml")
+	os.WriteFile(yamlFile, []byte(`
+- prompt: Was ist das Beste an Montagen?
+  answers:
+    - Dass sie irgendwann Dienstag werden.
+    - "  "
+    - Der erste Kaffee.
+- answers: [Darüber denke ich lieber nicht nach.]
+`), 0644)
+	pool, err := LoadAnswerPool(yamlFile)
+	if err != nil {
+		t.Fatalf("should be able to load a YAML pool: %v", err)
+	}
+	if pool.Len() != 3 {
+		t.Fatalf("expected 3 answers without the blank one, got %d", pool.Len())
+	}
+	got := pool.For("was ist das beste an montagen")
+	if len(got) != 3 || got[0].Text != "Dass sie irgendwann Dienstag werden." || got[2].Prompt != "" {
+		t.Fatalf("expected the prompt's answers before the generic one, got %+v", got)
+	}
+	generic := pool.For("Another question?")
+	if len(generic) != 1 {
+		t.Fatalf("expected only the generic answer for other prompts, got %+v", generic)
+	}
+	if a, err := pool.Get(generic[0].ID); err != nil || a.Text != "Darüber denke ich lieber nicht nach." {
+		t.Fatalf("should be able to get an answer by ID: %+v (%v)", a, err)
+	}
+	if _, err := pool.Get("99.1"); err != ErrPoolAnswerGone {
+		t.Fatalf("expected ErrPoolAnswerGone, got %v", err)
+	}
+
+	jsonFile := filepath.Join(dir, "answers.json")
+	os.WriteFile(jsonFile, []byte(`[{"prompt": "Q?", "answers": ["A."]}]`), 0644)
+	if pool, err := LoadAnswerPool(jsonFile); err != nil || len(pool.For("Q?")) != 1 {
+		t.Fatalf("should be able to load a JSON pool: %v", err)
+	}
+	os.WriteFile(jsonFile, []byte(`{"prompt": "Q?"}`), 0644)
+	if _, err := LoadAnswerPool(jsonFile); err == nil {
+		t.Fatal("expected an error for a malformed pool")
+	}
+}
@@ -0,0 +1,66 @@
+package game
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// Attachment kinds
+const (
+	AttachmentImage = "image" // shown inline with the prompt
+	AttachmentLink  = "link"  // shown as a link next to the prompt
+)
+
+// maxAttachmentURL caps attachment URLs, they go out with every state update.
+const maxAttachmentURL = 2048
+
+var ErrInvalidAttachment = errors.New("attachment must be an http(s) URL of at most 2048 characters")
+
+// Attachment is an image or link shown with a round's prompt, for rounds like
+// "caption this picture". Only the URL is stored, clients load it themselves.
+type Attachment struct {
+	Kind string `json:"kind"`
+	URL  string `json:"url"`
+}
+
+func (a Attachment) valid() bool {
+	if a.Kind != AttachmentImage && a.Kind != AttachmentLink || len(a.URL) > maxAttachmentURL {
+		return false
+	}
+	u, err := url.Parse(a.URL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// AttachToPrompt attaches an image or link to the current round's prompt,
+// replacing an earlier one; nil removes it. The prompt must still be up, in
+// Answering or Voting.
+func (s *SessionCtx) AttachToPrompt(hostToken string, a *Attachment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return ErrNotHost
+	}
+	r := s.currentRound()
+	if r == nil || s.Phase != PhaseAnswering && s.Phase != PhaseVoting {
+		return ErrInvalidPhase
+	}
+	if a != nil {
+		a = &Attachment{Kind: a.Kind, URL: strings.TrimSpace(a.URL)}
+		if !a.valid() {
+			return ErrInvalidAttachment
+		}
+	}
+	r.Attachment = a
+	s.logEvent(walEvent{Type: walAttachment, RoundID: r.ID, Attachment: a})
+	return nil
+}
+
+// setAttachment replays an attachment. Callers must hold s.mu.
+func (s *SessionCtx) setAttachment(roundID string, a *Attachment) {
+	for _, r := range s.Rounds {
+		if r.ID == roundID {
+			r.Attachment = a
+		}
+	}
+}
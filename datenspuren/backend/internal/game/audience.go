@@ -0,0 +1,85 @@
+package game
+
+import "errors"
+
+var ErrAudienceOff = errors.New("audience voting is off")
+
+// AudienceTally is how the audience voted in a round. Audience votes don't
+// score on their own: the answer most of the audience picked counts as
+// Weight player votes.
+type AudienceTally struct {
+	Votes    map[string]int `json:"votes"` // submissionID -> audience votes
+	Total    int            `json:"total"`
+	Majority string         `json:"majority,omitempty"` // empty on a tie
+	Weight   int            `json:"weight"`
+}
+
+// AudienceVote records the vote of an audience member, identified by a key
+// of the caller's choosing, e.g. their socket. Voting again changes the
+// vote. Audience members see all answers, voting groups don't apply to them.
+func (s *SessionCtx) AudienceVote(voter, submissionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Config.AudienceWeight <= 0 {
+		return ErrAudienceOff
+	}
+	if s.Phase != PhaseVoting {
+		return ErrInvalidPhase
+	}
+	if s.submissions[submissionID] == nil {
+		return ErrUnknownTarget
+	}
+	if r := s.currentRound(); r != nil {
+		submissionID = r.representative(submissionID)
+		if r.eliminated(submissionID) {
+			return ErrEliminated
+		}
+		if r.hidden(submissionID) {
+			return ErrUnknownTarget
+		}
+	}
+	if _, voted := s.audienceVotes[voter]; !voted && full(len(s.audienceVotes), s.limits.Votes) {
+		return ErrStorageFull
+	}
+	s.putAudienceVote(voter, submissionID)
+	s.logEvent(walEvent{Type: walAudienceVote, PlayerID: voter, SubmissionID: submissionID})
+	return nil
+}
+
+// putAudienceVote stores a vote. Callers must hold s.mu.
+func (s *SessionCtx) putAudienceVote(voter, submissionID string) {
+	if s.audienceVotes == nil {
+		s.audienceVotes = make(map[string]string)
+	}
+	s.audienceVotes[voter] = submissionID
+}
+
+// AudienceTally tallies the current round's audience votes, nil if the
+// session has no audience voting.
+func (s *SessionCtx) AudienceTally() *AudienceTally {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.audienceTally()
+}
+
+// audienceTally is AudienceTally for callers holding s.mu.
+func (s *SessionCtx) audienceTally() *AudienceTally {
+	if s.Config.AudienceWeight <= 0 {
+		return nil
+	}
+	t := &AudienceTally{Votes: map[string]int{}, Weight: s.Config.AudienceWeight}
+	for _, id := range s.audienceVotes {
+		t.Votes[id]++
+		t.Total++
+	}
+	best := 0
+	for id, n := range t.Votes {
+		switch {
+		case n > best:
+			best, t.Majority = n, id
+		case n == best:
+			t.Majority = ""
+		}
+	}
+	return t
+}
@@ -0,0 +1,179 @@
+package game
+
+import (
+	"sort"
+
+	"github.com/kiliankoe/gptdash/internal/i18n"
+)
+
+// Round award names.
+const (
+	// AwardFooledEveryone goes to the authors of answers that drew more
+	// votes than the AI's.
+	AwardFooledEveryone = "fooledEveryone"
+)
+
+// End-of-game award names, see ComputeAwards.
+const (
+	AwardBestDetector  = "bestDetector"  // found the AI most often
+	AwardBiggestLiar   = "biggestLiar"   // drew the most votes for their answers
+	AwardMostFooled    = "mostFooled"    // voted for human answers most often
+	AwardFastestWriter = "fastestWriter" // quickest answers on average
+)
+
+// awardTitles are the English titles of the awards, see i18n.
+var awardTitles = map[string]string{
+	AwardFooledEveryone: "Fooled everyone",
+	AwardBestDetector:   "AI detective",
+	AwardBiggestLiar:    "Biggest liar",
+	AwardMostFooled:     "Most often fooled",
+	AwardFastestWriter:  "Fastest writer",
+}
+
+// awardTitle returns an award's title in the session's language. Callers
+// must hold s.mu.
+func (s *SessionCtx) awardTitle(name string) string {
+	return i18n.T(s.Config.Language, awardTitles[name])
+}
+
+// Award is a superlative earned over the whole game. Value is what won it:
+// a count of votes or, for the fastest writer, average seconds per answer.
+// Tied players each get the award.
+type Award struct {
+	Name       string  `json:"name"`
+	Title      string  `json:"title"`
+	PlayerID   string  `json:"playerId"`
+	PlayerName string  `json:"playerName"`
+	Value      float64 `json:"value"`
+}
+
+// RoundAward is a named bonus a player earned in a round, shown at reveal.
+type RoundAward struct {
+	Name         string `json:"name"`
+	Title        string `json:"title"`
+	PlayerID     string `json:"playerId"`
+	PlayerName   string `json:"playerName"`
+	SubmissionID string `json:"submissionId"`
+	Points       int    `json:"points"`
+}
+
+// awardFooledBonus grants Config.FooledBonus to everyone whose answer beat
+// the AI's in votes. Callers must hold s.mu and pass the round's tally.
+func (s *SessionCtx) awardFooledBonus(r *Round, votesFor map[string]int, aiID string) {
+	bonus := s.Config.FooledBonus
+	if r == nil || aiID == "" || bonus <= 0 {
+		return
+	}
+	for subID, count := range votesFor {
+		sub := s.submissions[subID]
+		if sub == nil || subID == aiID || sub.PlayerID == "AI" || count <= votesFor[aiID] {
+			continue
+		}
+		authors := []string{sub.PlayerID}
+		if len(r.Clusters[subID]) > 0 {
+			authors = s.clusterAuthors(r, subID)
+		}
+		for _, id := range authors {
+			s.Scores[id] += bonus
+			s.roundPoints[id] += bonus
+			r.Awards = append(r.Awards, RoundAward{Name: AwardFooledEveryone, Title: s.awardTitle(AwardFooledEveryone), PlayerID: id, PlayerName: s.playerName(id), SubmissionID: subID, Points: bonus})
+		}
+	}
+	sort.Slice(r.Awards, func(i, j int) bool { return r.Awards[i].PlayerName < r.Awards[j].PlayerName })
+}
+
+// RoundAwards returns the awards of the current round, empty until it is
+// scored.
+func (s *SessionCtx) RoundAwards() []RoundAward {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r := s.currentRound(); r != nil {
+		return append([]RoundAward{}, r.Awards...)
+	}
+	return []RoundAward{}
+}
+
+// ComputeAwards hands out the end-of-game superlatives from the scored
+// rounds. Rounds whose answers were compacted away don't count.
+func ComputeAwards(s *SessionCtx) []Award {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.computeAwards()
+}
+
+// computeAwards is ComputeAwards for callers holding s.mu.
+func (s *SessionCtx) computeAwards() []Award {
+	detected := map[string]float64{}
+	fooledOthers := map[string]float64{}
+	fooled := map[string]float64{}
+	answerSeconds := map[string]float64{}
+	answers := map[string]int{}
+	for _, rs := range s.history {
+		votes := map[string]int{}
+		for _, sub := range rs.Submissions {
+			votes[sub.ID] = sub.Votes
+		}
+		for _, sub := range rs.Submissions {
+			if sub.IsAI {
+				for _, id := range sub.VoterIDs {
+					detected[id]++
+				}
+				continue
+			}
+			for _, id := range sub.VoterIDs {
+				fooled[id]++
+			}
+			if sub.AuthorID == "AI" {
+				// the real AI answer, swapped by a host cheat
+				continue
+			}
+			n := votes[sub.ID]
+			if sub.MergedInto != "" {
+				// merged answers share the votes of their option
+				n = votes[sub.MergedInto]
+			}
+			fooledOthers[sub.AuthorID] += float64(n)
+			if sub.AnswerSeconds > 0 {
+				answerSeconds[sub.AuthorID] += sub.AnswerSeconds
+				answers[sub.AuthorID]++
+			}
+		}
+	}
+	avgSeconds := map[string]float64{}
+	for id, n := range answers {
+		// negated, so the highest value wins like for the other awards
+		avgSeconds[id] = -answerSeconds[id] / float64(n)
+	}
+
+	var out []Award
+	out = append(out, s.award(AwardBestDetector, detected)...)
+	out = append(out, s.award(AwardBiggestLiar, fooledOthers)...)
+	out = append(out, s.award(AwardMostFooled, fooled)...)
+	fastest := s.award(AwardFastestWriter, avgSeconds)
+	for i := range fastest {
+		fastest[i].Value = -fastest[i].Value
+	}
+	return append(out, fastest...)
+}
+
+// award gives name to the players with the highest nonzero value. Callers
+// must hold s.mu.
+func (s *SessionCtx) award(name string, values map[string]float64) []Award {
+	best := 0.0
+	for id, v := range values {
+		if s.PlayersByID[id] != nil && v != 0 && (best == 0 || v > best) {
+			best = v
+		}
+	}
+	var out []Award
+	if best == 0 {
+		return out
+	}
+	for id, v := range values {
+		if s.PlayersByID[id] != nil && v == best {
+			out = append(out, Award{Name: name, Title: s.awardTitle(name), PlayerID: id, PlayerName: s.playerName(id), Value: v})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PlayerName < out[j].PlayerName })
+	return out
+}
@@ -0,0 +1,70 @@
+package game
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"math/rand"
+	"strings"
+	"sync"
+)
+
+var ErrNoPrompt = errors.New("no prompt matches")
+
+//go:embed builtin_prompts.json
+var builtinPromptsJSON []byte
+
+// BuiltinPrompts returns the curated prompts shipped with the server, for
+// hosts who don't have a deck of their own.
+var BuiltinPrompts = sync.OnceValue(func() []DeckPrompt {
+	deck, err := ParseDeck(bytes.NewReader(builtinPromptsJSON), "json")
+	if err != nil {
+		panic("invalid built-in prompts: " + err.Error())
+	}
+	return deck
+})
+
+// RandomPrompt suggests a prompt from the library, which may be nil, and
+// the built-in prompts, optionally only of a category and language.
+// Prompts without a language fit any. Prompts in played, e.g. the session's
+// earlier rounds, are only suggested once nothing else is left.
+func RandomPrompt(lib *PromptLibrary, category, language string, played map[string]bool) (DeckPrompt, error) {
+	var pool []DeckPrompt
+	if lib != nil {
+		pool = lib.List(category)
+	}
+	for _, p := range BuiltinPrompts() {
+		if category == "" || strings.EqualFold(p.Category, category) {
+			pool = append(pool, p)
+		}
+	}
+	var matching, fresh []DeckPrompt
+	for _, p := range pool {
+		if language != "" && p.Language != "" && !strings.EqualFold(p.Language, language) {
+			continue
+		}
+		matching = append(matching, p)
+		if !played[normalizePrompt(p.Prompt)] {
+			fresh = append(fresh, p)
+		}
+	}
+	if len(fresh) == 0 {
+		fresh = matching
+	}
+	if len(fresh) == 0 {
+		return DeckPrompt{}, ErrNoPrompt
+	}
+	return fresh[rand.Intn(len(fresh))], nil
+}
+
+// PlayedPrompts returns the normalized prompts of the session's rounds so
+// far, to pass to RandomPrompt.
+func (s *SessionCtx) PlayedPrompts() map[string]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	played := make(map[string]bool, len(s.Rounds))
+	for _, r := range s.Rounds {
+		played[normalizePrompt(r.Prompt)] = true
+	}
+	return played
+}
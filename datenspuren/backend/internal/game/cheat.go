@@ -0,0 +1,162 @@
+package game
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrCheatsDisabled = errors.New("cheats are disabled")
+	ErrUnknownTarget  = errors.New("unknown player or submission")
+)
+
+// Host cheats, for the laughs on stage. Every one ends up in the audit log.
+const (
+	CheatBonus  = "bonus"  // award (or take) points to a player
+	CheatMarkAI = "markAI" // reveal another submission as the AI's
+	CheatVotes  = "votes"  // override a submission's vote count
+)
+
+// CheatEntry is one audit-logged host cheat.
+type CheatEntry struct {
+	At         time.Time `json:"at"`
+	RoundIndex int       `json:"roundIndex"`
+	Action     string    `json:"action"`
+	Target     string    `json:"target"` // player ID for bonus, submission ID otherwise
+	Value      int       `json:"value,omitempty"`
+}
+
+// SetCheats unlocks or locks the host's cheat actions for the session.
+func (s *SessionCtx) SetCheats(hostToken string, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return ErrNotHost
+	}
+	s.cheats = enabled
+	s.logEvent(walEvent{Type: walCheats, Enabled: enabled})
+	return nil
+}
+
+func (s *SessionCtx) CheatsEnabled() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cheats
+}
+
+// Cheat performs a host cheat, see Cheat*. Bonus points can be handed out
+// at any time; marking the AI and overriding votes only while voting, so
+// they take effect when the round is scored.
+func (s *SessionCtx) Cheat(hostToken, action, target string, value int) (CheatEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return CheatEntry{}, ErrNotHost
+	}
+	if !s.cheats {
+		return CheatEntry{}, ErrCheatsDisabled
+	}
+	switch action {
+	case CheatBonus:
+		if s.PlayersByID[target] == nil {
+			return CheatEntry{}, ErrUnknownTarget
+		}
+	case CheatMarkAI, CheatVotes:
+		if s.Phase != PhaseVoting {
+			return CheatEntry{}, ErrInvalidPhase
+		}
+		if s.submissions[target] == nil {
+			return CheatEntry{}, ErrUnknownTarget
+		}
+		if action == CheatVotes && value < 0 {
+			return CheatEntry{}, errors.New("vote count must not be negative")
+		}
+	default:
+		return CheatEntry{}, errors.New("unknown cheat")
+	}
+	e := CheatEntry{At: s.now().UTC(), RoundIndex: s.RoundIx, Action: action, Target: target, Value: value}
+	s.applyCheat(e)
+	s.logEvent(walEvent{Type: walCheat, At: e.At, Cheat: &e})
+	return e, nil
+}
+
+// applyCheat records e and puts it into effect. Callers must hold s.mu.
+func (s *SessionCtx) applyCheat(e CheatEntry) {
+	switch e.Action {
+	case CheatBonus:
+		s.Scores[e.Target] += e.Value
+	case CheatMarkAI:
+		if r := s.currentRound(); r != nil {
+			r.FakeAI = e.Target
+		}
+	case CheatVotes:
+		if r := s.currentRound(); r != nil {
+			if r.VoteOverrides == nil {
+				r.VoteOverrides = make(map[string]int)
+			}
+			r.VoteOverrides[e.Target] = e.Value
+		}
+	}
+	s.cheatLog = evict(append(s.cheatLog, e), s.limits.CheatLog)
+}
+
+// CheatLog returns the audit log of all host cheats.
+func (s *SessionCtx) CheatLog() []CheatEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]CheatEntry(nil), s.cheatLog...)
+}
+
+// revealedAI is the submission revealed as the AI's, which a host may have
+// swapped. Scoring and the reveal go by it; the archive keeps
+// AISubmissionID, see RoundSummary.RevealedAI. Callers must hold s.mu.
+func (r *Round) revealedAI() string {
+	if r.FakeAI != "" {
+		return r.FakeAI
+	}
+	return r.AISubmissionID
+}
+
+// PlayerRound is CurrentRound as everyone but the host gets it: once a
+// submission was marked as the AI's, the round names it in AISubmissionID so
+// the real answer doesn't give the cheat away.
+func (s *SessionCtx) PlayerRound() *Round {
+	r := s.CurrentRound()
+	if r != nil && r.FakeAI != "" {
+		r.AISubmissionID = r.FakeAI
+	}
+	return r
+}
+
+// RevealedAISubmission is the ID of the submission revealed as the AI's in
+// the current round.
+func (s *SessionCtx) RevealedAISubmission() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r := s.currentRound(); r != nil {
+		return r.revealedAI()
+	}
+	return ""
+}
+
+// voteCounts tallies the votes per submission, honoring the host's
+// overrides. Callers must hold s.mu.
+func (s *SessionCtx) voteCounts() map[string]int {
+	counts := map[string]int{}
+	for _, v := range s.votesByVoter {
+		counts[v.TargetSubmissionID]++
+	}
+	if r := s.currentRound(); r != nil {
+		for id, n := range r.VoteOverrides {
+			counts[id] = n
+		}
+	}
+	return counts
+}
+
+// VoteCounts tallies the current round's votes per submission.
+func (s *SessionCtx) VoteCounts() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.voteCounts()
+}
@@ -0,0 +1,140 @@
+package game
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// clusterSimilarity is the share of words two answers must have in common
+// (Jaccard index) to be merged, see similarAnswers.
+const clusterSimilarity = 0.8
+
+// clusterAnswers merges near-duplicate human answers into one voting option
+// each, so large rooms don't vote on ten variants of the same joke. The
+// answer coming first in the seeded reading order represents its cluster;
+// the others leave the reading order and their authors share its points.
+// The AI's answer and hidden ones are never merged. Callers must hold s.mu.
+func (s *SessionCtx) clusterAnswers() {
+	r := s.currentRound()
+	if r == nil {
+		return
+	}
+	r.Clusters = nil
+	if !s.Config.ClusterAnswers {
+		return
+	}
+	type rep struct {
+		id    string
+		words map[string]bool
+		norm  string
+	}
+	var reps []*rep
+	order := make([]string, 0, len(r.ReadingOrder))
+	for _, id := range r.ReadingOrder {
+		sub := s.submissions[id]
+		if sub == nil || id == r.AISubmissionID || sub.PlayerID == "AI" || r.hidden(id) {
+			order = append(order, id)
+			continue
+		}
+		norm, words := normalizeAnswer(sub.Text)
+		merged := false
+		for _, c := range reps {
+			if similarAnswers(c.norm, c.words, norm, words) {
+				if r.Clusters == nil {
+					r.Clusters = make(map[string][]string)
+				}
+				r.Clusters[c.id] = append(r.Clusters[c.id], id)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			reps = append(reps, &rep{id: id, words: words, norm: norm})
+			order = append(order, id)
+		}
+	}
+	r.ReadingOrder = order
+}
+
+// normalizeAnswer lowercases an answer and drops punctuation, returning it
+// as a single string and as a set of words.
+func normalizeAnswer(text string) (string, map[string]bool) {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsNumber(c)
+	})
+	words := make(map[string]bool, len(fields))
+	for _, w := range fields {
+		words[w] = true
+	}
+	return strings.Join(fields, " "), words
+}
+
+// similarAnswers reports whether two normalized answers are the same, or
+// share enough words to count as near-duplicates. Very short answers must
+// match exactly, one changed word would be most of them.
+func similarAnswers(a string, aWords map[string]bool, b string, bWords map[string]bool) bool {
+	if a == b {
+		return a != ""
+	}
+	if len(aWords) < 3 || len(bWords) < 3 {
+		return false
+	}
+	common := 0
+	for w := range aWords {
+		if bWords[w] {
+			common++
+		}
+	}
+	union := len(aWords) + len(bWords) - common
+	return float64(common) >= clusterSimilarity*float64(union)
+}
+
+// representative returns the submission standing in for id's cluster, which
+// is id itself if it wasn't merged.
+func (r *Round) representative(id string) string {
+	for rep, members := range r.Clusters {
+		for _, m := range members {
+			if m == id {
+				return rep
+			}
+		}
+	}
+	return id
+}
+
+// clusterAuthors returns the players sharing the points of a voting option,
+// the representative's author first. Callers must hold s.mu.
+func (s *SessionCtx) clusterAuthors(r *Round, repID string) []string {
+	var authors []string
+	if sub := s.submissions[repID]; sub != nil {
+		authors = append(authors, sub.PlayerID)
+	}
+	var rest []string
+	for _, id := range r.Clusters[repID] {
+		if sub := s.submissions[id]; sub != nil {
+			rest = append(rest, sub.PlayerID)
+		}
+	}
+	sort.Strings(rest)
+	return append(authors, rest...)
+}
+
+// ClusterMembers returns copies of the answers merged into a voting option.
+func (s *SessionCtx) ClusterMembers(submissionID string) []*Submission {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.currentRound()
+	if r == nil {
+		return nil
+	}
+	var out []*Submission
+	for _, id := range r.Clusters[submissionID] {
+		if sub := s.submissions[id]; sub != nil {
+			cp := *sub
+			cp.Translations = copyStrings(sub.Translations)
+			out = append(out, &cp)
+		}
+	}
+	return out
+}
@@ -0,0 +1,268 @@
+package game
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidDeck       = errors.New("invalid prompt deck")
+	ErrLibraryPromptGone = errors.New("prompt not in library")
+)
+
+// maxDeckPrompts caps the prompts of a single import.
+const maxDeckPrompts = 1000
+
+// DeckPrompt is a prompt from an imported deck.
+type DeckPrompt struct {
+	ID       string `json:"id,omitempty"` // set once the prompt is in the library
+	Prompt   string `json:"prompt"`
+	Category string `json:"category,omitempty"`
+	Language string `json:"language,omitempty"` // ISO 639-1
+	AIAnswer string `json:"aiAnswer,omitempty"` // canned AI answer, skips generation
+}
+
+// ParseDeck reads a deck as JSON (a list of prompts, or an object with a
+// "prompts" list) or as CSV with a header row naming the columns prompt,
+// category, language and ai_answer. Prompts without text are skipped.
+func ParseDeck(r io.Reader, format string) ([]DeckPrompt, error) {
+	var deck []DeckPrompt
+	switch format {
+	case "json":
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &deck); err != nil {
+			var wrapped struct {
+				Prompts []DeckPrompt `json:"prompts"`
+			}
+			if json.Unmarshal(b, &wrapped) != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidDeck, err)
+			}
+			deck = wrapped.Prompts
+		}
+	case "csv":
+		rows, err := csv.NewReader(r).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDeck, err)
+		}
+		if len(rows) == 0 {
+			return nil, fmt.Errorf("%w: missing header row", ErrInvalidDeck)
+		}
+		cols := map[string]int{}
+		for i, name := range rows[0] {
+			name = strings.ToLower(strings.TrimSpace(name))
+			cols[strings.NewReplacer("_", "", " ", "").Replace(name)] = i
+		}
+		if _, ok := cols["prompt"]; !ok {
+			return nil, fmt.Errorf("%w: no prompt column", ErrInvalidDeck)
+		}
+		field := func(row []string, col string) string {
+			if i, ok := cols[col]; ok && i < len(row) {
+				return row[i]
+			}
+			return ""
+		}
+		for _, row := range rows[1:] {
+			deck = append(deck, DeckPrompt{
+				Prompt:   field(row, "prompt"),
+				Category: field(row, "category"),
+				Language: field(row, "language"),
+				AIAnswer: field(row, "aianswer"),
+			})
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidDeck, format)
+	}
+	out := deck[:0]
+	for _, p := range deck {
+		p.ID = ""
+		p.Prompt = strings.TrimSpace(p.Prompt)
+		p.Category = strings.TrimSpace(p.Category)
+		p.Language = strings.ToLower(strings.TrimSpace(p.Language))
+		p.AIAnswer = strings.TrimSpace(p.AIAnswer)
+		if p.Prompt == "" {
+			continue
+		}
+		if len(p.Prompt) > 500 || len(p.AIAnswer) > 1000 {
+			return nil, fmt.Errorf("%w: prompt %q is too long", ErrInvalidDeck, p.Prompt)
+		}
+		out = append(out, p)
+	}
+	if len(out) > maxDeckPrompts {
+		return nil, fmt.Errorf("%w: more than %d prompts", ErrInvalidDeck, maxDeckPrompts)
+	}
+	return out, nil
+}
+
+// PromptLibrary holds imported prompts available to every session, persisted
+// to a JSON file. An empty filename keeps them in memory only.
+type PromptLibrary struct {
+	mu       sync.Mutex
+	filename string
+	prompts  []DeckPrompt
+}
+
+func LoadPromptLibrary(filename string) (*PromptLibrary, error) {
+	lib := &PromptLibrary{filename: filename}
+	prompts, err := readPromptLibrary(filename)
+	if err != nil {
+		return nil, err
+	}
+	lib.prompts = prompts
+	return lib, nil
+}
+
+// Reload reads the library file again, e.g. after it was edited by hand.
+// On error the library keeps its prompts.
+func (l *PromptLibrary) Reload() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	prompts, err := readPromptLibrary(l.filename)
+	if err != nil {
+		return err
+	}
+	l.prompts = prompts
+	return nil
+}
+
+func readPromptLibrary(filename string) ([]DeckPrompt, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt library: %w", err)
+	}
+	var prompts []DeckPrompt
+	if err := json.Unmarshal(b, &prompts); err != nil {
+		return nil, fmt.Errorf("failed to parse prompt library: %w", err)
+	}
+	return prompts, nil
+}
+
+// Add puts deck prompts into the library, skipping prompts it already has,
+// and returns the ones added.
+func (l *PromptLibrary) Add(deck []DeckPrompt) ([]DeckPrompt, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	known := make(map[string]bool, len(l.prompts))
+	for _, p := range l.prompts {
+		known[normalizePrompt(p.Prompt)] = true
+	}
+	var added []DeckPrompt
+	for _, p := range deck {
+		key := normalizePrompt(p.Prompt)
+		if known[key] {
+			continue
+		}
+		known[key] = true
+		p.ID = uuid.NewString()
+		added = append(added, p)
+	}
+	l.prompts = append(l.prompts, added...)
+	return added, l.save()
+}
+
+// List returns the library's prompts, optionally only those of a category.
+func (l *PromptLibrary) List(category string) []DeckPrompt {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := []DeckPrompt{}
+	for _, p := range l.prompts {
+		if category == "" || strings.EqualFold(p.Category, category) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (l *PromptLibrary) Get(id string) (DeckPrompt, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, p := range l.prompts {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return DeckPrompt{}, ErrLibraryPromptGone
+}
+
+// Remove retires a prompt from the library.
+func (l *PromptLibrary) Remove(id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, p := range l.prompts {
+		if p.ID == id {
+			l.prompts = append(l.prompts[:i], l.prompts[i+1:]...)
+			return l.save()
+		}
+	}
+	return ErrLibraryPromptGone
+}
+
+// save writes the library atomically. Callers must hold l.mu.
+func (l *PromptLibrary) save() error {
+	if l.filename == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(l.prompts, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.filename), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	tmp := l.filename + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return fmt.Errorf("failed to write prompt library: %w", err)
+	}
+	return os.Rename(tmp, l.filename)
+}
+
+// normalizePrompt makes prompts that differ only in case, spacing or
+// punctuation compare equal.
+func normalizePrompt(prompt string) string {
+	norm, _ := normalizeAnswer(prompt)
+	return norm
+}
+
+// ImportPrompts queues deck prompts for upcoming rounds. Prompts with a
+// canned AI answer are ready to play right away.
+func (s *SessionCtx) ImportPrompts(hostToken string, deck []DeckPrompt) ([]QueuedPrompt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return nil, ErrNotHost
+	}
+	if len(deck) == 0 {
+		return nil, nil
+	}
+	if full(len(s.promptQueue)+len(deck)-1, s.limits.QueuedPrompts) {
+		return nil, ErrStorageFull
+	}
+	out := make([]QueuedPrompt, 0, len(deck))
+	for _, p := range deck {
+		q := s.queuePrompt(QueuedPrompt{Prompt: p.Prompt, Category: p.Category, Language: p.Language})
+		if p.AIAnswer != "" {
+			meta := AIMetadata{Provider: "deck", Model: "canned"}
+			s.setQueuedAIAnswer(q.ID, p.AIAnswer, meta)
+			s.logEvent(walEvent{Type: walQueuedAnswer, QueuedID: q.ID, Text: p.AIAnswer, Meta: &meta})
+		}
+		out = append(out, *q)
+	}
+	return out, nil
+}
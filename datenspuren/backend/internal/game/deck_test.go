@@ -0,0 +1,150 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDeck(t *testing.T) {
+	csv := "Prompt,Category,Language,AI Answer\n" +
+		"\"What's the best pizza topping?\",Food,EN,Pineapple\n" +
+		" ,Food,en,\n" +
+		"Was ist ein Computer?,Tech,de,\n"
+	deck, err := ParseDeck(strings.NewReader(csv), "csv")
+	if err != nil {
+		t.Fatalf("should be able to parse a CSV deck: %v", err)
+	}
+	if len(deck) != 2 {
+		t.Fatalf("expected 2 prompts without the empty row, got %+v", deck)
+	}
+	want := DeckPrompt{Prompt: "What's the best pizza topping?", Category: "Food", Language: "en", AIAnswer: "Pineapple"}
+	if deck[0] != want {
+		t.Fatalf("expected %+v, got %+v", want, deck[0])
+	}
+
+	for _, doc := range []string{
+		`[{"prompt":"Q1","category":"A"},{"prompt":"Q2","aiAnswer":"Canned"}]`,
+		`{"prompts":[{"prompt":"Q1","category":"A"},{"prompt":"Q2","aiAnswer":"Canned"}]}`,
+	} {
+		deck, err := ParseDeck(strings.NewReader(doc), "json")
+		if err != nil {
+			t.Fatalf("should be able to parse a JSON deck: %v", err)
+		}
+		if len(deck) != 2 || deck[0].Category != "A" || deck[1].AIAnswer != "Canned" {
+			t.Fatalf("unexpected deck from %s: %+v", doc, deck)
+		}
+	}
+
+	if _, err := ParseDeck(strings.NewReader("category\nFood\n"), "csv"); err == nil {
+		t.Fatal("expected an error for a CSV deck without prompt column")
+	}
+	if _, err := ParseDeck(strings.NewReader("{"), "json"); err == nil {
+		t.Fatal("expected an error for broken JSON")
+	}
+	if _, err := ParseDeck(strings.NewReader(""), "xml"); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
+
+func TestPromptLibrary(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "prompts.json")
+	lib, err := LoadPromptLibrary(file)
+	if err != nil {
+		t.Fatalf("should be able to load the prompt library: %v", err)
+	}
+	added, err := lib.Add([]DeckPrompt{{Prompt: "Q1", Category: "Food"}, {Prompt: "Q2"}})
+	if err != nil || len(added) != 2 {
+		t.Fatalf("should be able to add prompts: %v %+v", err, added)
+	}
+	added, _ = lib.Add([]DeckPrompt{{Prompt: "q1!"}, {Prompt: "Q3", Category: "food"}})
+	if len(added) != 1 || added[0].Prompt != "Q3" {
+		t.Fatalf("expected only Q3 to be added, got %+v", added)
+	}
+
+	// Reload from disk to verify persistence
+	lib, err = LoadPromptLibrary(file)
+	if err != nil {
+		t.Fatalf("should be able to reload the prompt library: %v", err)
+	}
+	if all := lib.List(""); len(all) != 3 {
+		t.Fatalf("expected 3 prompts, got %+v", all)
+	}
+	food := lib.List("FOOD")
+	if len(food) != 2 {
+		t.Fatalf("expected 2 food prompts, got %+v", food)
+	}
+	if p, err := lib.Get(food[1].ID); err != nil || p.Prompt != "Q3" {
+		t.Fatalf("should be able to get a prompt by ID: %v %+v", err, p)
+	}
+	if _, err := lib.Get("nope"); err != ErrLibraryPromptGone {
+		t.Fatalf("expected ErrLibraryPromptGone, got %v", err)
+	}
+
+	// the file edited by hand is picked up on Reload
+	os.WriteFile(file, []byte(`[{"id":"x","prompt":"Edited"}]`), 0600)
+	if err := lib.Reload(); err != nil {
+		t.Fatalf("should be able to reload the library: %v", err)
+	}
+	if all := lib.List(""); len(all) != 1 || all[0].Prompt != "Edited" {
+		t.Fatalf("expected the edited library, got %+v", all)
+	}
+	os.WriteFile(file, []byte("not json"), 0600)
+	if err := lib.Reload(); err == nil || len(lib.List("")) != 1 {
+		t.Fatal("expected a broken file to be rejected and the library kept")
+	}
+}
+
+func TestImportPrompts(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 2})
+	session, _ := rm.Get(code)
+	deck := []DeckPrompt{{Prompt: "Q1", Category: "Food", Language: "en", AIAnswer: "Canned"}, {Prompt: "Q2"}}
+
+	if _, err := session.ImportPrompts("wrong", deck); err != ErrNotHost {
+		t.Fatalf("expected ErrNotHost, got %v", err)
+	}
+	queued, err := session.ImportPrompts(hostToken, deck)
+	if err != nil {
+		t.Fatalf("should be able to import prompts: %v", err)
+	}
+	if len(queued) != 2 || !queued[0].AIReady || queued[1].AIReady || queued[0].Category != "Food" {
+		t.Fatalf("unexpected queue: %+v", queued)
+	}
+
+	if _, err := session.StartQueuedPrompt(hostToken, queued[0].ID); err != nil {
+		t.Fatalf("should be able to start an imported prompt: %v", err)
+	}
+	r := session.CurrentRound()
+	if r.AISubmissionID == "" || session.submissions[r.AISubmissionID].Text != "Canned" {
+		t.Fatalf("expected the canned AI answer in the round, got %+v", r)
+	}
+}
+
+func TestRandomPrompt(t *testing.T) {
+	if len(BuiltinPrompts()) == 0 {
+		t.Fatal("expected built-in prompts")
+	}
+	lib, _ := LoadPromptLibrary("")
+	lib.Add([]DeckPrompt{{Prompt: "Q1", Category: "Local"}, {Prompt: "Q2", Category: "Local", Language: "en"}})
+
+	for range 20 {
+		p, err := RandomPrompt(lib, "local", "de", nil)
+		if err != nil || p.Prompt != "Q1" {
+			t.Fatalf("expected only Q1 to be a German local prompt, got %+v, %v", p, err)
+		}
+	}
+	played := map[string]bool{normalizePrompt("Q1"): true}
+	for range 20 {
+		if p, _ := RandomPrompt(lib, "Local", "", played); p.Prompt != "Q2" {
+			t.Fatalf("expected the unplayed Q2, got %+v", p)
+		}
+	}
+	if p, err := RandomPrompt(nil, "Tiere", "de", nil); err != nil || p.Category != "Tiere" {
+		t.Fatalf("expected a built-in prompt without a library, got %+v, %v", p, err)
+	}
+	if _, err := RandomPrompt(lib, "nope", "", nil); err != ErrNoPrompt {
+		t.Fatalf("expected ErrNoPrompt for an unknown category, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package game
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"time"
+)
+
+// sessionDump is everything a session holds, for operators debugging a
+// running game. Tokens are left out.
+type sessionDump struct {
+	Snapshot
+	EndedAt          time.Time          `json:"endedAt,omitzero"`
+	Config           SessionConfig      `json:"config"`
+	Seed             int64              `json:"seed"`
+	PhaseStartedAt   time.Time          `json:"phaseStartedAt"`
+	PhaseExtra       time.Duration      `json:"phaseExtra"`
+	LastActivity     time.Time          `json:"lastActivity"`
+	PlayerList       []*Player          `json:"playerList"`
+	Rounds           []*Round           `json:"rounds"`
+	AudienceVotes    map[string]string  `json:"audienceVotes"`
+	PromptCandidates []*PromptCandidate `json:"promptCandidates"`
+	PromptVotes      map[string]string  `json:"promptVotes"`
+	Scores           map[string]int     `json:"scores"`
+	RoundPoints      map[string]int     `json:"roundPoints"`
+	HiddenScores     bool               `json:"hiddenScores"`
+	Cheats           bool               `json:"cheats"`
+	CheatLog         []CheatEntry       `json:"cheatLog"`
+	History          []RoundSummary     `json:"history"`
+	PromptQueue      []*QueuedPrompt    `json:"promptQueue"`
+	Ready            map[string]bool    `json:"ready"`
+}
+
+// Dump returns the session's complete state as JSON. It is marshaled while
+// the session is locked, so it is consistent with itself.
+func (s *SessionCtx) Dump() (json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := sessionDump{
+		Snapshot:         s.snapshot(s.lastActivity),
+		EndedAt:          s.EndedAt,
+		Config:           s.Config,
+		Seed:             s.Seed,
+		PhaseStartedAt:   s.phaseStartedAt,
+		PhaseExtra:       s.phaseExtra,
+		LastActivity:     s.lastActivity,
+		PlayerList:       slices.SortedFunc(maps.Values(s.PlayersByID), func(a, b *Player) int { return a.JoinedAt.Compare(b.JoinedAt) }),
+		Rounds:           s.Rounds,
+		AudienceVotes:    s.audienceVotes,
+		PromptCandidates: s.promptCandidates,
+		PromptVotes:      s.promptVotes,
+		Scores:           s.Scores,
+		RoundPoints:      s.roundPoints,
+		HiddenScores:     s.freezeScores,
+		Cheats:           s.cheats,
+		CheatLog:         s.cheatLog,
+		History:          s.history,
+		PromptQueue:      s.promptQueue,
+		Ready:            s.ready,
+	}
+	return json.Marshal(d)
+}
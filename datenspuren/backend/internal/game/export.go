@@ -1,164 +1,293 @@
 package game
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
 
-// ExportSession exports the current game state to a text file
-func ExportSession(s *SessionCtx, filename string) error {
+// ExportPath returns the file a session is exported to inside dir. Each
+// session gets its own file, named after its start time and code.
+func ExportPath(s *SessionCtx, dir string) string {
+	return filepath.Join(dir, fmt.Sprintf("gptdash-%s-%s.txt", s.CreatedAt.Format("20060102-150405"), s.Code))
+}
+
+// exportTimeFormat is how exports show timestamps to readers, with the zone
+// so it's clear which clock the event ran on.
+const exportTimeFormat = "2006-01-02 15:04:05 MST"
+
+// exportLocation returns the time zone of the session's exports: its own
+// TimeZone, else the server-wide one in opts, else the server's local time.
+func (s *SessionCtx) exportLocation(opts ExportOptions) *time.Location {
+	if s.Config.TimeZone != "" {
+		if loc, err := time.LoadLocation(s.Config.TimeZone); err == nil {
+			return loc
+		}
+	}
+	if opts.Location != nil {
+		return opts.Location
+	}
+	return time.Local
+}
+
+// ExportSession writes the session to its own self-contained file in dir: a
+// machine-parsable front-matter header followed by the human-readable results
+// of every scored round, redacted according to opts. The file is rewritten on
+// each call, so calling it after every round keeps it up to date.
+func ExportSession(s *SessionCtx, dir string, opts ExportOptions) (string, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	// Create directory if it doesn't exist
-	dir := filepath.Dir(filename)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+		return "", fmt.Errorf("failed to create directory: %w", err)
 	}
-
-	// Check if file exists to determine if we need headers
-	fileExists := false
-	if _, err := os.Stat(filename); err == nil {
-		fileExists = true
+	filename := ExportPath(s, dir)
+	text, err := s.renderExport(opts)
+	if err != nil {
+		return "", err
 	}
 
-	// Open file in append mode
-	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+	// Write atomically so a crash mid-write never leaves a truncated export
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, []byte(text), 0644); err != nil {
+		return "", fmt.Errorf("failed to write to file: %w", err)
 	}
-	defer file.Close()
+	if err := os.Rename(tmp, filename); err != nil {
+		return "", fmt.Errorf("failed to write to file: %w", err)
+	}
+	return filename, nil
+}
+
+// ExportText returns what ExportSession writes to the session's file.
+func ExportText(s *SessionCtx, opts ExportOptions) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.renderExport(opts)
+}
 
-	// Build the export content
+// renderExport renders the session's export file. Callers must hold s.mu.
+func (s *SessionCtx) renderExport(opts ExportOptions) (string, error) {
+	opts.Location = s.exportLocation(opts)
 	var sb strings.Builder
+	if err := s.writeFrontMatter(&sb, opts); err != nil {
+		return "", err
+	}
 
-	// Add header only for new files or first round of a new session
-	if !fileExists || s.RoundIx == 1 {
-		if fileExists {
-			sb.WriteString("\n\n") // Add spacing between sessions
-		}
-		sb.WriteString(fmt.Sprintf("GPTdash Game Results - Session %s\n", s.Code))
-		sb.WriteString(fmt.Sprintf("Started: %s\n", time.Now().Format("2006-01-02 15:04:05")))
-		sb.WriteString(strings.Repeat("=", 50) + "\n\n")
+	sb.WriteString(fmt.Sprintf("GPTdash Game Results - Session %s\n", s.Code))
+	sb.WriteString(fmt.Sprintf("Started: %s\n", s.CreatedAt.In(opts.Location).Format(exportTimeFormat)))
+	sb.WriteString(strings.Repeat("=", 50) + "\n\n")
 
-		// Players list (only on first round)
-		sb.WriteString("Players:\n")
-		for _, p := range s.PlayersByID {
+	names := s.pseudonyms()
+	sb.WriteString("Players:\n")
+	for _, p := range s.joinOrder() {
+		if opts.Anonymize {
+			sb.WriteString(fmt.Sprintf("- %s\n", names[p.ID]))
+		} else {
 			sb.WriteString(fmt.Sprintf("- %s\n", p.Name))
 		}
+	}
+	sb.WriteString("\n")
+
+	for _, rs := range s.history {
+		s.writeRound(&sb, s.redactRound(rs, opts, names), opts.Location)
+	}
+
+	if len(s.cheatLog) > 0 {
+		sb.WriteString("Host cheats:\n")
+		for _, e := range s.cheatLog {
+			s.writeCheat(&sb, e, opts, names)
+		}
 		sb.WriteString("\n")
 	}
 
-	// Export only the current round (the last one)
-	if len(s.Rounds) > 0 {
-		round := s.Rounds[len(s.Rounds)-1]
-		sb.WriteString(fmt.Sprintf("Round %d: \"%s\"\n", round.Index, round.Prompt))
-		sb.WriteString(strings.Repeat("-", 40) + "\n")
-
-		// We have submission data for the current round
-		if len(s.submissions) > 0 {
-			aiSubmissionID := round.AISubmissionID
-
-			// List all submissions
-			for _, sub := range s.submissions {
-				if sub.PlayerID == "AI" {
-					sb.WriteString(fmt.Sprintf("- AI: \"%s\"\n", sub.Text))
-				} else {
-					player := s.PlayersByID[sub.PlayerID]
-					if player != nil {
-						sb.WriteString(fmt.Sprintf("- %s: \"%s\"\n", player.Name, sub.Text))
-					}
+	if !s.EndedAt.IsZero() {
+		if awards := s.computeAwards(); len(awards) > 0 {
+			sb.WriteString("Awards:\n")
+			for _, a := range awards {
+				name := a.PlayerName
+				if opts.Anonymize {
+					name = names[a.PlayerID]
 				}
+				sb.WriteString(fmt.Sprintf("- %s: %s (%g)\n", a.Name, name, a.Value))
 			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("Game ended at %s\n", s.EndedAt.In(opts.Location).Format(exportTimeFormat)))
+		sb.WriteString(strings.Repeat("=", 50) + "\n")
+	} else if !opts.Terminated.IsZero() {
+		if rs, ok := s.unfinishedRound(); ok {
+			sb.WriteString(fmt.Sprintf("Unfinished round (still in %s):\n", s.Phase))
+			s.writeRound(&sb, s.redactRound(rs, opts, names), opts.Location)
+		}
+		sb.WriteString(fmt.Sprintf("Game terminated by a server shutdown at %s\n", opts.Terminated.In(opts.Location).Format(exportTimeFormat)))
+		sb.WriteString(strings.Repeat("=", 50) + "\n")
+	}
+	return sb.String(), nil
+}
 
-			// Count votes per submission and track voters
-			voteCounts := make(map[string]int)
-			votersForSubmission := make(map[string][]string)
-			for voterID, vote := range s.votesByVoter {
-				voteCounts[vote.TargetSubmissionID]++
-				// Get voter name
-				voterName := "Unknown"
-				if voter := s.PlayersByID[voterID]; voter != nil {
-					voterName = voter.Name
-				}
-				votersForSubmission[vote.TargetSubmissionID] = append(votersForSubmission[vote.TargetSubmissionID], voterName)
-			}
+// writeFrontMatter writes a YAML front-matter block describing the session.
+// Callers must hold s.mu.
+func (s *SessionCtx) writeFrontMatter(sb *strings.Builder, opts ExportOptions) error {
+	cfg, err := json.Marshal(s.Config)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	ended := ""
+	if !s.EndedAt.IsZero() {
+		ended = s.EndedAt.In(opts.Location).Format(time.RFC3339)
+	}
+	sb.WriteString("---\n")
+	sb.WriteString(fmt.Sprintf("session: %q\n", s.Code))
+	sb.WriteString(fmt.Sprintf("provider: %q\n", s.Config.Provider))
+	sb.WriteString(fmt.Sprintf("model: %q\n", s.Config.Model))
+	sb.WriteString(fmt.Sprintf("rounds: %d\n", len(s.history)))
+	sb.WriteString(fmt.Sprintf("players: %d\n", len(s.PlayersByID)))
+	sb.WriteString(fmt.Sprintf("started: %q\n", s.CreatedAt.In(opts.Location).Format(time.RFC3339)))
+	sb.WriteString(fmt.Sprintf("ended: %q\n", ended))
+	sb.WriteString(fmt.Sprintf("seed: %q\n", s.ShuffleSeed()))
+	if !opts.Terminated.IsZero() {
+		sb.WriteString(fmt.Sprintf("terminated: %q\n", opts.Terminated.In(opts.Location).Format(time.RFC3339)))
+	}
+	sb.WriteString(fmt.Sprintf("timezone: %q\n", opts.Location.String()))
+	sb.WriteString(fmt.Sprintf("anonymized: %t\n", opts.Anonymize))
+	sb.WriteString(fmt.Sprintf("config: %s\n", cfg))
+	sb.WriteString("---\n\n")
+	return nil
+}
 
-			// Show vote results
-			if len(voteCounts) > 0 {
-				sb.WriteString("\nVotes:\n")
-				for subID, count := range voteCounts {
-					sub := s.submissions[subID]
-					if sub != nil {
-						name := "Unknown"
-						if sub.PlayerID == "AI" {
-							name = "AI"
-						} else if player := s.PlayersByID[sub.PlayerID]; player != nil {
-							name = player.Name
-						}
-						voters := votersForSubmission[subID]
-						sb.WriteString(fmt.Sprintf("- %s: %d vote(s) from %s\n", name, count, strings.Join(voters, ", ")))
-					}
-				}
+// writeRound writes the human-readable results of an archived round.
+// Callers must hold s.mu.
+func (s *SessionCtx) writeRound(sb *strings.Builder, rs RoundSummary, loc *time.Location) {
+	sb.WriteString(fmt.Sprintf("Round %d: \"%s\"\n", rs.Index, rs.Prompt))
+	if rs.Type == RoundImage {
+		sb.WriteString("Image round: players captioned an image generated from the prompt\n")
+	}
+	if a := rs.Attachment; a != nil {
+		sb.WriteString(fmt.Sprintf("Attachment (%s): %s\n", a.Kind, a.URL))
+	}
+	if rs.Index > 0 && rs.Index <= len(s.Rounds) {
+		round := s.Rounds[rs.Index-1]
+		if m := round.AIMeta; m != nil {
+			sb.WriteString(fmt.Sprintf("AI: %s/%s, %dms, %d prompt + %d completion tokens, finish reason %q\n",
+				m.Provider, m.Model, m.LatencyMs, m.PromptTokens, m.CompletionTokens, m.FinishReason))
+		} else if len(s.Config.BlindModels) > 0 {
+			sb.WriteString(fmt.Sprintf("Blind test model: %s/%s\n", round.Model.Provider, round.Model.Model))
+		}
+		if round.AIOriginal != "" {
+			sb.WriteString(fmt.Sprintf("AI answer edited by the host, generated as: \"%s\"\n", round.AIOriginal))
+		}
+		for _, n := range round.Notes {
+			sb.WriteString(fmt.Sprintf("Note (%s): %s\n", n.At.In(loc).Format("15:04"), n.Text))
+		}
+	}
+	sb.WriteString(strings.Repeat("-", 40) + "\n")
+	if rs.Compacted {
+		sb.WriteString(fmt.Sprintf("(answers no longer kept, %d of %d votes found the AI)\n\n", rs.AIVotes, rs.TotalVotes))
+		return
+	}
 
-				// Show who correctly identified the AI
-				correctGuessers := []string{}
-				for voterID, vote := range s.votesByVoter {
-					if vote.TargetSubmissionID == aiSubmissionID {
-						if player := s.PlayersByID[voterID]; player != nil {
-							correctGuessers = append(correctGuessers, player.Name)
-						}
-					}
-				}
-				if len(correctGuessers) > 0 {
-					sb.WriteString(fmt.Sprintf("\nCorrectly identified AI: %s\n", strings.Join(correctGuessers, ", ")))
+	for _, sub := range rs.Submissions {
+		sb.WriteString(fmt.Sprintf("- %s: \"%s\"", sub.AuthorName, sub.Text))
+		if sub.MergedInto != "" {
+			sb.WriteString(" (merged")
+			for _, rep := range rs.Submissions {
+				if rep.ID == sub.MergedInto {
+					sb.WriteString(" with " + rep.AuthorName + "'s answer")
 				}
 			}
+			sb.WriteString(")")
 		}
+		if sub.Hidden {
+			sb.WriteString(" (hidden by the host)")
+		}
+		sb.WriteString("\n")
+	}
 
-		// Current scores after this round
-		if len(s.Scores) > 0 {
-			sb.WriteString("\nScores after this round:\n")
-			// Sort players by score for consistent output
-			type playerScore struct {
-				Name  string
-				Score int
-			}
-			scores := make([]playerScore, 0, len(s.Scores))
-			for playerID, score := range s.Scores {
-				if player := s.PlayersByID[playerID]; player != nil {
-					scores = append(scores, playerScore{Name: player.Name, Score: score})
-				}
+	if rs.TotalVotes > 0 {
+		sb.WriteString("\nVotes:\n")
+		for _, sub := range rs.Submissions {
+			if sub.Votes > 0 {
+				sb.WriteString(fmt.Sprintf("- %s: %d vote(s) from %s\n", sub.AuthorName, sub.Votes, strings.Join(sub.Voters, ", ")))
 			}
-			// Simple sort by score (descending)
-			for i := 0; i < len(scores); i++ {
-				for j := i + 1; j < len(scores); j++ {
-					if scores[j].Score > scores[i].Score {
-						scores[i], scores[j] = scores[j], scores[i]
-					}
-				}
-			}
-			for _, ps := range scores {
-				sb.WriteString(fmt.Sprintf("- %s: %d points\n", ps.Name, ps.Score))
+		}
+		// Show who correctly identified the AI
+		for _, sub := range rs.Submissions {
+			if sub.IsAI && len(sub.Voters) > 0 {
+				sb.WriteString(fmt.Sprintf("\nCorrectly identified AI: %s\n", strings.Join(sub.Voters, ", ")))
 			}
 		}
+	}
 
-		sb.WriteString("\n")
-
-		// Add "Game ended" marker if this is the last round
-		if s.RoundIx >= s.Config.RoundCount {
-			sb.WriteString(fmt.Sprintf("Game ended at %s\n", time.Now().Format("2006-01-02 15:04:05")))
-			sb.WriteString(strings.Repeat("=", 50) + "\n")
+	if len(rs.Scores) > 0 {
+		sb.WriteString("\nScores after this round:\n")
+		for _, e := range rs.Scores {
+			sb.WriteString(fmt.Sprintf("- %s: %d points\n", e.Name, e.Points))
 		}
+		sb.WriteString(fmt.Sprintf("- AI: %d points\n", rs.AIScore))
 	}
+	sb.WriteString("\n")
+}
 
-	// Write to file
-	if _, err := file.WriteString(sb.String()); err != nil {
-		return fmt.Errorf("failed to write to file: %w", err)
+// writeCheat writes one line of the cheat audit log. Callers must hold s.mu.
+func (s *SessionCtx) writeCheat(sb *strings.Builder, e CheatEntry, opts ExportOptions, names map[string]string) {
+	name := func(playerID string) string {
+		if opts.Anonymize && playerID != "AI" {
+			return names[playerID]
+		}
+		return s.playerName(playerID)
+	}
+	// submissions are gone after the round, so resolve them via the archive
+	answer := e.Target
+	for _, rs := range s.history {
+		if rs.Index != e.RoundIndex {
+			continue
+		}
+		for _, sub := range rs.Submissions {
+			if sub.ID == e.Target && opts.sensitive(sub.Text) {
+				answer = fmt.Sprintf("%q", OmittedAnswer)
+			} else if sub.ID == e.Target {
+				answer = fmt.Sprintf("%q", sub.Text)
+			}
+		}
+	}
+	prefix := fmt.Sprintf("- %s, round %d: ", e.At.In(opts.Location).Format("15:04:05"), e.RoundIndex)
+	switch e.Action {
+	case CheatBonus:
+		sb.WriteString(fmt.Sprintf("%s%+d points for %s\n", prefix, e.Value, name(e.Target)))
+	case CheatMarkAI:
+		sb.WriteString(fmt.Sprintf("%srevealed %s as the AI's answer\n", prefix, answer))
+	case CheatVotes:
+		sb.WriteString(fmt.Sprintf("%sset the votes for %s to %d\n", prefix, answer, e.Value))
 	}
+}
 
-	return nil
+// ExportCSV writes one row per answer of every scored round to w, redacted
+// according to opts, for spreadsheets.
+func ExportCSV(s *SessionCtx, w io.Writer, opts ExportOptions) error {
+	summary := s.RedactSummary(s.Summary(), opts)
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"round", "prompt", "answer", "author", "ai", "votes", "voters", "hidden"})
+	for _, rs := range summary.Rounds {
+		for _, sub := range rs.Submissions {
+			cw.Write([]string{
+				strconv.Itoa(rs.Index),
+				rs.Prompt,
+				sub.Text,
+				sub.AuthorName,
+				strconv.FormatBool(sub.IsAI),
+				strconv.Itoa(sub.Votes),
+				strings.Join(sub.Voters, ", "),
+				strconv.FormatBool(sub.Hidden),
+			})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
 }
@@ -0,0 +1,297 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestExportIncludesAIMetadata(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "openai", Model: "gpt-3.5-turbo", RoundCount: 1})
+	session, _ := rm.Get(code)
+	_, playerToken, _ := session.Join("Alice")
+	session.SetPrompt(hostToken, "Test question?")
+	session.Submit(playerToken, "Alice's answer")
+	session.AddAISubmission("AI answer")
+	session.RecordAIMetadata(session.Rounds[0].ID, AIMetadata{
+		Provider:         "openai",
+		Model:            "gpt-3.5-turbo-0125",
+		LatencyMs:        850,
+		PromptTokens:     42,
+		CompletionTokens: 17,
+		FinishReason:     "stop",
+	})
+	session.Advance(hostToken) // To Voting
+	session.Advance(hostToken) // To Scoreboard
+
+	file, err := ExportSession(session, t.TempDir(), ExportOptions{})
+	if err != nil {
+		t.Fatalf("should be able to export: %v", err)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("should be able to read export: %v", err)
+	}
+	want := `AI: openai/gpt-3.5-turbo-0125, 850ms, 42 prompt + 17 completion tokens, finish reason "stop"`
+	if !strings.Contains(string(b), want) {
+		t.Fatalf("expected export to contain %q, got:\n%s", want, b)
+	}
+}
+
+func TestExportFrontMatter(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "ollama", Model: "mistral", RoundCount: 1})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	session.SetPrompt(hostToken, "Test question?")
+	aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+	session.Submit(bobToken, "Bob's answer")
+	aiID, _ := session.AddAISubmission("AI answer")
+	session.Advance(hostToken) // To Voting
+	session.Vote(aliceToken, aiID)
+	session.Vote(bobToken, aliceSub)
+	session.Advance(hostToken) // To Scoreboard
+
+	dir := t.TempDir()
+	file, err := ExportSession(session, dir, ExportOptions{})
+	if err != nil {
+		t.Fatalf("should be able to export: %v", err)
+	}
+	b, _ := os.ReadFile(file)
+	if !strings.Contains(string(b), `ended: ""`) {
+		t.Fatalf("expected open-ended front matter before the game ends, got:\n%s", b)
+	}
+
+	session.Advance(hostToken) // To End
+	again, err := ExportSession(session, dir, ExportOptions{})
+	if err != nil {
+		t.Fatalf("should be able to export again: %v", err)
+	}
+	if again != file {
+		t.Fatalf("expected the same per-session file, got %s and %s", file, again)
+	}
+	b, _ = os.ReadFile(file)
+	out := string(b)
+	if !strings.HasPrefix(out, "---\nsession: \""+code+"\"\nprovider: \"ollama\"\nmodel: \"mistral\"\n") {
+		t.Fatalf("expected front matter with session, provider and model, got:\n%s", out)
+	}
+	for _, want := range []string{
+		"rounds: 1\n",
+		`"roundCount":1`,
+		"- Alice: 1 vote(s) from Bob",
+		"Correctly identified AI: Alice",
+		"Game ended at",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected export to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `ended: ""`) {
+		t.Fatal("expected an end timestamp once the game ended")
+	}
+	if strings.Count(out, "Round 1:") != 1 {
+		t.Fatal("rewriting the export must not duplicate rounds")
+	}
+}
+
+func TestExportListsPlayersInJoinOrder(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1})
+	session, _ := rm.Get(code)
+	for _, name := range []string{"Zoe", "Adam", "Mia", "Bob"} {
+		session.Join(name)
+		time.Sleep(time.Millisecond)
+	}
+	session.SetPrompt(hostToken, "Test question?")
+	session.Advance(hostToken) // no answers, straight to Scoreboard
+
+	file, err := ExportSession(session, t.TempDir(), ExportOptions{})
+	if err != nil {
+		t.Fatalf("should be able to export: %v", err)
+	}
+	b, _ := os.ReadFile(file)
+	if want := "Players:\n- Zoe\n- Adam\n- Mia\n- Bob\n"; !strings.Contains(string(b), want) {
+		t.Fatalf("expected players in join order, got:\n%s", b)
+	}
+}
+
+func TestExportIncludesRoundNotes(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "openai", Model: "gpt-3.5-turbo", RoundCount: 2})
+	session, _ := rm.Get(code)
+	if _, err := session.AddRoundNote(hostToken, 0, "too early"); err != ErrRoundNotFound {
+		t.Fatalf("expected ErrRoundNotFound before the first round, got %v", err)
+	}
+	session.SetPrompt(hostToken, "Test question?")
+	if _, err := session.AddRoundNote(hostToken, 0, "mic died here"); err != nil {
+		t.Fatalf("should be able to add a note to the current round: %v", err)
+	}
+	session.Advance(hostToken) // no answers, straight to Scoreboard
+	if _, err := session.AddRoundNote(hostToken, 1, "crowd favorite"); err != nil {
+		t.Fatalf("should be able to add a note to a scored round: %v", err)
+	}
+
+	last, _ := session.LastRound()
+	if len(last.Notes) != 2 {
+		t.Fatalf("expected both notes on the round summary, got %v", last.Notes)
+	}
+	file, err := ExportSession(session, t.TempDir(), ExportOptions{})
+	if err != nil {
+		t.Fatalf("should be able to export: %v", err)
+	}
+	b, _ := os.ReadFile(file)
+	for _, want := range []string{"mic died here", "crowd favorite"} {
+		if !strings.Contains(string(b), want) {
+			t.Fatalf("expected export to contain %q, got:\n%s", want, b)
+		}
+	}
+}
+
+func TestExportAnonymized(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "openai", Model: "gpt-3.5-turbo", RoundCount: 1})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	session.SetPrompt(hostToken, "Where do you live?")
+	aliceSub, _ := session.Submit(aliceToken, "Next to Bob in Dresden")
+	session.Submit(bobToken, "Somewhere nice")
+	aiID, _ := session.AddAISubmission("In the cloud")
+	session.Advance(hostToken) // To Voting
+	session.Vote(aliceToken, aiID)
+	session.Vote(bobToken, aliceSub)
+	session.Advance(hostToken) // To Scoreboard
+
+	file, err := ExportSession(session, t.TempDir(), ExportOptions{Anonymize: true, Sensitive: []string{"dresden"}})
+	if err != nil {
+		t.Fatalf("should be able to export: %v", err)
+	}
+	b, _ := os.ReadFile(file)
+	out := string(b)
+	for _, leaked := range []string{"Alice", "Bob", "Dresden"} {
+		if strings.Contains(out, leaked) {
+			t.Fatalf("anonymized export must not contain %q, got:\n%s", leaked, out)
+		}
+	}
+	for _, want := range []string{"anonymized: true", "- Player 1: \"" + OmittedAnswer + "\"", "- Player 2: \"Somewhere nice\"", "Correctly identified AI: Player 1"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected export to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	// the archived round itself stays intact for the live game
+	last, _ := session.LastRound()
+	if last.Submissions[0].AuthorName == "Player 1" || last.Submissions[0].Text == OmittedAnswer {
+		t.Fatal("redacting must not modify the archived round")
+	}
+}
+
+func TestExportTerminated(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "openai", Model: "gpt-4", RoundCount: 2})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	session.SetPrompt(hostToken, "Unfinished question?")
+	session.Submit(aliceToken, "Alice's answer")
+
+	if _, ok := session.UnfinishedRound(); !ok {
+		t.Fatal("expected an unfinished round while answering")
+	}
+	terminated := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	file, err := ExportSession(session, t.TempDir(), ExportOptions{Terminated: terminated})
+	if err != nil {
+		t.Fatalf("should be able to export: %v", err)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("should be able to read export: %v", err)
+	}
+	for _, want := range []string{
+		`terminated: "2026-10-16T20:00:00Z"`,
+		"Unfinished round (still in Answering):",
+		"Unfinished question?",
+		"Alice's answer",
+		"Game terminated by a server shutdown",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Fatalf("expected export to contain %q, got:\n%s", want, b)
+		}
+	}
+}
+
+func TestExportTimeZone(t *testing.T) {
+	rm := NewRoomManager()
+	if _, _, err := rm.CreateSession(SessionConfig{RoundCount: 1, TimeZone: "Mars/Olympus_Mons"}); err != ErrInvalidTimeZone {
+		t.Fatalf("expected ErrInvalidTimeZone, got %v", err)
+	}
+	code, _, err := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1, TimeZone: "Asia/Tokyo"})
+	if err != nil {
+		t.Fatalf("should be able to create a session with a time zone: %v", err)
+	}
+	session, _ := rm.Get(code)
+	session.CreatedAt = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	// the session's zone wins over the server-wide one
+	file, err := ExportSession(session, t.TempDir(), ExportOptions{Location: time.UTC})
+	if err != nil {
+		t.Fatalf("should be able to export: %v", err)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("should be able to read export: %v", err)
+	}
+	for _, want := range []string{
+		`started: "2026-10-16T21:00:00+09:00"`,
+		`timezone: "Asia/Tokyo"`,
+		"Started: 2026-10-16 21:00:00 JST",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Fatalf("expected export to contain %q, got:\n%s", want, b)
+		}
+	}
+}
+
+func TestExportFromWAL(t *testing.T) {
+	dir := t.TempDir()
+	rm := NewRoomManager()
+	rm.EnableWAL(dir, nil)
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	session.SetPrompt(hostToken, "Where do you live?")
+	aliceSub, _ := session.Submit(aliceToken, "In Dresden, sadly")
+	session.Submit(bobToken, "Somewhere nice")
+	aiID, _ := session.AddAISubmission("In the cloud")
+	session.Advance(hostToken) // To Voting
+	session.Vote(aliceToken, aiID)
+	session.Vote(bobToken, aliceSub)
+	session.Advance(hostToken) // To Scoreboard
+	session.Advance(hostToken) // To End
+
+	loaded, err := LoadWAL(filepath.Join(dir, code+".wal"))
+	if err != nil {
+		t.Fatalf("should be able to load an ended session's WAL: %v", err)
+	}
+	text, err := ExportText(loaded, ExportOptions{})
+	if err != nil || !strings.Contains(text, "GPTdash Game Results - Session "+code) || !strings.Contains(text, "Game ended at") {
+		t.Fatalf("expected the full text export, got %v:\n%s", err, text)
+	}
+
+	var sb strings.Builder
+	if err := ExportCSV(loaded, &sb, ExportOptions{Sensitive: []string{"dresden"}}); err != nil {
+		t.Fatalf("should be able to export CSV: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	if len(lines) != 4 || lines[0] != "round,prompt,answer,author,ai,votes,voters,hidden" {
+		t.Fatalf("expected a header and three answers, got:\n%s", sb.String())
+	}
+	if !strings.Contains(sb.String(), "1,Where do you live?,"+OmittedAnswer+",Alice,false,1,Bob,false") {
+		t.Fatalf("expected Alice's redacted answer with Bob's vote, got:\n%s", sb.String())
+	}
+}
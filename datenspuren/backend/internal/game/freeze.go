@@ -0,0 +1,77 @@
+package game
+
+// SetScoreFreeze makes future rounds withhold their scores from players until
+// the host reveals them with RevealScores, so the stage gets the moment first.
+func (s *SessionCtx) SetScoreFreeze(hostToken string, frozen bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return ErrNotHost
+	}
+	s.freezeScores = frozen
+	s.logEvent(walEvent{Type: walScoreFreeze, Frozen: frozen})
+	return nil
+}
+
+// RevealScores releases scores withheld by a freeze.
+func (s *SessionCtx) RevealScores(hostToken string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return ErrNotHost
+	}
+	if s.heldScores == nil {
+		return ErrInvalidPhase
+	}
+	s.heldScores = nil
+	s.logEvent(walEvent{Type: walRevealScores})
+	return nil
+}
+
+// ScoresWithheld reports whether the latest scores are still hidden from
+// players.
+func (s *SessionCtx) ScoresWithheld() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.heldScores != nil
+}
+
+// PlayerScores is the scoreboard as players may see it: the standings from
+// before the current round while its scores are withheld.
+func (s *SessionCtx) PlayerScores() []ScoreEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.playerScores()
+}
+
+// PlayerAIScore is the AI's score as players may see it, see PlayerScores.
+func (s *SessionCtx) PlayerAIScore() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.playerAIScore()
+}
+
+func (s *SessionCtx) playerAIScore() int {
+	if s.heldScores != nil {
+		return s.heldAIScore
+	}
+	return s.aiScore
+}
+
+func (s *SessionCtx) playerScores() []ScoreEntry {
+	if s.heldScores != nil {
+		out := make([]ScoreEntry, len(s.heldScores))
+		copy(out, s.heldScores)
+		return out
+	}
+	return s.scoreboard()
+}
+
+// holdScores snapshots the standings before a round is scored if a freeze
+// is on. Callers must hold s.mu.
+func (s *SessionCtx) holdScores() {
+	if s.freezeScores {
+		s.heldScores = s.scoreboard()
+		s.heldAIScore = s.aiScore
+	}
+}
@@ -0,0 +1,136 @@
+package game
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// FuzzSession throws random sequences of host and player actions at a
+// session, with valid and invalid tokens in any phase, and checks the
+// session's invariants after every step and that its WAL replays to the
+// same state. Each input byte is one action: the low 3 bits pick it, the
+// rest pick the actor or target.
+//
+//	go test ./internal/game -run '^$' -fuzz FuzzSession
+func FuzzSession(f *testing.F) {
+	// a regular round: 2 joins, prompt, 2 answers + AI, advance, 2 votes, advance
+	f.Add([]byte{0, 0, 4, 1, 9, 5, 3, 2, 10, 3, 3, 4})
+	f.Add([]byte{0, 4, 1, 3, 6, 4, 3, 3, 7, 3, 3, 3})
+	f.Add([]byte{3, 11, 2, 0, 1, 12, 6, 7})
+
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		if len(ops) > 200 {
+			ops = ops[:200]
+		}
+		dir := t.TempDir()
+		rm := NewRoomManager()
+		if err := rm.EnableWAL(dir, func(code string, err error) { t.Fatalf("WAL write failed: %v", err) }); err != nil {
+			t.Fatalf("should be able to enable the WAL: %v", err)
+		}
+		code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 3})
+		s, _ := rm.Get(code)
+		defer s.journal.f.Close()
+
+		var tokens []string
+		token := func(arg int) string {
+			if len(tokens) == 0 || arg%(len(tokens)+1) == len(tokens) {
+				return "bogus"
+			}
+			return tokens[arg%(len(tokens)+1)]
+		}
+		host := func(arg int) string {
+			if arg%4 == 0 {
+				return "bogus"
+			}
+			return hostToken
+		}
+		for step, op := range ops {
+			arg := int(op >> 3)
+			switch op & 7 {
+			case 0:
+				_, tok, _ := s.Join(fmt.Sprintf("P%d", len(tokens)))
+				tokens = append(tokens, tok)
+			case 1:
+				s.Submit(token(arg), fmt.Sprintf("answer %d", step))
+			case 2:
+				subs := s.ListVotingSubmissions()
+				target := "bogus"
+				if len(subs) > 0 && arg%4 != 0 {
+					target = subs[arg%len(subs)].ID
+				}
+				s.Vote(token(arg), target)
+			case 3:
+				s.Advance(host(arg))
+			case 4:
+				s.SetPrompt(host(arg), fmt.Sprintf("prompt %d", step))
+			case 5:
+				s.AddAISubmission("AI answer")
+			case 6:
+				s.ResetRound(host(arg))
+			case 7:
+				// every read path must get along with every state
+				s.PublicState()
+				s.Summary()
+				s.ScoresArray()
+				s.PlayerScores()
+				s.MetaScore()
+				s.PlayerMetaScore()
+				s.CurrentRound()
+				s.Votes()
+			}
+			checkInvariants(t, s, step)
+		}
+
+		replayed, err := replayWAL(filepath.Join(dir, code+".wal"), Limits{})
+		if err != nil {
+			t.Fatalf("should be able to replay the WAL: %v", err)
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if replayed.Phase != s.Phase || replayed.RoundIx != s.RoundIx || len(replayed.history) != len(s.history) {
+			t.Fatalf("replay diverged: phase %s/%s, round %d/%d, history %d/%d",
+				replayed.Phase, s.Phase, replayed.RoundIx, s.RoundIx, len(replayed.history), len(s.history))
+		}
+		if len(replayed.Scores) != len(s.Scores) || replayed.aiScore != s.aiScore {
+			t.Fatalf("replayed scores diverged: %v (AI %d), live %v (AI %d)", replayed.Scores, replayed.aiScore, s.Scores, s.aiScore)
+		}
+		for id, pts := range s.Scores {
+			if replayed.Scores[id] != pts {
+				t.Fatalf("replayed score of %s is %d, live %d", id, replayed.Scores[id], pts)
+			}
+		}
+	})
+}
+
+func checkInvariants(t *testing.T, s *SessionCtx, step int) {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch s.Phase {
+	case PhaseLobby, PhasePromptSet, PhaseAnswering, PhaseVoting, PhaseReveal, PhaseScoreboard, PhaseEnd:
+	default:
+		t.Fatalf("step %d: unknown phase %q", step, s.Phase)
+	}
+	if s.RoundIx < 0 || s.RoundIx > len(s.Rounds) {
+		t.Fatalf("step %d: round index %d out of %d rounds", step, s.RoundIx, len(s.Rounds))
+	}
+	for playerID, subID := range s.byPlayer {
+		if sub := s.submissions[subID]; sub == nil || sub.PlayerID != playerID {
+			t.Fatalf("step %d: player %s maps to a submission that isn't theirs", step, playerID)
+		}
+	}
+	for voterID, v := range s.votesByVoter {
+		if _, ok := s.byPlayer[voterID]; !ok {
+			t.Fatalf("step %d: %s voted without answering", step, voterID)
+		}
+		if s.submissions[v.TargetSubmissionID] == nil {
+			t.Fatalf("step %d: vote for unknown submission %q", step, v.TargetSubmissionID)
+		}
+	}
+	for id := range s.Scores {
+		if s.PlayersByID[id] == nil {
+			t.Fatalf("step %d: score for unknown player %s", step, id)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package game
+
+import "errors"
+
+// ErrOtherGroup is returned when voting for an answer outside the voter's
+// group.
+var ErrOtherGroup = errors.New("submission belongs to another group")
+
+// assignGroups splits the round's answers into parallel voting groups of
+// about Config.GroupSize, each voting on its own answers plus the AI's.
+// Groups follow the seeded reading order, so they replay alike and nobody
+// can steer who ends up together. Callers must hold s.mu.
+func (s *SessionCtx) assignGroups() {
+	r := s.currentRound()
+	if r == nil {
+		return
+	}
+	r.Groups = nil
+	size := s.Config.GroupSize
+	answers := make([]string, 0, len(r.ReadingOrder))
+	for _, id := range r.ReadingOrder {
+		if id != r.AISubmissionID {
+			answers = append(answers, id)
+		}
+	}
+	if size <= 0 || len(answers) <= size {
+		return
+	}
+	n := (len(answers) + size - 1) / size
+	groups := make([][]string, n)
+	for i, id := range answers {
+		groups[i%n] = append(groups[i%n], id)
+	}
+	r.Groups = groups
+}
+
+// groupOf returns the index of the voting group holding a submission, or -1
+// if the round isn't split or the submission is the AI's, which every group
+// votes on. Callers must hold s.mu.
+func (r *Round) groupOf(submissionID string) int {
+	for i, g := range r.Groups {
+		for _, id := range g {
+			if id == submissionID {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// VotingGroup returns the 0-based voting group of a player this round and
+// the number of groups, or -1 and 0 if voting isn't split.
+func (s *SessionCtx) VotingGroup(playerID string) (group, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.currentRound()
+	if r == nil || len(r.Groups) == 0 {
+		return -1, 0
+	}
+	return r.groupOf(r.representative(s.byPlayer[playerID])), len(r.Groups)
+}
+
+// ListVotingSubmissionsFor is ListVotingSubmissions limited to what the
+// player votes on: their group's answers and the AI's. Players without a
+// group, e.g. those who didn't answer, see everything.
+func (s *SessionCtx) ListVotingSubmissionsFor(playerID string) []*Submission {
+	subs := s.ListVotingSubmissions()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.currentRound()
+	if r == nil || len(r.Groups) == 0 {
+		return subs
+	}
+	group := r.groupOf(r.representative(s.byPlayer[playerID]))
+	if group < 0 {
+		return subs
+	}
+	out := subs[:0]
+	for _, sub := range subs {
+		if g := r.groupOf(sub.ID); g < 0 || g == group {
+			out = append(out, sub)
+		}
+	}
+	return out
+}
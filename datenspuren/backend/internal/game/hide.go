@@ -0,0 +1,59 @@
+package game
+
+import (
+	"errors"
+	"slices"
+)
+
+var ErrHideAI = errors.New("the AI answer can't be hidden")
+
+// HideSubmission takes an answer out of the vote for good, e.g. because it
+// is offensive or a duplicate. Unlike a hint it doesn't change the scoring;
+// votes it drew are handed back. Hidden answers stay in the export.
+func (s *SessionCtx) HideSubmission(hostToken, submissionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return ErrNotHost
+	}
+	r := s.currentRound()
+	if r == nil || s.Phase != PhaseAnswering && s.Phase != PhaseVoting {
+		return ErrInvalidPhase
+	}
+	sub := s.submissions[submissionID]
+	if sub == nil {
+		return ErrUnknownTarget
+	}
+	if submissionID == r.AISubmissionID || sub.PlayerID == "AI" {
+		return ErrHideAI
+	}
+	if r.hidden(submissionID) {
+		return nil
+	}
+	s.hide(submissionID)
+	s.logEvent(walEvent{Type: walHide, SubmissionID: submissionID})
+	return nil
+}
+
+// hide marks a submission hidden. Callers must hold s.mu.
+func (s *SessionCtx) hide(id string) {
+	r := s.currentRound()
+	if r == nil {
+		return
+	}
+	r.Hidden = append(r.Hidden, id)
+	for voter, v := range s.votesByVoter {
+		if v.TargetSubmissionID == id {
+			delete(s.votesByVoter, voter)
+		}
+	}
+	for voter, target := range s.audienceVotes {
+		if target == id {
+			delete(s.audienceVotes, voter)
+		}
+	}
+}
+
+func (r *Round) hidden(id string) bool {
+	return slices.Contains(r.Hidden, id)
+}
@@ -0,0 +1,83 @@
+package game
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+)
+
+var (
+	ErrNoHint     = errors.New("no answer left to eliminate")
+	ErrEliminated = errors.New("submission was eliminated")
+)
+
+// UseHint eliminates a random human answer from the voting list, trading
+// points for drama. Answers that already drew votes are more likely to go;
+// those votes, the audience's included, are handed back so their voters can
+// vote again. A hint always
+// leaves at least one human answer next to the AI's.
+//
+// Once a round used a hint, finding the AI earns no point and every vote a
+// remaining human answer draws earns one more, 3 instead of 2 by default.
+func (s *SessionCtx) UseHint(hostToken string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return "", ErrNotHost
+	}
+	r := s.currentRound()
+	if s.Phase != PhaseVoting || r == nil {
+		return "", ErrInvalidPhase
+	}
+	votes := map[string]int{}
+	for _, v := range s.votesByVoter {
+		votes[v.TargetSubmissionID]++
+	}
+	var candidates []string
+	total := 0
+	for _, id := range r.ReadingOrder {
+		if id == r.AISubmissionID || r.eliminated(id) || r.hidden(id) || s.submissions[id] == nil {
+			continue
+		}
+		candidates = append(candidates, id)
+		total += votes[id] + 1
+	}
+	if len(candidates) < 2 {
+		return "", ErrNoHint
+	}
+	pick := rand.Intn(total)
+	id := candidates[len(candidates)-1]
+	for _, c := range candidates {
+		if pick -= votes[c] + 1; pick < 0 {
+			id = c
+			break
+		}
+	}
+	s.eliminate(id)
+	s.logEvent(walEvent{Type: walHint, SubmissionID: id})
+	return id, nil
+}
+
+// eliminate takes a submission out of the current vote. Callers must hold
+// s.mu.
+func (s *SessionCtx) eliminate(id string) {
+	r := s.currentRound()
+	if r == nil {
+		return
+	}
+	r.Eliminated = append(r.Eliminated, id)
+	for voter, v := range s.votesByVoter {
+		if v.TargetSubmissionID == id {
+			delete(s.votesByVoter, voter)
+		}
+	}
+	for voter, target := range s.audienceVotes {
+		if target == id {
+			delete(s.audienceVotes, voter)
+		}
+	}
+}
+
+func (r *Round) eliminated(id string) bool {
+	return slices.Contains(r.Eliminated, id)
+}
@@ -0,0 +1,109 @@
+package game
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+var ErrNotHostless = errors.New("only possible in hostless sessions")
+
+// Hostless sessions run without a GM screen: the server advances phases once
+// everyone is done or the phase's timer runs out, and starts rounds once the
+// players are ready.
+const (
+	hostlessAnswerTime = 90 // seconds, unless configured
+	hostlessVoteTime   = 45
+	hostlessRoundCount = 5
+
+	// MinHostlessPlayers must be ready before a hostless game starts.
+	MinHostlessPlayers = 2
+)
+
+// applyHostlessDefaults makes sure nothing in a hostless session waits
+// forever on a host.
+func (c *SessionConfig) applyHostlessDefaults() {
+	if !c.Hostless {
+		return
+	}
+	if c.AnswerTime <= 0 {
+		c.AnswerTime = hostlessAnswerTime
+	}
+	if c.VoteTime <= 0 {
+		c.VoteTime = hostlessVoteTime
+	}
+	if c.RoundCount <= 0 {
+		c.RoundCount = hostlessRoundCount
+	}
+}
+
+// SetReady marks a player of a hostless session as ready to start the game
+// or the next round. It reports whether the session may go on: every player
+// is ready, and at least MinHostlessPlayers of them. Readiness only matters
+// between rounds and isn't journaled.
+func (s *SessionCtx) SetReady(playerToken string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.Config.Hostless {
+		return false, ErrNotHostless
+	}
+	p := s.PlayersByToken[playerToken]
+	if p == nil {
+		return false, errors.New("unauthorized")
+	}
+	if s.Phase != PhaseLobby && s.Phase != PhasePromptSet && s.Phase != PhaseScoreboard {
+		return false, ErrInvalidPhase
+	}
+	if s.ready == nil {
+		s.ready = make(map[string]bool)
+	}
+	s.ready[p.ID] = true
+	return s.allReady(), nil
+}
+
+// allReady reports whether every player is ready. Callers must hold s.mu.
+func (s *SessionCtx) allReady() bool {
+	if len(s.PlayersByID) < MinHostlessPlayers {
+		return false
+	}
+	for id := range s.PlayersByID {
+		if !s.ready[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadyPlayers returns the IDs of the players ready for the next round.
+func (s *SessionCtx) ReadyPlayers() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, 0, len(s.ready))
+	for id := range s.ready {
+		out = append(out, id)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// SuggestPrompt queues a player's prompt for an upcoming round of a
+// hostless session.
+func (s *SessionCtx) SuggestPrompt(playerToken, prompt string) (QueuedPrompt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.Config.Hostless {
+		return QueuedPrompt{}, ErrNotHostless
+	}
+	p := s.PlayersByToken[playerToken]
+	if p == nil {
+		return QueuedPrompt{}, errors.New("unauthorized")
+	}
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return QueuedPrompt{}, errors.New("empty prompt")
+	}
+	if full(len(s.promptQueue), s.limits.QueuedPrompts) {
+		return QueuedPrompt{}, ErrStorageFull
+	}
+	return *s.queuePrompt(QueuedPrompt{Prompt: prompt, SuggestedBy: p.Name}), nil
+}
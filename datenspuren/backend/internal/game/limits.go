@@ -0,0 +1,50 @@
+package game
+
+import "errors"
+
+var ErrStorageFull = errors.New("session storage limit reached")
+
+// Limits bounds what a session keeps in memory, so long sessions with
+// many rounds can't grow without bound. Zero fields are unlimited.
+type Limits struct {
+	Submissions   int // answers per round; further answers are rejected
+	Votes         int // votes per round; further votes are rejected
+	QueuedPrompts int // prompts waiting in the queue; further prompts are rejected
+	Notes         int // host notes per round; the oldest are dropped
+	CheatLog      int // entries of the cheat audit log; the oldest are dropped
+	History       int // archived rounds kept in full; older ones keep only their tallies
+}
+
+// SetLimits applies l to sessions created or recovered afterwards.
+func (rm *RoomManager) SetLimits(l Limits) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.limits = l
+}
+
+// full reports whether a collection of n items reached the limit max.
+func full(n, max int) bool {
+	return max > 0 && n >= max
+}
+
+// evict drops the oldest entries of list beyond max.
+func evict[T any](list []T, max int) []T {
+	if max > 0 && len(list) > max {
+		return append(list[:0:0], list[len(list)-max:]...)
+	}
+	return list
+}
+
+// compactHistory strips answers, votes and standings from archived rounds
+// beyond Limits.History. The vote tallies stay, so totals and the
+// meta-score still cover the whole game. Callers must hold s.mu.
+func (s *SessionCtx) compactHistory() {
+	for i := 0; i < len(s.history)-s.limits.History && s.limits.History > 0; i++ {
+		rs := &s.history[i]
+		if rs.Compacted {
+			continue
+		}
+		rs.Submissions, rs.Scores, rs.Audience = nil, nil, nil
+		rs.Compacted = true
+	}
+}
@@ -2,11 +2,17 @@ package game
 
 import (
 	"errors"
+	"fmt"
 	"math/rand"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"github.com/kiliankoe/gptdash/internal/moderation"
 )
 
 var (
@@ -14,14 +20,27 @@ var (
 	ErrNotHost         = errors.New("not host")
 	ErrInvalidPhase    = errors.New("invalid phase for action")
 	ErrAlreadyVoted    = errors.New("already voted")
+	ErrSelfVote        = errors.New("cannot vote for your own answer")
+	ErrEmptyAnswer     = errors.New("empty answer")
+	ErrAnswerTooLong   = errors.New("answer too long")
+	ErrNoAIAnswer      = errors.New("no AI answer yet")
+	ErrInvalidOrder    = errors.New("reading order must list every submission exactly once")
+	ErrTooManySessions = errors.New("too many running sessions")
+	ErrInvalidTimeZone = errors.New("unknown time zone")
+	ErrInvalidTrigger  = errors.New("unknown AI trigger")
+	ErrStaleStep       = errors.New("the session has moved on already")
 )
 
 type SessionCtx struct {
 	Code      string
+	JoinPin   string // numeric alternative to Code, easier to type on phones
 	CreatedAt time.Time
+	EndedAt   time.Time // zero until the game reaches PhaseEnd
 	Config    SessionConfig
+	Seed      int64 // drives every submission shuffle, see ShuffleSeed
 
-	HostToken string
+	HostToken    string
+	OverlayToken string // read-only access to the stream overlay feed
 
 	PlayersByToken map[string]*Player
 	PlayersByID    map[string]*Player
@@ -30,12 +49,43 @@ type SessionCtx struct {
 	RoundIx int
 	Rounds  []*Round
 
+	phaseStartedAt time.Time
+	phaseExtra     time.Duration // time the host added to the current phase's timer
+
 	// per round state
 	submissions  map[string]*Submission // submissionID -> Submission
 	byPlayer     map[string]string      // playerID -> submissionID
 	votesByVoter map[string]*Vote       // voterID -> Vote
+	// audience member -> submissionID, see AudienceVote
+	audienceVotes map[string]string
+
+	// suggestions for the next prompt, see OpenPromptCollection
+	promptCandidates []*PromptCandidate
+	promptVotes      map[string]string // playerID -> candidate ID
+
+	Scores      map[string]int // playerID -> points
+	roundPoints map[string]int // playerID -> points earned in the current round
+	aiScore     int            // points the AI earned as a pseudo-player
+
+	freezeScores bool         // withhold new scores from players until revealed
+	heldScores   []ScoreEntry // standings players see while scores are withheld
+	heldAIScore  int
+
+	cheats   bool         // host cheat actions unlocked
+	cheatLog []CheatEntry // audit log of host cheats
+
+	history     []RoundSummary  // archived results of scored rounds
+	promptQueue []*QueuedPrompt // prompts prepared for upcoming rounds
+	ready       map[string]bool // playerID -> ready for the next round, hostless sessions only
 
-	Scores map[string]int // playerID -> points
+	lastActivity time.Time // last logged event, see Reap
+
+	limits     Limits
+	wordFilter *moderation.Filter // masks or rejects blocked words in names and answers, nil = off
+
+	journal  *journal    // write-ahead log, nil when disabled
+	store    *storeQueue // to a SessionStore, e.g. SQLite; nil when disabled
+	replayAt time.Time   // timestamp of the event being replayed from the WAL
 
 	mu sync.Mutex
 }
@@ -43,41 +93,153 @@ type SessionCtx struct {
 type RoomManager struct {
 	mu       sync.RWMutex
 	sessions map[string]*SessionCtx
-	active   string // active session code when in single-session mode
+	pins     map[string]string    // join PIN -> session code
+	active   string               // latest hosted session, the one the join page offers
+	multi    bool                 // several hosted games at once, see Active
+	noExport bool                 // EXPORT_ENABLED=false, see SetExportDefault
+	seen     map[string]time.Time // session code -> last time a client was connected, see Reap
+
+	walDir     string
+	walOnError func(code string, err error)
+
+	store *storeQueue // replaces the WAL when set, see EnableStore
+
+	ownsCode func(code string) bool // restricts new codes to this instance's share
+
+	maxSessions int                // cap on sessions that haven't ended, 0 = unlimited
+	limits      Limits             // storage caps for new sessions
+	wordFilter  *moderation.Filter // for names and answers in new sessions
+	scoring     *Scoring           // for new sessions without their own, nil = DefaultScoring
+	fooledBonus int                // for new sessions without their own
+}
+
+// SetMaxSessions limits how many sessions may run at once. Ended sessions
+// don't count, so finished games never block new ones.
+func (rm *RoomManager) SetMaxSessions(n int) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.maxSessions = n
+}
+
+// SetCodeFilter makes CreateSession only hand out codes and join PINs for
+// which owns returns true, so in a multi-instance deployment every new
+// session is owned by the instance that created it, whichever of the two
+// a client routes by.
+func (rm *RoomManager) SetCodeFilter(owns func(code string) bool) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.ownsCode = owns
+}
+
+// SetExportDefault sets the server-wide EXPORT_ENABLED. Only new sessions
+// that record their results, see SessionConfig.Recording, are journaled to
+// the WAL or the store.
+func (rm *RoomManager) SetExportDefault(enabled bool) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.noExport = !enabled
+}
+
+// SetSingleSession chooses between one venue game at a time (the default),
+// where the latest hosted session is the active one, and several hosted
+// games side by side, see Active.
+func (rm *RoomManager) SetSingleSession(single bool) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.multi = !single
 }
 
 func NewRoomManager() *RoomManager {
-	return &RoomManager{sessions: make(map[string]*SessionCtx)}
+	return &RoomManager{sessions: make(map[string]*SessionCtx), pins: make(map[string]string), seen: make(map[string]time.Time)}
 }
 
 func (rm *RoomManager) CreateSession(cfg SessionConfig) (code string, hostToken string, err error) {
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
+	if rm.maxSessions > 0 && rm.running() >= rm.maxSessions {
+		return "", "", ErrTooManySessions
+	}
+	if cfg.TimeZone != "" {
+		if _, err := time.LoadLocation(cfg.TimeZone); err != nil {
+			return "", "", ErrInvalidTimeZone
+		}
+	}
+	switch cfg.AITrigger {
+	case "", AITriggerQueue, AITriggerSetPrompt, AITriggerVoting:
+	default:
+		return "", "", ErrInvalidTrigger
+	}
+	cfg.applyHostlessDefaults()
+	if cfg.Scoring == nil && rm.scoring != nil {
+		sc := *rm.scoring
+		cfg.Scoring = &sc
+	}
+	if cfg.FooledBonus == 0 {
+		cfg.FooledBonus = rm.fooledBonus
+	}
 	code = randomCode(5)
-	for rm.sessions[code] != nil {
+	for rm.sessions[code] != nil || rm.ownsCode != nil && !rm.ownsCode(code) {
 		code = randomCode(5)
 	}
+	pin := randomPin(6)
+	for rm.pins[pin] != "" || rm.ownsCode != nil && !rm.ownsCode(pin) {
+		pin = randomPin(6)
+	}
 	hostToken = uuid.NewString()
-	s := &SessionCtx{
+	s := newSession(code, pin, hostToken, uuid.NewString(), cfg, time.Now().UTC())
+	s.Seed = rand.Int63()
+	s.limits = rm.limits
+	s.wordFilter = rm.wordFilter
+	if cfg.Recording(!rm.noExport) {
+		if rm.walDir != "" {
+			j, err := rm.openJournal(code)
+			if err != nil {
+				return "", "", err
+			}
+			s.journal = j
+		}
+		s.store = rm.store
+		s.logEvent(walEvent{Type: walCreate, At: s.CreatedAt, Code: code, JoinPin: pin, HostToken: hostToken, OverlayToken: s.OverlayToken, Seed: s.Seed, Config: &cfg})
+	}
+
+	rm.sessions[code] = s
+	rm.pins[pin] = code
+	if !cfg.Hostless {
+		// players' own games don't take over the venue's big screen
+		rm.active = code
+	}
+	return code, hostToken, nil
+}
+
+func newSession(code, pin, hostToken, overlayToken string, cfg SessionConfig, createdAt time.Time) *SessionCtx {
+	s := &SessionCtx{}
+	s.reset(code, pin, hostToken, overlayToken, cfg, createdAt)
+	return s
+}
+
+// reset turns s into a new session in the lobby, dropping whatever it held.
+func (s *SessionCtx) reset(code, pin, hostToken, overlayToken string, cfg SessionConfig, createdAt time.Time) {
+	*s = SessionCtx{
 		Code:           code,
-		CreatedAt:      time.Now().UTC(),
+		JoinPin:        pin,
+		CreatedAt:      createdAt,
 		Config:         cfg,
 		HostToken:      hostToken,
+		OverlayToken:   overlayToken,
 		PlayersByToken: make(map[string]*Player),
 		PlayersByID:    make(map[string]*Player),
 		Phase:          PhaseLobby,
+		phaseStartedAt: createdAt,
+		lastActivity:   createdAt,
 		RoundIx:        0,
 		Rounds:         []*Round{},
 		submissions:    make(map[string]*Submission),
 		byPlayer:       make(map[string]string),
 		votesByVoter:   make(map[string]*Vote),
 		Scores:         make(map[string]int),
+		roundPoints:    make(map[string]int),
 	}
-
-	rm.sessions[code] = s
-	rm.active = code
-	return code, hostToken, nil
 }
 
 func (rm *RoomManager) Get(code string) (*SessionCtx, error) {
@@ -90,55 +252,274 @@ func (rm *RoomManager) Get(code string) (*SessionCtx, error) {
 	return s, nil
 }
 
+// Remove forgets a session, e.g. a finished demo game. Its WAL, if any, is
+// left alone.
+func (rm *RoomManager) Remove(code string) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	s := rm.sessions[code]
+	if s == nil {
+		return
+	}
+	delete(rm.sessions, code)
+	delete(rm.pins, s.JoinPin)
+	delete(rm.seen, code)
+	if rm.active == code {
+		rm.active = ""
+	}
+}
+
+// SessionCount returns the number of sessions and how many of them have
+// not ended yet.
+func (rm *RoomManager) SessionCount() (total, running int) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return len(rm.sessions), rm.running()
+}
+
+// Running returns the sessions that haven't ended.
+func (rm *RoomManager) Running() []*SessionCtx {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	out := []*SessionCtx{}
+	for _, s := range rm.sessions {
+		if s.GetPhase() != PhaseEnd {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// running counts sessions that haven't ended. Callers must hold rm.mu.
+func (rm *RoomManager) running() int {
+	n := 0
+	for _, s := range rm.sessions {
+		if s.GetPhase() != PhaseEnd {
+			n++
+		}
+	}
+	return n
+}
+
+// Lookup resolves either a session code or a numeric join PIN.
+func (rm *RoomManager) Lookup(codeOrPin string) (*SessionCtx, error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	codeOrPin = strings.TrimSpace(codeOrPin)
+	if s := rm.sessions[strings.ToUpper(codeOrPin)]; s != nil {
+		return s, nil
+	}
+	if code := rm.pins[codeOrPin]; code != "" {
+		if s := rm.sessions[code]; s != nil {
+			return s, nil
+		}
+	}
+	return nil, ErrSessionNotFound
+}
+
+// Active returns the session the join page offers. With a single session
+// that is the latest hosted one; with several it is the only hosted game
+// still running, if there is just one, and players enter a code otherwise.
 func (rm *RoomManager) Active() (string, *SessionCtx) {
 	rm.mu.RLock()
 	defer rm.mu.RUnlock()
+	if rm.multi {
+		var only *SessionCtx
+		for _, s := range rm.sessions {
+			if s.Config.Hostless || s.GetPhase() == PhaseEnd {
+				continue
+			}
+			if only != nil {
+				return "", nil
+			}
+			only = s
+		}
+		if only == nil {
+			return "", nil
+		}
+		return only.Code, only
+	}
 	if rm.active == "" {
 		return "", nil
 	}
 	return rm.active, rm.sessions[rm.active]
 }
 
+// SessionInfo is the admin view of a session in the session listing.
+type SessionInfo struct {
+	Code        string    `json:"sessionCode"`
+	JoinPin     string    `json:"joinPin"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Phase       Phase     `json:"phase"`
+	RoundIndex  int       `json:"roundIndex"`
+	RoundCount  int       `json:"roundCount"`
+	PlayerCount int       `json:"playerCount"`
+	Hostless    bool      `json:"hostless"`
+	Public      bool      `json:"public"`
+	Rehearsal   bool      `json:"rehearsal"`
+	Active      bool      `json:"active"` // offered on the join page
+}
+
+// Sessions lists all sessions, ended ones included, oldest first.
+func (rm *RoomManager) Sessions() []SessionInfo {
+	active, _ := rm.Active()
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	out := make([]SessionInfo, 0, len(rm.sessions))
+	for code, s := range rm.sessions {
+		s.mu.Lock()
+		out = append(out, SessionInfo{
+			Code:        code,
+			JoinPin:     s.JoinPin,
+			CreatedAt:   s.CreatedAt,
+			Phase:       s.Phase,
+			RoundIndex:  s.RoundIx,
+			RoundCount:  s.Config.RoundCount,
+			PlayerCount: len(s.PlayersByID),
+			Hostless:    s.Config.Hostless,
+			Public:      s.Config.Public,
+			Rehearsal:   s.Config.Rehearsal,
+			Active:      code == active,
+		})
+		s.mu.Unlock()
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].Code < out[j].Code
+	})
+	return out
+}
+
+// SessionListing is the public-safe view of a session in the session browser.
+type SessionListing struct {
+	Code        string `json:"sessionCode"`
+	PlayerCount int    `json:"playerCount"`
+	Phase       Phase  `json:"phase"`
+}
+
+// PublicSessions lists joinable sessions that opted into the session browser.
+func (rm *RoomManager) PublicSessions() []SessionListing {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	out := []SessionListing{}
+	for code, s := range rm.sessions {
+		s.mu.Lock()
+		if s.Config.Public && s.Phase != PhaseEnd {
+			out = append(out, SessionListing{Code: code, PlayerCount: len(s.PlayersByID), Phase: s.Phase})
+		}
+		s.mu.Unlock()
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out
+}
+
 func (s *SessionCtx) StartRound(prompt string) *Round {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.RoundIx++
-	r := &Round{ID: uuid.NewString(), Index: s.RoundIx, Prompt: prompt, Status: PhaseAnswering}
-	s.Rounds = append(s.Rounds, r)
-	s.submissions = make(map[string]*Submission)
-	s.byPlayer = make(map[string]string)
-	s.votesByVoter = make(map[string]*Vote)
-	s.Phase = PhaseAnswering
+	r := s.startRound(uuid.NewString(), prompt, nil, s.pickModel())
+	s.logRound(r, "", "")
 	return r
 }
 
 func (s *SessionCtx) SetPrompt(hostToken string, prompt string) error {
+	return s.SetPromptTranslated(hostToken, prompt, nil)
+}
+
+// SetPromptTranslated starts a round whose prompt is also available in other
+// languages; clients pick the version matching their locale.
+func (s *SessionCtx) SetPromptTranslated(hostToken string, prompt string, translations map[string]string) error {
+	return s.setPromptOfType(hostToken, prompt, translations, RoundText)
+}
+
+func (s *SessionCtx) setPromptOfType(hostToken string, prompt string, translations map[string]string, typ RoundType) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if hostToken != s.HostToken {
 		return ErrNotHost
 	}
-	if s.Phase != PhaseLobby && s.Phase != PhasePromptSet && s.Phase != PhaseScoreboard {
+	if s.Phase != PhaseLobby && s.Phase != PhasePromptSet && s.Phase != PhaseScoreboard && s.Phase != PhasePromptCollection && !s.Config.Rehearsal {
 		return ErrInvalidPhase
 	}
+	r := s.startRound(uuid.NewString(), prompt, translations, s.pickModel())
+	r.Type = typ
+	s.logRound(r, "", "")
+	return nil
+}
+
+// startRound begins a new round in Answering. Callers must hold s.mu and
+// have checked the phase.
+func (s *SessionCtx) startRound(id, prompt string, translations map[string]string, model ModelChoice) *Round {
+	// close out the previous phase before the new round becomes current
+	s.setPhase(PhaseAnswering)
 	s.RoundIx++
-	r := &Round{ID: uuid.NewString(), Index: s.RoundIx, Prompt: prompt, Status: PhaseAnswering}
+	r := &Round{ID: id, Index: s.RoundIx, Prompt: prompt, Status: PhaseAnswering, Model: model}
+	for lang, text := range translations {
+		if text = strings.TrimSpace(text); text != "" {
+			if r.Translations == nil {
+				r.Translations = make(map[string]string)
+			}
+			r.Translations[lang] = text
+		}
+	}
 	s.Rounds = append(s.Rounds, r)
 	s.submissions = make(map[string]*Submission)
 	s.byPlayer = make(map[string]string)
 	s.votesByVoter = make(map[string]*Vote)
-	s.Phase = PhaseAnswering
-	return nil
+	s.audienceVotes = nil
+	s.roundPoints = make(map[string]int)
+	return r
 }
 
-func (s *SessionCtx) Join(name string) (playerID, playerToken string) {
+// Join adds a player. Their name goes through the word filter, so it may be
+// masked or, in reject mode, refused with moderation.ErrBlocked.
+func (s *SessionCtx) Join(name string) (playerID, playerToken string, err error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	name, err = s.wordFilter.Apply(name)
+	if err != nil {
+		return "", "", err
+	}
 	p := &Player{ID: uuid.NewString(), Name: name, IsHost: false, JoinedAt: time.Now().UTC()}
 	token := uuid.NewString()
+	s.addPlayer(p, token)
+	s.logEvent(walEvent{Type: walJoin, At: p.JoinedAt, PlayerID: p.ID, Token: token, Name: name})
+	return p.ID, token, nil
+}
+
+func (s *SessionCtx) addPlayer(p *Player, token string) {
 	s.PlayersByToken[token] = p
 	s.PlayersByID[p.ID] = p
-	return p.ID, token
+}
+
+// pickModel returns the provider/model for a new round, drawn at random from
+// BlindModels when a blind test is configured.
+func (s *SessionCtx) pickModel() ModelChoice {
+	if n := len(s.Config.BlindModels); n > 0 {
+		return s.Config.BlindModels[rand.Intn(n)]
+	}
+	return ModelChoice{Provider: s.Config.Provider, Model: s.Config.Model}
+}
+
+// RoundModel returns the provider/model chosen for the current round.
+func (s *SessionCtx) RoundModel() ModelChoice {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.RoundIx == 0 || len(s.Rounds) < s.RoundIx {
+		return s.pickModel()
+	}
+	return s.Rounds[s.RoundIx-1].Model
+}
+
+// LinkProfile marks a player as playing under a claimed profile.
+func (s *SessionCtx) LinkProfile(playerID, profile string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p := s.PlayersByID[playerID]; p != nil {
+		p.Profile = profile
+		s.logEvent(walEvent{Type: walProfile, PlayerID: playerID, Profile: profile})
+	}
 }
 
 func (s *SessionCtx) Submit(playerToken, text string) (submissionID string, err error) {
@@ -151,63 +532,280 @@ func (s *SessionCtx) Submit(playerToken, text string) (submissionID string, err
 	if p == nil {
 		return "", errors.New("unauthorized")
 	}
-	if id, ok := s.byPlayer[p.ID]; ok {
-		// update existing
-		s.submissions[id].Text = text
-		return id, nil
+	text = collapseWhitespace(text)
+	if text == "" {
+		return "", ErrEmptyAnswer
 	}
-	id := uuid.NewString()
-	sub := &Submission{ID: id, PlayerID: p.ID, Text: text}
-	s.submissions[id] = sub
-	s.byPlayer[p.ID] = id
+	if max := s.Config.MaxAnswerLength; max > 0 && utf8.RuneCountInString(text) > max {
+		return "", ErrAnswerTooLong
+	}
+	text, err = s.wordFilter.Apply(text)
+	if err != nil {
+		return "", err
+	}
+	id, ok := s.byPlayer[p.ID]
+	if !ok {
+		if full(len(s.submissions), s.limits.Submissions) {
+			return "", ErrStorageFull
+		}
+		id = uuid.NewString()
+	}
+	s.putSubmission(id, p.ID, text)
+	s.logEvent(walEvent{Type: walSubmit, SubmissionID: id, PlayerID: p.ID, Text: text})
 	return id, nil
 }
 
+// collapseWhitespace trims an answer and collapses runs of whitespace,
+// including line breaks, into single spaces so every answer fits the same
+// voting layout.
+func collapseWhitespace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// putSubmission stores an answer for the current round, replacing the text
+// of an existing submission with the same ID. Callers must hold s.mu.
+func (s *SessionCtx) putSubmission(id, playerID, text string) {
+	if playerID == "AI" {
+		s.Rounds[s.RoundIx-1].AIOriginal = "" // a new answer, nothing edited yet
+	}
+	if sub := s.submissions[id]; sub != nil {
+		// earlier translations no longer match
+		sub.Text = text
+		sub.Translations = nil
+		return
+	}
+	s.submissions[id] = &Submission{ID: id, PlayerID: playerID, Text: text}
+	if s.Phase == PhaseAnswering {
+		s.submissions[id].AnswerTime = s.now().Sub(s.phaseStartedAt)
+	}
+	if playerID == "AI" {
+		s.Rounds[s.RoundIx-1].AISubmissionID = id
+	} else {
+		s.byPlayer[playerID] = id
+	}
+}
+
 func (s *SessionCtx) Advance(hostToken string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if hostToken != s.HostToken {
 		return ErrNotHost
 	}
+	s.advanceLogged()
+	return nil
+}
+
+// AdvanceFrom is Advance for a step decided on in phase of the given round.
+// If the session has moved on meanwhile, e.g. by its phase timer, it stays
+// where it is and ErrStaleStep is returned.
+func (s *SessionCtx) AdvanceFrom(hostToken string, phase Phase, round int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return ErrNotHost
+	}
+	if s.Phase != phase || s.RoundIx != round {
+		return ErrStaleStep
+	}
+	s.advanceLogged()
+	return nil
+}
+
+// advanceLogged advances and journals the step. Callers must hold s.mu.
+func (s *SessionCtx) advanceLogged() {
+	s.advance()
+	s.logEvent(walEvent{Type: walAdvance})
+	if r := s.currentRound(); r != nil && s.Phase == PhaseVoting {
+		// log the outcome too, so logs from before sessions had a seed
+		// replay with the same order
+		s.logEvent(walEvent{Type: walReadingOrder, Order: r.ReadingOrder})
+	}
+}
+
+// advance moves the session to the next phase, scoring the round on the way
+// to the scoreboard. Callers must hold s.mu.
+func (s *SessionCtx) advance() {
 	switch s.Phase {
 	case PhaseLobby, PhasePromptSet:
-		s.Phase = PhaseAnswering
+		s.setPhase(PhaseAnswering)
 	case PhaseAnswering:
-		s.Phase = PhaseVoting
+		s.fillBots()
+		s.setPhase(PhaseVoting)
+		s.shuffleReadingOrder()
+		s.clusterAnswers()
+		s.assignGroups()
 		if len(s.submissions) == 0 {
 			// prevent getting stuck; auto-advance to Reveal
-			s.Phase = PhaseReveal
+			s.setPhase(PhaseReveal)
+			s.holdScores()
 			s.computeScores()
-			s.Phase = PhaseScoreboard
+			s.archiveRound()
+			s.setPhase(PhaseScoreboard)
 		}
 	case PhaseVoting:
-		s.Phase = PhaseReveal
+		s.setPhase(PhaseReveal)
+		s.holdScores()
 		s.computeScores()
-		s.Phase = PhaseScoreboard
+		s.archiveRound()
+		s.setPhase(PhaseScoreboard)
 	case PhaseScoreboard:
+		// moving on reveals anything the host didn't
+		s.heldScores = nil
 		if s.RoundIx >= s.Config.RoundCount {
-			s.Phase = PhaseEnd
+			s.setPhase(PhaseEnd)
 		} else {
-			s.Phase = PhasePromptSet
+			s.setPhase(PhasePromptSet)
 		}
 	}
+}
+
+// ResetRound aborts the current round before it is scored: its submissions
+// and votes are discarded and the session goes back to PromptSet so the host
+// can try a different prompt.
+func (s *SessionCtx) ResetRound(hostToken string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return ErrNotHost
+	}
+	if s.Phase != PhaseAnswering && s.Phase != PhaseVoting {
+		return ErrInvalidPhase
+	}
+	if s.RoundIx == 0 || len(s.Rounds) < s.RoundIx {
+		return errors.New("no active round")
+	}
+	s.resetRound()
+	s.logEvent(walEvent{Type: walResetRound})
 	return nil
 }
 
-func (s *SessionCtx) ListVotingSubmissionsShuffled() []*Submission {
+// resetRound drops the current round. Callers must hold s.mu and have
+// checked that a round is active.
+func (s *SessionCtx) resetRound() {
+	s.setPhase(PhasePromptSet)
+	s.Rounds = s.Rounds[:s.RoundIx-1]
+	s.RoundIx--
+	s.submissions = make(map[string]*Submission)
+	s.byPlayer = make(map[string]string)
+	s.votesByVoter = make(map[string]*Vote)
+	s.audienceVotes = nil
+	s.roundPoints = make(map[string]int)
+}
+
+// setPhase moves the session to p, books the time spent in the previous
+// phase on the current round and stamps the end of the game. Callers must
+// hold s.mu.
+func (s *SessionCtx) setPhase(p Phase) {
+	now := s.now()
+	if s.RoundIx > 0 && len(s.Rounds) >= s.RoundIx && !s.phaseStartedAt.IsZero() {
+		r := s.Rounds[s.RoundIx-1]
+		if r.PhaseSeconds == nil {
+			r.PhaseSeconds = make(map[Phase]float64)
+		}
+		r.PhaseSeconds[s.Phase] += now.Sub(s.phaseStartedAt).Seconds()
+	}
+	s.Phase = p
+	s.phaseStartedAt = now
+	s.phaseExtra = 0
+	s.ready = nil
+	if p == PhaseEnd && s.EndedAt.IsZero() {
+		s.EndedAt = now.UTC()
+	}
+}
+
+// ListVotingSubmissions returns copies of the round's submissions in
+// reading order: shuffled when voting starts, then as arranged by the host.
+// Answers the host hid are left out.
+func (s *SessionCtx) ListVotingSubmissions() []*Submission {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.Phase != PhaseVoting && s.Phase != PhaseReveal && s.Phase != PhaseScoreboard {
 		return nil
 	}
+	r := s.currentRound()
+	if r == nil {
+		return nil
+	}
 	arr := make([]*Submission, 0, len(s.submissions))
-	for _, sub := range s.submissions {
-		arr = append(arr, sub)
+	for _, id := range r.ReadingOrder {
+		if s.Phase == PhaseVoting && r.eliminated(id) || r.hidden(id) {
+			continue
+		}
+		if sub := s.submissions[id]; sub != nil {
+			cp := *sub
+			cp.Translations = copyStrings(sub.Translations)
+			arr = append(arr, &cp)
+		}
 	}
-	rand.Shuffle(len(arr), func(i, j int) { arr[i], arr[j] = arr[j], arr[i] })
 	return arr
 }
 
+// SetReadingOrder lets the host arrange the order in which answers are read
+// aloud during voting, e.g. to save the funniest one for last.
+func (s *SessionCtx) SetReadingOrder(hostToken string, order []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return ErrNotHost
+	}
+	if s.Phase != PhaseVoting {
+		return ErrInvalidPhase
+	}
+	r := s.currentRound()
+	if len(order) != len(r.ReadingOrder) {
+		return ErrInvalidOrder
+	}
+	seen := make(map[string]bool, len(order))
+	for _, id := range order {
+		if !slices.Contains(r.ReadingOrder, id) || seen[id] {
+			return ErrInvalidOrder
+		}
+		seen[id] = true
+	}
+	r.ReadingOrder = append([]string(nil), order...)
+	s.logEvent(walEvent{Type: walReadingOrder, Order: order})
+	return nil
+}
+
+// shuffleReadingOrder puts the round's submissions in random order so the
+// AI answer can't be spotted by position. Callers must hold s.mu.
+func (s *SessionCtx) shuffleReadingOrder() {
+	r := s.currentRound()
+	if r == nil {
+		return
+	}
+	ids := make([]string, 0, len(s.submissions))
+	for id := range s.submissions {
+		ids = append(ids, id)
+	}
+	r.ReadingOrder = ShuffleOrder(s.Seed, r.Index, ids)
+}
+
+// ShuffleSeed returns the session's shuffle seed as shown to the GM and in
+// exports.
+func (s *SessionCtx) ShuffleSeed() string {
+	return fmt.Sprintf("%016x", s.Seed)
+}
+
+// ShuffleOrder returns the reading order of a round's submissions. It only
+// depends on its arguments, so anyone with the seed from an export can
+// check that an order on stage wasn't rigged.
+func ShuffleOrder(seed int64, round int, ids []string) []string {
+	order := append([]string(nil), ids...)
+	sort.Strings(order)
+	rng := rand.New(rand.NewSource(seed + int64(round)))
+	rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	return order
+}
+
+// currentRound returns the round in progress, if any. Callers must hold s.mu.
+func (s *SessionCtx) currentRound() *Round {
+	if s.RoundIx == 0 || len(s.Rounds) < s.RoundIx {
+		return nil
+	}
+	return s.Rounds[s.RoundIx-1]
+}
+
 func (s *SessionCtx) Vote(playerToken string, submissionID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -225,43 +823,96 @@ func (s *SessionCtx) Vote(playerToken string, submissionID string) error {
 	if _, exists := s.votesByVoter[p.ID]; exists {
 		return ErrAlreadyVoted
 	}
+	if s.submissions[submissionID] == nil {
+		return ErrUnknownTarget
+	}
+	own := s.byPlayer[p.ID]
+	if r := s.currentRound(); r != nil {
+		// a merged answer is voted for as part of its cluster
+		submissionID = r.representative(submissionID)
+		own = r.representative(own)
+		if r.eliminated(submissionID) {
+			return ErrEliminated
+		}
+		if r.hidden(submissionID) {
+			return ErrUnknownTarget
+		}
+		if g := r.groupOf(submissionID); g >= 0 && g != r.groupOf(own) {
+			return ErrOtherGroup
+		}
+	}
+	if submissionID == own && !s.Config.AllowSelfVote {
+		return ErrSelfVote
+	}
+	if full(len(s.votesByVoter), s.limits.Votes) {
+		return ErrStorageFull
+	}
 	v := &Vote{ID: uuid.NewString(), VoterID: p.ID, TargetSubmissionID: submissionID}
 	s.votesByVoter[p.ID] = v
+	s.logEvent(walEvent{Type: walVote, VoteID: v.ID, PlayerID: p.ID, SubmissionID: submissionID})
 	return nil
 }
 
 func (s *SessionCtx) computeScores() {
-	// +2 for each vote a player's submission receives; +1 for voting AI (if AI submission known)
+	// +2 for each vote a player's submission receives; +1 for voting AI (if AI submission known),
+	// or whatever the session's Scoring says. After a hint it's one more per vote and nothing
+	// for the AI, see UseHint
 	// Tally votes per submission
-	votesFor := map[string]int{}
-	for _, v := range s.votesByVoter {
-		votesFor[v.TargetSubmissionID]++
+	votesFor := s.voteCounts()
+	// the audience's favourite counts as a few extra votes
+	if t := s.audienceTally(); t != nil && t.Majority != "" {
+		votesFor[t.Majority] += t.Weight
 	}
-	// Award +2 per vote to submission authors
+	// Award points per vote to submission authors
 	aiID := ""
-	if s.RoundIx > 0 && len(s.Rounds) >= s.RoundIx {
-		r := s.Rounds[s.RoundIx-1]
-		aiID = r.AISubmissionID
+	sc := s.Config.scoring()
+	perVote, aiBonus := sc.PerVote, sc.FoundAI
+	r := s.currentRound()
+	if r != nil {
+		aiID = r.revealedAI()
+		if len(r.Eliminated) > 0 {
+			// a hint gave the AI away, see UseHint
+			perVote, aiBonus = perVote+1, 0
+		}
 	}
 	for subID, count := range votesFor {
 		sub := s.submissions[subID]
 		if sub == nil {
 			continue
 		}
-		if subID == aiID {
-			// AI does not gain points
+		if subID == aiID || sub.PlayerID == "AI" {
+			// the AI is no player, but keeps score for the human-vs-machine arc
+			s.aiScore += perVote * count
 			continue
 		}
-		s.Scores[sub.PlayerID] += 2 * count
+		if r == nil || len(r.Clusters[subID]) == 0 {
+			s.Scores[sub.PlayerID] += perVote * count
+			s.roundPoints[sub.PlayerID] += perVote * count
+			continue
+		}
+		// merged answers split their points, the remainder going to the
+		// first authors
+		authors := s.clusterAuthors(r, subID)
+		points := perVote * count
+		for i, id := range authors {
+			share := points / len(authors)
+			if i < points%len(authors) {
+				share++
+			}
+			s.Scores[id] += share
+			s.roundPoints[id] += share
+		}
 	}
 	// Award +1 to players who voted for AI (if any)
-	if aiID != "" {
+	if aiID != "" && aiBonus > 0 {
 		for _, v := range s.votesByVoter {
 			if v.TargetSubmissionID == aiID {
-				s.Scores[v.VoterID] += 1
+				s.Scores[v.VoterID] += aiBonus
+				s.roundPoints[v.VoterID] += aiBonus
 			}
 		}
 	}
+	s.awardFooledBonus(r, votesFor, aiID)
 }
 
 func (s *SessionCtx) Players() []*Player {
@@ -269,7 +920,7 @@ func (s *SessionCtx) Players() []*Player {
 	defer s.mu.Unlock()
 	out := make([]*Player, 0, len(s.PlayersByID))
 	for _, p := range s.PlayersByID {
-		out = append(out, &Player{ID: p.ID, Name: p.Name, IsHost: p.IsHost, JoinedAt: p.JoinedAt})
+		out = append(out, &Player{ID: p.ID, Name: p.Name, IsHost: p.IsHost, JoinedAt: p.JoinedAt, Profile: p.Profile, IsBot: p.IsBot})
 	}
 	return out
 }
@@ -280,6 +931,16 @@ func (s *SessionCtx) SubmissionCount() int {
 	return len(s.submissions)
 }
 
+// SubmissionText returns an answer as stored, after the word filter.
+func (s *SessionCtx) SubmissionText(submissionID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sub := s.submissions[submissionID]; sub != nil {
+		return sub.Text
+	}
+	return ""
+}
+
 func (s *SessionCtx) HumanSubmissionCount() int {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -307,6 +968,23 @@ func (s *SessionCtx) PlayerSubmissionStatus() map[string]bool {
 	return status
 }
 
+// PlayerName resolves a player ID (or "AI") to a display name.
+func (s *SessionCtx) PlayerName(playerID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.playerName(playerID)
+}
+
+func (s *SessionCtx) playerName(playerID string) string {
+	if playerID == "AI" {
+		return "AI"
+	}
+	if p := s.PlayersByID[playerID]; p != nil {
+		return p.Name
+	}
+	return ""
+}
+
 func (s *SessionCtx) GetPlayerIDByToken(token string) string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -322,28 +1000,25 @@ func (s *SessionCtx) Votes() []*Vote {
 	defer s.mu.Unlock()
 	out := make([]*Vote, 0, len(s.votesByVoter))
 	for _, v := range s.votesByVoter {
-		out = append(out, &Vote{ID: v.ID, VoterID: v.VoterID, TargetSubmissionID: v.TargetSubmissionID})
+		out = append(out, &Vote{ID: v.ID, VoterID: v.VoterID, VoterName: s.playerName(v.VoterID), TargetSubmissionID: v.TargetSubmissionID})
 	}
 	return out
 }
 
-func (s *SessionCtx) ScoresArray() []struct {
-	PlayerID string
-	Points   int
-} {
+// ScoresArray returns the scoreboard sorted by points with competition
+// ranking (tied players share a rank, the next rank is skipped).
+func (s *SessionCtx) ScoresArray() []ScoreEntry {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	out := make([]struct {
-		PlayerID string
-		Points   int
-	}, 0, len(s.Scores))
-	for id, pts := range s.Scores {
-		out = append(out, struct {
-			PlayerID string
-			Points   int
-		}{PlayerID: id, Points: pts})
-	}
-	return out
+	return s.scoreboard()
+}
+
+// AIScore is the points the AI earned from votes for its answers, at the
+// same points per vote a player gets.
+func (s *SessionCtx) AIScore() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.aiScore
 }
 
 // GetPhase returns the current phase (thread-safe)
@@ -364,12 +1039,167 @@ func (s *SessionCtx) AddAISubmission(text string) (string, error) {
 		return "", errors.New("no active round")
 	}
 	id := uuid.NewString()
-	sub := &Submission{ID: id, PlayerID: "AI", Text: text}
-	s.submissions[id] = sub
-	s.Rounds[s.RoundIx-1].AISubmissionID = id
+	s.putSubmission(id, "AI", text)
+	s.logEvent(walEvent{Type: walSubmit, SubmissionID: id, PlayerID: "AI", Text: text})
 	return id, nil
 }
 
+// SetAIAnswer lets the host provide the AI answer by hand, replacing any AI
+// answer already present for the current round.
+func (s *SessionCtx) SetAIAnswer(hostToken string, text string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return "", ErrNotHost
+	}
+	return s.replaceAISubmission(text)
+}
+
+// EditAIAnswer lets the host tweak the AI answer before voting, e.g. to
+// shorten or translate it. Unlike SetAIAnswer it keeps the generated text
+// for the export, however often the host edits.
+func (s *SessionCtx) EditAIAnswer(hostToken string, text string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return "", ErrNotHost
+	}
+	if s.Phase != PhaseAnswering {
+		return "", ErrInvalidPhase
+	}
+	id, err := s.editAIAnswer(text)
+	if err != nil {
+		return "", err
+	}
+	s.logEvent(walEvent{Type: walAIEdit, Text: text})
+	return id, nil
+}
+
+func (s *SessionCtx) editAIAnswer(text string) (string, error) {
+	r := s.currentRound()
+	if r == nil || s.submissions[r.AISubmissionID] == nil {
+		return "", ErrNoAIAnswer
+	}
+	original := r.AIOriginal
+	if original == "" {
+		original = s.submissions[r.AISubmissionID].Text
+	}
+	s.putSubmission(r.AISubmissionID, "AI", text)
+	if text != original {
+		r.AIOriginal = original
+	}
+	return r.AISubmissionID, nil
+}
+
+// ReplaceAISubmission swaps the current round's AI answer for text, e.g. a
+// regenerated one, keeping its submission ID. It adds the AI answer if there
+// is none yet.
+func (s *SessionCtx) ReplaceAISubmission(text string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.replaceAISubmission(text)
+}
+
+func (s *SessionCtx) replaceAISubmission(text string) (string, error) {
+	if s.Phase != PhaseAnswering {
+		return "", ErrInvalidPhase
+	}
+	if s.RoundIx == 0 || len(s.Rounds) < s.RoundIx {
+		return "", errors.New("no active round")
+	}
+	id := s.Rounds[s.RoundIx-1].AISubmissionID
+	if s.submissions[id] == nil {
+		id = uuid.NewString()
+	}
+	s.putSubmission(id, "AI", text)
+	s.logEvent(walEvent{Type: walSubmit, SubmissionID: id, PlayerID: "AI", Text: text})
+	return id, nil
+}
+
+// RecordAIMetadata attaches generation metadata to the given round.
+func (s *SessionCtx) RecordAIMetadata(roundID string, meta AIMetadata) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordAIMetadata(roundID, meta)
+	s.logEvent(walEvent{Type: walAIMeta, RoundID: roundID, Meta: &meta})
+}
+
+func (s *SessionCtx) recordAIMetadata(roundID string, meta AIMetadata) {
+	for _, r := range s.Rounds {
+		if r.ID == roundID {
+			r.AIMeta = &meta
+		}
+	}
+}
+
+// RoundAIMetadata returns the AI generation metadata of the round with the
+// given index, if any.
+func (s *SessionCtx) RoundAIMetadata(index int) *AIMetadata {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if index < 1 || index > len(s.Rounds) || s.Rounds[index-1].AIMeta == nil {
+		return nil
+	}
+	meta := *s.Rounds[index-1].AIMeta
+	return &meta
+}
+
+// SetRoundTranslation stores a translated prompt on a round unless the host
+// already supplied one for that language.
+func (s *SessionCtx) SetRoundTranslation(roundID, lang, text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setRoundTranslation(roundID, lang, text)
+	s.logEvent(walEvent{Type: walRoundTranslation, RoundID: roundID, Lang: lang, Text: text})
+}
+
+func (s *SessionCtx) setRoundTranslation(roundID, lang, text string) {
+	for _, r := range s.Rounds {
+		if r.ID != roundID {
+			continue
+		}
+		if r.Translations == nil {
+			r.Translations = make(map[string]string)
+		}
+		if _, ok := r.Translations[lang]; !ok {
+			r.Translations[lang] = text
+		}
+	}
+}
+
+// SetSubmissionTranslation stores a translated answer, provided the answer
+// hasn't been edited since the translation was requested.
+func (s *SessionCtx) SetSubmissionTranslation(submissionID, original, lang, text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.setSubmissionTranslation(submissionID, original, lang, text) {
+		s.logEvent(walEvent{Type: walSubmissionTranslation, SubmissionID: submissionID, Original: original, Lang: lang, Text: text})
+	}
+}
+
+func (s *SessionCtx) setSubmissionTranslation(submissionID, original, lang, text string) bool {
+	sub := s.submissions[submissionID]
+	if sub == nil || sub.Text != original {
+		return false
+	}
+	if sub.Translations == nil {
+		sub.Translations = make(map[string]string)
+	}
+	sub.Translations[lang] = text
+	return true
+}
+
+func copyStrings(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
 func randomCode(n int) string {
 	letters := []rune("ABCDEFGHJKLMNPQRSTUVWXYZ23456789")
 	b := make([]rune, n)
@@ -378,3 +1208,23 @@ func randomCode(n int) string {
 	}
 	return string(b)
 }
+
+func randomPin(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('0' + rand.Intn(10))
+	}
+	return string(b)
+}
+
+// PlayerToken returns the token a player joined with, "" for unknown IDs.
+func (s *SessionCtx) PlayerToken(playerID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for token, p := range s.PlayersByToken {
+		if p.ID == playerID {
+			return token
+		}
+	}
+	return ""
+}
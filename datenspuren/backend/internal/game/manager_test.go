@@ -1,7 +1,14 @@
 package game
 
 import (
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/kiliankoe/gptdash/internal/moderation"
 )
 
 func TestNewRoomManager(t *testing.T) {
@@ -69,7 +76,7 @@ func TestPlayerJoin(t *testing.T) {
 	}
 
 	// First player joins
-	playerID1, playerToken1 := session.Join("Alice")
+	playerID1, playerToken1, _ := session.Join("Alice")
 	if playerID1 == "" {
 		t.Fatal("player ID should not be empty")
 	}
@@ -95,7 +102,7 @@ func TestPlayerJoin(t *testing.T) {
 	}
 
 	// Second player joins
-	playerID2, playerToken2 := session.Join("Bob")
+	playerID2, playerToken2, _ := session.Join("Bob")
 	if playerID2 == playerID1 {
 		t.Fatal("different players should have different IDs")
 	}
@@ -162,8 +169,8 @@ func TestSubmissionFlow(t *testing.T) {
 	}
 
 	// Add players
-	playerID1, playerToken1 := session.Join("Alice")
-	playerID2, playerToken2 := session.Join("Bob")
+	playerID1, playerToken1, _ := session.Join("Alice")
+	playerID2, playerToken2, _ := session.Join("Bob")
 
 	// Start game (SetPrompt transitions directly to Answering)
 	session.SetPrompt(hostToken, "Test question?")
@@ -230,9 +237,9 @@ func TestVotingFlow(t *testing.T) {
 	}
 
 	// Add players
-	_, playerToken1 := session.Join("Alice")
-	_, playerToken2 := session.Join("Bob")
-	_, playerToken3 := session.Join("Charlie")
+	_, playerToken1, _ := session.Join("Alice")
+	_, playerToken2, _ := session.Join("Bob")
+	_, playerToken3, _ := session.Join("Charlie")
 
 	// Start game (SetPrompt transitions directly to Answering)
 	session.SetPrompt(hostToken, "Test question?")
@@ -280,11 +287,13 @@ func TestVotingFlow(t *testing.T) {
 		t.Fatalf("expected ErrAlreadyVoted, got %v", err)
 	}
 
-	// Note: Current implementation allows self-voting
-	// This could be a design choice or a feature to implement later
 	err = session.Vote(playerToken3, submissionID3)
+	if err != ErrSelfVote {
+		t.Fatalf("expected ErrSelfVote, got %v", err)
+	}
+	err = session.Vote(playerToken3, submissionID2)
 	if err != nil {
-		t.Fatalf("current implementation allows self-voting: %v", err)
+		t.Fatalf("should be able to vote: %v", err)
 	}
 
 	votes := session.Votes()
@@ -306,9 +315,9 @@ func TestScoringLogic(t *testing.T) {
 	}
 
 	// Add players
-	playerID1, playerToken1 := session.Join("Alice")
-	playerID2, playerToken2 := session.Join("Bob")
-	playerID3, playerToken3 := session.Join("Charlie")
+	playerID1, playerToken1, _ := session.Join("Alice")
+	playerID2, playerToken2, _ := session.Join("Bob")
+	playerID3, playerToken3, _ := session.Join("Charlie")
 
 	// Start game (SetPrompt transitions directly to Answering)
 	session.SetPrompt(hostToken, "Test question?")
@@ -400,7 +409,7 @@ func TestAuthenticationAndAuthorization(t *testing.T) {
 	}
 
 	// Test valid player operations (after SetPrompt we're in Answering phase)
-	_, playerToken := session.Join("Alice")
+	_, playerToken, _ := session.Join("Alice")
 
 	_, err = session.Submit(playerToken, "Valid answer")
 	if err != nil {
@@ -419,7 +428,7 @@ func TestInvalidPhaseActions(t *testing.T) {
 	if err != nil {
 		t.Fatalf("should be able to get session: %v", err)
 	}
-	_, playerToken := session.Join("Alice")
+	_, playerToken, _ := session.Join("Alice")
 
 	// Test submitting in wrong phase
 	_, err = session.Submit(playerToken, "Answer")
@@ -448,3 +457,1913 @@ func TestInvalidPhaseActions(t *testing.T) {
 		t.Fatalf("expected ErrInvalidPhase when voting in Answering, got %v", err)
 	}
 }
+
+func TestPacingStats(t *testing.T) {
+	rm := NewRoomManager()
+	config := SessionConfig{Provider: "openai", Model: "gpt-3.5-turbo", RoundCount: 3}
+	code, hostToken, err := rm.CreateSession(config)
+	if err != nil {
+		t.Fatalf("should be able to create session: %v", err)
+	}
+	session, err := rm.Get(code)
+	if err != nil {
+		t.Fatalf("should be able to get session: %v", err)
+	}
+
+	if p := session.Pacing(); p.Rounds != 0 || p.SuggestedAnswerTime != 0 {
+		t.Fatalf("expected empty pacing before any round, got %+v", p)
+	}
+
+	_, playerToken, _ := session.Join("Alice")
+	session.SetPrompt(hostToken, "Test question?")
+	session.Submit(playerToken, "Answer")
+	session.Advance(hostToken) // To Voting
+	session.Advance(hostToken) // To Scoreboard
+
+	round := session.Rounds[0]
+	if _, ok := round.PhaseSeconds[PhaseAnswering]; !ok {
+		t.Fatal("answering duration should be recorded on the round")
+	}
+	if _, ok := round.PhaseSeconds[PhaseVoting]; !ok {
+		t.Fatal("voting duration should be recorded on the round")
+	}
+
+	// Use fixed durations to check averaging and rounding
+	round.PhaseSeconds[PhaseAnswering] = 70
+	round.PhaseSeconds[PhaseVoting] = 20
+	session.Rounds = append(session.Rounds, &Round{Index: 2, PhaseSeconds: map[Phase]float64{PhaseAnswering: 80, PhaseVoting: 31}})
+
+	p := session.Pacing()
+	if p.Rounds != 2 {
+		t.Fatalf("expected 2 rounds, got %d", p.Rounds)
+	}
+	if p.AvgAnswerSeconds != 75 || p.SuggestedAnswerTime != 75 {
+		t.Fatalf("expected 75s answer average and suggestion, got %+v", p)
+	}
+	if p.AvgVoteSeconds != 25.5 || p.SuggestedVoteTime != 30 {
+		t.Fatalf("expected 25.5s vote average and 30s suggestion, got %+v", p)
+	}
+
+	// A round whose voting was skipped doesn't count towards the vote average
+	session.Rounds = append(session.Rounds, &Round{Index: 3, PhaseSeconds: map[Phase]float64{PhaseAnswering: 75, PhaseVoting: 0}})
+	p = session.Pacing()
+	if p.Rounds != 3 || p.AvgAnswerSeconds != 75 {
+		t.Fatalf("expected 3 answered rounds averaging 75s, got %+v", p)
+	}
+	if p.AvgVoteSeconds != 25.5 {
+		t.Fatalf("zero-length voting phase should be ignored, got %+v", p)
+	}
+}
+
+func TestGameSummary(t *testing.T) {
+	rm := NewRoomManager()
+	config := SessionConfig{Provider: "openai", Model: "gpt-3.5-turbo", RoundCount: 2}
+	code, hostToken, _ := rm.CreateSession(config)
+	session, _ := rm.Get(code)
+
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	_, charlieToken, _ := session.Join("Charlie")
+
+	// Round 1: Bob fools Alice and Charlie
+	session.SetPrompt(hostToken, "First question?")
+	session.Submit(aliceToken, "Alice 1")
+	bobSub, _ := session.Submit(bobToken, "Bob 1")
+	session.Submit(charlieToken, "Charlie 1")
+	aiID, _ := session.AddAISubmission("AI 1")
+	session.Advance(hostToken) // To Voting
+	session.Vote(aliceToken, bobSub)
+	session.Vote(bobToken, aiID)
+	session.Vote(charlieToken, bobSub)
+	session.Advance(hostToken) // To Scoreboard
+	session.Advance(hostToken) // To PromptSet
+
+	// Round 2: everyone finds the AI
+	session.SetPrompt(hostToken, "Second question?")
+	session.Submit(aliceToken, "Alice 2")
+	session.Submit(bobToken, "Bob 2")
+	session.Submit(charlieToken, "Charlie 2")
+	aiID, _ = session.AddAISubmission("AI 2")
+	session.Advance(hostToken) // To Voting
+	session.Vote(aliceToken, aiID)
+	session.Vote(bobToken, aiID)
+	session.Vote(charlieToken, aiID)
+	session.Advance(hostToken) // To Scoreboard
+	session.Advance(hostToken) // To End
+
+	summary := session.Summary()
+	if len(summary.Rounds) != 2 {
+		t.Fatalf("expected 2 archived rounds, got %d", len(summary.Rounds))
+	}
+	if summary.Rounds[1].Prompt != "Second question?" || len(summary.Rounds[1].Submissions) != 4 {
+		t.Fatalf("unexpected second round summary: %+v", summary.Rounds[1])
+	}
+	if summary.BestAnswer == nil || summary.BestAnswer.Submission.Text != "Bob 1" || summary.BestAnswer.Submission.AuthorName != "Bob" {
+		t.Fatalf("expected Bob's first answer as best answer, got %+v", summary.BestAnswer)
+	}
+	if summary.TotalVotes != 6 || summary.AIVotes != 4 {
+		t.Fatalf("expected 4 of 6 votes on the AI, got %d of %d", summary.AIVotes, summary.TotalVotes)
+	}
+	if summary.AIDetectionRate < 0.66 || summary.AIDetectionRate > 0.67 {
+		t.Fatalf("expected detection rate of 2/3, got %f", summary.AIDetectionRate)
+	}
+}
+
+func TestBestAnswerTieGoesToEarlierAnswer(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "openai", Model: "gpt-3.5-turbo", RoundCount: 2})
+	session, _ := rm.Get(code)
+
+	_, zoeToken, _ := session.Join("Zoe")
+	_, adamToken, _ := session.Join("Adam")
+
+	session.SetPrompt(hostToken, "First question?")
+	zoeSub, _ := session.Submit(zoeToken, "Zoe 1")
+	time.Sleep(time.Millisecond)
+	adamSub, _ := session.Submit(adamToken, "Adam 1")
+	session.AddAISubmission("AI 1")
+	session.Advance(hostToken) // To Voting
+	session.Vote(zoeToken, adamSub)
+	session.Vote(adamToken, zoeSub)
+	session.Advance(hostToken) // To Scoreboard
+	session.Advance(hostToken) // To PromptSet
+
+	// a later round with as many votes doesn't take the title
+	session.SetPrompt(hostToken, "Second question?")
+	zoeSub, _ = session.Submit(zoeToken, "Zoe 2")
+	adamSub, _ = session.Submit(adamToken, "Adam 2")
+	session.AddAISubmission("AI 2")
+	session.Advance(hostToken) // To Voting
+	session.Vote(zoeToken, adamSub)
+	session.Vote(adamToken, zoeSub)
+	session.Advance(hostToken) // To Scoreboard
+
+	if best := session.Summary().BestAnswer; best == nil || best.Submission.Text != "Zoe 1" {
+		t.Fatalf("expected the first answer of the tied pair to win, got %+v", best)
+	}
+}
+
+func TestManualAIAnswer(t *testing.T) {
+	rm := NewRoomManager()
+	config := SessionConfig{Provider: ProviderManual, RoundCount: 1}
+	code, hostToken, _ := rm.CreateSession(config)
+	session, _ := rm.Get(code)
+
+	if _, err := session.SetAIAnswer(hostToken, "Too early"); err != ErrInvalidPhase {
+		t.Fatalf("expected ErrInvalidPhase in Lobby, got %v", err)
+	}
+	session.SetPrompt(hostToken, "Test question?")
+	if _, err := session.SetAIAnswer("invalid-token", "Nope"); err != ErrNotHost {
+		t.Fatalf("expected ErrNotHost with invalid token, got %v", err)
+	}
+
+	id, err := session.SetAIAnswer(hostToken, "First try")
+	if err != nil {
+		t.Fatalf("should be able to set AI answer: %v", err)
+	}
+	replacedID, err := session.SetAIAnswer(hostToken, "Second try")
+	if err != nil {
+		t.Fatalf("should be able to replace AI answer: %v", err)
+	}
+	if replacedID != id {
+		t.Fatal("replacing the AI answer should keep its submission ID")
+	}
+	if session.SubmissionCount() != 1 {
+		t.Fatalf("expected a single AI submission, got %d", session.SubmissionCount())
+	}
+	if session.Rounds[0].AISubmissionID != id || session.submissions[id].Text != "Second try" {
+		t.Fatal("AI submission should be registered on the round with the latest text")
+	}
+}
+
+func TestBlindModelSelection(t *testing.T) {
+	rm := NewRoomManager()
+	config := SessionConfig{
+		Provider:   "openai",
+		Model:      "gpt-3.5-turbo",
+		RoundCount: 1,
+		BlindModels: []ModelChoice{
+			{Provider: "ollama", Model: "mistral"},
+			{Provider: "ollama", Model: "llama3"},
+		},
+	}
+	code, hostToken, _ := rm.CreateSession(config)
+	session, _ := rm.Get(code)
+	session.SetPrompt(hostToken, "Test question?")
+
+	choice := session.RoundModel()
+	if choice.Provider != "ollama" || (choice.Model != "mistral" && choice.Model != "llama3") {
+		t.Fatalf("expected a model from the blind set, got %+v", choice)
+	}
+
+	// The chosen model must never reach clients via the round payload
+	b, err := json.Marshal(session.Rounds[0])
+	if err != nil {
+		t.Fatalf("should be able to marshal round: %v", err)
+	}
+	if strings.Contains(string(b), choice.Model) {
+		t.Fatalf("round payload leaks blind model: %s", b)
+	}
+}
+
+func TestPublicSessions(t *testing.T) {
+	rm := NewRoomManager()
+	rm.CreateSession(SessionConfig{RoundCount: 1})
+	publicCode, hostToken, _ := rm.CreateSession(SessionConfig{RoundCount: 1, Public: true})
+	session, _ := rm.Get(publicCode)
+	session.Join("Alice")
+
+	list := rm.PublicSessions()
+	if len(list) != 1 {
+		t.Fatalf("expected only the public session to be listed, got %d", len(list))
+	}
+	if list[0].Code != publicCode || list[0].PlayerCount != 1 || list[0].Phase != PhaseLobby {
+		t.Fatalf("unexpected listing: %+v", list[0])
+	}
+
+	// Finished games are no longer joinable
+	session.SetPrompt(hostToken, "Test question?")
+	session.Advance(hostToken) // no submissions, straight to Scoreboard
+	session.Advance(hostToken) // To End
+	if list := rm.PublicSessions(); len(list) != 0 {
+		t.Fatalf("expected ended session to be hidden, got %+v", list)
+	}
+}
+
+func TestJoinPinLookup(t *testing.T) {
+	rm := NewRoomManager()
+	code, _, _ := rm.CreateSession(SessionConfig{RoundCount: 1})
+	session, _ := rm.Get(code)
+
+	if len(session.JoinPin) != 6 {
+		t.Fatalf("expected a 6 digit join PIN, got %q", session.JoinPin)
+	}
+	for _, key := range []string{code, strings.ToLower(code), session.JoinPin, " " + session.JoinPin + " "} {
+		found, err := rm.Lookup(key)
+		if err != nil || found != session {
+			t.Fatalf("expected %q to resolve to session %s, got %v", key, code, err)
+		}
+	}
+	if _, err := rm.Lookup("nope"); err != ErrSessionNotFound {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+}
+
+func TestPublicState(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{RoundCount: 3})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+
+	session.SetPrompt(hostToken, "Test question?")
+	aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+	session.Submit(bobToken, "Bob's answer")
+	session.Advance(hostToken) // To Voting
+	session.Vote(bobToken, aliceSub)
+	session.Advance(hostToken) // To Scoreboard
+
+	state := session.PublicState()
+	if state.Phase != PhaseScoreboard || state.RoundIndex != 1 || state.RoundCount != 3 || state.PlayerCount != 2 {
+		t.Fatalf("unexpected public state: %+v", state)
+	}
+	if len(state.Scoreboard) != 2 {
+		t.Fatalf("expected every player on the scoreboard, got %d", len(state.Scoreboard))
+	}
+	if state.Scoreboard[0].Name != "Alice" || state.Scoreboard[0].Points != 2 || state.Scoreboard[1].Points != 0 {
+		t.Fatalf("expected Alice leading with 2 points, got %+v", state.Scoreboard)
+	}
+}
+
+func TestSetPromptTranslated(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{RoundCount: 1, Language: "de"})
+	session, _ := rm.Get(code)
+
+	err := session.SetPromptTranslated(hostToken, "Was ist Liebe?", map[string]string{"en": " What is love? ", "fr": "  "})
+	if err != nil {
+		t.Fatalf("should be able to set translated prompt: %v", err)
+	}
+	round := session.Rounds[0]
+	if round.Prompt != "Was ist Liebe?" {
+		t.Fatalf("expected primary prompt to be kept, got %s", round.Prompt)
+	}
+	if len(round.Translations) != 1 || round.Translations["en"] != "What is love?" {
+		t.Fatalf("expected only the non-empty, trimmed translation, got %v", round.Translations)
+	}
+}
+
+func TestSubmissionTranslations(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{RoundCount: 1, SecondaryLanguage: "en"})
+	session, _ := rm.Get(code)
+	_, playerToken, _ := session.Join("Alice")
+	session.SetPrompt(hostToken, "Was ist Liebe?")
+
+	session.SetRoundTranslation(session.Rounds[0].ID, "en", "What is love?")
+	if session.Rounds[0].Translations["en"] != "What is love?" {
+		t.Fatal("prompt translation should be stored on the round")
+	}
+
+	id, _ := session.Submit(playerToken, "Ein Gefühl")
+	session.SetSubmissionTranslation(id, "Ein Gefühl", "en", "A feeling")
+	if session.submissions[id].Translations["en"] != "A feeling" {
+		t.Fatal("answer translation should be stored on the submission")
+	}
+
+	// Editing the answer drops the old translation and ignores late results for the old text
+	session.Submit(playerToken, "Chemie")
+	session.SetSubmissionTranslation(id, "Ein Gefühl", "en", "A feeling")
+	if len(session.submissions[id].Translations) != 0 {
+		t.Fatalf("stale translation should not be stored, got %v", session.submissions[id].Translations)
+	}
+}
+
+func TestPromptQueue(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "openai", RoundCount: 2, AITrigger: AITriggerQueue})
+	session, _ := rm.Get(code)
+
+	if _, err := session.QueuePrompt("invalid-token", "Nope?", nil); err != ErrNotHost {
+		t.Fatalf("expected ErrNotHost, got %v", err)
+	}
+	first, _ := session.QueuePrompt(hostToken, "First?", nil)
+	second, _ := session.QueuePrompt(hostToken, "Second?", nil)
+	session.SetQueuedAIAnswer(first.ID, "Pre-generated", AIMetadata{Provider: "openai", Model: "gpt-3.5-turbo"})
+
+	queue := session.PromptQueue()
+	if len(queue) != 2 || !queue[0].AIReady || queue[1].AIReady {
+		t.Fatalf("unexpected queue state: %+v", queue)
+	}
+
+	q, err := session.StartQueuedPrompt(hostToken, first.ID)
+	if err != nil {
+		t.Fatalf("should be able to start queued prompt: %v", err)
+	}
+	if q.Prompt != "First?" || session.Phase != PhaseAnswering {
+		t.Fatalf("expected round with queued prompt in Answering, got %q in %s", q.Prompt, session.Phase)
+	}
+	round := session.Rounds[0]
+	if round.AISubmissionID == "" || session.submissions[round.AISubmissionID].Text != "Pre-generated" {
+		t.Fatal("pre-generated AI answer should be added to the round")
+	}
+	if round.AIMeta == nil || round.AIMeta.Provider != "openai" {
+		t.Fatal("AI metadata should carry over from the queue")
+	}
+	if queue := session.PromptQueue(); len(queue) != 1 || queue[0].ID != second.ID {
+		t.Fatalf("started prompt should leave the queue, got %+v", queue)
+	}
+	if _, err := session.StartQueuedPrompt(hostToken, first.ID); err != ErrInvalidPhase {
+		t.Fatalf("expected ErrInvalidPhase while answering, got %v", err)
+	}
+}
+
+func TestResetRound(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{RoundCount: 2})
+	session, _ := rm.Get(code)
+	_, playerToken, _ := session.Join("Alice")
+
+	if err := session.ResetRound(hostToken); err != ErrInvalidPhase {
+		t.Fatalf("expected ErrInvalidPhase in Lobby, got %v", err)
+	}
+
+	session.SetPrompt(hostToken, "Dud question?")
+	session.Submit(playerToken, "Meh")
+	session.AddAISubmission("AI meh")
+	if err := session.ResetRound("invalid-token"); err != ErrNotHost {
+		t.Fatalf("expected ErrNotHost, got %v", err)
+	}
+	if err := session.ResetRound(hostToken); err != nil {
+		t.Fatalf("should be able to reset round: %v", err)
+	}
+	if session.Phase != PhasePromptSet || session.RoundIx != 0 || len(session.Rounds) != 0 {
+		t.Fatalf("expected PromptSet with no rounds, got %s with RoundIx %d", session.Phase, session.RoundIx)
+	}
+	if session.SubmissionCount() != 0 {
+		t.Fatal("submissions should be discarded")
+	}
+
+	// The replacement prompt becomes round 1 again
+	session.SetPrompt(hostToken, "Better question?")
+	if session.RoundIx != 1 || session.Rounds[0].Prompt != "Better question?" {
+		t.Fatalf("expected fresh round 1, got RoundIx %d", session.RoundIx)
+	}
+}
+
+func TestScoresArrayRanking(t *testing.T) {
+	rm := NewRoomManager()
+	code, _, _ := rm.CreateSession(SessionConfig{RoundCount: 1})
+	session, _ := rm.Get(code)
+	alice, _, _ := session.Join("Alice")
+	bob, _, _ := session.Join("Bob")
+	charlie, _, _ := session.Join("Charlie")
+	session.Join("Dora")
+	session.Scores[alice] = 3
+	session.Scores[bob] = 5
+	session.Scores[charlie] = 3
+
+	scores := session.ScoresArray()
+	want := []struct {
+		Name   string
+		Points int
+		Rank   int
+	}{{"Bob", 5, 1}, {"Alice", 3, 2}, {"Charlie", 3, 2}, {"Dora", 0, 4}}
+	if len(scores) != len(want) {
+		t.Fatalf("expected all %d players on the scoreboard, got %d", len(want), len(scores))
+	}
+	for i, w := range want {
+		if scores[i].Name != w.Name || scores[i].Points != w.Points || scores[i].Rank != w.Rank {
+			t.Fatalf("position %d: expected %+v, got %+v", i, w, scores[i])
+		}
+	}
+}
+
+func TestVotesIncludeVoterNames(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{RoundCount: 1})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	bobID, bobToken, _ := session.Join("Bob")
+
+	session.SetPrompt(hostToken, "Test question?")
+	session.Submit(aliceToken, "Alice's answer")
+	bobSub, _ := session.Submit(bobToken, "Bob's answer")
+	session.Advance(hostToken) // To Voting
+	session.Vote(aliceToken, bobSub)
+
+	votes := session.Votes()
+	if len(votes) != 1 || votes[0].VoterName != "Alice" {
+		t.Fatalf("expected vote from Alice, got %+v", votes)
+	}
+	if session.PlayerName(bobID) != "Bob" || session.PlayerName("AI") != "AI" || session.PlayerName("unknown") != "" {
+		t.Fatal("PlayerName should resolve players and the AI")
+	}
+}
+
+func TestSessionCount(t *testing.T) {
+	rm := NewRoomManager()
+	rm.CreateSession(SessionConfig{RoundCount: 1})
+	code, hostToken, _ := rm.CreateSession(SessionConfig{RoundCount: 1})
+	session, _ := rm.Get(code)
+	session.SetPrompt(hostToken, "Only question?")
+	session.Advance(hostToken) // no answers, straight to Scoreboard
+	session.Advance(hostToken) // To End
+
+	total, running := rm.SessionCount()
+	if total != 2 || running != 1 {
+		t.Fatalf("expected 2 sessions with 1 running, got %d/%d", total, running)
+	}
+}
+
+func TestScoreFreeze(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{RoundCount: 2})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	if err := session.SetScoreFreeze(hostToken, true); err != nil {
+		t.Fatalf("should be able to freeze scores: %v", err)
+	}
+	session.SetPrompt(hostToken, "Test question?")
+	aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+	session.Submit(bobToken, "Bob's answer")
+	session.Advance(hostToken) // To Voting
+	session.Vote(bobToken, aliceSub)
+	session.Advance(hostToken) // To Scoreboard
+
+	if !session.ScoresWithheld() {
+		t.Fatal("expected scores to be withheld")
+	}
+	for _, e := range session.PlayerScores() {
+		if e.Points != 0 {
+			t.Fatalf("expected players to see the old standings, got %+v", e)
+		}
+	}
+	if session.ScoresArray()[0].Points != 2 {
+		t.Fatal("expected the host to see the new standings")
+	}
+	if session.PublicState().Scoreboard[0].Points != 0 {
+		t.Fatal("expected the public state to withhold scores too")
+	}
+
+	if err := session.RevealScores(aliceToken); err != ErrNotHost {
+		t.Fatalf("expected ErrNotHost, got %v", err)
+	}
+	if err := session.RevealScores(hostToken); err != nil {
+		t.Fatalf("should be able to reveal scores: %v", err)
+	}
+	if session.ScoresWithheld() || session.PlayerScores()[0].Points != 2 {
+		t.Fatal("expected players to see the new standings after the reveal")
+	}
+	if err := session.RevealScores(hostToken); err != ErrInvalidPhase {
+		t.Fatalf("expected ErrInvalidPhase when nothing is withheld, got %v", err)
+	}
+}
+
+func TestReadingOrder(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{RoundCount: 1})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	session.SetPrompt(hostToken, "Test question?")
+	aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+	bobSub, _ := session.Submit(bobToken, "Bob's answer")
+	aiID, _ := session.AddAISubmission("AI answer")
+
+	if err := session.SetReadingOrder(hostToken, []string{aiID, aliceSub, bobSub}); err != ErrInvalidPhase {
+		t.Fatalf("expected ErrInvalidPhase before voting, got %v", err)
+	}
+	session.Advance(hostToken) // To Voting
+
+	first := session.ListVotingSubmissions()
+	if len(first) != 3 {
+		t.Fatalf("expected 3 submissions, got %d", len(first))
+	}
+	for i, sub := range session.ListVotingSubmissions() {
+		if sub.ID != first[i].ID {
+			t.Fatal("expected the shuffled order to stay stable during voting")
+		}
+	}
+
+	if err := session.SetReadingOrder(hostToken, []string{aiID, aliceSub}); err != ErrInvalidOrder {
+		t.Fatalf("expected ErrInvalidOrder for a partial order, got %v", err)
+	}
+	if err := session.SetReadingOrder(hostToken, []string{aiID, aiID, bobSub}); err != ErrInvalidOrder {
+		t.Fatalf("expected ErrInvalidOrder for duplicates, got %v", err)
+	}
+	if err := session.SetReadingOrder(aliceToken, []string{bobSub, aliceSub, aiID}); err != ErrNotHost {
+		t.Fatalf("expected ErrNotHost, got %v", err)
+	}
+	want := []string{bobSub, aiID, aliceSub}
+	if err := session.SetReadingOrder(hostToken, want); err != nil {
+		t.Fatalf("should be able to set the reading order: %v", err)
+	}
+	for i, sub := range session.ListVotingSubmissions() {
+		if sub.ID != want[i] {
+			t.Fatalf("expected %s at position %d, got %s", want[i], i, sub.ID)
+		}
+	}
+	if got := session.Rounds[0].ReadingOrder; len(got) != 3 || got[0] != bobSub {
+		t.Fatalf("expected the order to be stored on the round, got %v", got)
+	}
+}
+
+func TestShuffleSeed(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{RoundCount: 1})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	session.SetPrompt(hostToken, "Test question?")
+	aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+	bobSub, _ := session.Submit(bobToken, "Bob's answer")
+	aiID, _ := session.AddAISubmission("AI answer")
+	session.Advance(hostToken) // To Voting
+
+	if len(session.ShuffleSeed()) != 16 {
+		t.Fatalf("expected a 16 digit hex seed, got %q", session.ShuffleSeed())
+	}
+	want := ShuffleOrder(session.Seed, 1, []string{bobSub, aiID, aliceSub})
+	for i, sub := range session.ListVotingSubmissions() {
+		if sub.ID != want[i] {
+			t.Fatalf("expected the reading order to follow the seed, got %s at position %d, want %s", sub.ID, i, want[i])
+		}
+	}
+	again := ShuffleOrder(session.Seed, 1, []string{aliceSub, bobSub, aiID})
+	for i := range want {
+		if again[i] != want[i] {
+			t.Fatal("expected the order not to depend on the order of the given IDs")
+		}
+	}
+}
+
+func TestLobby(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{RoundCount: 1})
+	session, _ := rm.Get(code)
+	session.Join("Bob")
+	session.Join("Alice")
+
+	lobby := session.Lobby()
+	if lobby.SessionCode != code || lobby.Phase != PhaseLobby || !lobby.Joinable {
+		t.Fatalf("unexpected lobby info: %+v", lobby)
+	}
+	if lobby.PlayerCount != 2 || lobby.Players[0] != "Alice" || lobby.Players[1] != "Bob" {
+		t.Fatalf("expected sorted player names, got %v", lobby.Players)
+	}
+
+	session.SetPrompt(hostToken, "Only question?")
+	session.Advance(hostToken) // no answers, straight to Scoreboard
+	session.Advance(hostToken) // To End
+	if session.Lobby().Joinable {
+		t.Fatal("expected an ended session not to be joinable")
+	}
+}
+
+func TestSessionRecordingOverride(t *testing.T) {
+	off, on := false, true
+	if !(SessionConfig{}).Recording(true) || (SessionConfig{}).Recording(false) {
+		t.Fatal("expected sessions without an override to follow the server default")
+	}
+	if (SessionConfig{Export: &off}).Recording(true) {
+		t.Fatal("expected a session to be able to opt out of exports")
+	}
+	if !(SessionConfig{Export: &on}).Recording(false) {
+		t.Fatal("expected a session to be able to opt into exports")
+	}
+}
+
+func TestAIScore(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "openai", Model: "gpt-3.5-turbo", RoundCount: 2})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	session.SetPrompt(hostToken, "Test question?")
+	session.Submit(aliceToken, "Alice's answer")
+	session.Submit(bobToken, "Bob's answer")
+	aiID, _ := session.AddAISubmission("AI answer")
+	session.SetScoreFreeze(hostToken, true)
+	session.Advance(hostToken) // To Voting
+	session.Vote(aliceToken, aiID)
+	session.Vote(bobToken, aiID)
+	session.Advance(hostToken) // To Scoreboard
+
+	if got := session.AIScore(); got != 4 {
+		t.Fatalf("expected the AI to earn 2 points per vote, got %d", got)
+	}
+	for _, e := range session.ScoresArray() {
+		if e.PlayerID == "AI" {
+			t.Fatal("the AI must not show up among the players")
+		}
+	}
+	if got := session.PlayerAIScore(); got != 0 {
+		t.Fatalf("expected the AI score to be withheld from players, got %d", got)
+	}
+	session.RevealScores(hostToken)
+	if got := session.PlayerAIScore(); got != 4 {
+		t.Fatalf("expected the revealed AI score, got %d", got)
+	}
+	if last, _ := session.LastRound(); last.AIScore != 4 {
+		t.Fatalf("expected the archived round to carry the AI score, got %d", last.AIScore)
+	}
+	if got := session.Summary().AIScore; got != 4 {
+		t.Fatalf("expected the summary to carry the AI score, got %d", got)
+	}
+}
+
+func TestMetaScore(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "openai", Model: "gpt-3.5-turbo", RoundCount: 3})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	_, carolToken, _ := session.Join("Carol")
+
+	// aiVoters of the three players find the AI, the rest vote for a human
+	play := func(aiVoters int) {
+		session.SetPrompt(hostToken, "Test question?")
+		aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+		bobSub, _ := session.Submit(bobToken, "Bob's answer")
+		session.Submit(carolToken, "Carol's answer")
+		aiID, _ := session.AddAISubmission("AI answer")
+		session.Advance(hostToken) // To Voting
+		for i, token := range []string{bobToken, carolToken, aliceToken} {
+			target := aliceSub
+			if token == aliceToken {
+				target = bobSub
+			}
+			if i < aiVoters {
+				target = aiID
+			}
+			session.Vote(token, target)
+		}
+		session.Advance(hostToken) // To Scoreboard
+	}
+
+	play(3) // caught
+	if m := session.MetaScore(); m.Humans != 1 || m.AI != 0 || m.Leader != "humans" {
+		t.Fatalf("expected the humans to lead after catching the AI, got %+v", m)
+	}
+	session.Advance(hostToken)
+	play(0) // fooled everyone
+	session.Advance(hostToken)
+	session.SetScoreFreeze(hostToken, true)
+	play(1) // fooled the majority
+	if m := session.MetaScore(); m.Humans != 1 || m.AI != 2 || m.Leader != "ai" {
+		t.Fatalf("expected the AI to lead 2:1, got %+v", m)
+	}
+	if m := session.PlayerMetaScore(); m.AI != 1 || m.Leader != "" {
+		t.Fatalf("expected the withheld round to be left out for players, got %+v", m)
+	}
+	if m := session.Summary().MetaScore; m.AI != 2 {
+		t.Fatalf("expected the summary to carry the tally, got %+v", m)
+	}
+}
+
+func TestHint(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "openai", Model: "gpt-3.5-turbo", RoundCount: 1})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	_, carolToken, _ := session.Join("Carol")
+	session.SetPrompt(hostToken, "Test question?")
+	aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+	bobSub, _ := session.Submit(bobToken, "Bob's answer")
+	carolSub, _ := session.Submit(carolToken, "Carol's answer")
+	aiID, _ := session.AddAISubmission("AI answer")
+
+	if _, err := session.UseHint(hostToken); err != ErrInvalidPhase {
+		t.Fatalf("expected ErrInvalidPhase before voting, got %v", err)
+	}
+	session.Advance(hostToken) // To Voting
+	if _, err := session.UseHint(aliceToken); err != ErrNotHost {
+		t.Fatalf("expected ErrNotHost, got %v", err)
+	}
+
+	first, err := session.UseHint(hostToken)
+	if err != nil {
+		t.Fatalf("should be able to use a hint: %v", err)
+	}
+	if first == aiID {
+		t.Fatal("a hint must never eliminate the AI answer")
+	}
+	for _, sub := range session.ListVotingSubmissions() {
+		if sub.ID == first {
+			t.Fatal("the eliminated answer must leave the voting list")
+		}
+	}
+	second, err := session.UseHint(hostToken)
+	if err != nil || second == first || second == aiID {
+		t.Fatalf("should be able to eliminate a second human answer, got %q, %v", second, err)
+	}
+	if _, err := session.UseHint(hostToken); err != ErrNoHint {
+		t.Fatalf("expected ErrNoHint with one human answer left, got %v", err)
+	}
+	if err := session.Vote(aliceToken, first); err != ErrEliminated {
+		t.Fatalf("expected ErrEliminated, got %v", err)
+	}
+
+	remaining := map[string]string{aliceSub: aliceToken, bobSub: bobToken, carolSub: carolToken}
+	delete(remaining, first)
+	delete(remaining, second)
+	var survivor, survivorToken string
+	for id, token := range remaining {
+		survivor, survivorToken = id, token
+	}
+	for id, token := range map[string]string{aliceSub: aliceToken, bobSub: bobToken, carolSub: carolToken} {
+		if id == survivor {
+			session.Vote(token, aiID)
+		} else {
+			session.Vote(token, survivor)
+		}
+	}
+	session.Advance(hostToken) // To Scoreboard
+
+	for _, e := range session.ScoresArray() {
+		want := 0
+		if e.PlayerID == session.GetPlayerIDByToken(survivorToken) {
+			want = 6 // two votes at 3 points; finding the AI earns nothing after a hint
+		}
+		if e.Points != want {
+			t.Fatalf("expected %s to have %d points, got %d", e.Name, want, e.Points)
+		}
+	}
+}
+
+func TestHintDropsAudienceVotes(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1, AudienceWeight: 2})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	session.SetPrompt(hostToken, "Test question?")
+	aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+	bobSub, _ := session.Submit(bobToken, "Bob's answer")
+	session.AddAISubmission("AI answer")
+	session.Advance(hostToken) // To Voting
+	session.AudienceVote("viewer1", aliceSub)
+	session.AudienceVote("viewer2", bobSub)
+
+	gone, err := session.UseHint(hostToken)
+	if err != nil {
+		t.Fatalf("should be able to use a hint: %v", err)
+	}
+	survivor, survivorName := aliceSub, "Alice"
+	if gone == aliceSub {
+		survivor, survivorName = bobSub, "Bob"
+	}
+	tally := session.AudienceTally()
+	if tally.Votes[gone] != 0 || tally.Total != 1 || tally.Majority != survivor {
+		t.Fatalf("expected the vote for the eliminated answer to be dropped, got %+v", tally)
+	}
+	session.Advance(hostToken) // To Scoreboard
+
+	for _, e := range session.ScoresArray() {
+		want := 0
+		if e.Name == survivorName {
+			want = 6 // the audience's two votes at 3 points after a hint
+		}
+		if e.Points != want {
+			t.Fatalf("expected %s to have %d points, got %d", e.Name, want, e.Points)
+		}
+	}
+}
+
+func TestCheats(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "openai", Model: "gpt-3.5-turbo", RoundCount: 1})
+	session, _ := rm.Get(code)
+	aliceID, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	session.SetPrompt(hostToken, "Test question?")
+	aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+	bobSub, _ := session.Submit(bobToken, "Bob's answer")
+	aiID, _ := session.AddAISubmission("AI answer")
+	session.Advance(hostToken) // To Voting
+
+	if _, err := session.Cheat(hostToken, CheatBonus, aliceID, 5); err != ErrCheatsDisabled {
+		t.Fatalf("expected ErrCheatsDisabled, got %v", err)
+	}
+	if err := session.SetCheats(aliceToken, true); err != ErrNotHost {
+		t.Fatalf("expected ErrNotHost, got %v", err)
+	}
+	if err := session.SetCheats(hostToken, true); err != nil {
+		t.Fatalf("should be able to enable cheats: %v", err)
+	}
+	if _, err := session.Cheat(hostToken, CheatBonus, "nobody", 5); err != ErrUnknownTarget {
+		t.Fatalf("expected ErrUnknownTarget, got %v", err)
+	}
+	if _, err := session.Cheat(hostToken, CheatBonus, aliceID, 5); err != nil {
+		t.Fatalf("should be able to award bonus points: %v", err)
+	}
+	if _, err := session.Cheat(hostToken, CheatMarkAI, bobSub, 0); err != nil {
+		t.Fatalf("should be able to mark a submission as the AI: %v", err)
+	}
+	if r := session.PlayerRound(); r.AISubmissionID != bobSub {
+		t.Fatalf("expected players to see Bob's answer as the AI's, got %q", r.AISubmissionID)
+	}
+	if r := session.CurrentRound(); r.AISubmissionID != aiID {
+		t.Fatalf("expected the host to keep the real AI answer, got %q", r.AISubmissionID)
+	}
+	if _, err := session.Cheat(hostToken, CheatVotes, aliceSub, 3); err != nil {
+		t.Fatalf("should be able to override votes: %v", err)
+	}
+	session.Vote(aliceToken, bobSub)
+	session.Vote(bobToken, aiID)
+	session.Advance(hostToken) // To Scoreboard
+
+	// Alice: 5 bonus + 3 overridden votes at 2 + 1 for "finding" the fake AI
+	if got := session.Scores[aliceID]; got != 12 {
+		t.Fatalf("expected 12 points for Alice, got %d", got)
+	}
+	last, _ := session.LastRound()
+	if last.AISubmissionID != aiID || last.RevealedAI != bobSub || last.AIVotes != 1 || last.TotalVotes != 5 {
+		t.Fatalf("expected the round to be archived with the real AI answer, got %+v", last)
+	}
+	for _, sub := range last.Submissions {
+		if sub.ID == aiID && (!sub.IsAI || sub.AuthorName != "AI") || sub.ID == bobSub && (sub.IsAI || sub.AuthorName != "Bob") {
+			t.Fatalf("expected the archive to keep the real authors, got %+v", sub)
+		}
+	}
+	revealed := last.Revealed()
+	if revealed.AISubmissionID != bobSub || revealed.AIVotes != 1 {
+		t.Fatalf("expected the reveal to show Bob's answer as the AI's, got %+v", revealed)
+	}
+	for _, sub := range revealed.Submissions {
+		if sub.ID == aiID && (sub.IsAI || sub.AuthorName != "Bob") || sub.ID == bobSub && !sub.IsAI {
+			t.Fatalf("expected the real AI answer to take Bob's place on stage, got %+v", sub)
+		}
+	}
+	for _, sub := range last.Submissions {
+		if sub.ID == aiID && !sub.IsAI {
+			t.Fatal("expected Revealed to leave the archive untouched")
+		}
+	}
+	if log := session.CheatLog(); len(log) != 3 || log[0].Action != CheatBonus || log[0].Value != 5 {
+		t.Fatalf("expected all cheats in the audit log, got %+v", log)
+	}
+}
+
+func TestCodeFilter(t *testing.T) {
+	rm := NewRoomManager()
+	rm.SetCodeFilter(func(code string) bool { return code[0] == 'A' || code[0] == '1' })
+	for i := 0; i < 5; i++ {
+		code, _, err := rm.CreateSession(SessionConfig{Provider: "openai", Model: "gpt-3.5-turbo", RoundCount: 1})
+		if err != nil {
+			t.Fatalf("should be able to create session: %v", err)
+		}
+		if code[0] != 'A' {
+			t.Fatalf("expected only codes accepted by the filter, got %s", code)
+		}
+		if s, _ := rm.Get(code); s.JoinPin[0] != '1' {
+			t.Fatalf("expected only PINs accepted by the filter, got %s", s.JoinPin)
+		}
+	}
+}
+
+func TestMaxSessions(t *testing.T) {
+	rm := NewRoomManager()
+	rm.SetMaxSessions(2)
+	code, hostToken, err := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1})
+	if err != nil {
+		t.Fatalf("should be able to create session: %v", err)
+	}
+	if _, _, err := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1}); err != nil {
+		t.Fatalf("should be able to create second session: %v", err)
+	}
+	if _, _, err := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1}); err != ErrTooManySessions {
+		t.Fatalf("expected ErrTooManySessions, got %v", err)
+	}
+
+	// ended sessions free their slot
+	session, _ := rm.Get(code)
+	session.SetPrompt(hostToken, "Test question?")
+	for i := 0; i < 3; i++ {
+		session.Advance(hostToken)
+	}
+	if session.GetPhase() != PhaseEnd {
+		t.Fatalf("expected the game to have ended, got %s", session.GetPhase())
+	}
+	if _, _, err := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1}); err != nil {
+		t.Fatalf("should be able to create session after one ended: %v", err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	rm := NewRoomManager()
+	rm.SetLimits(Limits{Submissions: 2, Votes: 1, QueuedPrompts: 1, Notes: 2, History: 1})
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "openai", Model: "gpt-3.5-turbo", RoundCount: 2})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	_, carolToken, _ := session.Join("Carol")
+	session.SetPrompt(hostToken, "Test question?")
+	aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+	bobSub, _ := session.Submit(bobToken, "Bob's answer")
+	if _, err := session.Submit(carolToken, "Carol's answer"); err != ErrStorageFull {
+		t.Fatalf("expected ErrStorageFull for a third answer, got %v", err)
+	}
+	if _, err := session.Submit(aliceToken, "Alice's better answer"); err != nil {
+		t.Fatalf("should be able to edit an answer at the limit: %v", err)
+	}
+
+	if _, err := session.QueuePrompt(hostToken, "Next question?", nil); err != nil {
+		t.Fatalf("should be able to queue a prompt: %v", err)
+	}
+	if _, err := session.QueuePrompt(hostToken, "Another question?", nil); err != ErrStorageFull {
+		t.Fatalf("expected ErrStorageFull for a second queued prompt, got %v", err)
+	}
+	for _, text := range []string{"one", "two", "three"} {
+		session.AddRoundNote(hostToken, 0, text)
+	}
+	if notes := session.Rounds[0].Notes; len(notes) != 2 || notes[0].Text != "two" {
+		t.Fatalf("expected the oldest note to be dropped, got %+v", notes)
+	}
+
+	session.Advance(hostToken) // To Voting
+	if err := session.Vote(aliceToken, bobSub); err != nil {
+		t.Fatalf("should be able to vote: %v", err)
+	}
+	if err := session.Vote(bobToken, aliceSub); err != ErrStorageFull {
+		t.Fatalf("expected ErrStorageFull for a second vote, got %v", err)
+	}
+	session.Advance(hostToken) // To Scoreboard
+	session.Advance(hostToken) // To PromptSet
+	session.SetPrompt(hostToken, "Second question?")
+	session.Advance(hostToken) // no answers, straight to Scoreboard
+
+	summary := session.Summary()
+	first := summary.Rounds[0]
+	if !first.Compacted || first.Submissions != nil || first.Scores != nil {
+		t.Fatalf("expected the first round to be compacted, got %+v", first)
+	}
+	if first.TotalVotes != 1 || summary.TotalVotes != 1 || summary.Rounds[1].Compacted {
+		t.Fatalf("expected compacted rounds to keep their tallies, got %+v", summary)
+	}
+}
+
+func TestPhaseDeadline(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "openai", Model: "gpt-3.5-turbo", RoundCount: 1, AnswerTime: 60})
+	session, _ := rm.Get(code)
+	if _, ok := session.PhaseDeadline(); ok {
+		t.Fatal("expected no deadline in the lobby")
+	}
+	session.SetPrompt(hostToken, "Test question?")
+	deadline, ok := session.PhaseDeadline()
+	if !ok {
+		t.Fatal("expected a deadline while answering")
+	}
+	if left := time.Until(deadline); left <= 55*time.Second || left > 60*time.Second {
+		t.Fatalf("expected about 60s left, got %v", left)
+	}
+	session.Advance(hostToken) // To Voting, without a VoteTime
+	if _, ok := session.PhaseDeadline(); ok {
+		t.Fatal("expected no deadline without a vote timer")
+	}
+}
+
+func TestVoteDeadlineCoversStreamDelay(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "openai", Model: "gpt-3.5-turbo", RoundCount: 1, AnswerTime: 60, VoteTime: 30, StreamDelay: 20})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	session.SetPrompt(hostToken, "Test question?")
+	session.Submit(aliceToken, "Alice's answer")
+	if deadline, _ := session.PhaseDeadline(); time.Until(deadline) > 60*time.Second {
+		t.Fatalf("expected answering to ignore the stream delay, got %v", time.Until(deadline))
+	}
+	session.Advance(hostToken) // To Voting
+	deadline, ok := session.PhaseDeadline()
+	if left := time.Until(deadline); !ok || left <= 45*time.Second || left > 50*time.Second {
+		t.Fatalf("expected about 50s to vote, got %v", left)
+	}
+}
+
+func TestExtendTimer(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "openai", Model: "gpt-3.5-turbo", RoundCount: 1, AnswerTime: 60})
+	session, _ := rm.Get(code)
+	if _, err := session.ExtendTimer(hostToken, 30*time.Second); err != ErrNoTimer {
+		t.Fatalf("expected ErrNoTimer in the lobby, got %v", err)
+	}
+	session.SetPrompt(hostToken, "Test question?")
+	before, _ := session.PhaseDeadline()
+	if _, err := session.ExtendTimer("wrong", 30*time.Second); err != ErrNotHost {
+		t.Fatalf("expected ErrNotHost, got %v", err)
+	}
+	if _, err := session.ExtendTimer(hostToken, time.Hour); err != ErrInvalidExtension {
+		t.Fatalf("expected ErrInvalidExtension, got %v", err)
+	}
+	deadline, err := session.ExtendTimer(hostToken, 30*time.Second)
+	if err != nil {
+		t.Fatalf("should be able to extend the timer: %v", err)
+	}
+	if got := deadline.Sub(before); got != 30*time.Second {
+		t.Fatalf("expected the deadline to move by 30s, got %v", got)
+	}
+	if d, _ := session.PhaseDeadline(); !d.Equal(deadline) {
+		t.Fatalf("expected PhaseDeadline to include the extension, got %v", d)
+	}
+}
+
+func TestPhaseTimers(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1, AnswerTime: 1})
+	session, _ := rm.Get(code)
+	ticks := make(chan int, 100)
+	expired := make(chan Phase, 1)
+	timers := NewPhaseTimers(
+		func(_ *SessionCtx, _ Phase, remaining int, _ time.Time) { ticks <- remaining },
+		func(_ *SessionCtx, phase Phase) { expired <- phase },
+	)
+	timers.tick = 100 * time.Millisecond
+
+	timers.Sync(session)
+	if timers.Running(code) {
+		t.Fatal("expected no countdown in the lobby")
+	}
+	session.SetPrompt(hostToken, "Test question?")
+	timers.Sync(session)
+	if !timers.Running(code) {
+		t.Fatal("expected a countdown while answering")
+	}
+	select {
+	case phase := <-expired:
+		if phase != PhaseAnswering {
+			t.Fatalf("expected answering to expire, got %s", phase)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("expected the answer timer to expire")
+	}
+	if len(ticks) == 0 {
+		t.Fatal("expected the countdown to report the time left")
+	}
+	last := -1
+	for len(ticks) > 0 {
+		last = <-ticks
+	}
+	if last != 0 {
+		t.Fatalf("expected the last report to be 0 seconds left, got %d", last)
+	}
+	if timers.Running(code) {
+		t.Fatal("expected the countdown to be done")
+	}
+
+	// an extension replaces the countdown, the old deadline passes silently
+	rm2 := NewRoomManager()
+	code2, hostToken2, _ := rm2.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1, AnswerTime: 1})
+	session2, _ := rm2.Get(code2)
+	session2.SetPrompt(hostToken2, "Test question?")
+	timers.Sync(session2)
+	session2.ExtendTimer(hostToken2, 60*time.Second)
+	timers.Sync(session2)
+	select {
+	case <-expired:
+		t.Fatal("expected the extended timer not to expire at the old deadline")
+	case <-time.After(1500 * time.Millisecond):
+	}
+	timers.Stop(code2)
+	if timers.Running(code2) {
+		t.Fatal("expected Stop to end the countdown")
+	}
+}
+
+func TestRehearsal(t *testing.T) {
+	dir := t.TempDir()
+	rm := NewRoomManager()
+	if err := rm.EnableWAL(dir, nil); err != nil {
+		t.Fatalf("should be able to enable the WAL: %v", err)
+	}
+	export := true
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 2, Export: &export, Rehearsal: true})
+	session, _ := rm.Get(code)
+	if session.Config.Recording(true) {
+		t.Fatal("expected rehearsals never to be exported")
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.wal")); len(files) != 0 {
+		t.Fatalf("expected rehearsals not to be journaled, got %v", files)
+	}
+
+	_, aliceToken, _ := session.Join("Alice")
+	session.SetPrompt(hostToken, "Test question?")
+	session.Submit(aliceToken, "Alice's answer")
+	session.Advance(hostToken) // To Voting
+	if n := session.HumanSubmissionCount(); n != 1+rehearsalBots {
+		t.Fatalf("expected bots to fill in answers, got %d", n)
+	}
+	bots := 0
+	for _, p := range session.Players() {
+		if p.IsBot {
+			bots++
+		}
+	}
+	if bots != rehearsalBots {
+		t.Fatalf("expected %d bots, got %d", rehearsalBots, bots)
+	}
+
+	if err := session.SetPrompt(hostToken, "Next question?"); err != nil {
+		t.Fatalf("should be able to start a round from any phase in a rehearsal: %v", err)
+	}
+	if session.GetPhase() != PhaseAnswering || session.RoundIx != 2 {
+		t.Fatalf("expected the second round to be answering, got %s", session.GetPhase())
+	}
+}
+
+func TestAttachToPrompt(t *testing.T) {
+	dir := t.TempDir()
+	rm := NewRoomManager()
+	if err := rm.EnableWAL(dir, nil); err != nil {
+		t.Fatalf("should be able to enable WAL: %v", err)
+	}
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1})
+	session, _ := rm.Get(code)
+	picture := &Attachment{Kind: AttachmentImage, URL: "https://example.org/cat.jpg"}
+	if err := session.AttachToPrompt(hostToken, picture); err != ErrInvalidPhase {
+		t.Fatalf("expected ErrInvalidPhase before the first prompt, got %v", err)
+	}
+	session.SetPrompt(hostToken, "Caption this picture")
+	if err := session.AttachToPrompt("bogus", picture); err != ErrNotHost {
+		t.Fatalf("expected ErrNotHost, got %v", err)
+	}
+	for _, bad := range []Attachment{
+		{Kind: AttachmentImage, URL: "javascript:alert(1)"},
+		{Kind: AttachmentLink, URL: "ftp://example.org/file"},
+		{Kind: AttachmentLink, URL: "https://"},
+		{Kind: "video", URL: "https://example.org/cat.mp4"},
+		{Kind: AttachmentImage, URL: "https://example.org/" + strings.Repeat("a", maxAttachmentURL)},
+	} {
+		if err := session.AttachToPrompt(hostToken, &bad); err != ErrInvalidAttachment {
+			t.Fatalf("expected ErrInvalidAttachment for %+v, got %v", bad, err)
+		}
+	}
+	if err := session.AttachToPrompt(hostToken, picture); err != nil {
+		t.Fatalf("should be able to attach a picture: %v", err)
+	}
+	if a := session.CurrentRound().Attachment; a == nil || *a != *picture {
+		t.Fatalf("expected the picture on the round, got %+v", a)
+	}
+
+	recovered := NewRoomManager()
+	recovered.EnableWAL(dir, nil)
+	recovered.RecoverWAL()
+	restored, _ := recovered.Get(code)
+	if a := restored.CurrentRound().Attachment; a == nil || *a != *picture {
+		t.Fatalf("expected the attachment to survive recovery, got %+v", a)
+	}
+
+	if err := session.AttachToPrompt(hostToken, nil); err != nil {
+		t.Fatalf("should be able to remove the attachment: %v", err)
+	}
+	if a := session.CurrentRound().Attachment; a != nil {
+		t.Fatalf("expected the attachment to be gone, got %+v", a)
+	}
+}
+
+func TestVotingGroups(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1, GroupSize: 2})
+	session, _ := rm.Get(code)
+	ids := map[string]string{}
+	tokens := map[string]string{}
+	for _, name := range []string{"Alice", "Bob", "Carol", "Dave", "Eve"} {
+		id, token, _ := session.Join(name)
+		tokens[id] = token
+	}
+	session.SetPrompt(hostToken, "Test question?")
+	for id, token := range tokens {
+		sub, _ := session.Submit(token, "Answer")
+		ids[id] = sub
+	}
+	aiID, _ := session.AddAISubmission("AI answer")
+	session.Advance(hostToken) // To Voting
+
+	r := session.CurrentRound()
+	if len(r.Groups) != 3 {
+		t.Fatalf("expected 5 answers to be split into 3 groups, got %v", r.Groups)
+	}
+	for id, token := range tokens {
+		group, count := session.VotingGroup(id)
+		if group < 0 || count != 3 {
+			t.Fatalf("expected %s to be in one of 3 groups, got %d of %d", id, group, count)
+		}
+		subs := session.ListVotingSubmissionsFor(id)
+		if len(subs) != len(r.Groups[group])+1 {
+			t.Fatalf("expected the group's answers plus the AI's, got %d", len(subs))
+		}
+		for other, sub := range ids {
+			if g, _ := session.VotingGroup(other); g != group {
+				if err := session.Vote(token, sub); err != ErrOtherGroup {
+					t.Fatalf("expected ErrOtherGroup, got %v", err)
+				}
+			}
+		}
+		if err := session.Vote(token, aiID); err != nil {
+			t.Fatalf("every group should be able to vote for the AI: %v", err)
+		}
+	}
+	if len(session.ListVotingSubmissions()) != 6 {
+		t.Fatal("expected the host to still see every answer")
+	}
+	session.Advance(hostToken) // To Scoreboard
+	for _, e := range session.ScoresArray() {
+		if e.Points != 1 {
+			t.Fatalf("expected a merged scoreboard with a point per AI vote, got %+v", e)
+		}
+	}
+}
+
+func TestClusterAnswers(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1, ClusterAnswers: true})
+	session, _ := rm.Get(code)
+	aliceID, aliceToken, _ := session.Join("Alice")
+	bobID, bobToken, _ := session.Join("Bob")
+	_, carolToken, _ := session.Join("Carol")
+	session.SetPrompt(hostToken, "Why is the sky blue?")
+	aliceSub, _ := session.Submit(aliceToken, "Because of the ocean, obviously.")
+	bobSub, _ := session.Submit(bobToken, "because of the OCEAN obviously!!")
+	carolSub, _ := session.Submit(carolToken, "Rayleigh scattering")
+	session.AddAISubmission("Sunlight scatters off air molecules.")
+	session.Advance(hostToken) // To Voting
+
+	if n := len(session.ListVotingSubmissions()); n != 3 {
+		t.Fatalf("expected the near-duplicates to be merged into one option, got %d options", n)
+	}
+	rep, member := aliceSub, bobSub
+	if len(session.ClusterMembers(bobSub)) == 1 {
+		rep, member = bobSub, aliceSub
+	}
+	if members := session.ClusterMembers(rep); len(members) != 1 || members[0].ID != member {
+		t.Fatalf("expected %s to be merged into %s, got %v", member, rep, members)
+	}
+	if err := session.Vote(carolToken, member); err != nil {
+		t.Fatalf("should be able to vote for a merged answer: %v", err)
+	}
+	if votes := session.Votes(); len(votes) != 1 || votes[0].TargetSubmissionID != rep {
+		t.Fatalf("expected the vote to count for the cluster, got %+v", votes)
+	}
+	session.Vote(aliceToken, carolSub)
+	session.Advance(hostToken) // To Scoreboard
+
+	points := map[string]int{}
+	for _, e := range session.ScoresArray() {
+		points[e.PlayerID] = e.Points
+	}
+	if points[aliceID] != 1 || points[bobID] != 1 {
+		t.Fatalf("expected the merged answers' authors to split 2 points, got %v", points)
+	}
+	summary := session.Summary()
+	merged := 0
+	for _, sub := range summary.Rounds[0].Submissions {
+		if sub.MergedInto == rep {
+			merged++
+		}
+	}
+	if merged != 1 {
+		t.Fatalf("expected the summary to record the merge, got %+v", summary.Rounds[0].Submissions)
+	}
+}
+
+func TestSimilarAnswers(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want bool
+	}{
+		{"Mit Butter.", "mit butter", true},
+		{"Mit Butter", "Mit Margarine", false},
+		{"Weil es schon immer so war", "weil es schon immer so war, oder?", true},
+		{"Weil es schon immer so war", "Weil es noch nie so war", false},
+		{"the quick brown fox jumps over the lazy dog", "The quick brown fox jumps over a lazy dog", true},
+		{"", "!!!", false},
+	} {
+		a, aWords := normalizeAnswer(tc.a)
+		b, bWords := normalizeAnswer(tc.b)
+		if got := similarAnswers(a, aWords, b, bWords); got != tc.want {
+			t.Fatalf("expected similarAnswers(%q, %q) = %t", tc.a, tc.b, tc.want)
+		}
+	}
+}
+
+func TestHostless(t *testing.T) {
+	rm := NewRoomManager()
+	venue, _, _ := rm.CreateSession(SessionConfig{RoundCount: 1})
+	code, _, _ := rm.CreateSession(SessionConfig{Hostless: true})
+	session, _ := rm.Get(code)
+	if active, _ := rm.Active(); active != venue {
+		t.Fatalf("expected the hostless session not to become active, got %s", active)
+	}
+	if session.Config.AnswerTime != hostlessAnswerTime || session.Config.VoteTime != hostlessVoteTime || session.Config.RoundCount != hostlessRoundCount {
+		t.Fatalf("expected hostless defaults, got %+v", session.Config)
+	}
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+
+	if allReady, err := session.SetReady(aliceToken); err != nil || allReady {
+		t.Fatalf("expected Alice to be ready alone: %v %t", err, allReady)
+	}
+	if allReady, _ := session.SetReady(bobToken); !allReady {
+		t.Fatal("expected everyone to be ready")
+	}
+	if _, err := session.SetReady("invalid-token"); err == nil {
+		t.Fatal("expected an error for an unknown player")
+	}
+
+	q, err := session.SuggestPrompt(bobToken, "  Why? ")
+	if err != nil {
+		t.Fatalf("should be able to suggest a prompt: %v", err)
+	}
+	if q.Prompt != "Why?" || q.SuggestedBy != "Bob" {
+		t.Fatalf("unexpected suggestion: %+v", q)
+	}
+	if _, err := session.StartQueuedPrompt(session.HostToken, q.ID); err != nil {
+		t.Fatalf("should be able to start the suggested prompt: %v", err)
+	}
+	if ready := session.ReadyPlayers(); len(ready) != 0 {
+		t.Fatalf("expected readiness to reset with the round, got %v", ready)
+	}
+	if _, err := session.SetReady(bobToken); err != ErrInvalidPhase {
+		t.Fatalf("expected ErrInvalidPhase while answering, got %v", err)
+	}
+
+	venueSession, _ := rm.Get(venue)
+	if _, err := venueSession.SuggestPrompt("whatever", "Why?"); err != ErrNotHostless {
+		t.Fatalf("expected ErrNotHostless, got %v", err)
+	}
+}
+
+func TestMultiSession(t *testing.T) {
+	rm := NewRoomManager()
+	rm.SetSingleSession(false)
+	first, _, _ := rm.CreateSession(SessionConfig{RoundCount: 1})
+	if active, _ := rm.Active(); active != first {
+		t.Fatalf("expected the only running game to be offered, got %q", active)
+	}
+	second, _, _ := rm.CreateSession(SessionConfig{RoundCount: 1})
+	rm.CreateSession(SessionConfig{Hostless: true})
+	if active, _ := rm.Active(); active != "" {
+		t.Fatalf("expected no game to be singled out with two running, got %q", active)
+	}
+	sessions := rm.Sessions()
+	if len(sessions) != 3 || sessions[0].Code != first || sessions[1].Code != second || !sessions[2].Hostless {
+		t.Fatalf("expected all sessions oldest first, got %+v", sessions)
+	}
+	rm.Remove(first)
+	if active, _ := rm.Active(); active != second {
+		t.Fatalf("expected the remaining game to be offered, got %q", active)
+	}
+	if sessions := rm.Sessions(); !sessions[0].Active {
+		t.Fatalf("expected the offered game to be marked active, got %+v", sessions)
+	}
+}
+
+func TestReap(t *testing.T) {
+	rm := NewRoomManager()
+	dir := t.TempDir()
+	if err := rm.EnableWAL(dir, nil); err != nil {
+		t.Fatalf("should be able to enable the WAL: %v", err)
+	}
+	idle, _, _ := rm.CreateSession(SessionConfig{RoundCount: 1})
+	deserted, _, _ := rm.CreateSession(SessionConfig{RoundCount: 1})
+	busy, _, _ := rm.CreateSession(SessionConfig{RoundCount: 1})
+	connected := func(code string) int {
+		if code == busy {
+			return 1
+		}
+		return 0
+	}
+	ttl := 50 * time.Millisecond
+	if expired := rm.Reap(time.Now(), ttl, connected); len(expired) != 0 {
+		t.Fatalf("expected fresh sessions to stay, got %d expired", len(expired))
+	}
+	time.Sleep(2 * ttl)
+	// both see activity, but nobody is connected to deserted anymore
+	for _, code := range []string{deserted, busy} {
+		session, _ := rm.Get(code)
+		session.Join("Alice")
+	}
+	expired := map[string]bool{}
+	for _, s := range rm.Reap(time.Now(), ttl, connected) {
+		expired[s.Code] = true
+	}
+	if len(expired) != 2 || !expired[idle] || !expired[deserted] {
+		t.Fatalf("expected the idle and the deserted session to expire, got %v", expired)
+	}
+	if _, err := rm.Get(idle); err == nil {
+		t.Fatal("expected the expired session to be removed")
+	}
+	if _, err := rm.Get(busy); err != nil {
+		t.Fatalf("expected the busy session to stay: %v", err)
+	}
+
+	recovered := NewRoomManager()
+	recovered.EnableWAL(dir, nil)
+	if codes, err := recovered.RecoverWAL(); err != nil || len(codes) != 1 || codes[0] != busy {
+		t.Fatalf("expected only the busy session back after a restart, got %v %v", codes, err)
+	}
+}
+
+func TestAudienceVote(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1, AudienceWeight: 2})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	session.SetPrompt(hostToken, "Test question?")
+	aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+	bobSub, _ := session.Submit(bobToken, "Bob's answer")
+	session.AddAISubmission("AI answer")
+
+	if err := session.AudienceVote("viewer1", aliceSub); err != ErrInvalidPhase {
+		t.Fatalf("expected ErrInvalidPhase before voting, got %v", err)
+	}
+	session.Advance(hostToken) // To Voting
+	session.Vote(aliceToken, bobSub)
+	session.Vote(bobToken, aliceSub)
+	if err := session.AudienceVote("viewer1", bobSub); err != nil {
+		t.Fatalf("should be able to cast an audience vote: %v", err)
+	}
+	session.AudienceVote("viewer1", aliceSub) // changed their mind
+	session.AudienceVote("viewer2", aliceSub)
+	session.AudienceVote("viewer3", bobSub)
+	if err := session.AudienceVote("viewer4", "nope"); err != ErrUnknownTarget {
+		t.Fatalf("expected ErrUnknownTarget, got %v", err)
+	}
+
+	tally := session.AudienceTally()
+	if tally.Total != 3 || tally.Votes[aliceSub] != 2 || tally.Majority != aliceSub {
+		t.Fatalf("expected Alice's answer to win the audience 2:1, got %+v", tally)
+	}
+	session.Advance(hostToken) // To Scoreboard
+
+	for _, e := range session.ScoresArray() {
+		want := 2 // one player vote
+		if e.Name == "Alice" {
+			want = 6 // plus the audience's two
+		}
+		if e.Points != want {
+			t.Fatalf("expected %s to have %d points, got %d", e.Name, want, e.Points)
+		}
+	}
+	last, _ := session.LastRound()
+	if last.Audience == nil || last.Audience.Majority != aliceSub || last.TotalVotes != 2 {
+		t.Fatalf("expected the audience tally apart from the player votes, got %+v", last)
+	}
+
+	code, hostToken, _ = rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1})
+	off, _ := rm.Get(code)
+	_, carolToken, _ := off.Join("Carol")
+	off.SetPrompt(hostToken, "Test question?")
+	carolSub, _ := off.Submit(carolToken, "Carol's answer")
+	off.Advance(hostToken) // To Voting
+	if err := off.AudienceVote("viewer1", carolSub); err != ErrAudienceOff {
+		t.Fatalf("expected ErrAudienceOff without an audience weight, got %v", err)
+	}
+}
+
+func TestAllowSelfVote(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1, AllowSelfVote: true})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	session.SetPrompt(hostToken, "Test question?")
+	aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+	session.Advance(hostToken) // To Voting
+	if err := session.Vote(aliceToken, aliceSub); err != nil {
+		t.Fatalf("should be able to vote for the own answer when allowed: %v", err)
+	}
+}
+
+func TestFooledBonus(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1, FooledBonus: 3})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	bobID, bobToken, _ := session.Join("Bob")
+	_, carolToken, _ := session.Join("Carol")
+	session.SetPrompt(hostToken, "Test question?")
+	session.Submit(aliceToken, "Alice's answer")
+	bobSub, _ := session.Submit(bobToken, "Bob's answer")
+	session.Submit(carolToken, "Carol's answer")
+	aiID, _ := session.AddAISubmission("AI answer")
+	session.Advance(hostToken) // To Voting
+	session.Vote(aliceToken, bobSub)
+	session.Vote(carolToken, bobSub)
+	session.Vote(bobToken, aiID)
+	session.Advance(hostToken) // To Scoreboard
+
+	awards := session.RoundAwards()
+	if len(awards) != 1 || awards[0].Name != AwardFooledEveryone || awards[0].PlayerID != bobID || awards[0].SubmissionID != bobSub || awards[0].Points != 3 {
+		t.Fatalf("expected Bob to fool everyone, got %+v", awards)
+	}
+	for _, e := range session.ScoresArray() {
+		want := 0
+		if e.PlayerID == bobID {
+			want = 2*2 + 1 + 3 // two votes, found the AI, beat it
+		}
+		if e.Points != want {
+			t.Fatalf("expected %s to have %d points, got %d", e.Name, want, e.Points)
+		}
+	}
+	if last, _ := session.LastRound(); len(last.Awards) != 1 {
+		t.Fatalf("expected the award in the round summary, got %+v", last.Awards)
+	}
+}
+
+func TestComputeAwards(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1})
+	session, _ := rm.Get(code)
+	aliceID, aliceToken, _ := session.Join("Alice")
+	bobID, bobToken, _ := session.Join("Bob")
+	carolID, carolToken, _ := session.Join("Carol")
+	session.SetPrompt(hostToken, "Test question?")
+	aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+	time.Sleep(10 * time.Millisecond)
+	session.Submit(bobToken, "Bob's answer")
+	session.Submit(carolToken, "Carol's answer")
+	aiID, _ := session.AddAISubmission("AI answer")
+	session.Advance(hostToken) // To Voting
+	session.Vote(aliceToken, aiID)
+	session.Vote(bobToken, aliceSub)
+	session.Vote(carolToken, aliceSub)
+	session.Advance(hostToken) // To Scoreboard
+	if s := session.Summary(); len(s.Awards) != 0 {
+		t.Fatalf("expected no awards before the game ended, got %+v", s.Awards)
+	}
+	session.Advance(hostToken) // To End
+
+	won := map[string][]string{}
+	for _, a := range ComputeAwards(session) {
+		won[a.Name] = append(won[a.Name], a.PlayerID)
+	}
+	expect := map[string][]string{
+		AwardBestDetector:  {aliceID},
+		AwardBiggestLiar:   {aliceID},
+		AwardMostFooled:    {bobID, carolID},
+		AwardFastestWriter: {aliceID},
+	}
+	for name, ids := range expect {
+		if !slices.Equal(won[name], ids) {
+			t.Fatalf("expected %s to go to %v, got %v", name, ids, won[name])
+		}
+	}
+	if s := session.Summary(); len(s.Awards) != 5 {
+		t.Fatalf("expected the awards in the game summary, got %+v", s.Awards)
+	}
+}
+
+func TestPromptCollection(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	_, carolToken, _ := session.Join("Carol")
+
+	if _, err := session.SubmitPrompt(aliceToken, "Too early?"); err != ErrInvalidPhase {
+		t.Fatalf("expected ErrInvalidPhase before prompt collection, got %v", err)
+	}
+	if err := session.OpenPromptCollection(aliceToken); err != ErrNotHost {
+		t.Fatalf("expected ErrNotHost, got %v", err)
+	}
+	if err := session.OpenPromptCollection(hostToken); err != nil {
+		t.Fatalf("should be able to open prompt collection: %v", err)
+	}
+	alice, _ := session.SubmitPrompt(aliceToken, "Alice's prompt?")
+	bob, _ := session.SubmitPrompt(bobToken, "Bob's prompt?")
+	if again, _ := session.SubmitPrompt(aliceToken, "Alice's better prompt?"); again.ID != alice.ID {
+		t.Fatalf("expected a second suggestion to replace the first, got %+v", again)
+	}
+	if err := session.VotePrompt(aliceToken, alice.ID); err != ErrSelfVote {
+		t.Fatalf("expected ErrSelfVote, got %v", err)
+	}
+	if err := session.VotePrompt(aliceToken, "nope"); err != ErrPromptCandidateGone {
+		t.Fatalf("expected ErrPromptCandidateGone, got %v", err)
+	}
+	session.VotePrompt(aliceToken, bob.ID)
+	session.VotePrompt(carolToken, bob.ID)
+	session.VotePrompt(carolToken, alice.ID) // changed their mind
+
+	if own, vote := session.PromptChoice(carolToken); own != "" || vote != alice.ID {
+		t.Fatalf("expected Carol to have voted for Alice's prompt only, got %q %q", own, vote)
+	}
+	winner, ok := session.WinningPrompt()
+	if !ok || winner.ID != alice.ID || winner.Prompt != "Alice's better prompt?" || winner.Votes != 1 {
+		t.Fatalf("expected Alice's earlier prompt to win the tie, got %+v", winner)
+	}
+	if err := session.SetPrompt(hostToken, winner.Prompt); err != nil {
+		t.Fatalf("should be able to start the round with the winning prompt: %v", err)
+	}
+	if r := session.CurrentRound(); session.GetPhase() != PhaseAnswering || r.Prompt != winner.Prompt {
+		t.Fatalf("expected the round to start with the winning prompt, got %s %+v", session.GetPhase(), r)
+	}
+}
+
+func TestWordFilter(t *testing.T) {
+	rm := NewRoomManager()
+	replace, _ := moderation.New([]string{"darn"}, moderation.ModeReplace)
+	rm.SetWordFilter(replace)
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1})
+	session, _ := rm.Get(code)
+
+	aliceID, aliceToken, err := session.Join("DarnAlice")
+	if err != nil || session.PlayerName(aliceID) != "****Alice" {
+		t.Fatalf("expected the name to be masked, got %q %v", session.PlayerName(aliceID), err)
+	}
+	session.SetPrompt(hostToken, "Q")
+	id, _ := session.Submit(aliceToken, "darn good")
+	if got := session.SubmissionText(id); got != "**** good" {
+		t.Fatalf("expected the answer to be masked, got %q", got)
+	}
+
+	reject, _ := moderation.New([]string{"darn"}, moderation.ModeReject)
+	rm.SetWordFilter(reject)
+	code, hostToken, _ = rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1})
+	session, _ = rm.Get(code)
+	if _, _, err := session.Join("DarnBob"); err != moderation.ErrBlocked {
+		t.Fatalf("expected the name to be rejected, got %v", err)
+	}
+	_, bobToken, _ := session.Join("Bob")
+	session.SetPrompt(hostToken, "Q")
+	if _, err := session.Submit(bobToken, "darn"); err != moderation.ErrBlocked {
+		t.Fatalf("expected the answer to be rejected, got %v", err)
+	}
+	if len(session.Players()) != 1 {
+		t.Fatalf("expected only Bob to have joined, got %d players", len(session.Players()))
+	}
+
+	// a reloaded wordlist applies to running sessions too
+	rm.SetWordFilter(nil)
+	if _, err := session.Submit(bobToken, "darn"); err != nil {
+		t.Fatalf("should be able to answer once the filter is gone: %v", err)
+	}
+}
+
+func TestMaxAnswerLength(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1, MaxAnswerLength: 12})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	session.SetPrompt(hostToken, "Q")
+
+	if _, err := session.Submit(aliceToken, "far too long for this"); err != ErrAnswerTooLong {
+		t.Fatalf("expected ErrAnswerTooLong, got %v", err)
+	}
+	if _, err := session.Submit(aliceToken, " \n\t "); err != ErrEmptyAnswer {
+		t.Fatalf("expected ErrEmptyAnswer, got %v", err)
+	}
+	// whitespace doesn't count against the limit once collapsed
+	id, err := session.Submit(aliceToken, "  Grüße \n\n  aus DD ")
+	if err != nil {
+		t.Fatalf("should be able to submit a short answer: %v", err)
+	}
+	if got := session.SubmissionText(id); got != "Grüße aus DD" {
+		t.Fatalf("expected whitespace to be normalized, got %q", got)
+	}
+}
+
+func TestSimilarToAI(t *testing.T) {
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1})
+	session, _ := rm.Get(code)
+	aliceID, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	session.SetPrompt(hostToken, "Why is the sky blue?")
+
+	if got := session.SimilarToAI(); len(got) != 0 {
+		t.Fatalf("expected no warnings before the AI answered, got %+v", got)
+	}
+	session.Submit(aliceToken, "Because of Rayleigh scattering!")
+	session.Submit(bobToken, "The sky is sad")
+	session.AddAISubmission("Because of rayleigh scattering.")
+
+	got := session.SimilarToAI()
+	if len(got) != 1 || got[0].PlayerID != aliceID || got[0].Similarity != 1 {
+		t.Fatalf("expected only Alice's answer to match the AI's, got %+v", got)
+	}
+}
+
+func TestEditAIAnswer(t *testing.T) {
+	dir := t.TempDir()
+	rm := NewRoomManager()
+	rm.EnableWAL(dir, nil)
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1})
+	session, _ := rm.Get(code)
+	session.SetPrompt(hostToken, "Q")
+
+	if _, err := session.EditAIAnswer(hostToken, "Edited"); err != ErrNoAIAnswer {
+		t.Fatalf("expected ErrNoAIAnswer, got %v", err)
+	}
+	id, _ := session.AddAISubmission("Generated")
+	if _, err := session.EditAIAnswer("nope", "Edited"); err != ErrNotHost {
+		t.Fatalf("expected ErrNotHost, got %v", err)
+	}
+	session.EditAIAnswer(hostToken, "Edited")
+	if got, err := session.EditAIAnswer(hostToken, "Edited again"); err != nil || got != id {
+		t.Fatalf("should be able to edit the AI answer in place: %v", err)
+	}
+	if r := session.CurrentRound(); session.SubmissionText(id) != "Edited again" || r.AIOriginal != "Generated" {
+		t.Fatalf("expected the edit to keep the generated answer, got %q from %q", session.SubmissionText(id), r.AIOriginal)
+	}
+
+	restored := NewRoomManager()
+	restored.EnableWAL(dir, nil)
+	restored.RecoverWAL()
+	rs, _ := restored.Get(code)
+	if r := rs.CurrentRound(); rs.SubmissionText(id) != "Edited again" || r.AIOriginal != "Generated" {
+		t.Fatalf("expected the edit to survive a restart, got %q from %q", rs.SubmissionText(id), r.AIOriginal)
+	}
+
+	session.SetAIAnswer(hostToken, "Replaced")
+	if r := session.CurrentRound(); r.AIOriginal != "" {
+		t.Fatalf("expected a new AI answer to start out unedited, got %q", r.AIOriginal)
+	}
+	session.Advance(hostToken)
+	if _, err := session.EditAIAnswer(hostToken, "Too late"); err != ErrInvalidPhase {
+		t.Fatalf("expected ErrInvalidPhase once voting started, got %v", err)
+	}
+}
+
+func TestHideSubmission(t *testing.T) {
+	dir := t.TempDir()
+	rm := NewRoomManager()
+	rm.EnableWAL(dir, nil)
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	session.SetPrompt(hostToken, "Q")
+	aliceSub, _ := session.Submit(aliceToken, "Something rude")
+	bobSub, _ := session.Submit(bobToken, "Bob's answer")
+	aiID, _ := session.AddAISubmission("AI answer")
+
+	if err := session.HideSubmission(aliceToken, aliceSub); err != ErrNotHost {
+		t.Fatalf("expected ErrNotHost, got %v", err)
+	}
+	if err := session.HideSubmission(hostToken, aiID); err != ErrHideAI {
+		t.Fatalf("expected ErrHideAI, got %v", err)
+	}
+	session.Advance(hostToken) // To Voting
+	session.Vote(bobToken, aliceSub)
+	if err := session.HideSubmission(hostToken, aliceSub); err != nil {
+		t.Fatalf("should be able to hide an answer during voting: %v", err)
+	}
+	for _, sub := range session.ListVotingSubmissions() {
+		if sub.ID == aliceSub {
+			t.Fatal("the hidden answer must leave the voting list")
+		}
+	}
+	if len(session.Votes()) != 0 {
+		t.Fatal("expected votes for the hidden answer to be handed back")
+	}
+	if err := session.Vote(bobToken, aliceSub); err != ErrUnknownTarget {
+		t.Fatalf("expected ErrUnknownTarget voting for a hidden answer, got %v", err)
+	}
+
+	restored := NewRoomManager()
+	restored.EnableWAL(dir, nil)
+	restored.RecoverWAL()
+	rs, _ := restored.Get(code)
+	if len(rs.ListVotingSubmissions()) != 2 {
+		t.Fatalf("expected the answer to stay hidden after a restart, got %d answers", len(rs.ListVotingSubmissions()))
+	}
+
+	session.Vote(aliceToken, bobSub)
+	session.Advance(hostToken) // To Scoreboard
+	rsum, _ := session.LastRound()
+	for _, sub := range rsum.Submissions {
+		if sub.Hidden != (sub.ID == aliceSub) {
+			t.Fatalf("expected only the hidden answer to be flagged in the summary, got %+v", sub)
+		}
+	}
+}
+
+func TestImageRound(t *testing.T) {
+	dir := t.TempDir()
+	rm := NewRoomManager()
+	rm.EnableWAL(dir, nil)
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 2})
+	session, _ := rm.Get(code)
+
+	if err := session.SetImagePrompt("nope", "A cat on the moon"); err != ErrNotHost {
+		t.Fatalf("expected ErrNotHost, got %v", err)
+	}
+	if err := session.SetImagePrompt(hostToken, "A cat on the moon"); err != nil {
+		t.Fatalf("should be able to start an image round: %v", err)
+	}
+	r := session.CurrentRound()
+	if r.Type != RoundImage || r.Attachment != nil {
+		t.Fatalf("expected an image round without its image yet, got %+v", r)
+	}
+	session.SetRoundImage(r.ID, "/api/session/"+code+"/images/"+r.ID)
+
+	restored := NewRoomManager()
+	restored.EnableWAL(dir, nil)
+	restored.RecoverWAL()
+	rs, _ := restored.Get(code)
+	if got := rs.CurrentRound(); got.Type != RoundImage || got.Attachment == nil || got.Attachment.Kind != AttachmentImage {
+		t.Fatalf("expected the image round to survive a restart, got %+v", got)
+	}
+
+	session.SetAIAnswer(hostToken, "Houston, we have a cat")
+	session.Advance(hostToken) // To Voting
+	session.Advance(hostToken) // To Scoreboard
+	if last, _ := session.LastRound(); last.Type != RoundImage {
+		t.Fatalf("expected the summary to keep the round type, got %q", last.Type)
+	}
+	session.SetPrompt(hostToken, "Back to text?")
+	if r := session.CurrentRound(); r.Type != RoundText {
+		t.Fatalf("expected the next round to be a text round, got %q", r.Type)
+	}
+}
+
+func TestScoring(t *testing.T) {
+	rm := NewRoomManager()
+	rm.SetScoring(Scoring{PerVote: 5, FoundAI: 0}, 4)
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1})
+	session, _ := rm.Get(code)
+	if session.Config.Scoring == nil || *session.Config.Scoring != (Scoring{PerVote: 5}) || session.Config.FooledBonus != 4 {
+		t.Fatalf("expected the server's scoring, got %+v %d", session.Config.Scoring, session.Config.FooledBonus)
+	}
+	aliceID, aliceToken, _ := session.Join("Alice")
+	bobID, bobToken, _ := session.Join("Bob")
+	_, carolToken, _ := session.Join("Carol")
+	session.SetPrompt(hostToken, "Test question?")
+	aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+	session.Submit(bobToken, "Bob's answer")
+	session.Submit(carolToken, "Carol's answer")
+	aiID, _ := session.AddAISubmission("AI answer")
+	session.Advance(hostToken) // To Voting
+	session.Vote(aliceToken, aiID)
+	session.Vote(bobToken, aliceSub)
+	session.Vote(carolToken, aliceSub)
+	session.Advance(hostToken) // To Scoreboard
+	if session.Scores[aliceID] != 2*5+4 || session.Scores[bobID] != 0 {
+		t.Fatalf("expected 14 points for Alice and none for Bob, got %d and %d", session.Scores[aliceID], session.Scores[bobID])
+	}
+	if got := session.AIScore(); got != 5 {
+		t.Fatalf("expected the AI to earn 5 points for its vote like a player, got %d", got)
+	}
+
+	own := Scoring{PerVote: 1, FoundAI: 2}
+	code, _, _ = rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1, Scoring: &own, FooledBonus: 1})
+	session, _ = rm.Get(code)
+	if *session.Config.Scoring != own || session.Config.FooledBonus != 1 {
+		t.Fatalf("expected a session's own scoring to stay, got %+v %d", session.Config.Scoring, session.Config.FooledBonus)
+	}
+}
+
+func TestCreateSessionRejectsUnknownTrigger(t *testing.T) {
+	rm := NewRoomManager()
+	if _, _, err := rm.CreateSession(SessionConfig{RoundCount: 1, AITrigger: "whenever"}); err != ErrInvalidTrigger {
+		t.Fatalf("expected ErrInvalidTrigger, got %v", err)
+	}
+	if _, _, err := rm.CreateSession(SessionConfig{RoundCount: 1, AITrigger: AITriggerVoting}); err != nil {
+		t.Fatalf("should accept a known trigger: %v", err)
+	}
+}
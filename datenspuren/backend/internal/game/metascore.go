@@ -0,0 +1,54 @@
+package game
+
+// MetaScore is the running humans-vs-AI tally of a session: a round goes to
+// the humans if a majority of the votes found the AI and to the AI if a
+// majority was fooled. Rounds without a majority either way are draws.
+type MetaScore struct {
+	Humans int    `json:"humans"`
+	AI     int    `json:"ai"`
+	Draws  int    `json:"draws"`
+	Leader string `json:"leader"` // "humans", "ai" or "" while tied
+}
+
+func metaScore(rounds []RoundSummary) MetaScore {
+	var m MetaScore
+	for _, rs := range rounds {
+		switch fooled := rs.TotalVotes - rs.AIVotes; {
+		case rs.AIVotes > fooled:
+			m.Humans++
+		case fooled > rs.AIVotes:
+			m.AI++
+		default:
+			m.Draws++
+		}
+	}
+	switch {
+	case m.Humans > m.AI:
+		m.Leader = "humans"
+	case m.AI > m.Humans:
+		m.Leader = "ai"
+	}
+	return m
+}
+
+// MetaScore tallies all scored rounds.
+func (s *SessionCtx) MetaScore() MetaScore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return metaScore(s.history)
+}
+
+// PlayerMetaScore is the tally as players may see it, leaving out a round
+// whose scores are withheld.
+func (s *SessionCtx) PlayerMetaScore() MetaScore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.playerMetaScore()
+}
+
+func (s *SessionCtx) playerMetaScore() MetaScore {
+	if s.heldScores != nil && len(s.history) > 0 {
+		return metaScore(s.history[:len(s.history)-1])
+	}
+	return metaScore(s.history)
+}
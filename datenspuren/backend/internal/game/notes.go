@@ -0,0 +1,51 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrRoundNotFound = errors.New("round not found")
+
+// AddRoundNote attaches a host note to the round with the given 1-based
+// index, or to the current round if index is 0. Notes can be added at any
+// time, also to rounds that were already scored.
+func (s *SessionCtx) AddRoundNote(hostToken string, index int, text string) (RoundNote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return RoundNote{}, ErrNotHost
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return RoundNote{}, errors.New("empty note")
+	}
+	if index == 0 {
+		index = s.RoundIx
+	}
+	if index < 1 || index > len(s.Rounds) {
+		return RoundNote{}, ErrRoundNotFound
+	}
+	r := s.Rounds[index-1]
+	note := RoundNote{Text: text, At: time.Now().UTC()}
+	s.addRoundNote(r.ID, note)
+	s.logEvent(walEvent{Type: walRoundNote, At: note.At, RoundID: r.ID, Text: text})
+	return note, nil
+}
+
+// addRoundNote stores a note on the round and, if the round was already
+// archived, on its summary. Callers must hold s.mu.
+func (s *SessionCtx) addRoundNote(roundID string, note RoundNote) {
+	for _, r := range s.Rounds {
+		if r.ID != roundID {
+			continue
+		}
+		r.Notes = evict(append(r.Notes, note), s.limits.Notes)
+		for i := range s.history {
+			if s.history[i].Index == r.Index {
+				s.history[i].Notes = evict(append(s.history[i].Notes, note), s.limits.Notes)
+			}
+		}
+	}
+}
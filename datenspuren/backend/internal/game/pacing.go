@@ -0,0 +1,48 @@
+package game
+
+import "math"
+
+// PacingStats summarizes how long previous rounds spent answering and voting
+// so the host can pick sensible timer values over a long show.
+type PacingStats struct {
+	Rounds              int     `json:"rounds"`
+	AvgAnswerSeconds    float64 `json:"avgAnswerSeconds"`
+	AvgVoteSeconds      float64 `json:"avgVoteSeconds"`
+	SuggestedAnswerTime int     `json:"suggestedAnswerTime"` // seconds
+	SuggestedVoteTime   int     `json:"suggestedVoteTime"`   // seconds
+}
+
+// Pacing returns average answer/vote durations over all rounds that spent
+// time in the respective phase, plus suggested timers rounded up to 5s.
+// Skipped phases (0s) are left out so they don't drag the average down.
+func (s *SessionCtx) Pacing() PacingStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out PacingStats
+	var answerSum, voteSum float64
+	var answerN, voteN int
+	for _, r := range s.Rounds {
+		if secs := r.PhaseSeconds[PhaseAnswering]; secs > 0 {
+			answerSum += secs
+			answerN++
+		}
+		if secs := r.PhaseSeconds[PhaseVoting]; secs > 0 {
+			voteSum += secs
+			voteN++
+		}
+	}
+	if answerN > 0 {
+		out.AvgAnswerSeconds = answerSum / float64(answerN)
+		out.SuggestedAnswerTime = roundUpTo(out.AvgAnswerSeconds, 5)
+	}
+	if voteN > 0 {
+		out.AvgVoteSeconds = voteSum / float64(voteN)
+		out.SuggestedVoteTime = roundUpTo(out.AvgVoteSeconds, 5)
+	}
+	out.Rounds = max(answerN, voteN)
+	return out
+}
+
+func roundUpTo(v float64, step int) int {
+	return int(math.Ceil(v/float64(step))) * step
+}
@@ -0,0 +1,291 @@
+package game
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrProfileNotFound = errors.New("profile not found")
+	ErrInvalidPin      = errors.New("invalid pin")
+	ErrPinTooShort     = fmt.Errorf("pin must have at least %d characters", MinPinLength)
+)
+
+// MinPinLength is the shortest PIN a new profile can be claimed with.
+// Profiles claimed before keep working with their shorter PIN.
+const MinPinLength = 6
+
+// pinIterations is the PBKDF2 work factor of PIN hashes, so a leaked
+// profiles file can't be brute-forced in seconds.
+const pinIterations = 100_000
+
+// pinHashPrefix marks PBKDF2 hashes; hashes without it are plain salted
+// SHA-256 from before and get upgraded on the next login.
+const pinHashPrefix = "pbkdf2-sha256$"
+
+// Achievement identifiers awarded to profiles
+const (
+	AchievementFirstGame   = "first_game"
+	AchievementVeteran     = "veteran"      // 10 games played
+	AchievementAIHunter    = "ai_hunter"    // identified the AI 10 times
+	AchievementDeceiver    = "deceiver"     // received 10 votes
+	AchievementRoundWinner = "round_winner" // earned the most points in a round
+)
+
+type ProfileStats struct {
+	GamesPlayed   int `json:"gamesPlayed"`
+	RoundsPlayed  int `json:"roundsPlayed"`
+	Points        int `json:"points"`
+	AIGuesses     int `json:"aiGuesses"`     // times the player voted for the AI
+	VotesReceived int `json:"votesReceived"` // times others voted for the player's answer
+}
+
+// Profile is a lightweight named identity that survives across sessions.
+type Profile struct {
+	Name         string       `json:"name"`
+	PinHash      string       `json:"pinHash"`
+	Salt         string       `json:"salt"`
+	CreatedAt    time.Time    `json:"createdAt"`
+	LastSeenAt   time.Time    `json:"lastSeenAt"`
+	Stats        ProfileStats `json:"stats"`
+	Achievements []string     `json:"achievements"`
+}
+
+// PublicProfile is the subset of a profile safe to hand out via the API.
+type PublicProfile struct {
+	Name         string       `json:"name"`
+	CreatedAt    time.Time    `json:"createdAt"`
+	LastSeenAt   time.Time    `json:"lastSeenAt"`
+	Stats        ProfileStats `json:"stats"`
+	Achievements []string     `json:"achievements"`
+}
+
+// ProfileStore keeps profiles in memory and persists them to a JSON file.
+// An empty filename keeps profiles in memory only.
+type ProfileStore struct {
+	mu       sync.Mutex
+	filename string
+	profiles map[string]*Profile // normalized name -> Profile
+}
+
+func LoadProfiles(filename string) (*ProfileStore, error) {
+	ps := &ProfileStore{filename: filename, profiles: make(map[string]*Profile)}
+	if filename == "" {
+		return ps, nil
+	}
+	b, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return ps, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+	var list []*Profile
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles: %w", err)
+	}
+	for _, p := range list {
+		ps.profiles[normalizeProfileName(p.Name)] = p
+	}
+	return ps, nil
+}
+
+// Claim creates the profile if the name is free, or verifies the PIN if it
+// has been claimed before.
+func (ps *ProfileStore) Claim(name, pin string) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	key := normalizeProfileName(name)
+	if key == "" || pin == "" {
+		return ErrInvalidPin
+	}
+	now := time.Now().UTC()
+	if p := ps.profiles[key]; p != nil {
+		if !p.checkPin(pin) {
+			return ErrInvalidPin
+		}
+		if !strings.HasPrefix(p.PinHash, pinHashPrefix) {
+			p.PinHash = hashPin(p.Salt, pin)
+		}
+		p.LastSeenAt = now
+		return ps.save()
+	}
+	if len([]rune(pin)) < MinPinLength {
+		return ErrPinTooShort
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	p := &Profile{Name: strings.TrimSpace(name), Salt: hex.EncodeToString(salt), CreatedAt: now, LastSeenAt: now, Achievements: []string{}}
+	p.PinHash = hashPin(p.Salt, pin)
+	ps.profiles[key] = p
+	return ps.save()
+}
+
+func (ps *ProfileStore) Get(name string) (PublicProfile, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	p := ps.profiles[normalizeProfileName(name)]
+	if p == nil {
+		return PublicProfile{}, ErrProfileNotFound
+	}
+	return PublicProfile{
+		Name:         p.Name,
+		CreatedAt:    p.CreatedAt,
+		LastSeenAt:   p.LastSeenAt,
+		Stats:        p.Stats,
+		Achievements: append([]string{}, p.Achievements...),
+	}, nil
+}
+
+// RecordRound books the results of the session's current round onto the
+// profiles of the players who claimed one. Call once after scoring.
+func (ps *ProfileStore) RecordRound(s *SessionCtx) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	aiID := ""
+	if s.RoundIx > 0 && len(s.Rounds) >= s.RoundIx {
+		aiID = s.Rounds[s.RoundIx-1].AISubmissionID
+	}
+	best := 0
+	for _, pts := range s.roundPoints {
+		best = max(best, pts)
+	}
+	changed := false
+	for playerID, subID := range s.byPlayer {
+		player := s.PlayersByID[playerID]
+		if player == nil || player.Profile == "" {
+			continue
+		}
+		p := ps.profiles[normalizeProfileName(player.Profile)]
+		if p == nil {
+			continue
+		}
+		p.Stats.RoundsPlayed++
+		p.Stats.Points += s.roundPoints[playerID]
+		for _, v := range s.votesByVoter {
+			if v.TargetSubmissionID == subID {
+				p.Stats.VotesReceived++
+			}
+		}
+		if v := s.votesByVoter[playerID]; v != nil && aiID != "" && v.TargetSubmissionID == aiID {
+			p.Stats.AIGuesses++
+		}
+		if best > 0 && s.roundPoints[playerID] == best {
+			p.award(AchievementRoundWinner)
+		}
+		p.checkAchievements()
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return ps.save()
+}
+
+// RecordGame counts a finished game for every profile in the session.
+func (ps *ProfileStore) RecordGame(s *SessionCtx) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	changed := false
+	for _, player := range s.PlayersByID {
+		if player.Profile == "" {
+			continue
+		}
+		if p := ps.profiles[normalizeProfileName(player.Profile)]; p != nil {
+			p.Stats.GamesPlayed++
+			p.checkAchievements()
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return ps.save()
+}
+
+func (p *Profile) checkAchievements() {
+	if p.Stats.GamesPlayed >= 1 {
+		p.award(AchievementFirstGame)
+	}
+	if p.Stats.GamesPlayed >= 10 {
+		p.award(AchievementVeteran)
+	}
+	if p.Stats.AIGuesses >= 10 {
+		p.award(AchievementAIHunter)
+	}
+	if p.Stats.VotesReceived >= 10 {
+		p.award(AchievementDeceiver)
+	}
+}
+
+func (p *Profile) award(a string) {
+	for _, have := range p.Achievements {
+		if have == a {
+			return
+		}
+	}
+	p.Achievements = append(p.Achievements, a)
+}
+
+// save writes all profiles to disk. Callers must hold ps.mu.
+func (ps *ProfileStore) save() error {
+	if ps.filename == "" {
+		return nil
+	}
+	list := make([]*Profile, 0, len(ps.profiles))
+	for _, p := range ps.profiles {
+		list = append(list, p)
+	}
+	b, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(ps.filename), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	tmp := ps.filename + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return fmt.Errorf("failed to write profiles: %w", err)
+	}
+	return os.Rename(tmp, ps.filename)
+}
+
+func hashPin(salt, pin string) string {
+	key, err := pbkdf2.Key(sha256.New, pin, []byte(salt), pinIterations, sha256.Size)
+	if err != nil {
+		panic(err) // only fails for FIPS-disallowed parameters
+	}
+	return pinHashPrefix + hex.EncodeToString(key)
+}
+
+// checkPin compares pin against the stored hash in constant time.
+func (p *Profile) checkPin(pin string) bool {
+	want := hashPin(p.Salt, pin)
+	if !strings.HasPrefix(p.PinHash, pinHashPrefix) {
+		sum := sha256.Sum256([]byte(p.Salt + ":" + pin))
+		want = hex.EncodeToString(sum[:])
+	}
+	return subtle.ConstantTimeCompare([]byte(want), []byte(p.PinHash)) == 1
+}
+
+func normalizeProfileName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,94 @@
+package game
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfileClaimAndStats(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "profiles.json")
+	ps, err := LoadProfiles(file)
+	if err != nil {
+		t.Fatalf("should be able to load profiles: %v", err)
+	}
+
+	if err := ps.Claim("Alice", "123456"); err != nil {
+		t.Fatalf("should be able to claim a new profile: %v", err)
+	}
+	if err := ps.Claim("alice ", "123456"); err != nil {
+		t.Fatalf("should be able to log in with the same PIN: %v", err)
+	}
+	if err := ps.Claim("Alice", "000000"); err != ErrInvalidPin {
+		t.Fatalf("expected ErrInvalidPin, got %v", err)
+	}
+	if err := ps.Claim("Bob", "1234"); err != ErrPinTooShort {
+		t.Fatalf("expected short PINs to be refused for new profiles, got %v", err)
+	}
+
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{RoundCount: 1})
+	session, _ := rm.Get(code)
+	aliceID, aliceToken, _ := session.Join("Alice")
+	session.LinkProfile(aliceID, "Alice")
+	_, bobToken, _ := session.Join("Bob")
+
+	session.SetPrompt(hostToken, "Test question?")
+	aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+	session.Submit(bobToken, "Bob's answer")
+	aiID, _ := session.AddAISubmission("AI answer")
+	session.Advance(hostToken) // To Voting
+	session.Vote(aliceToken, aiID)
+	session.Vote(bobToken, aliceSub)
+	session.Advance(hostToken) // To Scoreboard
+
+	if err := ps.RecordRound(session); err != nil {
+		t.Fatalf("should be able to record round: %v", err)
+	}
+	session.Advance(hostToken) // To End
+	if err := ps.RecordGame(session); err != nil {
+		t.Fatalf("should be able to record game: %v", err)
+	}
+
+	// Reload from disk to verify persistence
+	ps, err = LoadProfiles(file)
+	if err != nil {
+		t.Fatalf("should be able to reload profiles: %v", err)
+	}
+	p, err := ps.Get("ALICE")
+	if err != nil {
+		t.Fatalf("should be able to look up profile: %v", err)
+	}
+	want := ProfileStats{GamesPlayed: 1, RoundsPlayed: 1, Points: 3, AIGuesses: 1, VotesReceived: 1}
+	if p.Stats != want {
+		t.Fatalf("expected stats %+v, got %+v", want, p.Stats)
+	}
+	if len(p.Achievements) != 2 {
+		t.Fatalf("expected round_winner and first_game achievements, got %v", p.Achievements)
+	}
+
+	if _, err := ps.Get("Bob"); err != ErrProfileNotFound {
+		t.Fatalf("expected ErrProfileNotFound for unclaimed name, got %v", err)
+	}
+}
+
+func TestProfileUpgradesLegacyPinHash(t *testing.T) {
+	ps, _ := LoadProfiles("")
+	sum := sha256.Sum256([]byte("salt:1234"))
+	ps.profiles["carol"] = &Profile{Name: "Carol", Salt: "salt", PinHash: hex.EncodeToString(sum[:])}
+
+	if err := ps.Claim("Carol", "0000"); err != ErrInvalidPin {
+		t.Fatalf("expected ErrInvalidPin, got %v", err)
+	}
+	if err := ps.Claim("Carol", "1234"); err != nil {
+		t.Fatalf("profiles with a short legacy PIN should still log in: %v", err)
+	}
+	if !strings.HasPrefix(ps.profiles["carol"].PinHash, pinHashPrefix) {
+		t.Fatalf("expected the legacy hash to be upgraded, got %q", ps.profiles["carol"].PinHash)
+	}
+	if err := ps.Claim("Carol", "1234"); err != nil {
+		t.Fatalf("should log in against the upgraded hash: %v", err)
+	}
+}
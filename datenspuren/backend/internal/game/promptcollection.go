@@ -0,0 +1,185 @@
+package game
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var ErrPromptCandidateGone = errors.New("prompt candidate not found")
+
+// PhasePromptCollection has the players suggest and vote on the next round's
+// prompt, see OpenPromptCollection.
+const PhasePromptCollection Phase = "PromptCollection"
+
+// PromptCandidate is a prompt a player suggested during PhasePromptCollection.
+// Candidates are shown without their author so nobody votes for friends.
+type PromptCandidate struct {
+	ID       string `json:"id"`
+	Prompt   string `json:"prompt"`
+	PlayerID string `json:"-"`
+	Votes    int    `json:"votes"`
+}
+
+// OpenPromptCollection lets the players pick the next round's prompt: each
+// suggests one with SubmitPrompt and votes for one with VotePrompt, until
+// the host starts the round with the WinningPrompt.
+func (s *SessionCtx) OpenPromptCollection(hostToken string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return ErrNotHost
+	}
+	if s.Phase != PhaseLobby && s.Phase != PhasePromptSet && s.Phase != PhaseScoreboard {
+		return ErrInvalidPhase
+	}
+	if s.RoundIx >= s.Config.RoundCount {
+		return ErrInvalidPhase
+	}
+	s.openPromptCollection()
+	s.logEvent(walEvent{Type: walPromptCollection})
+	return nil
+}
+
+// openPromptCollection moves to PhasePromptCollection with no candidates.
+// Callers must hold s.mu.
+func (s *SessionCtx) openPromptCollection() {
+	// leaving the scoreboard reveals anything the host didn't, see advance
+	s.heldScores = nil
+	s.setPhase(PhasePromptCollection)
+	s.promptCandidates = nil
+	s.promptVotes = make(map[string]string)
+}
+
+// SubmitPrompt suggests a prompt for the next round. Submitting again
+// replaces the player's suggestion; votes for it stay.
+func (s *SessionCtx) SubmitPrompt(playerToken, prompt string) (PromptCandidate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Phase != PhasePromptCollection {
+		return PromptCandidate{}, ErrInvalidPhase
+	}
+	p := s.PlayersByToken[playerToken]
+	if p == nil {
+		return PromptCandidate{}, errors.New("unauthorized")
+	}
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return PromptCandidate{}, errors.New("empty prompt")
+	}
+	id := uuid.NewString()
+	if c := s.candidateBy(p.ID); c != nil {
+		id = c.ID
+	} else if full(len(s.promptCandidates), s.limits.QueuedPrompts) {
+		return PromptCandidate{}, ErrStorageFull
+	}
+	c := s.putPromptCandidate(id, p.ID, prompt)
+	s.logEvent(walEvent{Type: walPromptCandidate, QueuedID: id, PlayerID: p.ID, Prompt: prompt})
+	return s.countPromptVotes(*c), nil
+}
+
+// putPromptCandidate stores a suggestion, replacing the text of an existing
+// one with the same ID. Callers must hold s.mu.
+func (s *SessionCtx) putPromptCandidate(id, playerID, prompt string) *PromptCandidate {
+	for _, c := range s.promptCandidates {
+		if c.ID == id {
+			c.Prompt = prompt
+			return c
+		}
+	}
+	c := &PromptCandidate{ID: id, Prompt: prompt, PlayerID: playerID}
+	s.promptCandidates = append(s.promptCandidates, c)
+	return c
+}
+
+// candidateBy returns a player's suggestion. Callers must hold s.mu.
+func (s *SessionCtx) candidateBy(playerID string) *PromptCandidate {
+	for _, c := range s.promptCandidates {
+		if c.PlayerID == playerID {
+			return c
+		}
+	}
+	return nil
+}
+
+// VotePrompt votes for the prompt a player wants to play next. Voting again
+// changes the vote. Players can't vote for their own suggestion unless the
+// session allows self-votes.
+func (s *SessionCtx) VotePrompt(playerToken, candidateID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Phase != PhasePromptCollection {
+		return ErrInvalidPhase
+	}
+	p := s.PlayersByToken[playerToken]
+	if p == nil {
+		return errors.New("unauthorized")
+	}
+	var target *PromptCandidate
+	for _, c := range s.promptCandidates {
+		if c.ID == candidateID {
+			target = c
+		}
+	}
+	if target == nil {
+		return ErrPromptCandidateGone
+	}
+	if target.PlayerID == p.ID && !s.Config.AllowSelfVote {
+		return ErrSelfVote
+	}
+	s.promptVotes[p.ID] = candidateID
+	s.logEvent(walEvent{Type: walPromptVote, PlayerID: p.ID, QueuedID: candidateID})
+	return nil
+}
+
+// PromptCandidates returns the suggestions in the order they came in, with
+// their votes.
+func (s *SessionCtx) PromptCandidates() []PromptCandidate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]PromptCandidate, 0, len(s.promptCandidates))
+	for _, c := range s.promptCandidates {
+		out = append(out, s.countPromptVotes(*c))
+	}
+	return out
+}
+
+// countPromptVotes fills in c's votes. Callers must hold s.mu.
+func (s *SessionCtx) countPromptVotes(c PromptCandidate) PromptCandidate {
+	c.Votes = 0
+	for _, id := range s.promptVotes {
+		if id == c.ID {
+			c.Votes++
+		}
+	}
+	return c
+}
+
+// WinningPrompt returns the suggestion with the most votes, the earliest one
+// on a tie. It reports false while nobody suggested anything.
+func (s *SessionCtx) WinningPrompt() (PromptCandidate, bool) {
+	var best PromptCandidate
+	found := false
+	for _, c := range s.PromptCandidates() {
+		if !found || c.Votes > best.Votes {
+			best, found = c, true
+		}
+	}
+	return best, found
+}
+
+// PromptChoice returns the ID of the player's own suggestion and of the one
+// they voted for, empty if none.
+func (s *SessionCtx) PromptChoice(playerToken string) (own, vote string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.PlayersByToken[playerToken]
+	if p == nil {
+		return "", ""
+	}
+	if c := s.candidateBy(p.ID); c != nil {
+		own = c.ID
+	}
+	return own, s.promptVotes[p.ID]
+}
@@ -0,0 +1,147 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PromptStats is how a prompt fared across all sessions, so hosts can spot
+// prompts the regulars have figured out.
+type PromptStats struct {
+	Prompt     string    `json:"prompt"`
+	Rounds     int       `json:"rounds"`
+	Answers    int       `json:"answers"` // human answers over all rounds
+	Votes      int       `json:"votes"`
+	AIVotes    int       `json:"aiVotes"` // votes that found the AI
+	LastPlayed time.Time `json:"lastPlayed"`
+
+	FoolRate   float64 `json:"foolRate"`   // share of votes the AI fooled
+	AvgAnswers float64 `json:"avgAnswers"` // human answers per round
+}
+
+func (p *PromptStats) updateRates() {
+	p.FoolRate, p.AvgAnswers = 0, 0
+	if p.Votes > 0 {
+		p.FoolRate = float64(p.Votes-p.AIVotes) / float64(p.Votes)
+	}
+	if p.Rounds > 0 {
+		p.AvgAnswers = float64(p.Answers) / float64(p.Rounds)
+	}
+}
+
+// PromptStatsStore keeps PromptStats in memory and persists them to a JSON
+// file. An empty filename keeps them in memory only.
+type PromptStatsStore struct {
+	mu       sync.Mutex
+	filename string
+	stats    map[string]*PromptStats // normalized prompt -> stats
+}
+
+func LoadPromptStats(filename string) (*PromptStatsStore, error) {
+	ps := &PromptStatsStore{filename: filename, stats: make(map[string]*PromptStats)}
+	if filename == "" {
+		return ps, nil
+	}
+	b, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return ps, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt stats: %w", err)
+	}
+	var list []*PromptStats
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse prompt stats: %w", err)
+	}
+	for _, p := range list {
+		ps.stats[normalizePrompt(p.Prompt)] = p
+	}
+	return ps, nil
+}
+
+// RecordRound books a scored round onto its prompt's stats. Call once per
+// round, e.g. with the session's LastRound after scoring.
+func (ps *PromptStatsStore) RecordRound(rs RoundSummary) error {
+	key := normalizePrompt(rs.Prompt)
+	if key == "" {
+		return nil
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	p := ps.stats[key]
+	if p == nil {
+		p = &PromptStats{Prompt: rs.Prompt}
+		ps.stats[key] = p
+	}
+	p.Rounds++
+	for _, sub := range rs.Submissions {
+		if !sub.IsAI {
+			p.Answers++
+		}
+	}
+	p.Votes += rs.TotalVotes
+	p.AIVotes += rs.AIVotes
+	p.LastPlayed = time.Now().UTC()
+	p.updateRates()
+	return ps.save()
+}
+
+// Get returns the stats of a prompt, matching prompts that differ only in
+// case, spacing or punctuation.
+func (ps *PromptStatsStore) Get(prompt string) (PromptStats, bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	p := ps.stats[normalizePrompt(prompt)]
+	if p == nil {
+		return PromptStats{}, false
+	}
+	return *p, true
+}
+
+// List returns the stats of all prompts played so far, the ones the AI
+// fools the fewest people with first.
+func (ps *PromptStatsStore) List() []PromptStats {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	out := make([]PromptStats, 0, len(ps.stats))
+	for _, p := range ps.stats {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].FoolRate != out[j].FoolRate {
+			return out[i].FoolRate < out[j].FoolRate
+		}
+		return out[i].Prompt < out[j].Prompt
+	})
+	return out
+}
+
+// save writes the stats atomically. Callers must hold ps.mu.
+func (ps *PromptStatsStore) save() error {
+	if ps.filename == "" {
+		return nil
+	}
+	list := make([]*PromptStats, 0, len(ps.stats))
+	for _, p := range ps.stats {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Prompt < list[j].Prompt })
+	b, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(ps.filename), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	tmp := ps.filename + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return fmt.Errorf("failed to write prompt stats: %w", err)
+	}
+	return os.Rename(tmp, ps.filename)
+}
@@ -0,0 +1,68 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPromptStats(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "prompt-stats.json")
+	ps, err := LoadPromptStats(file)
+	if err != nil {
+		t.Fatalf("should be able to load prompt stats: %v", err)
+	}
+
+	play := func(prompt string, fooled bool) {
+		rm := NewRoomManager()
+		code, hostToken, _ := rm.CreateSession(SessionConfig{RoundCount: 1})
+		session, _ := rm.Get(code)
+		_, aliceToken, _ := session.Join("Alice")
+		_, bobToken, _ := session.Join("Bob")
+		session.SetPrompt(hostToken, prompt)
+		aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+		bobSub, _ := session.Submit(bobToken, "Bob's answer")
+		aiID, _ := session.AddAISubmission("AI answer")
+		session.Advance(hostToken) // To Voting
+		if fooled {
+			session.Vote(aliceToken, bobSub)
+			session.Vote(bobToken, aliceSub)
+		} else {
+			session.Vote(aliceToken, aiID)
+			session.Vote(bobToken, aliceSub)
+		}
+		session.Advance(hostToken) // To Scoreboard
+		rs, ok := session.LastRound()
+		if !ok {
+			t.Fatal("expected a scored round")
+		}
+		if err := ps.RecordRound(rs); err != nil {
+			t.Fatalf("should be able to record round: %v", err)
+		}
+	}
+	play("Why is the sky blue?", true)
+	play("why is the sky blue", false)
+	play("What is love?", false)
+
+	// Reload from disk to verify persistence
+	ps, err = LoadPromptStats(file)
+	if err != nil {
+		t.Fatalf("should be able to reload prompt stats: %v", err)
+	}
+	st, ok := ps.Get("Why is the sky blue?")
+	if !ok {
+		t.Fatal("expected stats for the prompt")
+	}
+	if st.Rounds != 2 || st.Answers != 4 || st.Votes != 4 || st.AIVotes != 1 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+	if st.FoolRate != 0.75 || st.AvgAnswers != 2 {
+		t.Fatalf("expected a fool rate of 0.75 and 2 answers per round, got %+v", st)
+	}
+	list := ps.List()
+	if len(list) != 2 || list[0].Prompt != "What is love?" {
+		t.Fatalf("expected the least fooling prompt first, got %+v", list)
+	}
+	if _, ok := ps.Get("Unknown?"); ok {
+		t.Fatal("expected no stats for an unplayed prompt")
+	}
+}
@@ -0,0 +1,129 @@
+package game
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var ErrQueuedPromptNotFound = errors.New("queued prompt not found")
+
+// QueuedPrompt is a prompt the host prepared for an upcoming round. With
+// AITriggerQueue its AI answer is generated while it waits in the queue.
+type QueuedPrompt struct {
+	ID           string            `json:"id"`
+	Prompt       string            `json:"prompt"`
+	Translations map[string]string `json:"translations,omitempty"`
+	Category     string            `json:"category,omitempty"`
+	Language     string            `json:"language,omitempty"`
+	SuggestedBy  string            `json:"suggestedBy,omitempty"` // player who suggested it, hostless sessions only
+	AIReady      bool              `json:"aiReady"`
+
+	Model    ModelChoice `json:"-"`
+	AIAnswer string      `json:"-"`
+	AIMeta   *AIMetadata `json:"-"`
+}
+
+// QueuePrompt appends a prompt to the host's queue of upcoming rounds.
+func (s *SessionCtx) QueuePrompt(hostToken string, prompt string, translations map[string]string) (QueuedPrompt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return QueuedPrompt{}, ErrNotHost
+	}
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return QueuedPrompt{}, errors.New("empty prompt")
+	}
+	if full(len(s.promptQueue), s.limits.QueuedPrompts) {
+		return QueuedPrompt{}, ErrStorageFull
+	}
+	q := s.queuePrompt(QueuedPrompt{Prompt: prompt, Translations: copyStrings(translations)})
+	return *q, nil
+}
+
+// queuePrompt appends q to the queue under a new ID. Callers must hold s.mu.
+func (s *SessionCtx) queuePrompt(q QueuedPrompt) *QueuedPrompt {
+	q.ID = uuid.NewString()
+	q.Model = s.pickModel()
+	s.promptQueue = append(s.promptQueue, &q)
+	s.logEvent(walEvent{Type: walQueuePrompt, QueuedID: q.ID, Prompt: q.Prompt, Translations: q.Translations, Category: q.Category, Lang: q.Language, Name: q.SuggestedBy, Model: &q.Model})
+	return &q
+}
+
+func (s *SessionCtx) PromptQueue() []QueuedPrompt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]QueuedPrompt, 0, len(s.promptQueue))
+	for _, q := range s.promptQueue {
+		out = append(out, *q)
+	}
+	return out
+}
+
+// SetQueuedAIAnswer stores a pre-generated AI answer on a queued prompt. It
+// is a no-op if the prompt has been used or removed in the meantime.
+func (s *SessionCtx) SetQueuedAIAnswer(id string, text string, meta AIMetadata) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.setQueuedAIAnswer(id, text, meta) {
+		s.logEvent(walEvent{Type: walQueuedAnswer, QueuedID: id, Text: text, Meta: &meta})
+	}
+}
+
+func (s *SessionCtx) setQueuedAIAnswer(id string, text string, meta AIMetadata) bool {
+	for _, q := range s.promptQueue {
+		if q.ID == id {
+			q.AIAnswer = text
+			q.AIMeta = &meta
+			q.AIReady = true
+			return true
+		}
+	}
+	return false
+}
+
+// StartQueuedPrompt takes a prompt off the queue and starts its round. If
+// the AI answer was already generated it is added to the round right away.
+func (s *SessionCtx) StartQueuedPrompt(hostToken string, id string) (QueuedPrompt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return QueuedPrompt{}, ErrNotHost
+	}
+	if s.Phase != PhaseLobby && s.Phase != PhasePromptSet && s.Phase != PhaseScoreboard && s.Phase != PhasePromptCollection {
+		return QueuedPrompt{}, ErrInvalidPhase
+	}
+	q := s.takeQueued(id)
+	if q == nil {
+		return QueuedPrompt{}, ErrQueuedPromptNotFound
+	}
+	r, subID := s.startQueued(q, uuid.NewString(), uuid.NewString())
+	s.logRound(r, q.ID, subID)
+	return *q, nil
+}
+
+// takeQueued removes a prompt from the queue. Callers must hold s.mu.
+func (s *SessionCtx) takeQueued(id string) *QueuedPrompt {
+	for i, q := range s.promptQueue {
+		if q.ID == id {
+			s.promptQueue = append(s.promptQueue[:i], s.promptQueue[i+1:]...)
+			return q
+		}
+	}
+	return nil
+}
+
+// startQueued starts the round for q and adds the pre-generated AI answer
+// under subID if there is one. It returns the ID actually used for the AI
+// answer, or "" if there was none. Callers must hold s.mu.
+func (s *SessionCtx) startQueued(q *QueuedPrompt, roundID, subID string) (*Round, string) {
+	r := s.startRound(roundID, q.Prompt, q.Translations, q.Model)
+	if !q.AIReady {
+		return r, ""
+	}
+	s.putSubmission(subID, "AI", q.AIAnswer)
+	r.AIMeta = q.AIMeta
+	return r, subID
+}
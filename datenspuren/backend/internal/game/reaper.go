@@ -0,0 +1,80 @@
+package game
+
+import (
+	"context"
+	"time"
+)
+
+// LastActivity is when the session last changed, e.g. a player joined or
+// answered or the host moved on.
+func (s *SessionCtx) LastActivity() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastActivity
+}
+
+// Expire ends a session that sat idle or that an admin closed. It is logged
+// like any other event, so recovering the WAL or store after a restart
+// doesn't bring it back.
+func (s *SessionCtx) Expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Phase != PhaseEnd {
+		s.setPhase(PhaseEnd)
+	}
+	s.logEvent(walEvent{Type: walExpire})
+	if s.journal != nil {
+		s.journal.close()
+		s.journal = nil
+	}
+}
+
+// Reap expires and removes the sessions nobody used for longer than ttl:
+// either nothing happened in them or no client was connected to them for
+// that long. connected reports how many clients a session has. The removed
+// sessions are returned.
+func (rm *RoomManager) Reap(now time.Time, ttl time.Duration, connected func(code string) int) []*SessionCtx {
+	rm.mu.Lock()
+	sessions := make([]*SessionCtx, 0, len(rm.sessions))
+	for _, s := range rm.sessions {
+		sessions = append(sessions, s)
+	}
+	rm.mu.Unlock()
+
+	var expired []*SessionCtx
+	for _, s := range sessions {
+		rm.mu.Lock()
+		if connected(s.Code) > 0 || rm.seen[s.Code].IsZero() {
+			rm.seen[s.Code] = now
+		}
+		lastSeen := rm.seen[s.Code]
+		rm.mu.Unlock()
+		if now.Sub(s.LastActivity()) <= ttl && now.Sub(lastSeen) <= ttl {
+			continue
+		}
+		s.Expire()
+		rm.Remove(s.Code)
+		expired = append(expired, s)
+	}
+	return expired
+}
+
+// StartReaper runs Reap until ctx is done, calling onExpire for every
+// session it removes.
+func (rm *RoomManager) StartReaper(ctx context.Context, ttl time.Duration, connected func(code string) int, onExpire func(s *SessionCtx)) {
+	interval := min(max(ttl/4, time.Second), time.Minute)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				for _, s := range rm.Reap(now, ttl, connected) {
+					onExpire(s)
+				}
+			}
+		}
+	}()
+}
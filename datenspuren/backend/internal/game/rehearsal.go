@@ -0,0 +1,36 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// rehearsalBots is how many bot players a rehearsal fills in, so the tech
+// run-through has answers to vote on even with nobody in the room.
+const rehearsalBots = 3
+
+// fillBots makes sure a rehearsal round has answers: it adds bot players
+// up to rehearsalBots and answers for every player who hasn't submitted.
+// Rehearsals aren't journaled, so this never needs to be replayed. Callers
+// must hold s.mu.
+func (s *SessionCtx) fillBots() {
+	if !s.Config.Rehearsal || s.RoundIx == 0 || len(s.Rounds) < s.RoundIx {
+		return
+	}
+	bots := 0
+	for _, p := range s.PlayersByID {
+		if p.IsBot {
+			bots++
+		}
+	}
+	for ; bots < rehearsalBots; bots++ {
+		s.addPlayer(&Player{ID: uuid.NewString(), Name: fmt.Sprintf("Bot %d", bots+1), IsBot: true, JoinedAt: time.Now().UTC()}, uuid.NewString())
+	}
+	for id, p := range s.PlayersByID {
+		if _, ok := s.byPlayer[id]; !ok {
+			s.putSubmission(uuid.NewString(), id, fmt.Sprintf("Probeantwort von %s", p.Name))
+		}
+	}
+}
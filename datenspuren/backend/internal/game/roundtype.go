@@ -0,0 +1,29 @@
+package game
+
+// RoundType is what players answer in a round.
+type RoundType string
+
+const (
+	// RoundText is the classic round: players answer the prompt. It is the
+	// zero value, so rounds from before round types read as text rounds.
+	RoundText RoundType = ""
+	// RoundImage shows an image generated from the prompt, which players
+	// caption instead, see SetImagePrompt.
+	RoundImage RoundType = "image"
+)
+
+// SetImagePrompt starts an image round. The image arrives later through
+// SetRoundImage, once the image provider is done.
+func (s *SessionCtx) SetImagePrompt(hostToken string, prompt string) error {
+	return s.setPromptOfType(hostToken, prompt, nil, RoundImage)
+}
+
+// SetRoundImage shows the generated image of an image round as its
+// attachment. Unlike AttachToPrompt it takes server-relative URLs.
+func (s *SessionCtx) SetRoundImage(roundID, url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := &Attachment{Kind: AttachmentImage, URL: url}
+	s.setAttachment(roundID, a)
+	s.logEvent(walEvent{Type: walAttachment, RoundID: roundID, Attachment: a})
+}
@@ -0,0 +1,27 @@
+package game
+
+// Scoring is what a round pays out, besides the FooledBonus.
+type Scoring struct {
+	PerVote int `json:"perVote"` // for every vote an answer draws
+	FoundAI int `json:"foundAI"` // for voting for the AI answer
+}
+
+// DefaultScoring is the classic scoring of sessions that don't bring their
+// own.
+var DefaultScoring = Scoring{PerVote: 2, FoundAI: 1}
+
+func (c SessionConfig) scoring() Scoring {
+	if c.Scoring != nil {
+		return *c.Scoring
+	}
+	return DefaultScoring
+}
+
+// SetScoring gives new sessions without their own scoring sc, and
+// fooledBonus unless they set a FooledBonus.
+func (rm *RoomManager) SetScoring(sc Scoring, fooledBonus int) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.scoring = &sc
+	rm.fooledBonus = fooledBonus
+}
@@ -0,0 +1,70 @@
+package game
+
+import "sort"
+
+// similarityWarning is the bigram similarity (Dice coefficient) from which
+// a human answer counts as nearly the AI's, see SimilarToAI.
+const similarityWarning = 0.6
+
+// SimilarAnswer is a human answer that comes close to the AI's, so voting
+// on both would be a coin toss.
+type SimilarAnswer struct {
+	SubmissionID string  `json:"submissionId"`
+	PlayerID     string  `json:"playerId"`
+	PlayerName   string  `json:"playerName"`
+	Text         string  `json:"text"`
+	Similarity   float64 `json:"similarity"` // 0..1
+}
+
+// SimilarToAI returns the current round's human answers that are nearly the
+// same as the AI's, most similar first. It is empty until the AI answered.
+func (s *SessionCtx) SimilarToAI() []SimilarAnswer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []SimilarAnswer{}
+	r := s.currentRound()
+	if r == nil || s.submissions[r.AISubmissionID] == nil {
+		return out
+	}
+	ai, _ := normalizeAnswer(s.submissions[r.AISubmissionID].Text)
+	aiBigrams := bigrams(ai)
+	for id, sub := range s.submissions {
+		if id == r.AISubmissionID || sub.PlayerID == "AI" {
+			continue
+		}
+		norm, _ := normalizeAnswer(sub.Text)
+		if sim := dice(aiBigrams, bigrams(norm)); sim >= similarityWarning {
+			out = append(out, SimilarAnswer{SubmissionID: id, PlayerID: sub.PlayerID, PlayerName: s.playerName(sub.PlayerID), Text: sub.Text, Similarity: sim})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Similarity > out[j].Similarity })
+	return out
+}
+
+// bigrams counts the pairs of adjacent characters in text.
+func bigrams(text string) map[string]int {
+	runes := []rune(text)
+	out := make(map[string]int, len(runes))
+	for i := 0; i+1 < len(runes); i++ {
+		out[string(runes[i:i+2])]++
+	}
+	return out
+}
+
+// dice is the Dice coefficient of two bigram multisets: 1 for the same
+// text, 0 for nothing in common. Unlike the word overlap of similarAnswers
+// it also catches rephrased and misspelled variants of short answers.
+func dice(a, b map[string]int) float64 {
+	total, common := 0, 0
+	for bg, n := range a {
+		total += n
+		common += min(n, b[bg])
+	}
+	for _, n := range b {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	return 2 * float64(common) / float64(total)
+}
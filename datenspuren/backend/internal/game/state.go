@@ -0,0 +1,156 @@
+package game
+
+import (
+	"sort"
+	"time"
+)
+
+type ScoreEntry struct {
+	PlayerID string `json:"playerId"`
+	Name     string `json:"name"`
+	Points   int    `json:"points"`
+	Rank     int    `json:"rank"` // 1-based, shared on ties
+}
+
+// PublicState is the subset of session state that is safe to expose without
+// authentication, e.g. to stream overlays and info screens.
+type PublicState struct {
+	SessionCode string       `json:"sessionCode"`
+	Phase       Phase        `json:"phase"`
+	RoundIndex  int          `json:"roundIndex"`
+	RoundCount  int          `json:"roundCount"`
+	PlayerCount int          `json:"playerCount"`
+	Scoreboard  []ScoreEntry `json:"scoreboard"`
+	AIScore     int          `json:"aiScore"`
+	MetaScore   MetaScore    `json:"metaScore"`
+	Deadline    *time.Time   `json:"deadline,omitempty"` // when the phase timer runs out
+}
+
+func (s *SessionCtx) PublicState() PublicState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deadline *time.Time
+	if d, ok := s.phaseDeadline(); ok {
+		deadline = &d
+	}
+	return PublicState{
+		SessionCode: s.Code,
+		Phase:       s.Phase,
+		RoundIndex:  s.RoundIx,
+		RoundCount:  s.Config.RoundCount,
+		PlayerCount: len(s.PlayersByID),
+		Scoreboard:  s.playerScores(),
+		AIScore:     s.playerAIScore(),
+		MetaScore:   s.playerMetaScore(),
+		Deadline:    deadline,
+	}
+}
+
+// LobbyInfo is what the join page polls before joining, so people can tell
+// they are about to enter the right, running session.
+type LobbyInfo struct {
+	SessionCode string   `json:"sessionCode"`
+	Phase       Phase    `json:"phase"`
+	Joinable    bool     `json:"joinable"`
+	PlayerCount int      `json:"playerCount"`
+	Players     []string `json:"players"` // names, sorted
+	RoundIndex  int      `json:"roundIndex"`
+	RoundCount  int      `json:"roundCount"`
+	// consent notice: whether answers are recorded and names pseudonymized
+	Recording  bool `json:"recording"`
+	Anonymized bool `json:"anonymized"`
+}
+
+func (s *SessionCtx) Lobby() LobbyInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.PlayersByID))
+	for _, p := range s.PlayersByID {
+		names = append(names, p.Name)
+	}
+	sort.Strings(names)
+	return LobbyInfo{
+		SessionCode: s.Code,
+		Phase:       s.Phase,
+		Joinable:    s.Phase != PhaseEnd,
+		PlayerCount: len(names),
+		Players:     names,
+		RoundIndex:  s.RoundIx,
+		RoundCount:  s.Config.RoundCount,
+	}
+}
+
+// scoreboard lists every player with their points and rank, highest first.
+// Callers must hold s.mu.
+func (s *SessionCtx) scoreboard() []ScoreEntry {
+	out := make([]ScoreEntry, 0, len(s.PlayersByID))
+	for id, p := range s.PlayersByID {
+		out = append(out, ScoreEntry{PlayerID: id, Name: p.Name, Points: s.Scores[id]})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Points != out[j].Points {
+			return out[i].Points > out[j].Points
+		}
+		return out[i].Name < out[j].Name
+	})
+	for i := range out {
+		if i > 0 && out[i].Points == out[i-1].Points {
+			out[i].Rank = out[i-1].Rank
+		} else {
+			out[i].Rank = i + 1
+		}
+	}
+	return out
+}
+
+// Progress returns the phase and the index of the current round, cheaper
+// than PublicState when that is all a caller needs.
+func (s *SessionCtx) Progress() (Phase, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Phase, s.RoundIx
+}
+
+// CurrentRound returns a snapshot of the current round, nil before the
+// first prompt. The copy can be encoded while the game goes on.
+func (s *SessionCtx) CurrentRound() *Round {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.currentRound()
+	if r == nil {
+		return nil
+	}
+	cp := *r
+	cp.Translations = copyStrings(r.Translations)
+	cp.ReadingOrder = append([]string(nil), r.ReadingOrder...)
+	cp.Eliminated = append([]string(nil), r.Eliminated...)
+	cp.Hidden = append([]string(nil), r.Hidden...)
+	cp.Groups = nil
+	cp.Clusters = nil
+	for rep, members := range r.Clusters {
+		if cp.Clusters == nil {
+			cp.Clusters = make(map[string][]string, len(r.Clusters))
+		}
+		cp.Clusters[rep] = append([]string(nil), members...)
+	}
+	for _, g := range r.Groups {
+		cp.Groups = append(cp.Groups, append([]string(nil), g...))
+	}
+	cp.Notes = append([]RoundNote(nil), r.Notes...)
+	if r.PhaseSeconds != nil {
+		cp.PhaseSeconds = make(map[Phase]float64, len(r.PhaseSeconds))
+		for p, secs := range r.PhaseSeconds {
+			cp.PhaseSeconds[p] = secs
+		}
+	}
+	cp.VoteOverrides = nil // cheats stay server-side
+	if r.AIMeta != nil {
+		meta := *r.AIMeta
+		cp.AIMeta = &meta
+	}
+	if r.Attachment != nil {
+		a := *r.Attachment
+		cp.Attachment = &a
+	}
+	return &cp
+}
@@ -0,0 +1,296 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SessionStore persists sessions outside the WAL directory, e.g. in the
+// SQLite database of internal/store. It gets every journaled event together
+// with a snapshot of the session right after it, and hands back the events of
+// unfinished sessions on startup so they can be replayed. Events arrive in
+// batches from a single goroutine, never while a session is locked.
+type SessionStore interface {
+	Save(records []StoreRecord) error
+	Unfinished() (map[string][][]byte, error) // session code -> events in order
+}
+
+// StoreRecord is one event of a session and the session right after it.
+// Once the snapshot shows the session ended, its events aren't needed for
+// recovery anymore and a store may drop them.
+type StoreRecord struct {
+	Code     string
+	Event    []byte // JSON, as the WAL journals it
+	Snapshot Snapshot
+}
+
+// Snapshot is a session's state after its latest event. Restoring replays
+// the events; the snapshot lets operators look at running games and is how
+// a store tells finished sessions apart.
+type Snapshot struct {
+	Code        string       `json:"code"`
+	JoinPin     string       `json:"joinPin"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+	Phase       Phase        `json:"phase"`
+	RoundIndex  int          `json:"roundIndex"`
+	RoundCount  int          `json:"roundCount"`
+	Players     []string     `json:"players"` // names, in join order
+	Prompt      string       `json:"prompt,omitempty"`
+	Submissions []Submission `json:"submissions"` // in the current round
+	Votes       []Vote       `json:"votes"`       // in the current round
+	Scoreboard  []ScoreEntry `json:"scoreboard"`
+	AIScore     int          `json:"aiScore"`
+}
+
+// snapshot captures the session for its store. It shares nothing with the
+// session, so it can be marshaled after s.mu is released. Callers must hold
+// s.mu.
+func (s *SessionCtx) snapshot(at time.Time) Snapshot {
+	players := make([]*Player, 0, len(s.PlayersByID))
+	for _, p := range s.PlayersByID {
+		players = append(players, p)
+	}
+	slices.SortFunc(players, func(a, b *Player) int { return a.JoinedAt.Compare(b.JoinedAt) })
+	names := make([]string, len(players))
+	for i, p := range players {
+		names[i] = p.Name
+	}
+	subs := make([]Submission, 0, len(s.submissions))
+	for _, sub := range s.submissions {
+		c := *sub
+		c.Translations = maps.Clone(sub.Translations)
+		subs = append(subs, c)
+	}
+	slices.SortFunc(subs, func(a, b Submission) int { return strings.Compare(a.ID, b.ID) })
+	votes := make([]Vote, 0, len(s.votesByVoter))
+	for _, v := range s.votesByVoter {
+		votes = append(votes, Vote{ID: v.ID, VoterID: v.VoterID, VoterName: s.playerName(v.VoterID), TargetSubmissionID: v.TargetSubmissionID})
+	}
+	slices.SortFunc(votes, func(a, b Vote) int { return strings.Compare(a.VoterID, b.VoterID) })
+	snap := Snapshot{
+		Code:        s.Code,
+		JoinPin:     s.JoinPin,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   at,
+		Phase:       s.Phase,
+		RoundIndex:  s.RoundIx,
+		RoundCount:  s.Config.RoundCount,
+		Players:     names,
+		Submissions: subs,
+		Votes:       votes,
+		Scoreboard:  s.playerScores(),
+		AIScore:     s.playerAIScore(),
+	}
+	if r := s.currentRound(); r != nil {
+		snap.Prompt = r.Prompt
+	}
+	return snap
+}
+
+// persist queues an event and the resulting snapshot for the session's
+// store. Callers must hold s.mu.
+func (s *SessionCtx) persist(ev walEvent) {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		s.store.fail(s.Code, err)
+		return
+	}
+	s.store.push(StoreRecord{Code: s.Code, Event: b, Snapshot: s.snapshot(ev.At)})
+}
+
+// storeQueue hands events to a SessionStore from one goroutine, so sessions
+// don't wait for the disk while holding their lock. Whatever piles up while
+// a batch is being saved goes out with the next one, in order.
+type storeQueue struct {
+	st      SessionStore
+	onError func(code string, err error)
+	wake    chan struct{}
+
+	mu      sync.Mutex
+	idle    *sync.Cond // broadcast after every batch
+	pending []StoreRecord
+	saving  bool
+}
+
+func newStoreQueue(st SessionStore, onError func(code string, err error)) *storeQueue {
+	q := &storeQueue{st: st, onError: onError, wake: make(chan struct{}, 1)}
+	q.idle = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+func (q *storeQueue) push(r StoreRecord) {
+	q.mu.Lock()
+	q.pending = append(q.pending, r)
+	q.mu.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *storeQueue) run() {
+	for range q.wake {
+		q.mu.Lock()
+		batch := q.pending
+		q.pending, q.saving = nil, true
+		q.mu.Unlock()
+		if len(batch) > 0 {
+			if err := q.st.Save(batch); err != nil {
+				failed := map[string]bool{}
+				for _, r := range batch {
+					if !failed[r.Code] {
+						failed[r.Code] = true
+						q.fail(r.Code, err)
+					}
+				}
+			}
+		}
+		q.mu.Lock()
+		q.saving = false
+		q.idle.Broadcast()
+		q.mu.Unlock()
+	}
+}
+
+func (q *storeQueue) fail(code string, err error) {
+	if q.onError != nil {
+		q.onError(code, err)
+	}
+}
+
+// flush waits until everything queued so far is saved.
+func (q *storeQueue) flush() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.pending) > 0 || q.saving {
+		q.idle.Wait()
+	}
+}
+
+// EnableStore makes every new session persist its events to st, in place of
+// the WAL. onError is called when an event cannot be saved.
+func (rm *RoomManager) EnableStore(st SessionStore, onError func(code string, err error)) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.store = newStoreQueue(st, onError)
+	rm.walDir = ""
+}
+
+// FlushStore waits until every event so far has reached the store, e.g.
+// before closing it on shutdown.
+func (rm *RoomManager) FlushStore() {
+	rm.mu.RLock()
+	q := rm.store
+	rm.mu.RUnlock()
+	if q != nil {
+		q.flush()
+	}
+}
+
+// Checkpoint marks every running session for a shutdown. Each gets a final
+// checkpoint event, which syncs its WAL and leaves its store with an
+// up-to-date snapshot; replaying skips it.
+func (rm *RoomManager) Checkpoint() {
+	now := time.Now().UTC()
+	for _, s := range rm.Running() {
+		s.mu.Lock()
+		s.logEvent(walEvent{Type: walCheckpoint, At: now})
+		s.mu.Unlock()
+	}
+}
+
+// RecoverStore rebuilds the unfinished sessions kept in the store and
+// returns their codes, like RecoverWAL does for the WAL directory.
+func (rm *RoomManager) RecoverStore() ([]string, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if rm.store == nil {
+		return nil, nil
+	}
+	sessions, err := rm.store.st.Unfinished()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		recovered []string
+		errs      []error
+		latest    *SessionCtx
+	)
+	for code, events := range sessions {
+		s, err := replay(slices.Values(events), rm.limits)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", code, err))
+			continue
+		}
+		if s.Phase == PhaseEnd || rm.sessions[s.Code] != nil {
+			continue
+		}
+		s.wordFilter = rm.wordFilter
+		s.store = rm.store
+		rm.sessions[s.Code] = s
+		rm.pins[s.JoinPin] = s.Code
+		recovered = append(recovered, s.Code)
+		if !s.Config.Hostless && (latest == nil || s.CreatedAt.After(latest.CreatedAt)) {
+			latest = s
+		}
+	}
+	if latest != nil {
+		rm.active = latest.Code
+	}
+	slices.Sort(recovered)
+	return recovered, errors.Join(errs...)
+}
+
+// replay rebuilds a session from its events. A record that doesn't decode,
+// like the torn last line of a crashed WAL write, ends the replay.
+func replay(events iter.Seq[[]byte], limits Limits) (*SessionCtx, error) {
+	s := &SessionCtx{}
+	if err := s.rehydrate(events, limits); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Rehydrate rebuilds s from a session's events as its WAL or store recorded
+// them, one JSON object each, starting with the session's creation: players
+// joining, prompts, answers, votes, phase changes and so on, each with the
+// time it happened. Whatever s held before is replaced, so s must not be in
+// use yet. The rebuilt session journals nothing and belongs to no
+// RoomManager, e.g. to audit or replay a game.
+func (s *SessionCtx) Rehydrate(events [][]byte) error {
+	return s.rehydrate(slices.Values(events), Limits{})
+}
+
+func (s *SessionCtx) rehydrate(events iter.Seq[[]byte], limits Limits) error {
+	created := false
+	for b := range events {
+		var ev walEvent
+		if err := json.Unmarshal(b, &ev); err != nil {
+			break
+		}
+		if !created {
+			if ev.Type != walCreate || ev.Config == nil {
+				return errors.New("log does not start with a create event")
+			}
+			s.reset(ev.Code, ev.JoinPin, ev.HostToken, ev.OverlayToken, *ev.Config, ev.At)
+			s.Seed = ev.Seed
+			s.limits = limits
+			created = true
+			continue
+		}
+		s.apply(ev)
+	}
+	if !created {
+		return errors.New("empty log")
+	}
+	return nil
+}
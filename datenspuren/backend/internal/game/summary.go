@@ -0,0 +1,231 @@
+package game
+
+import "sort"
+
+// SubmissionResult is a submission together with its author and the votes it
+// received, as archived when a round is scored.
+type SubmissionResult struct {
+	ID         string   `json:"id"`
+	Text       string   `json:"text"`
+	AuthorID   string   `json:"authorId"`
+	AuthorName string   `json:"authorName"`
+	IsAI       bool     `json:"isAI"`
+	Votes      int      `json:"votes"`
+	Voters     []string `json:"voters"`               // names of the players who voted for it
+	VoterIDs   []string `json:"-"`                    // parallel to Voters
+	MergedInto string   `json:"mergedInto,omitempty"` // voting option this near-duplicate was merged into
+	Hidden     bool     `json:"hidden,omitempty"`     // taken out of the vote by the host
+	// seconds from the start of answering until it was first submitted
+	AnswerSeconds float64 `json:"answerSeconds,omitempty"`
+}
+
+type RoundSummary struct {
+	Index          int                `json:"index"`
+	Prompt         string             `json:"prompt"`
+	Type           RoundType          `json:"type,omitempty"`
+	Attachment     *Attachment        `json:"attachment,omitempty"`
+	AISubmissionID string             `json:"aiSubmissionId"`
+	Submissions    []SubmissionResult `json:"submissions"`
+	TotalVotes     int                `json:"totalVotes"`
+	AIVotes        int                `json:"aiVotes"`
+	Scores         []ScoreEntry       `json:"scores"`  // standings after this round
+	AIScore        int                `json:"aiScore"` // the AI's points after this round
+	Notes          []RoundNote        `json:"notes,omitempty"`
+	Audience       *AudienceTally     `json:"audience,omitempty"` // audience votes, not included in the counts above
+	Awards         []RoundAward       `json:"awards,omitempty"`
+	Compacted      bool               `json:"compacted,omitempty"` // answers and standings were dropped to save memory
+	// RevealedAI is the submission a host cheat revealed as the AI's on
+	// stage instead of AISubmissionID, see Revealed.
+	RevealedAI string `json:"revealedAi,omitempty"`
+}
+
+// BestAnswer is the human answer with the most votes over the whole game.
+// Ties go to the earlier round, then to the faster answer.
+type BestAnswer struct {
+	RoundIndex int              `json:"roundIndex"`
+	Prompt     string           `json:"prompt"`
+	Submission SubmissionResult `json:"submission"`
+}
+
+// GameSummary is the full narrative of a finished game for the end screen.
+type GameSummary struct {
+	Rounds          []RoundSummary `json:"rounds"`
+	BestAnswer      *BestAnswer    `json:"bestAnswer"`
+	TotalVotes      int            `json:"totalVotes"`
+	AIVotes         int            `json:"aiVotes"`
+	AIDetectionRate float64        `json:"aiDetectionRate"` // share of all votes that found the AI
+	Scores          map[string]int `json:"scores"`
+	AIScore         int            `json:"aiScore"`
+	MetaScore       MetaScore      `json:"metaScore"`        // who won the night
+	Awards          []Award        `json:"awards,omitempty"` // once the game ended
+}
+
+// archiveRound snapshots the current round's submissions and votes so they
+// survive the per-round reset. Callers must hold s.mu.
+func (s *SessionCtx) archiveRound() {
+	if r := s.currentRound(); r != nil {
+		s.history = append(s.history, s.summarizeRound(r))
+		s.compactHistory()
+	}
+}
+
+// UnfinishedRound summarizes the answers and votes of a round that hasn't
+// been scored yet, e.g. for the export of a session cut short by a server
+// shutdown. It reports false outside of Answering and Voting.
+func (s *SessionCtx) UnfinishedRound() (RoundSummary, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.unfinishedRound()
+}
+
+func (s *SessionCtx) unfinishedRound() (RoundSummary, bool) {
+	r := s.currentRound()
+	if r == nil || s.Phase != PhaseAnswering && s.Phase != PhaseVoting {
+		return RoundSummary{}, false
+	}
+	return s.summarizeRound(r), true
+}
+
+// summarizeRound builds the summary of the current round r from its live
+// submissions and votes. Callers must hold s.mu.
+func (s *SessionCtx) summarizeRound(r *Round) RoundSummary {
+	votesFor := s.voteCounts()
+	votersFor := map[string][]*Vote{}
+	for _, v := range s.votesByVoter {
+		votersFor[v.TargetSubmissionID] = append(votersFor[v.TargetSubmissionID], v)
+	}
+	totalVotes := 0
+	for _, n := range votesFor {
+		totalVotes += n
+	}
+	aiID := r.AISubmissionID
+	rs := RoundSummary{
+		Index:          r.Index,
+		Prompt:         r.Prompt,
+		Type:           r.Type,
+		Attachment:     r.Attachment,
+		AISubmissionID: aiID,
+		TotalVotes:     totalVotes,
+		AIVotes:        votesFor[aiID],
+		Scores:         s.scoreboard(),
+		AIScore:        s.aiScore,
+		Notes:          append([]RoundNote(nil), r.Notes...),
+		Audience:       s.audienceTally(),
+		Awards:         append([]RoundAward(nil), r.Awards...),
+	}
+	if fake := r.revealedAI(); fake != aiID {
+		rs.RevealedAI = fake
+	}
+	for _, sub := range s.submissions {
+		votes := votersFor[sub.ID]
+		sort.Slice(votes, func(i, j int) bool { return s.playerName(votes[i].VoterID) < s.playerName(votes[j].VoterID) })
+		res := SubmissionResult{ID: sub.ID, Text: sub.Text, AuthorID: sub.PlayerID, Votes: votesFor[sub.ID], AnswerSeconds: sub.AnswerTime.Seconds()}
+		for _, v := range votes {
+			res.Voters = append(res.Voters, s.playerName(v.VoterID))
+			res.VoterIDs = append(res.VoterIDs, v.VoterID)
+		}
+		if sub.ID == aiID {
+			res.AuthorID, res.AuthorName = "AI", "AI"
+			res.IsAI = true
+		} else if p := s.PlayersByID[sub.PlayerID]; p != nil {
+			res.AuthorName = p.Name
+		}
+		if rep := r.representative(sub.ID); rep != sub.ID {
+			res.MergedInto = rep
+		}
+		res.Hidden = r.hidden(sub.ID)
+		rs.Submissions = append(rs.Submissions, res)
+	}
+	// stable order for exports: most votes first
+	sort.SliceStable(rs.Submissions, func(i, j int) bool {
+		if rs.Submissions[i].Votes != rs.Submissions[j].Votes {
+			return rs.Submissions[i].Votes > rs.Submissions[j].Votes
+		}
+		if rs.Submissions[i].AuthorName != rs.Submissions[j].AuthorName {
+			return rs.Submissions[i].AuthorName < rs.Submissions[j].AuthorName
+		}
+		return rs.Submissions[i].ID < rs.Submissions[j].ID
+	})
+	return rs
+}
+
+// beats reports whether a should replace b as the best answer of a round:
+// more votes win, then the earlier submission, then the lower ID so the
+// result never depends on map order.
+func beats(a, b SubmissionResult) bool {
+	if a.Votes != b.Votes {
+		return a.Votes > b.Votes
+	}
+	if a.AnswerSeconds != b.AnswerSeconds {
+		return a.AnswerSeconds < b.AnswerSeconds
+	}
+	return a.ID < b.ID
+}
+
+// Revealed returns rs the way it was revealed on stage: when a host cheat
+// marked another submission as the AI's, it and the real AI answer swap
+// authors. Only reveal screens use this; exports and stats keep the truth.
+func (rs RoundSummary) Revealed() RoundSummary {
+	if rs.RevealedAI == "" {
+		return rs
+	}
+	subs := append([]SubmissionResult(nil), rs.Submissions...)
+	var faked SubmissionResult
+	for _, sub := range subs {
+		if sub.ID == rs.RevealedAI {
+			faked = sub
+		}
+	}
+	for i, sub := range subs {
+		switch sub.ID {
+		case rs.RevealedAI:
+			subs[i].AuthorID, subs[i].AuthorName, subs[i].IsAI = "AI", "AI", true
+		case rs.AISubmissionID:
+			subs[i].AuthorID, subs[i].AuthorName, subs[i].IsAI = faked.AuthorID, faked.AuthorName, false
+		}
+	}
+	rs.Submissions = subs
+	rs.AISubmissionID, rs.AIVotes, rs.RevealedAI = rs.RevealedAI, faked.Votes, ""
+	return rs
+}
+
+// Summary builds the game narrative from all archived rounds.
+func (s *SessionCtx) Summary() GameSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := GameSummary{Rounds: append([]RoundSummary{}, s.history...), Scores: make(map[string]int, len(s.Scores)), AIScore: s.aiScore}
+	for id, pts := range s.Scores {
+		out.Scores[id] = pts
+	}
+	for _, rs := range s.history {
+		out.TotalVotes += rs.TotalVotes
+		out.AIVotes += rs.AIVotes
+		for _, sub := range rs.Submissions {
+			if sub.IsAI || sub.Votes == 0 {
+				continue
+			}
+			if best := out.BestAnswer; best == nil || sub.Votes > best.Submission.Votes ||
+				best.RoundIndex == rs.Index && beats(sub, best.Submission) {
+				out.BestAnswer = &BestAnswer{RoundIndex: rs.Index, Prompt: rs.Prompt, Submission: sub}
+			}
+		}
+	}
+	out.MetaScore = metaScore(s.history)
+	if s.Phase == PhaseEnd {
+		out.Awards = s.computeAwards()
+	}
+	if out.TotalVotes > 0 {
+		out.AIDetectionRate = float64(out.AIVotes) / float64(out.TotalVotes)
+	}
+	return out
+}
+
+// LastRound returns the archived results of the most recently scored round.
+func (s *SessionCtx) LastRound() (RoundSummary, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.history) == 0 {
+		return RoundSummary{}, false
+	}
+	return s.history[len(s.history)-1], true
+}
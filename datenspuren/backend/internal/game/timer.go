@@ -0,0 +1,169 @@
+package game
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoTimer          = errors.New("phase has no timer")
+	ErrInvalidExtension = errors.New("timer extension must be positive and at most 5 minutes")
+)
+
+// maxTimerExtension caps the time the host can add to a phase in one go.
+const maxTimerExtension = 5 * time.Minute
+
+// TimerTick is how often a running phase timer reports the time left.
+const TimerTick = time.Second
+
+// PhaseDeadline returns when the current phase's timer runs out, if the
+// session configured a time for it (AnswerTime for Answering, VoteTime for
+// Voting). Clients count down to this instead of their own clocks. Voting
+// runs StreamDelay longer so viewers of the delayed stream can still vote.
+func (s *SessionCtx) PhaseDeadline() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.phaseDeadline()
+}
+
+func (s *SessionCtx) phaseDeadline() (time.Time, bool) {
+	secs := 0
+	switch s.Phase {
+	case PhaseAnswering:
+		secs = s.Config.AnswerTime
+	case PhaseVoting:
+		secs = s.Config.VoteTime
+		if secs > 0 {
+			secs += max(s.Config.StreamDelay, 0)
+		}
+	}
+	if secs <= 0 {
+		return time.Time{}, false
+	}
+	return s.phaseStartedAt.Add(time.Duration(secs)*time.Second + s.phaseExtra), true
+}
+
+// ExtendTimer gives everyone more time in the current phase, e.g. while the
+// host deals with a crashed phone. It returns the new deadline.
+func (s *SessionCtx) ExtendTimer(hostToken string, d time.Duration) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hostToken != s.HostToken {
+		return time.Time{}, ErrNotHost
+	}
+	if d <= 0 || d > maxTimerExtension {
+		return time.Time{}, ErrInvalidExtension
+	}
+	if _, ok := s.phaseDeadline(); !ok {
+		return time.Time{}, ErrNoTimer
+	}
+	s.phaseExtra += d
+	s.logEvent(walEvent{Type: walExtendTimer, Seconds: int(d / time.Second)})
+	deadline, _ := s.phaseDeadline()
+	return deadline, nil
+}
+
+// PhaseTimers runs the countdown of each session's current phase. While a
+// phase with a timer runs, OnTick gets the time left every TimerTick; once
+// the deadline passes, OnExpire is called so the phase can be ended even if
+// some players disconnected. A countdown whose phase moved on or whose
+// deadline changed in the meantime expires silently.
+type PhaseTimers struct {
+	OnTick   func(s *SessionCtx, phase Phase, remaining int, deadline time.Time)
+	OnExpire func(s *SessionCtx, phase Phase)
+
+	tick    time.Duration
+	mu      sync.Mutex
+	running map[string]chan struct{} // session code -> stops its countdown
+}
+
+func NewPhaseTimers(onTick func(s *SessionCtx, phase Phase, remaining int, deadline time.Time), onExpire func(s *SessionCtx, phase Phase)) *PhaseTimers {
+	return &PhaseTimers{OnTick: onTick, OnExpire: onExpire, tick: TimerTick, running: make(map[string]chan struct{})}
+}
+
+// Sync replaces the session's countdown with one for its current phase and
+// deadline, or just stops it if the phase has no timer. Call it after every
+// transition and whenever the deadline changes.
+func (t *PhaseTimers) Sync(s *SessionCtx) {
+	s.mu.Lock()
+	phase := s.Phase
+	deadline, ok := s.phaseDeadline()
+	s.mu.Unlock()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if stop := t.running[s.Code]; stop != nil {
+		close(stop)
+		delete(t.running, s.Code)
+	}
+	if !ok {
+		return
+	}
+	stop := make(chan struct{})
+	t.running[s.Code] = stop
+	go t.run(s, phase, deadline, stop)
+}
+
+// Stop ends the session's countdown without expiring it.
+func (t *PhaseTimers) Stop(code string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if stop := t.running[code]; stop != nil {
+		close(stop)
+		delete(t.running, code)
+	}
+}
+
+// Running reports whether the session has a countdown going.
+func (t *PhaseTimers) Running(code string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.running[code] != nil
+}
+
+func (t *PhaseTimers) run(s *SessionCtx, phase Phase, deadline time.Time, stop chan struct{}) {
+	ticker := time.NewTicker(t.tick)
+	defer ticker.Stop()
+	expire := time.NewTimer(time.Until(deadline))
+	defer expire.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if t.OnTick != nil {
+				t.OnTick(s, phase, remainingSeconds(deadline), deadline)
+			}
+		case <-expire.C:
+			t.mu.Lock()
+			current := t.running[s.Code] == stop
+			if current {
+				delete(t.running, s.Code)
+			}
+			t.mu.Unlock()
+			if !current || s.GetPhase() != phase {
+				return
+			}
+			if d, ok := s.PhaseDeadline(); !ok || !d.Equal(deadline) {
+				return
+			}
+			if t.OnTick != nil {
+				t.OnTick(s, phase, 0, deadline)
+			}
+			if t.OnExpire != nil {
+				t.OnExpire(s, phase)
+			}
+			return
+		}
+	}
+}
+
+// remainingSeconds rounds up, so the count only reaches 0 when time is up.
+func remainingSeconds(deadline time.Time) int {
+	left := time.Until(deadline)
+	if left <= 0 {
+		return 0
+	}
+	return int((left + time.Second - 1) / time.Second)
+}
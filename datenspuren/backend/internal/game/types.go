@@ -16,12 +16,100 @@ const (
 	PhaseEnd        Phase = "End"
 )
 
+// ProviderManual sessions make no AI call; the host enters the AI answer.
+const ProviderManual = "manual"
+
 type SessionConfig struct {
 	Provider   string `json:"provider"`
 	Model      string `json:"model"`
 	RoundCount int    `json:"roundCount"`
 	AnswerTime int    `json:"answerTime"` // seconds
 	VoteTime   int    `json:"voteTime"`   // seconds
+	// BlindModels enables blind tests: each round picks one of these at
+	// random instead of Provider/Model and the choice is never sent to clients.
+	BlindModels []ModelChoice `json:"blindModels,omitempty"`
+	// Public lists the session in the public session browser.
+	Public bool `json:"public"`
+	// StreamDelay holds back overlay/stream events by this many seconds so
+	// remote viewers of a delayed stream see them in sync with the picture.
+	// Timed voting stays open this much longer so they can still vote.
+	StreamDelay int `json:"streamDelay"`
+	// Language is the primary language (ISO 639-1) the AI answers in.
+	Language string `json:"language,omitempty"`
+	// SecondaryLanguage enables automatic translation of prompts and answers
+	// into this language for display, if a translator is configured.
+	SecondaryLanguage string `json:"secondaryLanguage,omitempty"`
+	// AITrigger decides when the AI answer is generated, see AITrigger*.
+	AITrigger string `json:"aiTrigger,omitempty"`
+	// Export overrides the server-wide EXPORT_ENABLED for this session, e.g.
+	// to keep a privacy-sensitive group's answers off disk: sessions that
+	// aren't exported aren't journaled either, so they don't survive a
+	// restart. Nil keeps the server default.
+	Export *bool `json:"export,omitempty"`
+	// Anonymize pseudonymizes player names in this session's exports, on
+	// top of the server-wide EXPORT_ANONYMIZE.
+	Anonymize bool `json:"anonymize,omitempty"`
+	// Rehearsal marks a tech run-through: nothing is exported or journaled,
+	// the host may skip phases freely and bots fill in missing answers.
+	Rehearsal bool `json:"rehearsal,omitempty"`
+	// HideVoteCounts keeps the vote breakdown from players at reveal, for
+	// casual groups: they only learn whether they found the AI. Hosts,
+	// spectators and overlays still see everything.
+	HideVoteCounts bool `json:"hideVoteCounts,omitempty"`
+	// GroupSize splits voting in large rooms into parallel groups of about
+	// this many answers, so nobody scrolls through 80 of them. Every group
+	// also votes on the AI answer; scores are merged. 0 disables groups.
+	GroupSize int `json:"groupSize,omitempty"`
+	// ClusterAnswers merges near-duplicate answers into one voting option
+	// whose authors split its points, see clusterAnswers.
+	ClusterAnswers bool `json:"clusterAnswers,omitempty"`
+	// TimeZone is the IANA time zone of the event, e.g. "Europe/Berlin",
+	// used for timestamps in exports. Empty uses the server's default.
+	TimeZone string `json:"timeZone,omitempty"`
+	// Hostless runs the session without a GM screen: any player may create
+	// it, players suggest prompts or they're drawn from a pool, and phases
+	// advance on their own, see applyHostlessDefaults.
+	Hostless bool `json:"hostless,omitempty"`
+	// AudienceWeight lets spectators vote too: the answer most of them pick
+	// gets this many extra votes. 0 disables audience voting.
+	AudienceWeight int `json:"audienceWeight,omitempty"`
+	// AllowSelfVote lets players vote for their own answer, which is
+	// otherwise refused so nobody farms points.
+	AllowSelfVote bool `json:"allowSelfVote,omitempty"`
+	// FooledBonus is awarded on top of the vote points to every player whose
+	// answer got more votes than the AI's. 0 disables the award.
+	FooledBonus int `json:"fooledBonus,omitempty"`
+	// Scoring sets the points per vote and for finding the AI. Nil uses
+	// the server's, see SetScoring.
+	Scoring *Scoring `json:"scoring,omitempty"`
+	// MaxAnswerLength caps answers at this many characters, so walls of text
+	// don't break the voting screen or give the AI away. 0 = no cap besides
+	// the 500 characters of any socket payload.
+	MaxAnswerLength int `json:"maxAnswerLength,omitempty"`
+}
+
+// Recording reports whether the session's results are exported, given the
+// server-wide default.
+func (c SessionConfig) Recording(serverDefault bool) bool {
+	if c.Rehearsal {
+		return false
+	}
+	if c.Export != nil {
+		return *c.Export
+	}
+	return serverDefault
+}
+
+// When the AI answer for a round gets generated
+const (
+	AITriggerQueue     = "queue"     // as soon as the prompt is queued
+	AITriggerSetPrompt = "setPrompt" // when the round starts (default)
+	AITriggerVoting    = "voting"    // lazily, right before voting opens
+)
+
+type ModelChoice struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
 }
 
 type Player struct {
@@ -29,24 +117,63 @@ type Player struct {
 	Name     string    `json:"name"`
 	IsHost   bool      `json:"isHost"`
 	JoinedAt time.Time `json:"joinedAt"`
+	Profile  string    `json:"profile,omitempty"` // claimed profile name, if any
+	IsBot    bool      `json:"isBot,omitempty"`   // filled in by a rehearsal
 }
 
 type Round struct {
-	ID             string `json:"id"`
-	Index          int    `json:"index"`
-	Prompt         string `json:"prompt"`
-	AISubmissionID string `json:"aiSubmissionId"`
-	Status         Phase  `json:"status"`
+	ID             string              `json:"id"`
+	Index          int                 `json:"index"`
+	Prompt         string              `json:"prompt"`
+	Translations   map[string]string   `json:"translations,omitempty"` // language -> prompt, for bilingual audiences
+	Type           RoundType           `json:"type,omitempty"`         // empty for text rounds
+	Attachment     *Attachment         `json:"attachment,omitempty"`   // image or link shown with the prompt
+	AISubmissionID string              `json:"aiSubmissionId"`
+	Status         Phase               `json:"status"`
+	PhaseSeconds   map[Phase]float64   `json:"phaseSeconds,omitempty"` // time spent per phase
+	ReadingOrder   []string            `json:"readingOrder,omitempty"` // submission IDs in the order they are read aloud
+	Eliminated     []string            `json:"eliminated,omitempty"`   // submission IDs removed from the vote by hints
+	Hidden         []string            `json:"hidden,omitempty"`       // submission IDs the host took out of the vote
+	Groups         [][]string          `json:"groups,omitempty"`       // submission IDs per parallel voting group, besides the AI's
+	Clusters       map[string][]string `json:"clusters,omitempty"`     // voting option -> near-duplicate submission IDs merged into it
+	Model          ModelChoice         `json:"-"`                      // provider/model answering this round
+	AIMeta         *AIMetadata         `json:"-"`                      // set once the AI answer was generated
+	Notes          []RoundNote         `json:"-"`                      // host's notes for the post-show writeup
+	FakeAI         string              `json:"-"`                      // submission the host cheated into being revealed as the AI's
+	VoteOverrides  map[string]int      `json:"-"`                      // submission ID -> vote count set by a host cheat
+	Awards         []RoundAward        `json:"-"`                      // bonuses earned when the round was scored
+	AIOriginal     string              `json:"-"`                      // generated AI answer the host edited, see EditAIAnswer
+}
+
+// RoundNote is a free-text remark the host attached to a round, e.g. "mic
+// died here" or "crowd favorite".
+type RoundNote struct {
+	Text string    `json:"text"`
+	At   time.Time `json:"at"`
+}
+
+// AIMetadata records how the AI answer of a round was generated, for
+// post-show analysis.
+type AIMetadata struct {
+	Provider         string `json:"provider"`
+	Model            string `json:"model"`
+	LatencyMs        int64  `json:"latencyMs"`
+	PromptTokens     int    `json:"promptTokens"`
+	CompletionTokens int    `json:"completionTokens"`
+	FinishReason     string `json:"finishReason"`
 }
 
 type Submission struct {
-	ID       string `json:"id"`
-	PlayerID string `json:"playerId"`
-	Text     string `json:"text"`
+	ID           string            `json:"id"`
+	PlayerID     string            `json:"playerId"`
+	Text         string            `json:"text"`
+	Translations map[string]string `json:"translations,omitempty"` // language -> text
+	AnswerTime   time.Duration     `json:"-"`                      // from the start of answering to the first version
 }
 
 type Vote struct {
 	ID                 string `json:"id"`
 	VoterID            string `json:"voterId"`
+	VoterName          string `json:"voterName,omitempty"` // resolved when listing votes
 	TargetSubmissionID string `json:"targetSubmissionId"`
 }
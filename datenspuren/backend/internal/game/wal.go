@@ -0,0 +1,333 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WAL event types. Every state change of a session is appended to its
+// write-ahead log together with the IDs it generated, so replaying the log
+// rebuilds the exact same session.
+const (
+	walCreate                = "create"
+	walJoin                  = "join"
+	walProfile               = "profile"
+	walRound                 = "round"
+	walSubmit                = "submit"
+	walVote                  = "vote"
+	walAdvance               = "advance"
+	walResetRound            = "resetRound"
+	walAIMeta                = "aiMeta"
+	walRoundTranslation      = "roundTranslation"
+	walSubmissionTranslation = "submissionTranslation"
+	walQueuePrompt           = "queuePrompt"
+	walQueuedAnswer          = "queuedAnswer"
+	walScoreFreeze           = "scoreFreeze"
+	walRevealScores          = "revealScores"
+	walReadingOrder          = "readingOrder"
+	walRoundNote             = "roundNote"
+	walHint                  = "hint"
+	walHide                  = "hide"
+	walCheats                = "cheats"
+	walCheat                 = "cheat"
+	walAttachment            = "attachment"
+	walExtendTimer           = "extendTimer"
+	walExpire                = "expire"
+	walAudienceVote          = "audienceVote"
+	walPromptCollection      = "promptCollection"
+	walPromptCandidate       = "promptCandidate"
+	walPromptVote            = "promptVote"
+	walAIEdit                = "aiEdit"
+	walCheckpoint            = "checkpoint" // shutdown marker, nothing to replay
+)
+
+type walEvent struct {
+	Type string    `json:"type"`
+	At   time.Time `json:"at"`
+
+	// create
+	Code         string         `json:"code,omitempty"`
+	JoinPin      string         `json:"joinPin,omitempty"`
+	HostToken    string         `json:"hostToken,omitempty"`
+	OverlayToken string         `json:"overlayToken,omitempty"`
+	Seed         int64          `json:"seed,omitempty"`
+	Config       *SessionConfig `json:"config,omitempty"`
+
+	PlayerID     string            `json:"playerId,omitempty"`
+	Token        string            `json:"token,omitempty"`
+	Name         string            `json:"name,omitempty"`
+	Profile      string            `json:"profile,omitempty"`
+	RoundID      string            `json:"roundId,omitempty"`
+	Prompt       string            `json:"prompt,omitempty"`
+	Translations map[string]string `json:"translations,omitempty"`
+	Model        *ModelChoice      `json:"model,omitempty"`
+	QueuedID     string            `json:"queuedId,omitempty"`
+	SubmissionID string            `json:"submissionId,omitempty"`
+	VoteID       string            `json:"voteId,omitempty"`
+	Text         string            `json:"text,omitempty"`
+	Original     string            `json:"original,omitempty"`
+	Lang         string            `json:"lang,omitempty"`
+	Category     string            `json:"category,omitempty"`
+	Meta         *AIMetadata       `json:"meta,omitempty"`
+	Frozen       bool              `json:"frozen,omitempty"`
+	Order        []string          `json:"order,omitempty"`
+	Enabled      bool              `json:"enabled,omitempty"`
+	Cheat        *CheatEntry       `json:"cheat,omitempty"`
+	Attachment   *Attachment       `json:"attachment,omitempty"`
+	Seconds      int               `json:"seconds,omitempty"`
+	RoundType    RoundType         `json:"roundType,omitempty"`
+}
+
+// journal appends events to a session's WAL file, syncing after every
+// event so a crash loses at most the event being written.
+type journal struct {
+	f       *os.File
+	onError func(error)
+}
+
+func (j *journal) close() {
+	if err := j.f.Close(); err != nil && j.onError != nil {
+		j.onError(err)
+	}
+}
+
+func (j *journal) append(ev walEvent) {
+	b, err := json.Marshal(ev)
+	if err == nil {
+		_, err = j.f.Write(append(b, '\n'))
+	}
+	if err == nil {
+		err = j.f.Sync()
+	}
+	if err != nil && j.onError != nil {
+		j.onError(err)
+	}
+}
+
+// EnableWAL makes every new session journal its events to <dir>/<CODE>.wal.
+// onError is called when an event cannot be written. A session store
+// replaces the WAL, so it can't be enabled along with one.
+func (rm *RoomManager) EnableWAL(dir string, onError func(code string, err error)) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if rm.store != nil {
+		return errors.New("sessions are kept in a store, not the WAL")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create WAL directory: %w", err)
+	}
+	rm.walDir = dir
+	rm.walOnError = onError
+	return nil
+}
+
+func (rm *RoomManager) openJournal(code string) (*journal, error) {
+	f, err := os.OpenFile(filepath.Join(rm.walDir, code+".wal"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open WAL: %w", err)
+	}
+	j := &journal{f: f}
+	if rm.walOnError != nil {
+		j.onError = func(err error) { rm.walOnError(code, err) }
+	}
+	return j, nil
+}
+
+// RecoverWAL rebuilds the sessions found in the WAL directory that had not
+// ended yet and returns their codes. The most recently created one becomes
+// the active session. Unreadable logs are skipped and reported in the error.
+func (rm *RoomManager) RecoverWAL() ([]string, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if rm.walDir == "" {
+		return nil, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(rm.walDir, "*.wal"))
+	if err != nil {
+		return nil, err
+	}
+	var (
+		recovered []string
+		errs      []error
+		latest    *SessionCtx
+	)
+	for _, path := range paths {
+		s, err := replayWAL(path, rm.limits)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(path), err))
+			continue
+		}
+		if s.Phase == PhaseEnd || rm.sessions[s.Code] != nil {
+			continue
+		}
+		if s.journal, err = rm.openJournal(s.Code); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		s.wordFilter = rm.wordFilter
+		rm.sessions[s.Code] = s
+		rm.pins[s.JoinPin] = s.Code
+		recovered = append(recovered, s.Code)
+		if !s.Config.Hostless && (latest == nil || s.CreatedAt.After(latest.CreatedAt)) {
+			latest = s
+		}
+	}
+	if latest != nil {
+		rm.active = latest.Code
+	}
+	return recovered, errors.Join(errs...)
+}
+
+// LoadWAL rebuilds the session journaled in a WAL file, finished or not,
+// e.g. to export it again. The session isn't registered anywhere and
+// journals nothing.
+func LoadWAL(path string) (*SessionCtx, error) {
+	return replayWAL(path, Limits{})
+}
+
+// replayWAL rebuilds a session from its log file.
+func replayWAL(path string, limits Limits) (*SessionCtx, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 4<<20)
+	s, err := replay(func(yield func([]byte) bool) {
+		for sc.Scan() && yield(sc.Bytes()) {
+		}
+	}, limits)
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return s, err
+}
+
+// apply replays a single logged event. Validation already happened when the
+// event was recorded, so it goes straight to the state changes.
+func (s *SessionCtx) apply(ev walEvent) {
+	s.replayAt = ev.At
+	defer func() { s.replayAt = time.Time{} }()
+	s.lastActivity = ev.At
+	switch ev.Type {
+	case walJoin:
+		s.addPlayer(&Player{ID: ev.PlayerID, Name: ev.Name, JoinedAt: ev.At}, ev.Token)
+	case walProfile:
+		if p := s.PlayersByID[ev.PlayerID]; p != nil {
+			p.Profile = ev.Profile
+		}
+	case walRound:
+		if q := s.takeQueued(ev.QueuedID); q != nil {
+			s.startQueued(q, ev.RoundID, ev.SubmissionID)
+			return
+		}
+		var model ModelChoice
+		if ev.Model != nil {
+			model = *ev.Model
+		}
+		s.startRound(ev.RoundID, ev.Prompt, ev.Translations, model).Type = ev.RoundType
+	case walSubmit:
+		s.putSubmission(ev.SubmissionID, ev.PlayerID, ev.Text)
+	case walVote:
+		s.votesByVoter[ev.PlayerID] = &Vote{ID: ev.VoteID, VoterID: ev.PlayerID, TargetSubmissionID: ev.SubmissionID}
+	case walAudienceVote:
+		s.putAudienceVote(ev.PlayerID, ev.SubmissionID)
+	case walPromptCollection:
+		s.openPromptCollection()
+	case walPromptCandidate:
+		s.putPromptCandidate(ev.QueuedID, ev.PlayerID, ev.Prompt)
+	case walPromptVote:
+		s.promptVotes[ev.PlayerID] = ev.QueuedID
+	case walAIEdit:
+		s.editAIAnswer(ev.Text)
+	case walAdvance:
+		s.advance()
+	case walResetRound:
+		if s.RoundIx > 0 && len(s.Rounds) >= s.RoundIx {
+			s.resetRound()
+		}
+	case walAIMeta:
+		if ev.Meta != nil {
+			s.recordAIMetadata(ev.RoundID, *ev.Meta)
+		}
+	case walRoundTranslation:
+		s.setRoundTranslation(ev.RoundID, ev.Lang, ev.Text)
+	case walSubmissionTranslation:
+		s.setSubmissionTranslation(ev.SubmissionID, ev.Original, ev.Lang, ev.Text)
+	case walQueuePrompt:
+		q := &QueuedPrompt{ID: ev.QueuedID, Prompt: ev.Prompt, Translations: ev.Translations, Category: ev.Category, Language: ev.Lang, SuggestedBy: ev.Name}
+		if ev.Model != nil {
+			q.Model = *ev.Model
+		}
+		s.promptQueue = append(s.promptQueue, q)
+	case walQueuedAnswer:
+		if ev.Meta != nil {
+			s.setQueuedAIAnswer(ev.QueuedID, ev.Text, *ev.Meta)
+		}
+	case walScoreFreeze:
+		s.freezeScores = ev.Frozen
+	case walRevealScores:
+		s.heldScores = nil
+	case walRoundNote:
+		s.addRoundNote(ev.RoundID, RoundNote{Text: ev.Text, At: ev.At})
+	case walCheats:
+		s.cheats = ev.Enabled
+	case walCheat:
+		if ev.Cheat != nil {
+			s.applyCheat(*ev.Cheat)
+		}
+	case walHint:
+		s.eliminate(ev.SubmissionID)
+	case walHide:
+		s.hide(ev.SubmissionID)
+	case walAttachment:
+		s.setAttachment(ev.RoundID, ev.Attachment)
+	case walExtendTimer:
+		s.phaseExtra += time.Duration(ev.Seconds) * time.Second
+	case walExpire:
+		s.setPhase(PhaseEnd)
+	case walReadingOrder:
+		if r := s.currentRound(); r != nil {
+			r.ReadingOrder = ev.Order
+		}
+	}
+}
+
+// logEvent appends ev to the session's WAL and store, if enabled, and counts
+// as activity for the reaper. Callers must hold s.mu so events are logged in
+// the order they were applied.
+func (s *SessionCtx) logEvent(ev walEvent) {
+	s.lastActivity = s.now()
+	if s.journal == nil && s.store == nil {
+		return
+	}
+	if ev.At.IsZero() {
+		ev.At = time.Now().UTC()
+	}
+	if s.journal != nil {
+		s.journal.append(ev)
+	}
+	if s.store != nil {
+		s.persist(ev)
+	}
+}
+
+func (s *SessionCtx) logRound(r *Round, queuedID, aiSubmissionID string) {
+	model := r.Model
+	s.logEvent(walEvent{Type: walRound, RoundID: r.ID, Prompt: r.Prompt, Translations: r.Translations, Model: &model, QueuedID: queuedID, SubmissionID: aiSubmissionID, RoundType: r.Type})
+}
+
+// now is the clock for phase bookkeeping; while replaying it is the time
+// the event was originally recorded.
+func (s *SessionCtx) now() time.Time {
+	if !s.replayAt.IsZero() {
+		return s.replayAt
+	}
+	return time.Now()
+}
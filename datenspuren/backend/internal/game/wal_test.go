@@ -0,0 +1,267 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWALRecovery(t *testing.T) {
+	dir := t.TempDir()
+	rm := NewRoomManager()
+	if err := rm.EnableWAL(dir, nil); err != nil {
+		t.Fatalf("should be able to enable WAL: %v", err)
+	}
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "openai", Model: "gpt-3.5-turbo", RoundCount: 3})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	session.SetPrompt(hostToken, "First question?")
+	aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+	session.Submit(bobToken, "Bob's answer")
+	aiID, _ := session.AddAISubmission("AI answer")
+	session.Advance(hostToken) // To Voting
+	session.Vote(aliceToken, aiID)
+	session.Vote(bobToken, aliceSub)
+	session.Advance(hostToken) // To Scoreboard
+	session.SetPrompt(hostToken, "Second question?")
+	session.Submit(aliceToken, "Alice again")
+
+	recovered := NewRoomManager()
+	if err := recovered.EnableWAL(dir, nil); err != nil {
+		t.Fatalf("should be able to enable WAL: %v", err)
+	}
+	codes, err := recovered.RecoverWAL()
+	if err != nil || len(codes) != 1 || codes[0] != code {
+		t.Fatalf("expected to recover session %s, got %v (%v)", code, codes, err)
+	}
+	restored, err := recovered.Lookup(session.JoinPin)
+	if err != nil {
+		t.Fatalf("should be able to look up recovered session by PIN: %v", err)
+	}
+	if active, _ := recovered.Active(); active != code {
+		t.Fatalf("expected recovered session to be active, got %q", active)
+	}
+	if restored.Phase != PhaseAnswering || restored.RoundIx != 2 {
+		t.Fatalf("expected round 2 in answering, got round %d in %s", restored.RoundIx, restored.Phase)
+	}
+	if restored.Rounds[0].ID != session.Rounds[0].ID {
+		t.Fatal("expected round IDs to survive recovery")
+	}
+	for id, points := range session.Scores {
+		if restored.Scores[id] != points {
+			t.Fatalf("expected %d points for %s, got %d", points, id, restored.Scores[id])
+		}
+	}
+	if len(restored.Summary().Rounds) != 1 {
+		t.Fatal("expected the scored round to be archived again")
+	}
+	if restored.SubmissionCount() != 1 {
+		t.Fatalf("expected Alice's pending answer to be restored, got %d submissions", restored.SubmissionCount())
+	}
+
+	// tokens keep working and new events keep being logged
+	if _, err := restored.Submit(bobToken, "Bob again"); err != nil {
+		t.Fatalf("should be able to submit with a token from before the crash: %v", err)
+	}
+	if err := restored.Advance(hostToken); err != nil {
+		t.Fatalf("host token should survive recovery: %v", err)
+	}
+	again := NewRoomManager()
+	again.EnableWAL(dir, nil)
+	again.RecoverWAL()
+	s, _ := again.Get(code)
+	if s == nil || s.Phase != PhaseVoting || s.SubmissionCount() != 2 {
+		t.Fatal("expected events after recovery to be appended to the same log")
+	}
+}
+
+func TestRehydrate(t *testing.T) {
+	dir := t.TempDir()
+	rm := NewRoomManager()
+	rm.EnableWAL(dir, nil)
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 2})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	session.SetPrompt(hostToken, "Why?")
+	aliceSub, _ := session.Submit(aliceToken, "Because")
+	session.Submit(bobToken, "Why not")
+	session.Advance(hostToken) // To Voting
+	session.Vote(bobToken, aliceSub)
+	session.Advance(hostToken) // To Scoreboard
+
+	b, err := os.ReadFile(filepath.Join(dir, code+".wal"))
+	if err != nil {
+		t.Fatalf("should be able to read the event log: %v", err)
+	}
+	var events [][]byte
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		events = append(events, []byte(line))
+	}
+	var s SessionCtx
+	if err := s.Rehydrate(events); err != nil {
+		t.Fatalf("should be able to rehydrate the session: %v", err)
+	}
+	if s.Code != code || s.Phase != PhaseScoreboard || len(s.PlayersByID) != 2 {
+		t.Fatalf("expected the scoreboard of %s with two players, got %s in %s", code, s.Code, s.Phase)
+	}
+	for id, points := range session.Scores {
+		if s.Scores[id] != points {
+			t.Fatalf("expected %d points for %s, got %d", points, id, s.Scores[id])
+		}
+	}
+
+	// replaying part of the log rebuilds the game as it was back then
+	var earlier SessionCtx
+	if err := earlier.Rehydrate(events[:len(events)-2]); err != nil || earlier.Phase != PhaseVoting || len(earlier.votesByVoter) != 0 {
+		t.Fatalf("expected the vote before anyone voted, got %s (%v)", earlier.Phase, err)
+	}
+	if err := earlier.Rehydrate(events[1:]); err == nil {
+		t.Fatal("expected a log without its create event to be rejected")
+	}
+}
+
+func TestWALIgnoresTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	rm := NewRoomManager()
+	rm.EnableWAL(dir, nil)
+	code, _, _ := rm.CreateSession(SessionConfig{RoundCount: 1})
+	session, _ := rm.Get(code)
+	session.Join("Alice")
+
+	f, _ := os.OpenFile(filepath.Join(dir, code+".wal"), os.O_WRONLY|os.O_APPEND, 0600)
+	f.WriteString(`{"type":"join","playerId":"x`)
+	f.Close()
+
+	recovered := NewRoomManager()
+	recovered.EnableWAL(dir, nil)
+	if _, err := recovered.RecoverWAL(); err != nil {
+		t.Fatalf("a torn final record should not fail recovery: %v", err)
+	}
+	restored, _ := recovered.Get(code)
+	if restored == nil || len(restored.Players()) != 1 {
+		t.Fatal("expected events before the torn record to be replayed")
+	}
+}
+
+func TestWALSkipsEndedSessions(t *testing.T) {
+	dir := t.TempDir()
+	rm := NewRoomManager()
+	rm.EnableWAL(dir, nil)
+	code, hostToken, _ := rm.CreateSession(SessionConfig{RoundCount: 1})
+	session, _ := rm.Get(code)
+	session.SetPrompt(hostToken, "Only question?")
+	session.Advance(hostToken) // no answers, straight to Scoreboard
+	session.Advance(hostToken) // To End
+
+	recovered := NewRoomManager()
+	recovered.EnableWAL(dir, nil)
+	codes, _ := recovered.RecoverWAL()
+	if len(codes) != 0 {
+		t.Fatalf("expected ended sessions not to be recovered, got %v", codes)
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	rm := NewRoomManager()
+	rm.EnableWAL(dir, nil)
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 3})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	session.SetPrompt(hostToken, "First question?")
+	session.Submit(aliceToken, "Alice's answer")
+
+	rm.Checkpoint()
+	if b, err := os.ReadFile(filepath.Join(dir, code+".wal")); err != nil || !strings.Contains(string(b), `"type":"checkpoint"`) {
+		t.Fatalf("expected a checkpoint at the end of the WAL, got %s (%v)", b, err)
+	}
+
+	recovered := NewRoomManager()
+	recovered.EnableWAL(dir, nil)
+	if codes, err := recovered.RecoverWAL(); err != nil || len(codes) != 1 {
+		t.Fatalf("expected the checkpointed session to recover, got %v (%v)", codes, err)
+	}
+	if restored, _ := recovered.Get(code); restored.SubmissionCount() != 1 {
+		t.Fatal("expected the checkpoint to leave the round untouched")
+	}
+}
+
+// memStore counts what sessions save, see SessionStore.
+type memStore struct {
+	mu    sync.Mutex
+	saved map[string]int
+}
+
+func (m *memStore) Save(records []StoreRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range records {
+		m.saved[r.Code]++
+	}
+	return nil
+}
+
+func (m *memStore) Unfinished() (map[string][][]byte, error) { return nil, nil }
+
+func TestUnrecordedSessionsStayOffDisk(t *testing.T) {
+	dir := t.TempDir()
+	st := &memStore{saved: map[string]int{}}
+	journaled, stored := NewRoomManager(), NewRoomManager()
+	journaled.EnableWAL(dir, nil)
+	stored.EnableStore(st, nil)
+	play := func(cfg SessionConfig) (string, string) {
+		var codes [2]string
+		for i, rm := range []*RoomManager{journaled, stored} {
+			code, hostToken, _ := rm.CreateSession(cfg)
+			session, _ := rm.Get(code)
+			_, aliceToken, _ := session.Join("Alice")
+			session.SetPrompt(hostToken, "Private question?")
+			session.Submit(aliceToken, "Alice's secret")
+			codes[i] = code
+		}
+		return codes[0], codes[1]
+	}
+	off := false
+	privateWAL, privateStore := play(SessionConfig{Provider: "manual", RoundCount: 1, Export: &off})
+	journaled.SetExportDefault(false)
+	stored.SetExportDefault(false)
+	byDefaultWAL, byDefaultStore := play(SessionConfig{Provider: "manual", RoundCount: 1})
+	on := true
+	optedInWAL, optedInStore := play(SessionConfig{Provider: "manual", RoundCount: 1, Export: &on})
+	stored.FlushStore()
+
+	for _, code := range []string{privateWAL, byDefaultWAL} {
+		if _, err := os.Stat(filepath.Join(dir, code+".wal")); !os.IsNotExist(err) {
+			t.Fatalf("expected no WAL for unrecorded session %s, got %v", code, err)
+		}
+	}
+	for _, code := range []string{privateStore, byDefaultStore} {
+		if st.saved[code] != 0 {
+			t.Fatalf("expected nothing stored for unrecorded session %s, got %d events", code, st.saved[code])
+		}
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 1 || filepath.Base(files[0]) != optedInWAL+".wal" {
+		t.Fatalf("expected only the exported session's WAL, got %v", files)
+	}
+	if st.saved[optedInStore] == 0 {
+		t.Fatal("expected the exported session to be stored")
+	}
+}
+
+func TestStoreReplacesWAL(t *testing.T) {
+	dir := t.TempDir()
+	rm := NewRoomManager()
+	rm.EnableWAL(dir, nil)
+	rm.EnableStore(&memStore{saved: map[string]int{}}, nil)
+	rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1})
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 0 {
+		t.Fatalf("expected no WAL next to the store, got %v", files)
+	}
+	if err := rm.EnableWAL(dir, nil); err == nil {
+		t.Fatal("expected the WAL not to be enabled next to a store")
+	}
+}
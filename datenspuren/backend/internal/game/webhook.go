@@ -0,0 +1,210 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Webhook event names.
+const (
+	WebhookRoundCompleted = "round.completed"
+	WebhookGameEnded      = "game.ended"
+)
+
+// Webhook signature headers. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" under the shared secret, so receivers can reject
+// forged and replayed deliveries.
+const (
+	WebhookSignatureHeader = "X-GPTdash-Signature"
+	WebhookTimestampHeader = "X-GPTdash-Timestamp"
+)
+
+// WebhookDelivery is the JSON body POSTed to the webhook. Round is set for
+// round.completed, Summary for game.ended.
+type WebhookDelivery struct {
+	ID          string        `json:"id"` // stays the same across retries
+	Event       string        `json:"event"`
+	SessionCode string        `json:"sessionCode"`
+	SentAt      time.Time     `json:"sentAt"`
+	RoundCount  int           `json:"roundCount"`
+	Round       *RoundSummary `json:"round,omitempty"`
+	Summary     *GameSummary  `json:"summary,omitempty"`
+}
+
+// Webhook POSTs signed deliveries about finished rounds and games to a URL.
+// Deliveries go out one at a time in the order they happened, each retried
+// with backoff, so a slow or flaky receiver never stalls a game. Once the
+// queue is full new deliveries are dropped and reported to OnError. Close
+// delivers what is still queued.
+type Webhook struct {
+	URL     string
+	Secret  string
+	Retries int           // extra attempts per delivery
+	Backoff time.Duration // before the first retry, doubling after that
+	OnError func(code string, err error)
+
+	http   *http.Client
+	queue  chan WebhookDelivery
+	done   chan struct{} // closed once run has drained the queue
+	ctx    context.Context
+	cancel context.CancelFunc // gives up on the queue, see Close
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func NewWebhook(url, secret string) *Webhook {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Webhook{
+		URL:     url,
+		Secret:  secret,
+		Retries: 3,
+		Backoff: 2 * time.Second,
+		http:    &http.Client{Timeout: 10 * time.Second},
+		queue:   make(chan WebhookDelivery, 100),
+		done:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	go w.run()
+	return w
+}
+
+// Close stops taking deliveries and waits until the queued ones are sent.
+// Once ctx is done the rest are dropped and reported to OnError, and ctx's
+// error is returned.
+func (w *Webhook) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		w.cancel()
+		<-w.done
+		return ctx.Err()
+	}
+}
+
+// RoundCompleted queues a delivery of the session's last scored round.
+func (w *Webhook) RoundCompleted(s *SessionCtx, opts ExportOptions) {
+	last, ok := s.LastRound()
+	if !ok {
+		return
+	}
+	round := s.RedactRound(last, opts)
+	w.dispatch(s, WebhookDelivery{Event: WebhookRoundCompleted, Round: &round})
+}
+
+// GameEnded queues a delivery of the whole game's summary.
+func (w *Webhook) GameEnded(s *SessionCtx, opts ExportOptions) {
+	summary := s.RedactSummary(s.Summary(), opts)
+	w.dispatch(s, WebhookDelivery{Event: WebhookGameEnded, Summary: &summary})
+}
+
+func (w *Webhook) dispatch(s *SessionCtx, d WebhookDelivery) {
+	d.ID = newWebhookID()
+	d.SessionCode = s.Code
+	d.SentAt = time.Now().UTC()
+	d.RoundCount = s.Config.RoundCount
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		w.fail(s.Code, fmt.Errorf("webhook closed, dropped %s", d.Event))
+		return
+	}
+	select {
+	case w.queue <- d:
+	default:
+		w.fail(s.Code, fmt.Errorf("webhook queue full, dropped %s", d.Event))
+	}
+}
+
+func (w *Webhook) run() {
+	defer close(w.done)
+	for d := range w.queue {
+		if w.ctx.Err() != nil {
+			w.fail(d.SessionCode, fmt.Errorf("shutting down, dropped %s", d.Event))
+			continue
+		}
+		wait := w.Backoff
+		for attempt := 0; ; attempt++ {
+			err := w.Send(w.ctx, d)
+			if err == nil {
+				break
+			}
+			if attempt >= w.Retries || w.ctx.Err() != nil {
+				w.fail(d.SessionCode, fmt.Errorf("giving up on %s after %d attempts: %w", d.Event, attempt+1, err))
+				break
+			}
+			select {
+			case <-time.After(wait):
+			case <-w.ctx.Done():
+			}
+			wait *= 2
+		}
+	}
+}
+
+// Send POSTs one delivery, signed if the webhook has a secret.
+func (w *Webhook) Send(ctx context.Context, d WebhookDelivery) error {
+	body, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.Secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, ts)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(w.Secret, ts, body))
+	}
+	resp, err := w.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (w *Webhook) fail(code string, err error) {
+	if w.OnError != nil {
+		w.OnError(code, err)
+	}
+}
+
+// SignWebhook computes the hex signature of a delivery body sent at
+// timestamp (Unix seconds).
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newWebhookID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,107 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhook(t *testing.T) {
+	received := make(chan WebhookDelivery, 4)
+	failures := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if failures == 0 {
+			// the first attempt fails, the retry must arrive with the same ID
+			failures++
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		sig := strings.TrimPrefix(r.Header.Get(WebhookSignatureHeader), "sha256=")
+		if sig != SignWebhook("s3cret", r.Header.Get(WebhookTimestampHeader), body) {
+			t.Errorf("expected a valid signature, got %q", sig)
+		}
+		var d WebhookDelivery
+		if err := json.Unmarshal(body, &d); err != nil {
+			t.Errorf("should be able to decode the delivery: %v", err)
+		}
+		received <- d
+	}))
+	defer ts.Close()
+
+	rm := NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	session.SetPrompt(hostToken, "Test question?")
+	session.Submit(aliceToken, "Alice's answer")
+	session.AddAISubmission("AI answer")
+	session.Advance(hostToken) // To Voting
+	session.Advance(hostToken) // To Scoreboard
+
+	hook := NewWebhook(ts.URL, "s3cret")
+	hook.Backoff = 10 * time.Millisecond
+	hook.RoundCompleted(session, ExportOptions{Anonymize: true})
+	session.Advance(hostToken) // To End
+	hook.GameEnded(session, ExportOptions{})
+
+	next := func() WebhookDelivery {
+		t.Helper()
+		select {
+		case d := <-received:
+			return d
+		case <-time.After(3 * time.Second):
+			t.Fatal("expected a webhook delivery")
+		}
+		return WebhookDelivery{}
+	}
+	round := next()
+	if round.Event != WebhookRoundCompleted || round.SessionCode != code || round.Round == nil || round.Round.Prompt != "Test question?" {
+		t.Fatalf("expected the completed round first, got %+v", round)
+	}
+	for _, sub := range round.Round.Submissions {
+		if sub.AuthorName == "Alice" {
+			t.Fatalf("expected the round to be anonymized, got %+v", sub)
+		}
+	}
+	if game := next(); game.Event != WebhookGameEnded || game.Summary == nil || len(game.Summary.Rounds) != 1 {
+		t.Fatalf("expected the game summary second, got %+v", game)
+	}
+}
+
+func TestWebhookCloseDrainsQueue(t *testing.T) {
+	var received atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		received.Add(1)
+	}))
+	defer ts.Close()
+
+	rm := NewRoomManager()
+	code, _, _ := rm.CreateSession(SessionConfig{Provider: "manual", RoundCount: 1})
+	session, _ := rm.Get(code)
+	hook := NewWebhook(ts.URL, "s3cret")
+	var dropped []error
+	hook.OnError = func(_ string, err error) { dropped = append(dropped, err) }
+	for i := 0; i < 3; i++ {
+		hook.GameEnded(session, ExportOptions{})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := hook.Close(ctx); err != nil {
+		t.Fatalf("should be able to drain the queue: %v", err)
+	}
+	if n := received.Load(); n != 3 {
+		t.Fatalf("expected all 3 queued deliveries before Close returns, got %d", n)
+	}
+	hook.GameEnded(session, ExportOptions{})
+	if len(dropped) != 1 {
+		t.Fatalf("expected a delivery after Close to be reported as dropped, got %v", dropped)
+	}
+}
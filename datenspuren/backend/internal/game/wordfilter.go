@@ -0,0 +1,17 @@
+package game
+
+import "github.com/kiliankoe/gptdash/internal/moderation"
+
+// SetWordFilter screens the names and answers of players, in running
+// sessions and those created or recovered afterwards, see moderation.Filter.
+// Nil disables it. Names and answers already in stay as they are.
+func (rm *RoomManager) SetWordFilter(f *moderation.Filter) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.wordFilter = f
+	for _, s := range rm.sessions {
+		s.mu.Lock()
+		s.wordFilter = f
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,408 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: gptdash/v1/game.proto
+
+package gptdashv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionCode   string                 `protobuf:"bytes,1,opt,name=session_code,json=sessionCode,proto3" json:"session_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionRequest) Reset() {
+	*x = SessionRequest{}
+	mi := &file_gptdash_v1_game_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionRequest) ProtoMessage() {}
+
+func (x *SessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gptdash_v1_game_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionRequest.ProtoReflect.Descriptor instead.
+func (*SessionRequest) Descriptor() ([]byte, []int) {
+	return file_gptdash_v1_game_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SessionRequest) GetSessionCode() string {
+	if x != nil {
+		return x.SessionCode
+	}
+	return ""
+}
+
+type SetPromptRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	SessionCode string                 `protobuf:"bytes,1,opt,name=session_code,json=sessionCode,proto3" json:"session_code,omitempty"`
+	// at most 500 characters
+	Prompt string `protobuf:"bytes,2,opt,name=prompt,proto3" json:"prompt,omitempty"`
+	// the prompt in other languages, by ISO 639-1 code
+	Translations map[string]string `protobuf:"bytes,3,rep,name=translations,proto3" json:"translations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// start this queued prompt instead of prompt
+	QueuedId      string `protobuf:"bytes,4,opt,name=queued_id,json=queuedId,proto3" json:"queued_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetPromptRequest) Reset() {
+	*x = SetPromptRequest{}
+	mi := &file_gptdash_v1_game_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetPromptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPromptRequest) ProtoMessage() {}
+
+func (x *SetPromptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gptdash_v1_game_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPromptRequest.ProtoReflect.Descriptor instead.
+func (*SetPromptRequest) Descriptor() ([]byte, []int) {
+	return file_gptdash_v1_game_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SetPromptRequest) GetSessionCode() string {
+	if x != nil {
+		return x.SessionCode
+	}
+	return ""
+}
+
+func (x *SetPromptRequest) GetPrompt() string {
+	if x != nil {
+		return x.Prompt
+	}
+	return ""
+}
+
+func (x *SetPromptRequest) GetTranslations() map[string]string {
+	if x != nil {
+		return x.Translations
+	}
+	return nil
+}
+
+func (x *SetPromptRequest) GetQueuedId() string {
+	if x != nil {
+		return x.QueuedId
+	}
+	return ""
+}
+
+type ExtendTimerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionCode   string                 `protobuf:"bytes,1,opt,name=session_code,json=sessionCode,proto3" json:"session_code,omitempty"`
+	Seconds       int32                  `protobuf:"varint,2,opt,name=seconds,proto3" json:"seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtendTimerRequest) Reset() {
+	*x = ExtendTimerRequest{}
+	mi := &file_gptdash_v1_game_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtendTimerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtendTimerRequest) ProtoMessage() {}
+
+func (x *ExtendTimerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gptdash_v1_game_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtendTimerRequest.ProtoReflect.Descriptor instead.
+func (*ExtendTimerRequest) Descriptor() ([]byte, []int) {
+	return file_gptdash_v1_game_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ExtendTimerRequest) GetSessionCode() string {
+	if x != nil {
+		return x.SessionCode
+	}
+	return ""
+}
+
+func (x *ExtendTimerRequest) GetSeconds() int32 {
+	if x != nil {
+		return x.Seconds
+	}
+	return 0
+}
+
+type PhaseResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the phase the session is in after the call, e.g. "answering"
+	Phase         string `protobuf:"bytes,1,opt,name=phase,proto3" json:"phase,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PhaseResponse) Reset() {
+	*x = PhaseResponse{}
+	mi := &file_gptdash_v1_game_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PhaseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PhaseResponse) ProtoMessage() {}
+
+func (x *PhaseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gptdash_v1_game_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PhaseResponse.ProtoReflect.Descriptor instead.
+func (*PhaseResponse) Descriptor() ([]byte, []int) {
+	return file_gptdash_v1_game_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PhaseResponse) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+// Event is one overlay event, see WatchEvents.
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// e.g. "state", "phase", "votes" or "reveal"
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Data          *structpb.Value        `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	At            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=at,proto3" json:"at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_gptdash_v1_game_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_gptdash_v1_game_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_gptdash_v1_game_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Event) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Event) GetData() *structpb.Value {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Event) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+var File_gptdash_v1_game_proto protoreflect.FileDescriptor
+
+const file_gptdash_v1_game_proto_rawDesc = "" +
+	"\n" +
+	"\x15gptdash/v1/game.proto\x12\n" +
+	"gptdash.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"3\n" +
+	"\x0eSessionRequest\x12!\n" +
+	"\fsession_code\x18\x01 \x01(\tR\vsessionCode\"\xff\x01\n" +
+	"\x10SetPromptRequest\x12!\n" +
+	"\fsession_code\x18\x01 \x01(\tR\vsessionCode\x12\x16\n" +
+	"\x06prompt\x18\x02 \x01(\tR\x06prompt\x12R\n" +
+	"\ftranslations\x18\x03 \x03(\v2..gptdash.v1.SetPromptRequest.TranslationsEntryR\ftranslations\x12\x1b\n" +
+	"\tqueued_id\x18\x04 \x01(\tR\bqueuedId\x1a?\n" +
+	"\x11TranslationsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"Q\n" +
+	"\x12ExtendTimerRequest\x12!\n" +
+	"\fsession_code\x18\x01 \x01(\tR\vsessionCode\x12\x18\n" +
+	"\aseconds\x18\x02 \x01(\x05R\aseconds\"%\n" +
+	"\rPhaseResponse\x12\x14\n" +
+	"\x05phase\x18\x01 \x01(\tR\x05phase\"s\n" +
+	"\x05Event\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12*\n" +
+	"\x04data\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\x04data\x12*\n" +
+	"\x02at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02at2\xe7\x03\n" +
+	"\vGameService\x12?\n" +
+	"\bGetState\x12\x1a.gptdash.v1.SessionRequest\x1a\x17.google.protobuf.Struct\x12A\n" +
+	"\n" +
+	"GetSummary\x12\x1a.gptdash.v1.SessionRequest\x1a\x17.google.protobuf.Struct\x12D\n" +
+	"\tSetPrompt\x12\x1c.gptdash.v1.SetPromptRequest\x1a\x19.gptdash.v1.PhaseResponse\x12@\n" +
+	"\aAdvance\x12\x1a.gptdash.v1.SessionRequest\x1a\x19.gptdash.v1.PhaseResponse\x12B\n" +
+	"\fRevealScores\x12\x1a.gptdash.v1.SessionRequest\x1a\x16.google.protobuf.Empty\x12H\n" +
+	"\vExtendTimer\x12\x1e.gptdash.v1.ExtendTimerRequest\x1a\x19.gptdash.v1.PhaseResponse\x12>\n" +
+	"\vWatchEvents\x12\x1a.gptdash.v1.SessionRequest\x1a\x11.gptdash.v1.Event0\x01B@Z>github.com/kiliankoe/gptdash/internal/gen/gptdash/v1;gptdashv1b\x06proto3"
+
+var (
+	file_gptdash_v1_game_proto_rawDescOnce sync.Once
+	file_gptdash_v1_game_proto_rawDescData []byte
+)
+
+func file_gptdash_v1_game_proto_rawDescGZIP() []byte {
+	file_gptdash_v1_game_proto_rawDescOnce.Do(func() {
+		file_gptdash_v1_game_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_gptdash_v1_game_proto_rawDesc), len(file_gptdash_v1_game_proto_rawDesc)))
+	})
+	return file_gptdash_v1_game_proto_rawDescData
+}
+
+var file_gptdash_v1_game_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_gptdash_v1_game_proto_goTypes = []any{
+	(*SessionRequest)(nil),        // 0: gptdash.v1.SessionRequest
+	(*SetPromptRequest)(nil),      // 1: gptdash.v1.SetPromptRequest
+	(*ExtendTimerRequest)(nil),    // 2: gptdash.v1.ExtendTimerRequest
+	(*PhaseResponse)(nil),         // 3: gptdash.v1.PhaseResponse
+	(*Event)(nil),                 // 4: gptdash.v1.Event
+	nil,                           // 5: gptdash.v1.SetPromptRequest.TranslationsEntry
+	(*structpb.Value)(nil),        // 6: google.protobuf.Value
+	(*timestamppb.Timestamp)(nil), // 7: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),       // 8: google.protobuf.Struct
+	(*emptypb.Empty)(nil),         // 9: google.protobuf.Empty
+}
+var file_gptdash_v1_game_proto_depIdxs = []int32{
+	5,  // 0: gptdash.v1.SetPromptRequest.translations:type_name -> gptdash.v1.SetPromptRequest.TranslationsEntry
+	6,  // 1: gptdash.v1.Event.data:type_name -> google.protobuf.Value
+	7,  // 2: gptdash.v1.Event.at:type_name -> google.protobuf.Timestamp
+	0,  // 3: gptdash.v1.GameService.GetState:input_type -> gptdash.v1.SessionRequest
+	0,  // 4: gptdash.v1.GameService.GetSummary:input_type -> gptdash.v1.SessionRequest
+	1,  // 5: gptdash.v1.GameService.SetPrompt:input_type -> gptdash.v1.SetPromptRequest
+	0,  // 6: gptdash.v1.GameService.Advance:input_type -> gptdash.v1.SessionRequest
+	0,  // 7: gptdash.v1.GameService.RevealScores:input_type -> gptdash.v1.SessionRequest
+	2,  // 8: gptdash.v1.GameService.ExtendTimer:input_type -> gptdash.v1.ExtendTimerRequest
+	0,  // 9: gptdash.v1.GameService.WatchEvents:input_type -> gptdash.v1.SessionRequest
+	8,  // 10: gptdash.v1.GameService.GetState:output_type -> google.protobuf.Struct
+	8,  // 11: gptdash.v1.GameService.GetSummary:output_type -> google.protobuf.Struct
+	3,  // 12: gptdash.v1.GameService.SetPrompt:output_type -> gptdash.v1.PhaseResponse
+	3,  // 13: gptdash.v1.GameService.Advance:output_type -> gptdash.v1.PhaseResponse
+	9,  // 14: gptdash.v1.GameService.RevealScores:output_type -> google.protobuf.Empty
+	3,  // 15: gptdash.v1.GameService.ExtendTimer:output_type -> gptdash.v1.PhaseResponse
+	4,  // 16: gptdash.v1.GameService.WatchEvents:output_type -> gptdash.v1.Event
+	10, // [10:17] is the sub-list for method output_type
+	3,  // [3:10] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_gptdash_v1_game_proto_init() }
+func file_gptdash_v1_game_proto_init() {
+	if File_gptdash_v1_game_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_gptdash_v1_game_proto_rawDesc), len(file_gptdash_v1_game_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_gptdash_v1_game_proto_goTypes,
+		DependencyIndexes: file_gptdash_v1_game_proto_depIdxs,
+		MessageInfos:      file_gptdash_v1_game_proto_msgTypes,
+	}.Build()
+	File_gptdash_v1_game_proto = out.File
+	file_gptdash_v1_game_proto_goTypes = nil
+	file_gptdash_v1_game_proto_depIdxs = nil
+}
@@ -0,0 +1,297 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: gptdash/v1/game.proto
+
+package gptdashv1connect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	v1 "github.com/kiliankoe/gptdash/internal/gen/gptdash/v1"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// GameServiceName is the fully-qualified name of the GameService service.
+	GameServiceName = "gptdash.v1.GameService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// GameServiceGetStateProcedure is the fully-qualified name of the GameService's GetState RPC.
+	GameServiceGetStateProcedure = "/gptdash.v1.GameService/GetState"
+	// GameServiceGetSummaryProcedure is the fully-qualified name of the GameService's GetSummary RPC.
+	GameServiceGetSummaryProcedure = "/gptdash.v1.GameService/GetSummary"
+	// GameServiceSetPromptProcedure is the fully-qualified name of the GameService's SetPrompt RPC.
+	GameServiceSetPromptProcedure = "/gptdash.v1.GameService/SetPrompt"
+	// GameServiceAdvanceProcedure is the fully-qualified name of the GameService's Advance RPC.
+	GameServiceAdvanceProcedure = "/gptdash.v1.GameService/Advance"
+	// GameServiceRevealScoresProcedure is the fully-qualified name of the GameService's RevealScores
+	// RPC.
+	GameServiceRevealScoresProcedure = "/gptdash.v1.GameService/RevealScores"
+	// GameServiceExtendTimerProcedure is the fully-qualified name of the GameService's ExtendTimer RPC.
+	GameServiceExtendTimerProcedure = "/gptdash.v1.GameService/ExtendTimer"
+	// GameServiceWatchEventsProcedure is the fully-qualified name of the GameService's WatchEvents RPC.
+	GameServiceWatchEventsProcedure = "/gptdash.v1.GameService/WatchEvents"
+)
+
+// GameServiceClient is a client for the gptdash.v1.GameService service.
+type GameServiceClient interface {
+	// GetState returns the session's public state, the same JSON as
+	// GET /api/session/:code/state. Read-only.
+	GetState(context.Context, *connect.Request[v1.SessionRequest]) (*connect.Response[structpb.Struct], error)
+	// GetSummary returns the narrative of the game so far. Read-only.
+	GetSummary(context.Context, *connect.Request[v1.SessionRequest]) (*connect.Response[structpb.Struct], error)
+	// SetPrompt starts the next round.
+	SetPrompt(context.Context, *connect.Request[v1.SetPromptRequest]) (*connect.Response[v1.PhaseResponse], error)
+	// Advance moves the session to its next phase.
+	Advance(context.Context, *connect.Request[v1.SessionRequest]) (*connect.Response[v1.PhaseResponse], error)
+	// RevealScores releases scores the host withheld.
+	RevealScores(context.Context, *connect.Request[v1.SessionRequest]) (*connect.Response[emptypb.Empty], error)
+	// ExtendTimer gives everyone more time in the current phase.
+	ExtendTimer(context.Context, *connect.Request[v1.ExtendTimerRequest]) (*connect.Response[v1.PhaseResponse], error)
+	// WatchEvents streams the session's overlay events, starting with the
+	// current state, until the client goes away. Read-only.
+	WatchEvents(context.Context, *connect.Request[v1.SessionRequest]) (*connect.ServerStreamForClient[v1.Event], error)
+}
+
+// NewGameServiceClient constructs a client for the gptdash.v1.GameService service. By default, it
+// uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewGameServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) GameServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	gameServiceMethods := v1.File_gptdash_v1_game_proto.Services().ByName("GameService").Methods()
+	return &gameServiceClient{
+		getState: connect.NewClient[v1.SessionRequest, structpb.Struct](
+			httpClient,
+			baseURL+GameServiceGetStateProcedure,
+			connect.WithSchema(gameServiceMethods.ByName("GetState")),
+			connect.WithClientOptions(opts...),
+		),
+		getSummary: connect.NewClient[v1.SessionRequest, structpb.Struct](
+			httpClient,
+			baseURL+GameServiceGetSummaryProcedure,
+			connect.WithSchema(gameServiceMethods.ByName("GetSummary")),
+			connect.WithClientOptions(opts...),
+		),
+		setPrompt: connect.NewClient[v1.SetPromptRequest, v1.PhaseResponse](
+			httpClient,
+			baseURL+GameServiceSetPromptProcedure,
+			connect.WithSchema(gameServiceMethods.ByName("SetPrompt")),
+			connect.WithClientOptions(opts...),
+		),
+		advance: connect.NewClient[v1.SessionRequest, v1.PhaseResponse](
+			httpClient,
+			baseURL+GameServiceAdvanceProcedure,
+			connect.WithSchema(gameServiceMethods.ByName("Advance")),
+			connect.WithClientOptions(opts...),
+		),
+		revealScores: connect.NewClient[v1.SessionRequest, emptypb.Empty](
+			httpClient,
+			baseURL+GameServiceRevealScoresProcedure,
+			connect.WithSchema(gameServiceMethods.ByName("RevealScores")),
+			connect.WithClientOptions(opts...),
+		),
+		extendTimer: connect.NewClient[v1.ExtendTimerRequest, v1.PhaseResponse](
+			httpClient,
+			baseURL+GameServiceExtendTimerProcedure,
+			connect.WithSchema(gameServiceMethods.ByName("ExtendTimer")),
+			connect.WithClientOptions(opts...),
+		),
+		watchEvents: connect.NewClient[v1.SessionRequest, v1.Event](
+			httpClient,
+			baseURL+GameServiceWatchEventsProcedure,
+			connect.WithSchema(gameServiceMethods.ByName("WatchEvents")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// gameServiceClient implements GameServiceClient.
+type gameServiceClient struct {
+	getState     *connect.Client[v1.SessionRequest, structpb.Struct]
+	getSummary   *connect.Client[v1.SessionRequest, structpb.Struct]
+	setPrompt    *connect.Client[v1.SetPromptRequest, v1.PhaseResponse]
+	advance      *connect.Client[v1.SessionRequest, v1.PhaseResponse]
+	revealScores *connect.Client[v1.SessionRequest, emptypb.Empty]
+	extendTimer  *connect.Client[v1.ExtendTimerRequest, v1.PhaseResponse]
+	watchEvents  *connect.Client[v1.SessionRequest, v1.Event]
+}
+
+// GetState calls gptdash.v1.GameService.GetState.
+func (c *gameServiceClient) GetState(ctx context.Context, req *connect.Request[v1.SessionRequest]) (*connect.Response[structpb.Struct], error) {
+	return c.getState.CallUnary(ctx, req)
+}
+
+// GetSummary calls gptdash.v1.GameService.GetSummary.
+func (c *gameServiceClient) GetSummary(ctx context.Context, req *connect.Request[v1.SessionRequest]) (*connect.Response[structpb.Struct], error) {
+	return c.getSummary.CallUnary(ctx, req)
+}
+
+// SetPrompt calls gptdash.v1.GameService.SetPrompt.
+func (c *gameServiceClient) SetPrompt(ctx context.Context, req *connect.Request[v1.SetPromptRequest]) (*connect.Response[v1.PhaseResponse], error) {
+	return c.setPrompt.CallUnary(ctx, req)
+}
+
+// Advance calls gptdash.v1.GameService.Advance.
+func (c *gameServiceClient) Advance(ctx context.Context, req *connect.Request[v1.SessionRequest]) (*connect.Response[v1.PhaseResponse], error) {
+	return c.advance.CallUnary(ctx, req)
+}
+
+// RevealScores calls gptdash.v1.GameService.RevealScores.
+func (c *gameServiceClient) RevealScores(ctx context.Context, req *connect.Request[v1.SessionRequest]) (*connect.Response[emptypb.Empty], error) {
+	return c.revealScores.CallUnary(ctx, req)
+}
+
+// ExtendTimer calls gptdash.v1.GameService.ExtendTimer.
+func (c *gameServiceClient) ExtendTimer(ctx context.Context, req *connect.Request[v1.ExtendTimerRequest]) (*connect.Response[v1.PhaseResponse], error) {
+	return c.extendTimer.CallUnary(ctx, req)
+}
+
+// WatchEvents calls gptdash.v1.GameService.WatchEvents.
+func (c *gameServiceClient) WatchEvents(ctx context.Context, req *connect.Request[v1.SessionRequest]) (*connect.ServerStreamForClient[v1.Event], error) {
+	return c.watchEvents.CallServerStream(ctx, req)
+}
+
+// GameServiceHandler is an implementation of the gptdash.v1.GameService service.
+type GameServiceHandler interface {
+	// GetState returns the session's public state, the same JSON as
+	// GET /api/session/:code/state. Read-only.
+	GetState(context.Context, *connect.Request[v1.SessionRequest]) (*connect.Response[structpb.Struct], error)
+	// GetSummary returns the narrative of the game so far. Read-only.
+	GetSummary(context.Context, *connect.Request[v1.SessionRequest]) (*connect.Response[structpb.Struct], error)
+	// SetPrompt starts the next round.
+	SetPrompt(context.Context, *connect.Request[v1.SetPromptRequest]) (*connect.Response[v1.PhaseResponse], error)
+	// Advance moves the session to its next phase.
+	Advance(context.Context, *connect.Request[v1.SessionRequest]) (*connect.Response[v1.PhaseResponse], error)
+	// RevealScores releases scores the host withheld.
+	RevealScores(context.Context, *connect.Request[v1.SessionRequest]) (*connect.Response[emptypb.Empty], error)
+	// ExtendTimer gives everyone more time in the current phase.
+	ExtendTimer(context.Context, *connect.Request[v1.ExtendTimerRequest]) (*connect.Response[v1.PhaseResponse], error)
+	// WatchEvents streams the session's overlay events, starting with the
+	// current state, until the client goes away. Read-only.
+	WatchEvents(context.Context, *connect.Request[v1.SessionRequest], *connect.ServerStream[v1.Event]) error
+}
+
+// NewGameServiceHandler builds an HTTP handler from the service implementation. It returns the path
+// on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewGameServiceHandler(svc GameServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	gameServiceMethods := v1.File_gptdash_v1_game_proto.Services().ByName("GameService").Methods()
+	gameServiceGetStateHandler := connect.NewUnaryHandler(
+		GameServiceGetStateProcedure,
+		svc.GetState,
+		connect.WithSchema(gameServiceMethods.ByName("GetState")),
+		connect.WithHandlerOptions(opts...),
+	)
+	gameServiceGetSummaryHandler := connect.NewUnaryHandler(
+		GameServiceGetSummaryProcedure,
+		svc.GetSummary,
+		connect.WithSchema(gameServiceMethods.ByName("GetSummary")),
+		connect.WithHandlerOptions(opts...),
+	)
+	gameServiceSetPromptHandler := connect.NewUnaryHandler(
+		GameServiceSetPromptProcedure,
+		svc.SetPrompt,
+		connect.WithSchema(gameServiceMethods.ByName("SetPrompt")),
+		connect.WithHandlerOptions(opts...),
+	)
+	gameServiceAdvanceHandler := connect.NewUnaryHandler(
+		GameServiceAdvanceProcedure,
+		svc.Advance,
+		connect.WithSchema(gameServiceMethods.ByName("Advance")),
+		connect.WithHandlerOptions(opts...),
+	)
+	gameServiceRevealScoresHandler := connect.NewUnaryHandler(
+		GameServiceRevealScoresProcedure,
+		svc.RevealScores,
+		connect.WithSchema(gameServiceMethods.ByName("RevealScores")),
+		connect.WithHandlerOptions(opts...),
+	)
+	gameServiceExtendTimerHandler := connect.NewUnaryHandler(
+		GameServiceExtendTimerProcedure,
+		svc.ExtendTimer,
+		connect.WithSchema(gameServiceMethods.ByName("ExtendTimer")),
+		connect.WithHandlerOptions(opts...),
+	)
+	gameServiceWatchEventsHandler := connect.NewServerStreamHandler(
+		GameServiceWatchEventsProcedure,
+		svc.WatchEvents,
+		connect.WithSchema(gameServiceMethods.ByName("WatchEvents")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/gptdash.v1.GameService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case GameServiceGetStateProcedure:
+			gameServiceGetStateHandler.ServeHTTP(w, r)
+		case GameServiceGetSummaryProcedure:
+			gameServiceGetSummaryHandler.ServeHTTP(w, r)
+		case GameServiceSetPromptProcedure:
+			gameServiceSetPromptHandler.ServeHTTP(w, r)
+		case GameServiceAdvanceProcedure:
+			gameServiceAdvanceHandler.ServeHTTP(w, r)
+		case GameServiceRevealScoresProcedure:
+			gameServiceRevealScoresHandler.ServeHTTP(w, r)
+		case GameServiceExtendTimerProcedure:
+			gameServiceExtendTimerHandler.ServeHTTP(w, r)
+		case GameServiceWatchEventsProcedure:
+			gameServiceWatchEventsHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedGameServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedGameServiceHandler struct{}
+
+func (UnimplementedGameServiceHandler) GetState(context.Context, *connect.Request[v1.SessionRequest]) (*connect.Response[structpb.Struct], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("gptdash.v1.GameService.GetState is not implemented"))
+}
+
+func (UnimplementedGameServiceHandler) GetSummary(context.Context, *connect.Request[v1.SessionRequest]) (*connect.Response[structpb.Struct], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("gptdash.v1.GameService.GetSummary is not implemented"))
+}
+
+func (UnimplementedGameServiceHandler) SetPrompt(context.Context, *connect.Request[v1.SetPromptRequest]) (*connect.Response[v1.PhaseResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("gptdash.v1.GameService.SetPrompt is not implemented"))
+}
+
+func (UnimplementedGameServiceHandler) Advance(context.Context, *connect.Request[v1.SessionRequest]) (*connect.Response[v1.PhaseResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("gptdash.v1.GameService.Advance is not implemented"))
+}
+
+func (UnimplementedGameServiceHandler) RevealScores(context.Context, *connect.Request[v1.SessionRequest]) (*connect.Response[emptypb.Empty], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("gptdash.v1.GameService.RevealScores is not implemented"))
+}
+
+func (UnimplementedGameServiceHandler) ExtendTimer(context.Context, *connect.Request[v1.ExtendTimerRequest]) (*connect.Response[v1.PhaseResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("gptdash.v1.GameService.ExtendTimer is not implemented"))
+}
+
+func (UnimplementedGameServiceHandler) WatchEvents(context.Context, *connect.Request[v1.SessionRequest], *connect.ServerStream[v1.Event]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("gptdash.v1.GameService.WatchEvents is not implemented"))
+}
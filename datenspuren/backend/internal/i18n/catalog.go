@@ -0,0 +1,51 @@
+package i18n
+
+// SystemPrompt is the AI's default system prompt, see T.
+const SystemPrompt = "You are a concise AI. Answer briefly in 1-2 sentences."
+
+// catalog holds the translations of the English messages per language.
+var catalog = map[string]map[string]string{
+	"de": {
+		SystemPrompt: "Du bist eine prägnante, sich kurzfassende KI. Antworte knapp in 1-2 Sätzen.",
+
+		// signage
+		"No game running right now":          "Gerade läuft kein Spiel",
+		"Game running":                       "Spiel läuft",
+		"join at %s":                         "mitmachen auf %s",
+		"join with code %s":                  "mitmachen mit Code %s",
+		"Round %d of %d":                     "Runde %d von %d",
+		"%d players":                         "%d Mitspielende",
+		"This game doesn't exist (anymore).": "Dieses Spiel gibt es nicht (mehr).",
+
+		// image rounds
+		"Write a short, witty caption for a picture showing: %s": "Schreib eine kurze, witzige Bildunterschrift zu einem Bild, auf dem Folgendes zu sehen ist: %s",
+
+		// awards
+		"Fooled everyone":   "Alle reingelegt",
+		"AI detective":      "KI-Detektiv:in",
+		"Biggest liar":      "Größte:r Lügner:in",
+		"Most often fooled": "Am häufigsten reingefallen",
+		"Fastest writer":    "Schnellste Feder",
+
+		// errors
+		"Session not found": "Session nicht gefunden",
+		"Session is full":   "Die Session ist voll",
+		"Too many games are running, please try again later": "Gerade laufen zu viele Spiele, bitte versuche es später noch einmal",
+		"Invalid host token":                                "Ungültiger Host-Token",
+		"Invalid player token":                              "Ungültiger Spieler-Token",
+		"Only spectators can cast audience votes":           "Nur Zuschauende können als Publikum abstimmen",
+		"Only the host can regenerate the AI answer":        "Nur der Host kann die KI-Antwort neu erzeugen",
+		"Please choose a different name":                    "Bitte wähle einen anderen Namen",
+		"Name is claimed by a profile with a different PIN": "Der Name gehört zu einem Profil mit anderer PIN",
+		"PINs need at least %d characters":                  "PINs brauchen mindestens %d Zeichen",
+		"Too many wrong PINs, please try again later":       "Zu viele falsche PINs, bitte versuche es später noch einmal",
+		"Your answer contains a blocked word":               "Deine Antwort enthält ein gesperrtes Wort",
+		"Answers may be at most %d characters":              "Antworten dürfen höchstens %d Zeichen lang sein",
+		"Skipping phases is only possible in rehearsals":    "Phasen überspringen geht nur im Probelauf",
+		"Content was flagged by moderation":                 "Der Text wurde von der Moderation blockiert",
+		"Internal error":                                    "Interner Fehler",
+		"Too many requests, please slow down":               "Zu viele Anfragen, bitte etwas langsamer",
+		"Too many join attempts, please try again later":    "Zu viele Beitrittsversuche, bitte versuche es später noch einmal",
+		"Image rounds need an image provider":               "Bildrunden brauchen einen Bildgenerator",
+	},
+}
@@ -0,0 +1,62 @@
+// Package i18n translates the few texts the server shows players itself:
+// the AI's default system prompt, signage, award titles and error messages.
+// Messages are written in English and double as their own catalog keys, so
+// a message without a translation is shown as is.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Default is the language of sessions that don't set one. The game started
+// out at German events, which is what players there still expect.
+const Default = "de"
+
+// Supported lists the languages with a catalog, English being the source.
+var Supported = []string{"de", "en"}
+
+// Normalize maps a language tag like "en-US" to a supported language,
+// falling back to Default.
+func Normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	for _, l := range Supported {
+		if l == lang {
+			return l
+		}
+	}
+	return Default
+}
+
+// Negotiate picks a language from an Accept-Language header, for requests
+// not tied to a session. Quality values are ignored; browsers list the
+// preferred language first anyway.
+func Negotiate(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(part, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if i := strings.IndexAny(tag, "-_"); i >= 0 {
+			tag = tag[:i]
+		}
+		for _, l := range Supported {
+			if l == tag {
+				return l
+			}
+		}
+	}
+	return Default
+}
+
+// T translates msg into lang and formats it with args, if any.
+func T(lang, msg string, args ...any) string {
+	if tr, ok := catalog[Normalize(lang)][msg]; ok {
+		msg = tr
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
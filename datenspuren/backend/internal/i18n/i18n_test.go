@@ -0,0 +1,38 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestT(t *testing.T) {
+	if got := T("", "Round %d of %d", 2, 5); got != "Runde 2 von 5" {
+		t.Fatalf("expected the default language to be German, got %q", got)
+	}
+	if got := T("en-GB", "Round %d of %d", 2, 5); got != "Round 2 of 5" {
+		t.Fatalf("expected English, got %q", got)
+	}
+	if got := T("de", "Something new"); got != "Something new" {
+		t.Fatalf("expected untranslated messages as is, got %q", got)
+	}
+	for lang, msgs := range catalog {
+		for msg, tr := range msgs {
+			if strings.Count(msg, "%") != strings.Count(tr, "%") {
+				t.Fatalf("expected %s translation of %q to keep its verbs, got %q", lang, msg, tr)
+			}
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	for header, want := range map[string]string{
+		"":                          "de",
+		"en-US,en;q=0.9":            "en",
+		"fr-FR, de;q=0.8, en;q=0.5": "de",
+		"ja":                        "de",
+	} {
+		if got := Negotiate(header); got != want {
+			t.Fatalf("expected %q for %q, got %q", want, header, got)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// timeout bounds every wait for an ack or event
+const timeout = 5 * time.Second
+
+type event struct {
+	Name string
+	Data json.RawMessage
+}
+
+// client is a minimal Socket.IO v2 (Engine.IO v3) client speaking the same
+// websocket protocol as the browser client.
+type client struct {
+	t      *testing.T
+	conn   *websocket.Conn
+	wmu    sync.Mutex // serializes writes
+	amu    sync.Mutex
+	nextID int
+	acks   map[int]chan json.RawMessage
+	events chan event
+	done   chan struct{}
+}
+
+// dial connects to the server at base (an http:// URL) and waits until the
+// default namespace is joined.
+func dial(t *testing.T, base string) *client {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(base, "http") + "/socket.io/?EIO=3&transport=websocket"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("should be able to connect: %v", err)
+	}
+	c := &client{t: t, conn: conn, acks: map[int]chan json.RawMessage{}, events: make(chan event, 256), done: make(chan struct{})}
+	connected := make(chan struct{})
+	go c.read(connected)
+	select {
+	case <-connected:
+	case <-time.After(timeout):
+		t.Fatal("timed out waiting for the namespace connect")
+	}
+	t.Cleanup(c.close)
+	return c
+}
+
+func (c *client) read(connected chan struct{}) {
+	defer close(c.done)
+	for {
+		_, msg, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		packet := string(msg)
+		switch {
+		case packet == "40":
+			close(connected)
+		case strings.HasPrefix(packet, "42"):
+			var args []json.RawMessage
+			if json.Unmarshal([]byte(packet[2:]), &args) != nil || len(args) == 0 {
+				continue
+			}
+			ev := event{}
+			json.Unmarshal(args[0], &ev.Name)
+			if len(args) > 1 {
+				ev.Data = args[1]
+			}
+			select {
+			case c.events <- ev:
+			default: // nobody is listening for this many events
+			}
+		case strings.HasPrefix(packet, "43"):
+			body := packet[2:]
+			i := strings.IndexByte(body, '[')
+			if i < 0 {
+				continue
+			}
+			id, _ := strconv.Atoi(body[:i])
+			var args []json.RawMessage
+			json.Unmarshal([]byte(body[i:]), &args)
+			c.amu.Lock()
+			ch := c.acks[id]
+			delete(c.acks, id)
+			c.amu.Unlock()
+			if ch != nil && len(args) > 0 {
+				ch <- args[0]
+			}
+		}
+	}
+}
+
+func (c *client) close() {
+	c.conn.Close()
+	<-c.done
+}
+
+// emit sends an event and returns the server's ack, decoded into a map.
+// It is safe to call from other goroutines; failures are reported with
+// Errorf and a nil ack.
+func (c *client) emit(name string, payload any) map[string]any {
+	c.t.Helper()
+	c.amu.Lock()
+	c.nextID++
+	id := c.nextID
+	ch := make(chan json.RawMessage, 1)
+	c.acks[id] = ch
+	c.amu.Unlock()
+
+	args := []any{name}
+	if payload != nil {
+		args = append(args, payload)
+	}
+	b, _ := json.Marshal(args)
+	c.wmu.Lock()
+	err := c.conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("42%d%s", id, b)))
+	c.wmu.Unlock()
+	if err != nil {
+		c.t.Errorf("should be able to send %s: %v", name, err)
+		return nil
+	}
+	select {
+	case raw := <-ch:
+		var ack map[string]any
+		json.Unmarshal(raw, &ack)
+		return ack
+	case <-time.After(timeout):
+		c.t.Errorf("timed out waiting for the ack of %s", name)
+		return nil
+	}
+}
+
+// waitFor returns the data of the next event called name for which match
+// returns true, skipping everything else.
+func (c *client) waitFor(name string, match func(data map[string]any) bool) map[string]any {
+	c.t.Helper()
+	deadline := time.After(timeout)
+	for {
+		select {
+		case ev := <-c.events:
+			if ev.Name != name {
+				continue
+			}
+			var data map[string]any
+			json.Unmarshal(ev.Data, &data)
+			if match == nil || match(data) {
+				return data
+			}
+		case <-deadline:
+			c.t.Fatalf("timed out waiting for %s", name)
+			return nil
+		}
+	}
+}
+
+// phase matches game:state events of the given phase.
+func phase(p string) func(map[string]any) bool {
+	return func(data map[string]any) bool { return data["phase"] == p }
+}
@@ -0,0 +1,4 @@
+// Package integration holds end-to-end tests that boot the HTTP and
+// Socket.IO server with a stub AI provider and play complete games over
+// real websocket connections.
+package integration
@@ -0,0 +1,290 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/ai"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/kiliankoe/gptdash/internal/ws"
+)
+
+// stubProvider answers every prompt instantly with the same text.
+type stubProvider struct{}
+
+const aiAnswer = "I am definitely a human."
+
+func (stubProvider) Complete(ctx context.Context, model, prompt string) (string, error) {
+	return aiAnswer, nil
+}
+
+func (stubProvider) CompleteWithSystem(ctx context.Context, model, systemPrompt, prompt string) (string, error) {
+	return aiAnswer, nil
+}
+
+func (stubProvider) CompleteDetailed(ctx context.Context, model, systemPrompt, prompt string) (ai.Completion, error) {
+	return ai.Completion{Text: aiAnswer, FinishReason: "stop"}, nil
+}
+
+// boot starts the server with the stub provider and returns its URL.
+func boot(t *testing.T) (string, *game.RoomManager) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	srv := ws.New(rm, config.Config{})
+	srv.SetProvider(stubProvider{})
+	r := gin.New()
+	srv.Mount(r)
+	ts := httptest.NewServer(r)
+	t.Cleanup(ts.Close)
+	return ts.URL, rm
+}
+
+// host creates a session over the socket and returns the host client and
+// the session code and host token.
+func host(t *testing.T, url string, cfg game.SessionConfig) (*client, string, string) {
+	t.Helper()
+	h := dial(t, url)
+	ack := h.emit("game:create", map[string]any{"config": cfg})
+	code, _ := ack["sessionCode"].(string)
+	token, _ := ack["hostToken"].(string)
+	if code == "" || token == "" {
+		t.Fatalf("should be able to create a session, got %v", ack)
+	}
+	return h, code, token
+}
+
+// join connects a player and returns the client and the player token.
+func join(t *testing.T, url, code, name string) (*client, string) {
+	t.Helper()
+	p := dial(t, url)
+	ack := p.emit("game:join", map[string]any{"sessionCode": code, "name": name})
+	token, _ := ack["playerToken"].(string)
+	if token == "" {
+		t.Fatalf("%s should be able to join, got %v", name, ack)
+	}
+	return p, token
+}
+
+func TestFullGame(t *testing.T) {
+	url, _ := boot(t)
+	h, code, _ := host(t, url, game.SessionConfig{Provider: "openai", Model: "stub", RoundCount: 1})
+	alice, _ := join(t, url, code, "Alice")
+	bob, _ := join(t, url, code, "Bob")
+
+	if ack := h.emit("game:setPrompt", map[string]any{"prompt": "Who are you?"}); ack["error"] != nil {
+		t.Fatalf("should be able to set the prompt: %v", ack)
+	}
+	alice.waitFor("game:state", phase("Answering"))
+	h.waitFor("game:aiAnswer", nil)
+	aliceSub := alice.emit("game:submit", map[string]any{"text": "Alice's answer"})["submissionId"]
+	bobSub := bob.emit("game:submit", map[string]any{"text": "Bob's answer"})["submissionId"]
+	if aliceSub == nil || bobSub == nil {
+		t.Fatal("players should be able to submit")
+	}
+
+	h.emit("game:advance", nil)
+	voting := alice.waitFor("game:voting", nil)
+	subs, _ := voting["submissions"].([]any)
+	if len(subs) != 3 {
+		t.Fatalf("expected both answers and the AI's to vote on, got %v", voting)
+	}
+	var aiSub any
+	for _, s := range subs {
+		if s := s.(map[string]any); s["text"] == aiAnswer {
+			aiSub = s["id"]
+		}
+	}
+	if ack := alice.emit("game:vote", map[string]any{"submissionId": aiSub}); ack["error"] != nil {
+		t.Fatalf("Alice should be able to vote: %v", ack)
+	}
+	if ack := bob.emit("game:vote", map[string]any{"submissionId": aliceSub}); ack["error"] != nil {
+		t.Fatalf("Bob should be able to vote: %v", ack)
+	}
+
+	h.emit("game:advance", nil)
+	results := bob.waitFor("game:results", nil)
+	if results["aiSubmissionId"] != aiSub {
+		t.Fatalf("expected the AI answer to be revealed, got %v", results["aiSubmissionId"])
+	}
+	state := alice.waitFor("game:state", phase("Scoreboard"))
+	scores, _ := state["scores"].([]any)
+	if len(scores) != 2 {
+		t.Fatalf("expected scores for both players, got %v", state["scores"])
+	}
+
+	h.emit("game:advance", nil)
+	summary := alice.waitFor("game:summary", nil)
+	if rounds, _ := summary["rounds"].([]any); len(rounds) != 1 {
+		t.Fatalf("expected a one-round summary, got %v", summary)
+	}
+}
+
+func TestResume(t *testing.T) {
+	url, _ := boot(t)
+	h, code, hostToken := host(t, url, game.SessionConfig{Provider: "manual", RoundCount: 1})
+	alice, aliceToken := join(t, url, code, "Alice")
+	h.emit("game:setPrompt", map[string]any{"prompt": "Who are you?"})
+	alice.waitFor("game:state", phase("Answering"))
+	alice.close()
+
+	// a fresh connection picks up where the old one left off
+	alice = dial(t, url)
+	if ack := alice.emit("game:resume", map[string]any{"sessionCode": code, "role": "player", "token": "wrong"}); ack["error"] == nil {
+		t.Fatal("expected resuming with a wrong token to fail")
+	}
+	if ack := alice.emit("game:resume", map[string]any{"sessionCode": code, "role": "player", "token": aliceToken}); ack["error"] != nil {
+		t.Fatalf("Alice should be able to resume: %v", ack)
+	}
+	state := alice.waitFor("game:state", phase("Answering"))
+	if you, _ := state["you"].(map[string]any); you["playerId"] == nil {
+		t.Fatalf("expected the resumed state to identify Alice, got %v", state["you"])
+	}
+	if ack := alice.emit("game:submit", map[string]any{"text": "Back again"}); ack["error"] != nil {
+		t.Fatalf("Alice should be able to submit after resuming: %v", ack)
+	}
+
+	h.close()
+	h = dial(t, url)
+	if ack := h.emit("game:resume", map[string]any{"sessionCode": code, "role": "host", "token": hostToken}); ack["error"] != nil {
+		t.Fatalf("the host should be able to resume: %v", ack)
+	}
+	if ack := h.emit("game:advance", nil); ack["error"] != nil {
+		t.Fatalf("the resumed host should be able to advance: %v", ack)
+	}
+	alice.waitFor("game:state", phase("Voting"))
+}
+
+func TestDisconnects(t *testing.T) {
+	url, rm := boot(t)
+	h, code, _ := host(t, url, game.SessionConfig{Provider: "manual", RoundCount: 1})
+	viewer := dial(t, url)
+	viewer.emit("game:spectate", map[string]any{"sessionCode": code})
+	h.waitFor("game:state", func(data map[string]any) bool { return data["spectators"] == float64(1) })
+	viewer.close()
+	h.waitFor("game:state", func(data map[string]any) bool { return data["spectators"] == float64(0) })
+
+	alice, _ := join(t, url, code, "Alice")
+	bob, _ := join(t, url, code, "Bob")
+	h.emit("game:setPrompt", map[string]any{"prompt": "Who are you?"})
+	alice.waitFor("game:state", phase("Answering"))
+	alice.emit("game:submit", map[string]any{"text": "Alice's answer"})
+	alice.close()
+
+	// the game goes on without Alice's socket, and her answer stays
+	bob.emit("game:submit", map[string]any{"text": "Bob's answer"})
+	h.emit("game:advance", nil)
+	voting := bob.waitFor("game:voting", nil)
+	if subs, _ := voting["submissions"].([]any); len(subs) != 2 {
+		t.Fatalf("expected both answers after Alice disconnected, got %v", voting)
+	}
+	sess, _ := rm.Get(code)
+	if n := len(sess.Players()); n != 2 {
+		t.Fatalf("expected Alice to stay in the game, got %d players", n)
+	}
+}
+
+func TestConcurrentSubmissions(t *testing.T) {
+	const players = 20
+	url, rm := boot(t)
+	h, code, _ := host(t, url, game.SessionConfig{Provider: "manual", RoundCount: 1})
+	clients := make([]*client, players)
+	for i := range clients {
+		clients[i], _ = join(t, url, code, fmt.Sprintf("Player %d", i+1))
+	}
+	h.emit("game:setPrompt", map[string]any{"prompt": "Who are you?"})
+	for _, c := range clients {
+		c.waitFor("game:state", phase("Answering"))
+	}
+
+	var wg sync.WaitGroup
+	for i, c := range clients {
+		wg.Add(1)
+		go func(i int, c *client) {
+			defer wg.Done()
+			if ack := c.emit("game:submit", map[string]any{"text": fmt.Sprintf("Answer %d", i)}); ack["submissionId"] == nil {
+				t.Errorf("player %d should be able to submit: %v", i+1, ack)
+			}
+		}(i, c)
+	}
+	wg.Wait()
+
+	sess, _ := rm.Get(code)
+	if n := sess.HumanSubmissionCount(); n != players {
+		t.Fatalf("expected %d answers, got %d", players, n)
+	}
+	h.waitFor("game:submissions", func(data map[string]any) bool { return data["count"] == float64(players) })
+}
+
+func TestHiddenVoteCounts(t *testing.T) {
+	url, _ := boot(t)
+	h, code, _ := host(t, url, game.SessionConfig{Provider: "openai", Model: "stub", RoundCount: 1, HideVoteCounts: true})
+	alice, _ := join(t, url, code, "Alice")
+	bob, _ := join(t, url, code, "Bob")
+	h.emit("game:setPrompt", map[string]any{"prompt": "Who are you?"})
+	h.waitFor("game:aiAnswer", nil)
+	aliceSub := alice.emit("game:submit", map[string]any{"text": "Alice's answer"})["submissionId"]
+	bob.emit("game:submit", map[string]any{"text": "Bob's answer"})
+	h.emit("game:advance", nil)
+	var aiSub any
+	for _, s := range alice.waitFor("game:voting", nil)["submissions"].([]any) {
+		if s := s.(map[string]any); s["text"] == aiAnswer {
+			aiSub = s["id"]
+		}
+	}
+	alice.emit("game:vote", map[string]any{"submissionId": aiSub})
+	bob.emit("game:vote", map[string]any{"submissionId": aliceSub})
+	h.emit("game:advance", nil)
+
+	// results go out on every transition, the reveal's follow the scoreboard state
+	for _, c := range []*client{h, alice, bob} {
+		c.waitFor("game:state", phase("Scoreboard"))
+	}
+	results := alice.waitFor("game:results", nil)
+	if results["voteCounts"] != nil || results["foundAI"] != true {
+		t.Fatalf("expected Alice to only learn that she found the AI, got %v", results)
+	}
+	if votes, _ := results["votes"].([]any); len(votes) != 1 {
+		t.Fatalf("expected Alice to only see her own vote, got %v", results["votes"])
+	}
+	if results := bob.waitFor("game:results", nil); results["foundAI"] != false {
+		t.Fatalf("expected Bob to learn that he missed the AI, got %v", results)
+	}
+	if results := h.waitFor("game:results", nil); results["voteCounts"] == nil {
+		t.Fatalf("expected the host to still see the vote counts, got %v", results)
+	}
+}
+
+func TestConnectivity(t *testing.T) {
+	url, _ := boot(t)
+	h, code, _ := host(t, url, game.SessionConfig{Provider: "manual", RoundCount: 1})
+	alice, _ := join(t, url, code, "Alice")
+	if ack := alice.emit("net:ping", map[string]any{"rtt": 1500}); ack["error"] != nil {
+		t.Fatalf("Alice should be able to ping: %v", ack)
+	}
+	h.waitFor("game:connectivity", func(data map[string]any) bool {
+		for _, c := range data {
+			if c := c.(map[string]any); c["quality"] == "poor" && c["rttMs"] == float64(1500) {
+				return true
+			}
+		}
+		return false
+	})
+	ack := h.emit("net:ping", map[string]any{"rtt": 20})
+	if conn, _ := ack["connectivity"].(map[string]any); len(conn) != 1 {
+		t.Fatalf("expected the host's ping to report Alice's connection, got %v", ack)
+	}
+
+	alice.close()
+	h.waitFor("game:connectivity", func(data map[string]any) bool {
+		for _, c := range data {
+			return c.(map[string]any)["quality"] == "offline"
+		}
+		return false
+	})
+}
@@ -0,0 +1,94 @@
+package moderation
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ErrBlocked is returned in reject mode for text containing a listed word.
+var ErrBlocked = errors.New("text contains a blocked word")
+
+type Mode string
+
+const (
+	ModeReplace Mode = "replace" // mask blocked words with asterisks
+	ModeReject  Mode = "reject"  // refuse the whole text
+)
+
+// Filter checks player names and answers against a wordlist. Words match
+// case-insensitively anywhere in the text, so "xXbadwordXx" is caught too.
+// A nil Filter lets everything through.
+type Filter struct {
+	Mode  Mode
+	words [][]rune // lower case
+}
+
+func New(words []string, mode Mode) (*Filter, error) {
+	if mode != ModeReplace && mode != ModeReject {
+		return nil, fmt.Errorf("unknown filter mode %q", mode)
+	}
+	f := &Filter{Mode: mode}
+	for _, w := range words {
+		if w = strings.TrimSpace(w); w != "" {
+			f.words = append(f.words, []rune(strings.Map(unicode.ToLower, w)))
+		}
+	}
+	return f, nil
+}
+
+// Load reads a wordlist with one word per line. Empty lines and lines
+// starting with # are skipped.
+func Load(path string, mode Mode) (*Filter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var words []string
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		if line := strings.TrimSpace(sc.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			words = append(words, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return New(words, mode)
+}
+
+// Apply returns text with blocked words masked, or ErrBlocked in reject mode.
+func (f *Filter) Apply(text string) (string, error) {
+	if f == nil || len(f.words) == 0 {
+		return text, nil
+	}
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	found := false
+	for _, w := range f.words {
+		for i := 0; i+len(w) <= len(lower); i++ {
+			if !slices.Equal(lower[i:i+len(w)], w) {
+				continue
+			}
+			found = true
+			for k := i; k < i+len(w); k++ {
+				runes[k] = '*'
+			}
+		}
+	}
+	if !found {
+		return text, nil
+	}
+	if f.Mode == ModeReject {
+		return "", ErrBlocked
+	}
+	return string(runes), nil
+}
@@ -0,0 +1,26 @@
+package moderation
+
+import "testing"
+
+func TestFilter(t *testing.T) {
+	replace, _ := New([]string{"Darn", "# not a comment here", " heck "}, ModeReplace)
+	if got, _ := replace.Apply("Oh DARN it, xXheckXx"); got != "Oh **** it, xX****Xx" {
+		t.Fatalf("expected blocked words to be masked, got %q", got)
+	}
+	if got, err := replace.Apply("All good"); err != nil || got != "All good" {
+		t.Fatalf("expected clean text to pass unchanged, got %q %v", got, err)
+	}
+
+	reject, _ := New([]string{"darn"}, ModeReject)
+	if _, err := reject.Apply("darnit"); err != ErrBlocked {
+		t.Fatalf("expected ErrBlocked, got %v", err)
+	}
+
+	var none *Filter
+	if got, err := none.Apply("darn"); err != nil || got != "darn" {
+		t.Fatalf("expected a nil filter to let everything through, got %q %v", got, err)
+	}
+	if _, err := New(nil, "shout"); err == nil {
+		t.Fatalf("expected an unknown mode to be refused")
+	}
+}
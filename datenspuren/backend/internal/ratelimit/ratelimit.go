@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter allows up to Burst events per key within each Window.
+type Limiter struct {
+	Burst  int
+	Window time.Duration
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	sweptAt time.Time
+}
+
+type bucket struct {
+	start time.Time
+	count int
+}
+
+func New(burst int, window time.Duration) *Limiter {
+	return &Limiter{Burst: burst, Window: window, buckets: make(map[string]*bucket)}
+}
+
+// Allow records an event for key and reports whether it is within the limit.
+func (l *Limiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.sweep(now)
+	b := l.buckets[key]
+	if b == nil || now.Sub(b.start) >= l.Window {
+		l.buckets[key] = &bucket{start: now, count: 1}
+		return true
+	}
+	if b.count >= l.Burst {
+		return false
+	}
+	b.count++
+	return true
+}
+
+// Exhausted reports whether key has used up its Burst within the current
+// window, without recording an event.
+func (l *Limiter) Exhausted(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.buckets[key]
+	return b != nil && time.Since(b.start) < l.Window && b.count >= l.Burst
+}
+
+// sweep drops expired buckets so the map doesn't grow with every client ever
+// seen. Callers must hold l.mu.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.sweptAt) < l.Window {
+		return
+	}
+	for k, b := range l.buckets {
+		if now.Sub(b.start) >= l.Window {
+			delete(l.buckets, k)
+		}
+	}
+	l.sweptAt = now
+}
+
+// Bucket is a token bucket per key: a key may spend up to Burst events at
+// once, refilled at Rate events per second. Unlike Limiter it smooths bursts
+// instead of resetting at window boundaries.
+type Bucket struct {
+	Rate  float64
+	Burst int
+
+	mu     sync.Mutex
+	tokens map[string]*tokens
+}
+
+type tokens struct {
+	left float64
+	at   time.Time
+}
+
+func NewBucket(rate float64, burst int) *Bucket {
+	return &Bucket{Rate: rate, Burst: max(burst, 1), tokens: make(map[string]*tokens)}
+}
+
+// Allow takes a token for key and reports whether one was left.
+func (b *Bucket) Allow(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	t := b.tokens[key]
+	if t == nil {
+		t = &tokens{left: float64(b.Burst), at: now}
+		b.tokens[key] = t
+	}
+	t.left = min(float64(b.Burst), t.left+now.Sub(t.at).Seconds()*b.Rate)
+	t.at = now
+	if t.left < 1 {
+		return false
+	}
+	t.left--
+	return true
+}
+
+// Forget drops key's bucket, e.g. once its connection is gone.
+func (b *Bucket) Forget(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.tokens, key)
+}
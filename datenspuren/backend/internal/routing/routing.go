@@ -0,0 +1,154 @@
+// Package routing pins every session to one instance of a multi-instance
+// deployment without shared state: all instances are configured with the
+// same peer list and hash session codes and join PINs onto it, so they agree
+// on which instance owns a session. Sockets opened before the code is known
+// ask /api/route/:code and reconnect with it.
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ForwardedHeader marks requests already forwarded by a peer; they are
+// always served locally so a disagreeing peer list can't cause loops.
+const ForwardedHeader = "X-GPTdash-Forwarded"
+
+// Routing modes for requests that reach an instance not owning the session
+const (
+	ModeForward  = "forward"  // proxy the request, including WebSockets
+	ModeRedirect = "redirect" // answer with a 307 to the owner
+)
+
+// virtual nodes per instance, to spread sessions evenly
+const replicas = 64
+
+type Instance struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// Ring is a consistent-hash ring of instances.
+type Ring struct {
+	self      Instance
+	instances map[string]Instance
+	proxies   map[string]*httputil.ReverseProxy
+	points    []uint32
+	owners    map[uint32]string
+}
+
+// Parse builds the ring from a peer list like "a=http://10.0.0.1:8080,b=http://10.0.0.2:8080".
+// self must be one of the listed instance IDs.
+func Parse(self, peers string) (*Ring, error) {
+	r := &Ring{instances: map[string]Instance{}, proxies: map[string]*httputil.ReverseProxy{}, owners: map[uint32]string{}}
+	for _, entry := range strings.Split(peers, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		id, raw, ok := strings.Cut(entry, "=")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid instance %q, expected id=url", entry)
+		}
+		u, err := url.Parse(raw)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid URL for instance %q", id)
+		}
+		if _, dup := r.instances[id]; dup {
+			return nil, fmt.Errorf("duplicate instance %q", id)
+		}
+		r.instances[id] = Instance{ID: id, URL: strings.TrimSuffix(raw, "/")}
+		r.proxies[id] = httputil.NewSingleHostReverseProxy(u)
+		for i := 0; i < replicas; i++ {
+			p := crc32.ChecksumIEEE([]byte(id + "#" + strconv.Itoa(i)))
+			r.points = append(r.points, p)
+			r.owners[p] = id
+		}
+	}
+	if len(r.instances) == 0 {
+		return nil, errors.New("no instances configured")
+	}
+	var ok bool
+	if r.self, ok = r.instances[self]; !ok {
+		return nil, fmt.Errorf("own instance %q is not in the instance list", self)
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+	return r, nil
+}
+
+func (r *Ring) Self() Instance { return r.self }
+
+// Owner returns the instance responsible for a session code or join PIN.
+func (r *Ring) Owner(code string) Instance {
+	h := crc32.ChecksumIEEE([]byte(strings.ToUpper(code)))
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.instances[r.owners[r.points[i]]]
+}
+
+// Local reports whether this instance owns the session.
+func (r *Ring) Local(code string) bool {
+	return r.Owner(code).ID == r.self.ID
+}
+
+// SessionCode extracts the session a request is about: the "code" query
+// parameter (used by the Socket.IO handshake), the X-Session-Code header
+// (for API clients) or the code in /api/session/:code/... and
+// /api/host/sessions/:code/... paths.
+func SessionCode(req *http.Request) string {
+	if code := req.URL.Query().Get("code"); code != "" {
+		return code
+	}
+	if code := req.Header.Get("X-Session-Code"); code != "" {
+		return code
+	}
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for i := 1; i+1 < len(parts); i++ {
+		if parts[i-1] == "api" && parts[i] == "session" || parts[i-1] == "host" && parts[i] == "sessions" {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// Middleware hands requests for sessions owned by another instance over to
+// that instance, either by proxying them or by redirecting the client.
+func (r *Ring) Middleware(mode string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("X-GPTdash-Instance", r.self.ID)
+		code := SessionCode(c.Request)
+		if code == "" || c.GetHeader(ForwardedHeader) != "" || r.Local(code) {
+			c.Next()
+			return
+		}
+		owner := r.Owner(code)
+		if mode == ModeRedirect {
+			c.Redirect(http.StatusTemporaryRedirect, owner.URL+c.Request.URL.RequestURI())
+			c.Abort()
+			return
+		}
+		c.Request.Header.Set(ForwardedHeader, r.self.ID)
+		r.proxies[owner.ID].ServeHTTP(c.Writer, c.Request)
+		c.Abort()
+	}
+}
+
+// Handler answers where a session lives, for load balancers and clients
+// that want to connect to the owner directly.
+func (r *Ring) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		code := strings.ToUpper(c.Param("code"))
+		owner := r.Owner(code)
+		c.JSON(http.StatusOK, gin.H{"code": code, "instance": owner.ID, "url": owner.URL, "local": owner.ID == r.self.ID})
+	}
+}
@@ -0,0 +1,111 @@
+package routing
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParse(t *testing.T) {
+	for _, peers := range []string{"", "a", "a=not-a-url", "a=http://a,a=http://b"} {
+		if _, err := Parse("a", peers); err == nil {
+			t.Fatalf("expected an error for %q", peers)
+		}
+	}
+	if _, err := Parse("c", "a=http://a,b=http://b"); err == nil {
+		t.Fatal("expected an error when the own instance isn't listed")
+	}
+}
+
+func TestOwnerIsSharedAndSpread(t *testing.T) {
+	a, _ := Parse("a", "a=http://a:8080, b=http://b:8080,c=http://c:8080")
+	b, _ := Parse("b", "c=http://c:8080,a=http://a:8080,b=http://b:8080")
+	counts := map[string]int{}
+	for _, code := range []string{"ABCDE", "FGHJK", "LMNPQ", "RSTUV", "WXYZ2", "34567", "89ABC", "DEFGH", "JKLMN", "PQRST", "UVWXY", "Z2345"} {
+		if a.Owner(code) != b.Owner(code) {
+			t.Fatalf("instances disagree on the owner of %s", code)
+		}
+		if a.Owner(code) != a.Owner(code[:2]+string(code[2]|0x20)+code[3:]) {
+			t.Fatalf("owner of %s must not depend on case", code)
+		}
+		counts[a.Owner(code).ID]++
+	}
+	if len(counts) < 2 {
+		t.Fatalf("expected sessions to spread over instances, got %v", counts)
+	}
+}
+
+func TestSessionCode(t *testing.T) {
+	cases := map[string]string{
+		"/socket.io/?EIO=3&transport=polling&code=ABCDE": "ABCDE",
+		"/api/session/ABCDE/lobby":                       "ABCDE",
+		"/api/host/sessions/ABCDE/connections":           "ABCDE",
+		"/api/sessions/public":                           "",
+		"/play/ABCDE":                                    "",
+	}
+	for target, want := range cases {
+		if got := SessionCode(httptest.NewRequest(http.MethodGet, target, nil)); got != want {
+			t.Fatalf("%s: expected %q, got %q", target, want, got)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	owner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("owner:" + r.Header.Get(ForwardedHeader)))
+	}))
+	defer owner.Close()
+	ring, err := Parse("self", "self=http://self.invalid,other="+owner.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := "AAAAA"
+	for ring.Local(code) {
+		code = string(rune(code[0]+1)) + code[1:]
+	}
+
+	// don't follow redirects, we want to see them
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	get := func(url string, forwarded bool) (*http.Response, string) {
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		if forwarded {
+			req.Header.Set(ForwardedHeader, "other")
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return res, string(body)
+	}
+
+	for _, mode := range []string{ModeForward, ModeRedirect} {
+		r := gin.New()
+		r.Use(ring.Middleware(mode))
+		r.GET("/api/session/:code/lobby", func(c *gin.Context) { c.String(http.StatusOK, "local") })
+		self := httptest.NewServer(r)
+		defer self.Close()
+
+		res, body := get(self.URL+"/api/session/"+code+"/lobby", false)
+		switch mode {
+		case ModeForward:
+			if body != "owner:self" {
+				t.Fatalf("expected the request to be forwarded, got %q", body)
+			}
+		case ModeRedirect:
+			if res.StatusCode != http.StatusTemporaryRedirect || res.Header.Get("Location") != owner.URL+"/api/session/"+code+"/lobby" {
+				t.Fatalf("expected a redirect to the owner, got %d %q", res.StatusCode, res.Header.Get("Location"))
+			}
+		}
+
+		// forwarded requests are never passed on again
+		if _, body := get(self.URL+"/api/session/"+code+"/lobby", true); body != "local" {
+			t.Fatalf("expected a forwarded request to be served locally, got %q", body)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Package sioclient is a minimal Socket.IO v2 (Engine.IO v3) client speaking
+// the same websocket protocol as the browser client. It drives scripted
+// games against a running server, see the simulate command and
+// cmd/loadtest.
+package sioclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Event is an event the server emitted, stamped with when it arrived.
+type Event struct {
+	Name string
+	Data json.RawMessage
+	At   time.Time
+}
+
+// Decode unmarshals the event's payload into v.
+func (e Event) Decode(v any) error {
+	return json.Unmarshal(e.Data, v)
+}
+
+// ErrClosed is returned for acks that can't arrive anymore.
+var ErrClosed = errors.New("connection closed")
+
+type Client struct {
+	conn    *websocket.Conn
+	wmu     sync.Mutex // serializes writes
+	amu     sync.Mutex
+	nextID  int
+	acks    map[int]chan json.RawMessage
+	events  chan Event
+	dropped atomic.Int64
+	done    chan struct{}
+}
+
+// Dial connects to the server at base (an http:// or https:// URL) and waits
+// until the default namespace is joined. Events are buffered up to buffer;
+// beyond that they are dropped and counted, see Dropped.
+func Dial(ctx context.Context, base string, buffer int) (*Client, error) {
+	url := "ws" + strings.TrimPrefix(strings.TrimRight(base, "/"), "http") + "/socket.io/?EIO=3&transport=websocket"
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{conn: conn, acks: map[int]chan json.RawMessage{}, events: make(chan Event, buffer), done: make(chan struct{})}
+	connected := make(chan struct{})
+	go c.read(connected)
+	select {
+	case <-connected:
+		return c, nil
+	case <-c.done:
+		return nil, ErrClosed
+	case <-ctx.Done():
+		c.Close()
+		return nil, ctx.Err()
+	}
+}
+
+func (c *Client) read(connected chan struct{}) {
+	defer close(c.done)
+	for {
+		_, msg, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		packet := string(msg)
+		switch {
+		case strings.HasPrefix(packet, "0"):
+			// handshake: the server expects a ping every pingInterval
+			var open struct {
+				PingInterval int `json:"pingInterval"`
+			}
+			json.Unmarshal([]byte(packet[1:]), &open)
+			if open.PingInterval > 0 {
+				go c.ping(time.Duration(open.PingInterval) * time.Millisecond)
+			}
+		case packet == "40":
+			close(connected)
+		case strings.HasPrefix(packet, "42"):
+			var args []json.RawMessage
+			if json.Unmarshal([]byte(packet[2:]), &args) != nil || len(args) == 0 {
+				continue
+			}
+			ev := Event{At: time.Now()}
+			json.Unmarshal(args[0], &ev.Name)
+			if len(args) > 1 {
+				ev.Data = args[1]
+			}
+			select {
+			case c.events <- ev:
+			default:
+				c.dropped.Add(1)
+			}
+		case strings.HasPrefix(packet, "43"):
+			body := packet[2:]
+			i := strings.IndexByte(body, '[')
+			if i < 0 {
+				continue
+			}
+			id, _ := strconv.Atoi(body[:i])
+			var args []json.RawMessage
+			json.Unmarshal([]byte(body[i:]), &args)
+			c.amu.Lock()
+			ch := c.acks[id]
+			delete(c.acks, id)
+			c.amu.Unlock()
+			if ch != nil && len(args) > 0 {
+				ch <- args[0]
+			}
+		}
+	}
+}
+
+func (c *Client) ping(every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-t.C:
+			if c.write("2") != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) write(packet string) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	return c.conn.WriteMessage(websocket.TextMessage, []byte(packet))
+}
+
+// Emit sends an event and waits for the server's ack, decoded into a map.
+// It is safe to call from several goroutines.
+func (c *Client) Emit(ctx context.Context, name string, payload any) (map[string]any, error) {
+	c.amu.Lock()
+	c.nextID++
+	id := c.nextID
+	ch := make(chan json.RawMessage, 1)
+	c.acks[id] = ch
+	c.amu.Unlock()
+	defer func() {
+		c.amu.Lock()
+		delete(c.acks, id)
+		c.amu.Unlock()
+	}()
+
+	args := []any{name}
+	if payload != nil {
+		args = append(args, payload)
+	}
+	b, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.write(fmt.Sprintf("42%d%s", id, b)); err != nil {
+		return nil, err
+	}
+	select {
+	case raw := <-ch:
+		var ack map[string]any
+		if err := json.Unmarshal(raw, &ack); err != nil {
+			return nil, fmt.Errorf("invalid ack for %s: %w", name, err)
+		}
+		return ack, nil
+	case <-c.done:
+		return nil, ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Events delivers the events the server emits, in order.
+func (c *Client) Events() <-chan Event {
+	return c.events
+}
+
+// WaitFor returns the next event called name for which match returns true,
+// skipping everything else. A nil match takes the first one.
+func (c *Client) WaitFor(ctx context.Context, name string, match func(Event) bool) (Event, error) {
+	for {
+		select {
+		case ev := <-c.events:
+			if ev.Name == name && (match == nil || match(ev)) {
+				return ev, nil
+			}
+		case <-c.done:
+			return Event{}, ErrClosed
+		case <-ctx.Done():
+			return Event{}, fmt.Errorf("waiting for %s: %w", name, ctx.Err())
+		}
+	}
+}
+
+// Dropped counts the events discarded because the buffer was full.
+func (c *Client) Dropped() int64 {
+	return c.dropped.Load()
+}
+
+// Done is closed once the connection is gone.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *Client) Close() error {
+	err := c.conn.Close()
+	<-c.done
+	return err
+}
@@ -0,0 +1,64 @@
+package sioclient
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/kiliankoe/gptdash/internal/ws"
+)
+
+func TestClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := ws.New(game.NewRoomManager(), config.Config{})
+	r := gin.New()
+	srv.Mount(r)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	host, err := Dial(ctx, ts.URL, 16)
+	if err != nil {
+		t.Fatalf("should be able to connect: %v", err)
+	}
+	defer host.Close()
+	ack, err := host.Emit(ctx, "game:create", map[string]any{"config": game.SessionConfig{Provider: "manual"}})
+	if err != nil || ack["sessionCode"] == nil {
+		t.Fatalf("should be able to create a session: %v %v", ack, err)
+	}
+	if _, err := host.WaitFor(ctx, "game:state", nil); err != nil {
+		t.Fatalf("expected the session state: %v", err)
+	}
+
+	// nobody reads the player's events, so they pile up and get dropped
+	player, err := Dial(ctx, ts.URL, 1)
+	if err != nil {
+		t.Fatalf("should be able to connect: %v", err)
+	}
+	defer player.Close()
+	if ack, _ := player.Emit(ctx, "game:join", map[string]any{"sessionCode": "nope", "name": "Alice"}); ack["error"] == nil {
+		t.Fatalf("expected joining an unknown session to fail, got %v", ack)
+	}
+	if ack, err := player.Emit(ctx, "game:spectate", map[string]any{"sessionCode": ack["sessionCode"]}); err != nil || ack["error"] != nil {
+		t.Fatalf("should be able to spectate: %v %v", ack, err)
+	}
+	ev, err := host.WaitFor(ctx, "game:state", func(ev Event) bool {
+		var st struct{ Spectators int }
+		return ev.Decode(&st) == nil && st.Spectators > 0
+	})
+	if err != nil || ev.At.IsZero() {
+		t.Fatalf("expected the host to see the spectator: %v", err)
+	}
+	if ack, err := host.Emit(ctx, "game:setPrompt", map[string]any{"prompt": "Who are you?"}); err != nil || ack["error"] != nil {
+		t.Fatalf("should be able to set the prompt: %v %v", ack, err)
+	}
+	host.WaitFor(ctx, "game:state", nil)
+	if player.Dropped() == 0 {
+		t.Fatal("expected events beyond the buffer to be dropped")
+	}
+}
@@ -0,0 +1,130 @@
+// Package store keeps sessions in a SQLite database, so a crash or redeploy
+// mid-event doesn't lose the running games.
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kiliankoe/gptdash/internal/game"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS sessions (
+	code        TEXT PRIMARY KEY,
+	join_pin    TEXT NOT NULL,
+	created_at  TIMESTAMP NOT NULL,
+	updated_at  TIMESTAMP NOT NULL,
+	phase       TEXT NOT NULL,
+	round_index INTEGER NOT NULL,
+	snapshot    TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS events (
+	code  TEXT NOT NULL REFERENCES sessions(code) ON DELETE CASCADE,
+	seq   INTEGER NOT NULL,
+	event TEXT NOT NULL,
+	PRIMARY KEY (code, seq)
+);
+`
+
+// SQLite implements game.SessionStore. Each batch of events is written in
+// one transaction with the sessions' snapshots, so the two never disagree.
+// Events of ended sessions are dropped, their snapshots kept.
+type SQLite struct {
+	db *sql.DB
+}
+
+var _ game.SessionStore = (*SQLite)(nil)
+
+// Open opens or creates the database at path.
+func Open(path string) (*SQLite, error) {
+	// synchronous=NORMAL is crash-safe in WAL mode and only risks the last
+	// batches on power loss; sessions are saved in batches off their locks
+	db, err := sql.Open("sqlite3", path+"?_journal_mode=WAL&_synchronous=NORMAL&_busy_timeout=5000&_foreign_keys=on")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session store: %w", err)
+	}
+	// batches come from a single goroutine anyway, see game.SessionStore
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create session store schema: %w", err)
+	}
+	// sessions that ended before pruning was in place
+	if _, err := db.Exec(`DELETE FROM events WHERE code IN (SELECT code FROM sessions WHERE phase = ?)`, string(game.PhaseEnd)); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to prune session store: %w", err)
+	}
+	return &SQLite{db: db}, nil
+}
+
+func (st *SQLite) Close() error {
+	return st.db.Close()
+}
+
+// Save appends events to their sessions' logs and replaces their snapshots.
+// A session's log is deleted once it ends, since only unfinished sessions
+// are recovered.
+func (st *SQLite) Save(records []game.StoreRecord) error {
+	tx, err := st.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, r := range records {
+		if err := save(tx, r); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func save(tx *sql.Tx, r game.StoreRecord) error {
+	snap := r.Snapshot
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO sessions (code, join_pin, created_at, updated_at, phase, round_index, snapshot)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (code) DO UPDATE SET updated_at = excluded.updated_at, phase = excluded.phase,
+			round_index = excluded.round_index, snapshot = excluded.snapshot`,
+		r.Code, snap.JoinPin, snap.CreatedAt, snap.UpdatedAt, string(snap.Phase), snap.RoundIndex, string(b))
+	if err != nil {
+		return err
+	}
+	if snap.Phase == game.PhaseEnd {
+		_, err = tx.Exec(`DELETE FROM events WHERE code = ?`, r.Code)
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO events (code, seq, event)
+		VALUES (?, (SELECT COALESCE(MAX(seq), 0) + 1 FROM events WHERE code = ?), ?)`,
+		r.Code, r.Code, string(r.Event))
+	return err
+}
+
+// Unfinished returns the events of every session that hasn't ended.
+func (st *SQLite) Unfinished() (map[string][][]byte, error) {
+	rows, err := st.db.Query(`SELECT e.code, e.event FROM events e
+		JOIN sessions s ON s.code = e.code
+		WHERE s.phase != ?
+		ORDER BY e.code, e.seq`, string(game.PhaseEnd))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string][][]byte{}
+	for rows.Next() {
+		var (
+			code  string
+			event []byte
+		)
+		if err := rows.Scan(&code, &event); err != nil {
+			return nil, err
+		}
+		out[code] = append(out[code], event)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+func TestSQLiteRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.db")
+	st, err := Open(path)
+	if err != nil {
+		t.Fatalf("should be able to open the store: %v", err)
+	}
+	rm := game.NewRoomManager()
+	rm.EnableStore(st, func(code string, err error) { t.Fatalf("saving %s failed: %v", code, err) })
+	code, hostToken, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 2})
+	session, _ := rm.Get(code)
+	_, aliceToken, _ := session.Join("Alice")
+	_, bobToken, _ := session.Join("Bob")
+	session.SetPrompt(hostToken, "First question?")
+	aliceSub, _ := session.Submit(aliceToken, "Alice's answer")
+	session.Submit(bobToken, "Bob's answer")
+	aiID, _ := session.AddAISubmission("AI answer")
+	session.Advance(hostToken) // To Voting
+	session.Vote(aliceToken, aiID)
+	session.Vote(bobToken, aliceSub)
+	session.Advance(hostToken) // To Scoreboard
+
+	// a finished session isn't restored
+	endedCode, endedHost, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1})
+	ended, _ := rm.Get(endedCode)
+	ended.SetPrompt(endedHost, "Only question?")
+	for i := 0; i < 3 && ended.GetPhase() != game.PhaseEnd; i++ {
+		ended.Advance(endedHost)
+	}
+	if ended.GetPhase() != game.PhaseEnd {
+		t.Fatalf("expected the second session to end, got %s", ended.GetPhase())
+	}
+	rm.FlushStore()
+	var endedEvents int
+	st.db.QueryRow(`SELECT COUNT(*) FROM events WHERE code = ?`, endedCode).Scan(&endedEvents)
+	if endedEvents != 0 {
+		t.Fatalf("expected the ended session's events to be pruned, got %d", endedEvents)
+	}
+
+	var raw string
+	if err := st.db.QueryRow(`SELECT snapshot FROM sessions WHERE code = ?`, code).Scan(&raw); err != nil {
+		t.Fatalf("should have a snapshot of the session: %v", err)
+	}
+	var snap game.Snapshot
+	json.Unmarshal([]byte(raw), &snap)
+	if snap.Phase != game.PhaseScoreboard || len(snap.Players) != 2 || snap.Players[0] != "Alice" || len(snap.Scoreboard) != 2 {
+		t.Fatalf("expected the snapshot to show the scoreboard with Alice and Bob, got %+v", snap)
+	}
+	if len(snap.Submissions) != 3 || len(snap.Votes) != 2 {
+		t.Fatalf("expected the snapshot to hold the round's answers and votes, got %+v", snap)
+	}
+	var before int
+	st.db.QueryRow(`SELECT COUNT(*) FROM events WHERE code = ?`, code).Scan(&before)
+	st.Close()
+
+	st, err = Open(path)
+	if err != nil {
+		t.Fatalf("should be able to reopen the store: %v", err)
+	}
+	defer st.Close()
+	recovered := game.NewRoomManager()
+	recovered.EnableStore(st, nil)
+	codes, err := recovered.RecoverStore()
+	if err != nil || len(codes) != 1 || codes[0] != code {
+		t.Fatalf("expected to recover only session %s, got %v (%v)", code, codes, err)
+	}
+	restored, _ := recovered.Get(code)
+	if restored.GetPhase() != game.PhaseScoreboard {
+		t.Fatalf("expected the scoreboard, got %s", restored.GetPhase())
+	}
+	for id, points := range session.Scores {
+		if restored.Scores[id] != points {
+			t.Fatalf("expected %d points for %s, got %d", points, id, restored.Scores[id])
+		}
+	}
+	restored.Join("Carol")
+	recovered.FlushStore()
+	var after int
+	st.db.QueryRow(`SELECT COUNT(*) FROM events WHERE code = ?`, code).Scan(&after)
+	if after != before+1 {
+		t.Fatalf("expected the restored session to keep saving events, got %d after %d", after, before)
+	}
+}
@@ -0,0 +1,114 @@
+// Package token issues and checks the host and player tokens handed to
+// clients as HMAC-signed JWTs (HS256). A token names its session, role and
+// player and expires, so it can be checked without looking anything up;
+// refreshed tokens go on a revocation list until they'd expire anyway.
+// Tokens are checked when a client presents them, so a socket that already
+// authenticated stays authenticated until it disconnects, even if its token
+// expires or is revoked meanwhile.
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrMalformed = errors.New("malformed token")
+	ErrSignature = errors.New("invalid token signature")
+	ErrExpired   = errors.New("token expired")
+	ErrRevoked   = errors.New("token revoked")
+)
+
+// Claims are what a token says about its holder.
+type Claims struct {
+	ID        string `json:"jti"`
+	Session   string `json:"sid"`
+	Role      string `json:"role"` // "host" or "player"
+	PlayerID  string `json:"pid,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// header is the same for every token, so it's encoded once.
+var header = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	revoked map[string]time.Time // token ID -> its expiry
+}
+
+// NewSigner signs tokens with secret; they are valid for ttl.
+func NewSigner(secret string, ttl time.Duration) *Signer {
+	return &Signer{secret: []byte(secret), ttl: ttl, revoked: make(map[string]time.Time)}
+}
+
+// Issue returns a fresh token for a session role.
+func (s *Signer) Issue(session, role, playerID string) string {
+	id := make([]byte, 12)
+	rand.Read(id)
+	now := time.Now()
+	c := Claims{ID: hex.EncodeToString(id), Session: session, Role: role, PlayerID: playerID, IssuedAt: now.Unix(), ExpiresAt: now.Add(s.ttl).Unix()}
+	payload, _ := json.Marshal(c)
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + s.sign(unsigned)
+}
+
+func (s *Signer) sign(unsigned string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a token's signature, expiry and revocation and returns its
+// claims.
+func (s *Signer) Verify(tok string) (Claims, error) {
+	parts := strings.Split(tok, ".")
+	if len(parts) != 3 || parts[0] != header {
+		return Claims{}, ErrMalformed
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(s.sign(parts[0]+"."+parts[1]))) {
+		return Claims{}, ErrSignature
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return Claims{}, ErrMalformed
+	}
+	var c Claims
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return Claims{}, ErrMalformed
+	}
+	if time.Now().Unix() >= c.ExpiresAt {
+		return Claims{}, ErrExpired
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.revoked[c.ID]; ok {
+		return Claims{}, ErrRevoked
+	}
+	return c, nil
+}
+
+// Revoke rejects a token from now on. Entries are dropped once the token
+// would have expired anyway, so the list stays small.
+func (s *Signer) Revoke(c Claims) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for id, exp := range s.revoked {
+		if now.After(exp) {
+			delete(s.revoked, id)
+		}
+	}
+	s.revoked[c.ID] = time.Unix(c.ExpiresAt, 0)
+}
@@ -0,0 +1,45 @@
+package token
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSigner(t *testing.T) {
+	s := NewSigner("secret", time.Hour)
+	tok := s.Issue("ABCDE", "player", "p1")
+	c, err := s.Verify(tok)
+	if err != nil {
+		t.Fatalf("should be able to verify an issued token: %v", err)
+	}
+	if c.Session != "ABCDE" || c.Role != "player" || c.PlayerID != "p1" || c.ID == "" {
+		t.Fatalf("expected the claims to survive the round trip, got %+v", c)
+	}
+	if tok == s.Issue("ABCDE", "player", "p1") {
+		t.Fatal("expected every token to be unique")
+	}
+
+	if _, err := NewSigner("other", time.Hour).Verify(tok); err != ErrSignature {
+		t.Fatalf("expected a token of another secret to be rejected, got %v", err)
+	}
+	// a player's signature on a host's claims
+	host := strings.Split(s.Issue("ABCDE", "host", ""), ".")
+	forged := host[0] + "." + host[1] + "." + strings.Split(tok, ".")[2]
+	if _, err := s.Verify(forged); err != ErrSignature {
+		t.Fatalf("expected a tampered token to be rejected, got %v", err)
+	}
+	for _, bad := range []string{"", "not-a-jwt", "a.b.c"} {
+		if _, err := s.Verify(bad); err != ErrMalformed {
+			t.Fatalf("expected %q to be malformed, got %v", bad, err)
+		}
+	}
+	if _, err := NewSigner("secret", -time.Second).Verify(NewSigner("secret", -time.Second).Issue("ABCDE", "host", "")); err != ErrExpired {
+		t.Fatalf("expected an expired token to be rejected, got %v", err)
+	}
+
+	s.Revoke(c)
+	if _, err := s.Verify(tok); err != ErrRevoked {
+		t.Fatalf("expected a revoked token to be rejected, got %v", err)
+	}
+}
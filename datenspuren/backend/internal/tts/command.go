@@ -0,0 +1,48 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Command speaks through a local program that reads the text on stdin and
+// writes WAV audio to stdout, e.g. "espeak-ng -v {lang} --stdin --stdout".
+// {lang} in the arguments is replaced with the language.
+type Command struct {
+	Path string
+	Args []string
+}
+
+// ParseCommand splits a command line like the one above at spaces.
+func ParseCommand(line string) (*Command, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, errors.New("empty TTS command")
+	}
+	return &Command{Path: fields[0], Args: fields[1:]}, nil
+}
+
+func (c *Command) Speak(ctx context.Context, text, lang string) (Audio, error) {
+	if lang == "" {
+		lang = "de"
+	}
+	args := make([]string, len(c.Args))
+	for i, a := range c.Args {
+		args[i] = strings.ReplaceAll(a, "{lang}", lang)
+	}
+	cmd := exec.CommandContext(ctx, c.Path, args...)
+	cmd.Stdin = strings.NewReader(text)
+	var out, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &stderr
+	if err := cmd.Run(); err != nil {
+		return Audio{}, fmt.Errorf("%s: %w: %s", c.Path, err, strings.TrimSpace(stderr.String()))
+	}
+	if out.Len() == 0 {
+		return Audio{}, errors.New("TTS command produced no audio")
+	}
+	return Audio{Data: out.Bytes(), ContentType: "audio/wav"}, nil
+}
@@ -0,0 +1,59 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAI speaks through OpenAI's speech endpoint, which picks up the
+// language from the text itself.
+type OpenAI struct {
+	APIKey  string
+	BaseURL string
+	Model   string // e.g. "tts-1"
+	Voice   string // e.g. "alloy"
+	http    *http.Client
+}
+
+func NewOpenAI(apiKey, baseURL, model, voice string) *OpenAI {
+	if baseURL == "" {
+		baseURL = "https://api.openai.com"
+	}
+	if model == "" {
+		model = "tts-1"
+	}
+	if voice == "" {
+		voice = "alloy"
+	}
+	return &OpenAI{APIKey: apiKey, BaseURL: strings.TrimRight(baseURL, "/"), Model: model, Voice: voice, http: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (c *OpenAI) Speak(ctx context.Context, text, _ string) (Audio, error) {
+	if c.APIKey == "" {
+		return Audio{}, errors.New("missing OPENAI_API_KEY")
+	}
+	b, _ := json.Marshal(map[string]any{"model": c.Model, "voice": c.Voice, "input": text, "response_format": "mp3"})
+	req, _ := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/v1/audio/speech", bytes.NewReader(b))
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Audio{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return Audio{}, fmt.Errorf("openai status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Audio{}, err
+	}
+	return Audio{Data: data, ContentType: "audio/mpeg"}, nil
+}
@@ -0,0 +1,62 @@
+// Package tts turns answers into speech so the host can have them read
+// aloud, either through OpenAI's speech endpoint or a local engine like
+// espeak-ng for venues without internet.
+package tts
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+)
+
+// Audio is synthesized speech ready to be served.
+type Audio struct {
+	Data        []byte
+	ContentType string
+}
+
+// Speaker synthesizes text spoken in the given language (ISO 639-1).
+type Speaker interface {
+	Speak(ctx context.Context, text, lang string) (Audio, error)
+}
+
+// Cache remembers the audio of the most recent texts, since hosts tend to
+// play an answer more than once and each synthesis costs time and money.
+type Cache struct {
+	Speaker Speaker
+	Size    int
+
+	mu    sync.Mutex
+	audio map[[32]byte]Audio
+	order [][32]byte // oldest first
+}
+
+// NewCache wraps s, keeping the audio of up to size texts.
+func NewCache(s Speaker, size int) *Cache {
+	return &Cache{Speaker: s, Size: size, audio: make(map[[32]byte]Audio)}
+}
+
+func (c *Cache) Speak(ctx context.Context, text, lang string) (Audio, error) {
+	key := sha256.Sum256([]byte(lang + "\x00" + text))
+	c.mu.Lock()
+	a, ok := c.audio[key]
+	c.mu.Unlock()
+	if ok {
+		return a, nil
+	}
+	a, err := c.Speaker.Speak(ctx, text, lang)
+	if err != nil {
+		return Audio{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.audio[key]; !ok {
+		c.audio[key] = a
+		c.order = append(c.order, key)
+		if len(c.order) > c.Size {
+			delete(c.audio, c.order[0])
+			c.order = c.order[1:]
+		}
+	}
+	return a, nil
+}
@@ -0,0 +1,63 @@
+package tts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type countingSpeaker struct{ calls int }
+
+func (s *countingSpeaker) Speak(_ context.Context, text, lang string) (Audio, error) {
+	s.calls++
+	return Audio{Data: []byte(lang + ":" + text), ContentType: "audio/wav"}, nil
+}
+
+func TestCache(t *testing.T) {
+	s := &countingSpeaker{}
+	c := NewCache(s, 2)
+	ctx := context.Background()
+	c.Speak(ctx, "one", "de")
+	if a, _ := c.Speak(ctx, "one", "de"); string(a.Data) != "de:one" || s.calls != 1 {
+		t.Fatalf("expected the second call to be cached, got %q after %d calls", a.Data, s.calls)
+	}
+	c.Speak(ctx, "one", "en")
+	c.Speak(ctx, "two", "de")
+	c.Speak(ctx, "one", "de")
+	if s.calls != 4 {
+		t.Fatalf("expected the oldest text to be evicted, got %d calls", s.calls)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	c, err := ParseCommand("cat")
+	if err != nil {
+		t.Fatalf("should be able to parse the command: %v", err)
+	}
+	a, err := c.Speak(context.Background(), "Hallo", "de")
+	if err != nil || string(a.Data) != "Hallo" || a.ContentType != "audio/wav" {
+		t.Fatalf("expected the command's output as audio, got %q, %v", a.Data, err)
+	}
+	if _, err := ParseCommand("  "); err == nil {
+		t.Fatal("expected an error for an empty command")
+	}
+}
+
+func TestOpenAI(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/v1/audio/speech" || body["input"] != "Hallo" || body["voice"] != "alloy" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("mp3"))
+	}))
+	defer srv.Close()
+	a, err := NewOpenAI("key", srv.URL, "", "").Speak(context.Background(), "Hallo", "de")
+	if err != nil || string(a.Data) != "mp3" || a.ContentType != "audio/mpeg" {
+		t.Fatalf("expected the speech endpoint's audio, got %q, %v", a.Data, err)
+	}
+}
@@ -0,0 +1,43 @@
+package ws
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// AdminSessionHandler serves GET /api/admin/sessions/:code, everything the
+// server holds for a session.
+func (srv *Server) AdminSessionHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sess, err := srv.RM.Lookup(c.Param("code"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session_not_found"})
+			return
+		}
+		dump, err := sess.Dump()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"session": dump, "connections": srv.Connections(sess.Code)})
+	}
+}
+
+// CloseSessionHandler serves DELETE /api/admin/sessions/:code: the session
+// ends, its clients get game:closed with reason "closed" and it is removed.
+func (srv *Server) CloseSessionHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sess, err := srv.RM.Lookup(c.Param("code"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session_not_found"})
+			return
+		}
+		sess.Expire()
+		srv.RM.Remove(sess.Code)
+		srv.closeSession(sess, "closed")
+		log.Info().Str("code", sess.Code).Msg("session closed by admin")
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
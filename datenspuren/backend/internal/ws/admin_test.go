@@ -0,0 +1,77 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+func TestAdminSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, _, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1, AnswerTime: 60})
+	sess, _ := rm.Get(code)
+	sess.Join("Alice")
+	srv := New(rm, config.Config{})
+	r := gin.New()
+	srv.Mount(r)
+	r.GET("/api/admin/sessions", srv.SessionsHandler())
+	r.GET("/api/admin/sessions/:code", srv.AdminSessionHandler())
+	r.DELETE("/api/admin/sessions/:code", srv.CloseSessionHandler())
+	s := newStreamConn(httptest.NewRequest("GET", "/api/session/"+code+"/events", nil), code)
+	s.SetContext(&ConnCtx{Code: code, Role: "spectator"})
+	s.Join(code)
+	srv.addMember(code, s)
+
+	call := func(method, url string, out any) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, url, nil))
+		if out != nil {
+			json.Unmarshal(w.Body.Bytes(), out)
+		}
+		return w.Code
+	}
+
+	var list struct {
+		Sessions []SessionStatus `json:"sessions"`
+	}
+	if call(http.MethodGet, "/api/admin/sessions", &list); len(list.Sessions) != 1 || list.Sessions[0].Code != code || list.Sessions[0].PlayerCount != 1 {
+		t.Fatalf("expected the session with its player, got %+v", list.Sessions)
+	}
+
+	var detail struct {
+		Session struct {
+			Phase      game.Phase     `json:"phase"`
+			PlayerList []*game.Player `json:"playerList"`
+			HostToken  string         `json:"HostToken"`
+		} `json:"session"`
+	}
+	if status := call(http.MethodGet, "/api/admin/sessions/"+code, &detail); status != http.StatusOK {
+		t.Fatalf("should be able to dump the session: %d", status)
+	}
+	if detail.Session.Phase != game.PhaseLobby || len(detail.Session.PlayerList) != 1 || detail.Session.PlayerList[0].Name != "Alice" {
+		t.Fatalf("expected the dump to show the lobby with Alice, got %+v", detail.Session)
+	}
+	if detail.Session.HostToken != "" {
+		t.Fatal("expected the dump to leave out tokens")
+	}
+
+	if status := call(http.MethodDelete, "/api/admin/sessions/"+code, nil); status != http.StatusOK {
+		t.Fatalf("should be able to close the session: %d", status)
+	}
+	ev := <-s.events
+	if data, _ := ev.Data.(map[string]any); ev.Name != "game:closed" || data["reason"] != "closed" {
+		t.Fatalf("expected game:closed with reason closed, got %s %v", ev.Name, ev.Data)
+	}
+	if _, err := rm.Get(code); err == nil {
+		t.Fatal("expected the session to be removed")
+	}
+	if status := call(http.MethodGet, "/api/admin/sessions/"+code, nil); status != http.StatusNotFound {
+		t.Fatalf("expected a closed session to be gone, got %d", status)
+	}
+}
@@ -0,0 +1,322 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/kiliankoe/gptdash/internal/i18n"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// languageNames covers the languages we expect at events; other codes are
+// passed to the model verbatim.
+var languageNames = map[string]string{
+	"de": "German",
+	"en": "English",
+	"fr": "French",
+	"es": "Spanish",
+	"it": "Italian",
+	"nl": "Dutch",
+	"pl": "Polish",
+	"cs": "Czech",
+}
+
+// systemPromptFor returns the system prompt for a session, instructing the
+// model to answer in the session's primary language if one is configured.
+// Without SYSTEM_PROMPT the default prompt is written in that language.
+func (srv *Server) systemPromptFor(sess *game.SessionCtx) string {
+	lang := strings.ToLower(sess.Config.Language)
+	base := srv.config().SystemPrompt
+	if base == "" && i18n.Normalize(lang) == lang {
+		return i18n.T(lang, i18n.SystemPrompt)
+	}
+	if base == "" {
+		base = i18n.T(i18n.Default, i18n.SystemPrompt)
+	}
+	if lang == "" {
+		return base
+	}
+	name := languageNames[lang]
+	if name == "" {
+		name = lang
+	}
+	return strings.TrimSpace(base + " Always answer in " + name + ".")
+}
+
+// aiPrompt is what the AI answers in a round. In image rounds it can't see
+// the image, so it captions the prompt the image was generated from.
+func aiPrompt(sess *game.SessionCtx, r *game.Round) string {
+	if r.Type == game.RoundImage {
+		return i18n.T(sess.Config.Language, "Write a short, witty caption for a picture showing: %s", r.Prompt)
+	}
+	return r.Prompt
+}
+
+func aiTrigger(sess *game.SessionCtx) string {
+	if t := sess.Config.AITrigger; t != "" {
+		return t
+	}
+	return game.AITriggerSetPrompt
+}
+
+func isManual(choice game.ModelChoice) bool {
+	return strings.ToLower(choice.Provider) == game.ProviderManual
+}
+
+// generateAIAnswer asks the provider picked for a round to answer prompt.
+func (srv *Server) generateAIAnswer(ctx context.Context, sess *game.SessionCtx, choice game.ModelChoice, prompt string) (string, game.AIMetadata, error) {
+	// pick provider per session
+	prov := srv.provider
+	if srv.provByName != nil {
+		if p := srv.provByName[strings.ToLower(choice.Provider)]; p != nil {
+			prov = p
+		}
+	}
+	if prov == nil {
+		return "", game.AIMetadata{}, errors.New("no AI provider configured")
+	}
+	// use session config model if present
+	model := choice.Model
+	if model == "" {
+		model = "gpt-3.5-turbo"
+	}
+	_, round := sess.Progress()
+	ctx, span := tracer.Start(ctx, "ai.generate", trace.WithAttributes(
+		attribute.String("session.code", sess.Code),
+		attribute.Int("game.round_index", round),
+		attribute.String("ai.provider", choice.Provider),
+		attribute.String("ai.model", model),
+	))
+	defer span.End()
+	start := time.Now()
+	out, err := prov.CompleteDetailed(ctx, model, srv.systemPromptFor(sess), prompt)
+	if err == nil && out.Text == "" {
+		err = errors.New("empty AI answer")
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return "", game.AIMetadata{}, err
+	}
+	if out.Provider != "" {
+		span.SetAttributes(attribute.String("ai.answered_by", out.Provider))
+	}
+	meta := game.AIMetadata{
+		Provider:         choice.Provider,
+		Model:            model,
+		LatencyMs:        time.Since(start).Milliseconds(),
+		PromptTokens:     out.PromptTokens,
+		CompletionTokens: out.CompletionTokens,
+		FinishReason:     out.FinishReason,
+	}
+	if out.Model != "" {
+		meta.Model = out.Model
+	}
+	if out.Provider != "" {
+		// a fallback provider answered
+		meta.Provider = out.Provider
+	}
+	return out.Text, meta, nil
+}
+
+// deliverAIAnswer puts a generated answer into its round, replacing an
+// earlier one, and tells the host.
+func (srv *Server) deliverAIAnswer(sess *game.SessionCtx, roundID string, text string, meta game.AIMetadata) {
+	sess.RecordAIMetadata(roundID, meta)
+	if r := currentRoundPtr(sess); r == nil || r.ID != roundID {
+		return // round was replaced while we waited
+	}
+	id, err := sess.ReplaceAISubmission(text)
+	if err != nil {
+		log.Warn().Err(err).Str("code", sess.Code).Msg("could not add AI answer")
+		return
+	}
+	srv.translateSubmission(sess, id, text)
+	srv.notifyAIAnswer(sess, text, &meta)
+	srv.advanceWhenAnswered(sess)
+}
+
+// notifyAIAnswer tells the host(s) of a session that the AI answer is ready.
+func (srv *Server) notifyAIAnswer(sess *game.SessionCtx, text string, meta *game.AIMetadata) {
+	msg := map[string]any{"answer": text}
+	// keep blind tests blind, even for the host
+	if meta != nil && len(sess.Config.BlindModels) == 0 {
+		msg["meta"] = meta
+	}
+	for _, c := range srv.membersOf(sess.Code) {
+		if ctx, ok := c.Context().(*ConnCtx); ok && ctx.Role == "host" {
+			c.Emit("game:aiAnswer", msg)
+		}
+	}
+}
+
+// notifyAIFailure tells the host(s) that no AI answer could be generated, so
+// they can enter one by hand instead of waiting. Cancelled calls, e.g. for a
+// reset round, aren't failures.
+func (srv *Server) notifyAIFailure(sess *game.SessionCtx, queuedID string, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	msg := map[string]any{"error": err.Error()}
+	if queuedID != "" {
+		msg["queuedId"] = queuedID
+	}
+	srv.emitToHosts(sess.Code, "game:aiFailed", msg)
+}
+
+// generateForCurrentRound kicks off AI generation for the current round in
+// the background (best-effort). A call still running for the round, e.g.
+// when the host regenerates the answer, is cancelled.
+func (srv *Server) generateForCurrentRound(sess *game.SessionCtx) {
+	r := currentRoundPtr(sess)
+	if r == nil {
+		return
+	}
+	choice := sess.RoundModel()
+	if isManual(choice) {
+		return // manual sessions wait for the host to enter the AI answer
+	}
+	roundID, prompt := r.ID, aiPrompt(sess, r)
+	ctx := srv.trackAICall(roundID)
+	background("generate", sess.Code, func() {
+		defer srv.untrackAICall(roundID, ctx)
+		text, meta, err := srv.generateAIAnswer(ctx, sess, choice, prompt)
+		if err != nil {
+			log.Warn().Err(err).Str("code", sess.Code).Msg("AI generation failed")
+			srv.notifyAIFailure(sess, "", err)
+			return
+		}
+		if ctx.Err() != nil {
+			return // superseded by a newer call
+		}
+		srv.deliverAIAnswer(sess, roundID, text, meta)
+	})
+}
+
+// generateForQueuedPrompt pre-generates the AI answer of a queued prompt.
+func (srv *Server) generateForQueuedPrompt(sess *game.SessionCtx, q game.QueuedPrompt) {
+	if isManual(q.Model) {
+		return
+	}
+	background("generateQueued", sess.Code, func() {
+		text, meta, err := srv.generateAIAnswer(context.Background(), sess, q.Model, q.Prompt)
+		if err != nil {
+			log.Warn().Err(err).Str("code", sess.Code).Msg("AI generation for queued prompt failed")
+			srv.notifyAIFailure(sess, q.ID, err)
+			return
+		}
+		sess.SetQueuedAIAnswer(q.ID, text, meta)
+		srv.emitQueueTo(sess)
+	})
+}
+
+// emitQueueTo sends the prompt queue to the host(s) of a session.
+func (srv *Server) emitQueueTo(sess *game.SessionCtx) {
+	queue := sess.PromptQueue()
+	prompts := make(map[string]string, len(queue))
+	for _, q := range queue {
+		prompts[q.ID] = q.Prompt
+	}
+	stats := srv.promptStats(prompts)
+	for _, c := range srv.membersOf(sess.Code) {
+		if ctx, ok := c.Context().(*ConnCtx); ok && ctx.Role == "host" {
+			c.Emit("game:promptQueue", map[string]any{"prompts": queue, "stats": stats})
+		}
+	}
+}
+
+// votingAITimeout caps how long voting waits for a lazily generated AI
+// answer before it opens without one.
+const votingAITimeout = 20 * time.Second
+
+// generateBeforeVoting starts generating the AI answer for sessions using
+// AITriggerVoting and opens voting once it is in, or once votingAITimeout
+// runs out. It reports whether the caller should hold off advancing, which
+// is also the case while an earlier call for the round is still running.
+func (srv *Server) generateBeforeVoting(sess *game.SessionCtx) bool {
+	r := currentRoundPtr(sess)
+	choice := sess.RoundModel()
+	if r == nil || r.AISubmissionID != "" || isManual(choice) {
+		return false
+	}
+	if prev, _ := srv.votingAI.Swap(sess.Code, r.ID); prev == r.ID {
+		return srv.aiCallRunning(r.ID)
+	}
+	roundID, round, prompt := r.ID, r.Index, aiPrompt(sess, r)
+	call := srv.trackAICall(roundID)
+	background("generateBeforeVoting", sess.Code, func() {
+		ctx, cancel := context.WithTimeout(call, votingAITimeout)
+		text, meta, err := srv.generateAIAnswer(ctx, sess, choice, prompt)
+		cancel()
+		reset := call.Err() != nil
+		srv.untrackAICall(roundID, call)
+		if reset {
+			return // the round was reset, nothing to open voting for
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("code", sess.Code).Msg("AI generation before voting failed")
+			srv.notifyAIFailure(sess, "", err)
+		} else {
+			srv.deliverAIAnswer(sess, roundID, text, meta)
+		}
+		if _, err := srv.stepFrom(sess, game.PhaseAnswering, round, "aiReady"); err != nil {
+			log.Warn().Err(err).Str("code", sess.Code).Msg("could not open voting")
+		}
+	})
+	return true
+}
+
+// aiCall is an in-flight AI call made on behalf of a round.
+type aiCall struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// trackAICall returns a context for an AI call made on behalf of a round,
+// cancelled by cancelAICall when the round is reset or by the next call
+// tracked for the same round.
+func (srv *Server) trackAICall(roundID string) context.Context {
+	srv.aiMu.Lock()
+	defer srv.aiMu.Unlock()
+	if old, ok := srv.aiCalls[roundID]; ok {
+		old.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	srv.aiCalls[roundID] = aiCall{ctx: ctx, cancel: cancel}
+	return ctx
+}
+
+// untrackAICall ends the call tracked as ctx, leaving a newer call for the
+// round alone.
+func (srv *Server) untrackAICall(roundID string, ctx context.Context) {
+	srv.aiMu.Lock()
+	defer srv.aiMu.Unlock()
+	if c, ok := srv.aiCalls[roundID]; ok && c.ctx == ctx {
+		c.cancel()
+		delete(srv.aiCalls, roundID)
+	}
+}
+
+// aiCallRunning reports whether an AI call for the round is in flight.
+func (srv *Server) aiCallRunning(roundID string) bool {
+	srv.aiMu.Lock()
+	defer srv.aiMu.Unlock()
+	_, ok := srv.aiCalls[roundID]
+	return ok
+}
+
+// cancelAICall aborts an in-flight AI call for the round, if any.
+func (srv *Server) cancelAICall(roundID string) {
+	srv.aiMu.Lock()
+	defer srv.aiMu.Unlock()
+	if c, ok := srv.aiCalls[roundID]; ok {
+		c.cancel()
+		delete(srv.aiCalls, roundID)
+	}
+}
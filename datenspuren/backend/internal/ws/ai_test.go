@@ -0,0 +1,148 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kiliankoe/gptdash/internal/ai"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+// counting answers "Answer 1", "Answer 2", ... in turn.
+type counting struct{ calls atomic.Int32 }
+
+func (c *counting) Complete(ctx context.Context, model string, prompt string) (string, error) {
+	return c.CompleteWithSystem(ctx, model, "", prompt)
+}
+
+func (c *counting) CompleteWithSystem(ctx context.Context, model string, systemPrompt string, prompt string) (string, error) {
+	out, err := c.CompleteDetailed(ctx, model, systemPrompt, prompt)
+	return out.Text, err
+}
+
+func (c *counting) CompleteDetailed(ctx context.Context, model string, systemPrompt string, prompt string) (ai.Completion, error) {
+	return ai.Completion{Text: fmt.Sprintf("Answer %d", c.calls.Add(1))}, nil
+}
+
+func TestRegenerateAI(t *testing.T) {
+	rm := game.NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(game.SessionConfig{Provider: "counting", RoundCount: 1})
+	sess, _ := rm.Get(code)
+	srv := New(rm, config.Config{})
+	srv.SetProviders(map[string]AIProvider{"counting": &counting{}})
+	sess.Join("Alice")
+	sess.SetPrompt(hostToken, "Q")
+
+	aiAnswer := func(want string) string {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			if id := sess.CurrentRound().AISubmissionID; id != "" && sess.SubmissionText(id) == want {
+				return id
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected the AI answer %q", want)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	srv.generateForCurrentRound(sess)
+	first := aiAnswer("Answer 1")
+	srv.generateForCurrentRound(sess)
+	if second := aiAnswer("Answer 2"); second != first {
+		t.Fatalf("expected the regenerated answer to replace the first, got %s and %s", first, second)
+	}
+
+	sess.Advance(hostToken)
+	if subs := sess.ListVotingSubmissions(); len(subs) != 1 || subs[0].Text != "Answer 2" {
+		t.Fatalf("expected only the regenerated AI answer up for voting, got %+v", subs)
+	}
+}
+
+func TestSystemPromptFor(t *testing.T) {
+	rm := game.NewRoomManager()
+	srv := New(rm, config.Config{})
+	prompt := func(lang string) string {
+		code, _, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1, Language: lang})
+		sess, _ := rm.Get(code)
+		return srv.systemPromptFor(sess)
+	}
+	if got := prompt(""); got != "Du bist eine prägnante, sich kurzfassende KI. Antworte knapp in 1-2 Sätzen." {
+		t.Fatalf("expected the German default, got %q", got)
+	}
+	if got := prompt("en"); got != "You are a concise AI. Answer briefly in 1-2 sentences." {
+		t.Fatalf("expected the English default, got %q", got)
+	}
+	if got := prompt("fr"); !strings.HasSuffix(got, "Always answer in French.") {
+		t.Fatalf("expected languages without a catalog to be asked for, got %q", got)
+	}
+	srv.Reload(config.Config{SystemPrompt: "Be funny."})
+	if got := prompt("en"); got != "Be funny. Always answer in English." {
+		t.Fatalf("expected SYSTEM_PROMPT to override the default, got %q", got)
+	}
+}
+
+// gated answers once release is closed.
+type gated struct{ release chan struct{} }
+
+func (g *gated) Complete(ctx context.Context, model string, prompt string) (string, error) {
+	return g.CompleteWithSystem(ctx, model, "", prompt)
+}
+
+func (g *gated) CompleteWithSystem(ctx context.Context, model string, systemPrompt string, prompt string) (string, error) {
+	out, err := g.CompleteDetailed(ctx, model, systemPrompt, prompt)
+	return out.Text, err
+}
+
+func (g *gated) CompleteDetailed(ctx context.Context, model string, systemPrompt string, prompt string) (ai.Completion, error) {
+	select {
+	case <-g.release:
+		return ai.Completion{Text: "Late answer"}, nil
+	case <-ctx.Done():
+		return ai.Completion{}, ctx.Err()
+	}
+}
+
+func TestGenerateBeforeVotingDoesNotBlock(t *testing.T) {
+	rm := game.NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(game.SessionConfig{Provider: "gated", RoundCount: 1, AITrigger: game.AITriggerVoting})
+	sess, _ := rm.Get(code)
+	srv := New(rm, config.Config{})
+	prov := &gated{release: make(chan struct{})}
+	srv.SetProviders(map[string]AIProvider{"gated": prov})
+	_, aliceToken, _ := sess.Join("Alice")
+	sess.Join("Bob")
+	sess.SetPrompt(hostToken, "Q")
+	sess.Submit(aliceToken, "Alice's answer")
+
+	start := time.Now()
+	if err := hostStep(srv, sess, hostToken); err != nil {
+		t.Fatalf("should be able to advance: %v", err)
+	}
+	if err := hostStep(srv, sess, hostToken); err != nil {
+		t.Fatalf("should be able to press advance again: %v", err)
+	}
+	if waited := time.Since(start); waited > time.Second {
+		t.Fatalf("expected advancing not to wait for the AI, took %v", waited)
+	}
+	if phase := sess.GetPhase(); phase != game.PhaseAnswering {
+		t.Fatalf("expected voting to wait for the AI answer, got %s", phase)
+	}
+
+	close(prov.release)
+	deadline := time.Now().Add(2 * time.Second)
+	for sess.GetPhase() != game.PhaseVoting {
+		if time.Now().After(deadline) {
+			t.Fatal("expected voting to open once the AI answered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if subs := sess.ListVotingSubmissions(); len(subs) != 2 {
+		t.Fatalf("expected the AI answer up for voting, got %+v", subs)
+	}
+}
@@ -0,0 +1,230 @@
+package ws
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/game"
+	gptdashv1 "github.com/kiliankoe/gptdash/internal/gen/gptdash/v1"
+	"github.com/kiliankoe/gptdash/internal/gen/gptdash/v1/gptdashv1connect"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// APIService is the Connect service of the programmatic API for companion
+// tools, defined in proto/gptdash/v1/game.proto. It speaks the Connect, gRPC
+// and gRPC-Web protocols with binary or JSON messages, e.g.
+//
+//	curl -H 'Authorization: Bearer <host token>' -H 'Content-Type: application/json' \
+//	  -d '{"sessionCode":"ABCD"}' http://localhost:8080/gptdash.v1.GameService/Advance
+const APIService = gptdashv1connect.GameServiceName
+
+// apiService implements the generated GameServiceHandler on top of srv.
+type apiService struct{ srv *Server }
+
+// MountAPI registers the API procedures on r. Every call authenticates with
+// the session's host token as Bearer token; read-only calls also accept the
+// overlay token. gRPC clients also need ScopeH2C around the router.
+func (srv *Server) MountAPI(r gin.IRoutes) {
+	path, h := gptdashv1connect.NewGameServiceHandler(apiService{srv})
+	r.POST(path+":method", gin.WrapH(h))
+}
+
+// ScopeH2C serves cleartext HTTP/2, which gRPC clients speak without TLS, for
+// the API alone: h2c connections only reach API procedures, everything else
+// stays on HTTP/1.1 (or HTTP/2 over TLS) as before.
+func ScopeH2C(next http.Handler) http.Handler {
+	prefix := "/" + APIService + "/"
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, prefix) {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+	h2 := h2c.NewHandler(api, &http2.Server{})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// prior-knowledge connections open with "PRI *", before any path
+		if r.Method == "PRI" || strings.HasPrefix(r.URL.Path, prefix) {
+			h2.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// toStruct converts v to the JSON object it marshals to, for responses that
+// hand out the same JSON as the REST endpoints.
+func toStruct(v any) (*structpb.Struct, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	out := &structpb.Struct{}
+	if err := protojson.Unmarshal(b, out); err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	return out, nil
+}
+
+// toEvent converts an overlay event for WatchEvents.
+func toEvent(ev overlayEvent) (*gptdashv1.Event, error) {
+	b, err := json.Marshal(ev.Data)
+	if err != nil {
+		return nil, err
+	}
+	data := &structpb.Value{}
+	if err := protojson.Unmarshal(b, data); err != nil {
+		return nil, err
+	}
+	return &gptdashv1.Event{Name: ev.Name, Data: data, At: timestamppb.New(ev.At)}, nil
+}
+
+// apiSession looks up the session and checks the caller's Bearer token.
+func (srv *Server) apiSession(header http.Header, code string, readOnly bool) (*game.SessionCtx, error) {
+	sess, err := srv.RM.Lookup(code)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	}
+	token := strings.TrimPrefix(header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("missing bearer token"))
+	}
+	if role, _ := srv.authenticate(sess, token); role == "host" {
+		return sess, nil
+	}
+	if readOnly && subtle.ConstantTimeCompare([]byte(token), []byte(sess.OverlayToken)) == 1 {
+		return sess, nil
+	}
+	return nil, connect.NewError(connect.CodePermissionDenied, game.ErrNotHost)
+}
+
+// apiError maps game errors onto Connect codes.
+func apiError(err error) error {
+	switch {
+	case errors.Is(err, game.ErrNotHost):
+		return connect.NewError(connect.CodePermissionDenied, err)
+	case errors.Is(err, game.ErrInvalidPhase), errors.Is(err, game.ErrNoTimer), errors.Is(err, game.ErrStaleStep):
+		return connect.NewError(connect.CodeFailedPrecondition, err)
+	}
+	return connect.NewError(connect.CodeInvalidArgument, err)
+}
+
+func (a apiService) GetState(_ context.Context, req *connect.Request[gptdashv1.SessionRequest]) (*connect.Response[structpb.Struct], error) {
+	sess, err := a.srv.apiSession(req.Header(), req.Msg.SessionCode, true)
+	if err != nil {
+		return nil, err
+	}
+	state, err := toStruct(sess.PublicState())
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(state), nil
+}
+
+func (a apiService) GetSummary(_ context.Context, req *connect.Request[gptdashv1.SessionRequest]) (*connect.Response[structpb.Struct], error) {
+	sess, err := a.srv.apiSession(req.Header(), req.Msg.SessionCode, true)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := toStruct(sess.Summary())
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(summary), nil
+}
+
+func (a apiService) SetPrompt(_ context.Context, req *connect.Request[gptdashv1.SetPromptRequest]) (*connect.Response[gptdashv1.PhaseResponse], error) {
+	sess, err := a.srv.apiSession(req.Header(), req.Msg.SessionCode, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Msg.Prompt) > 500 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("prompt must be at most 500 characters"))
+	}
+	if err := a.srv.setPrompt(sess, sess.HostToken, req.Msg.Prompt, req.Msg.Translations, req.Msg.QueuedId); err != nil {
+		return nil, apiError(err)
+	}
+	log.Info().Str("code", sess.Code).Msg("api: SetPrompt")
+	return connect.NewResponse(&gptdashv1.PhaseResponse{Phase: string(sess.GetPhase())}), nil
+}
+
+func (a apiService) Advance(_ context.Context, req *connect.Request[gptdashv1.SessionRequest]) (*connect.Response[gptdashv1.PhaseResponse], error) {
+	sess, err := a.srv.apiSession(req.Header(), req.Msg.SessionCode, false)
+	if err != nil {
+		return nil, err
+	}
+	st := sess.PublicState()
+	if err := a.srv.advanceFrom(sess, sess.HostToken, st.Phase, st.RoundIndex, log.Logger); err != nil {
+		return nil, apiError(err)
+	}
+	log.Info().Str("code", sess.Code).Msg("api: Advance")
+	return connect.NewResponse(&gptdashv1.PhaseResponse{Phase: string(sess.GetPhase())}), nil
+}
+
+func (a apiService) RevealScores(_ context.Context, req *connect.Request[gptdashv1.SessionRequest]) (*connect.Response[emptypb.Empty], error) {
+	sess, err := a.srv.apiSession(req.Header(), req.Msg.SessionCode, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.srv.revealScores(sess, sess.HostToken); err != nil {
+		return nil, apiError(err)
+	}
+	log.Info().Str("code", sess.Code).Msg("api: RevealScores")
+	return connect.NewResponse(&emptypb.Empty{}), nil
+}
+
+func (a apiService) ExtendTimer(_ context.Context, req *connect.Request[gptdashv1.ExtendTimerRequest]) (*connect.Response[gptdashv1.PhaseResponse], error) {
+	sess, err := a.srv.apiSession(req.Header(), req.Msg.SessionCode, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.srv.extendTimer(sess, sess.HostToken, time.Duration(req.Msg.Seconds)*time.Second); err != nil {
+		return nil, apiError(err)
+	}
+	log.Info().Str("code", sess.Code).Int32("seconds", req.Msg.Seconds).Msg("api: ExtendTimer")
+	return connect.NewResponse(&gptdashv1.PhaseResponse{Phase: string(sess.GetPhase())}), nil
+}
+
+// WatchEvents streams the session's overlay events, starting with the
+// current state, until the client goes away.
+func (a apiService) WatchEvents(ctx context.Context, req *connect.Request[gptdashv1.SessionRequest], stream *connect.ServerStream[gptdashv1.Event]) error {
+	sess, err := a.srv.apiSession(req.Header(), req.Msg.SessionCode, true)
+	if err != nil {
+		return err
+	}
+	ch := a.srv.overlay.subscribe(sess.Code)
+	defer a.srv.overlay.unsubscribe(sess.Code, ch)
+	send := func(ev overlayEvent) error {
+		msg, err := toEvent(ev)
+		if err != nil {
+			return connect.NewError(connect.CodeInternal, err)
+		}
+		return stream.Send(msg)
+	}
+	if err := send(overlayEvent{Name: "state", Data: sess.PublicState(), At: time.Now()}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-ch:
+			if err := send(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package ws
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+	gptdashv1 "github.com/kiliankoe/gptdash/internal/gen/gptdash/v1"
+	"github.com/kiliankoe/gptdash/internal/gen/gptdash/v1/gptdashv1connect"
+	"golang.org/x/net/http2"
+)
+
+func TestAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1})
+	sess, _ := rm.Get(code)
+	srv := New(rm, config.Config{})
+	r := gin.New()
+	srv.Mount(r)
+	srv.MountAPI(r)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	client := gptdashv1connect.NewGameServiceClient(ts.Client(), ts.URL, connect.WithProtoJSON())
+	session := func(token string) *connect.Request[gptdashv1.SessionRequest] {
+		return withToken(connect.NewRequest(&gptdashv1.SessionRequest{SessionCode: code}), token)
+	}
+
+	if _, err := client.Advance(context.Background(), connect.NewRequest(&gptdashv1.SessionRequest{SessionCode: code})); connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("expected unauthenticated without token, got %v", err)
+	}
+	if _, err := client.Advance(context.Background(), session(sess.OverlayToken)); connect.CodeOf(err) != connect.CodePermissionDenied {
+		t.Fatalf("expected the overlay token to be read-only, got %v", err)
+	}
+	state, err := client.GetState(context.Background(), session(sess.OverlayToken))
+	if err != nil || state.Msg.Fields["sessionCode"].GetStringValue() != code {
+		t.Fatalf("should be able to read the state with the overlay token: %v", err)
+	}
+	if _, err := client.RevealScores(context.Background(), session(hostToken)); connect.CodeOf(err) != connect.CodeFailedPrecondition {
+		t.Fatalf("expected revealing without withheld scores to fail, got %v", err)
+	}
+
+	res, err := client.SetPrompt(context.Background(), withToken(connect.NewRequest(&gptdashv1.SetPromptRequest{SessionCode: code, Prompt: "Test question?"}), hostToken))
+	if err != nil {
+		t.Fatalf("should be able to set the prompt: %v", err)
+	}
+	if res.Msg.Phase != string(game.PhaseAnswering) {
+		t.Fatalf("expected Answering, got %s", res.Msg.Phase)
+	}
+	if _, err := client.ExtendTimer(context.Background(), withToken(connect.NewRequest(&gptdashv1.ExtendTimerRequest{SessionCode: code, Seconds: 30}), hostToken)); connect.CodeOf(err) != connect.CodeFailedPrecondition {
+		t.Fatalf("expected extending a phase without timer to fail, got %v", err)
+	}
+	// nobody answered, so this skips right to the scoreboard
+	if res, err := client.Advance(context.Background(), session(hostToken)); err != nil || res.Msg.Phase != string(game.PhaseScoreboard) {
+		t.Fatalf("should be able to advance past Answering: %v", err)
+	}
+	var connectErr *connect.Error
+	if _, err := client.GetState(context.Background(), session("wrong")); !errors.As(err, &connectErr) {
+		t.Fatalf("expected a connect error, got %v", err)
+	}
+
+	// plain JSON over HTTP/1.1, like the simple host page and curl
+	body := strings.NewReader(`{"sessionCode":"` + code + `"}`)
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/"+APIService+"/GetState", body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+hostToken)
+	plain, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("should be able to call the API with plain JSON: %v", err)
+	}
+	defer plain.Body.Close()
+	var got map[string]any
+	if err := json.NewDecoder(plain.Body).Decode(&got); err != nil || got["phase"] != string(game.PhaseScoreboard) {
+		t.Fatalf("expected the state as plain JSON, got %v (%v)", got, err)
+	}
+}
+
+func TestAPIOverH2C(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1})
+	srv := New(rm, config.Config{})
+	r := gin.New()
+	srv.MountAPI(r)
+	r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, "index") })
+	ts := httptest.NewServer(ScopeH2C(r.Handler()))
+	defer ts.Close()
+
+	// cleartext HTTP/2 with prior knowledge, the way gRPC clients connect
+	h2 := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+	client := gptdashv1connect.NewGameServiceClient(h2, ts.URL, connect.WithGRPC())
+	state, err := client.GetState(context.Background(), withToken(connect.NewRequest(&gptdashv1.SessionRequest{SessionCode: code}), hostToken))
+	if err != nil || state.Msg.Fields["sessionCode"].GetStringValue() != code {
+		t.Fatalf("should be able to call the API over gRPC: %v", err)
+	}
+	if res, err := h2.Get(ts.URL + "/"); err != nil || res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected h2c to stay limited to the API, got %v (%v)", res, err)
+	}
+	if res, err := ts.Client().Get(ts.URL + "/"); err != nil || res.StatusCode != http.StatusOK {
+		t.Fatalf("expected the rest to be served over HTTP/1.1, got %v (%v)", res, err)
+	}
+}
+
+func TestSimpleHostPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, _, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1})
+	srv := New(rm, config.Config{})
+	r := gin.New()
+	r.GET("/host/simple", srv.SimpleHostHandler())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/host/simple?code="+strings.ToLower(code), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	for _, want := range []string{`const code = "` + code + `"`, APIService, "Weiter", "+30 Sekunden"} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Fatalf("expected the page to contain %q, got:\n%s", want, w.Body)
+		}
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/host/simple?code=NOPE", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown session, got %d", w.Code)
+	}
+}
+
+func withToken[T any](req *connect.Request[T], token string) *connect.Request[T] {
+	req.Header().Set("Authorization", "Bearer "+token)
+	return req
+}
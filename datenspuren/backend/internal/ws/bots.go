@@ -0,0 +1,167 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/rs/zerolog/log"
+)
+
+// Bots are simulated players that join a session like anybody else and play
+// along on their own: they answer after a random delay and vote at random,
+// and in hostless sessions they mark themselves ready. They fill a game for
+// testing phase logic or for demoing without a crowd.
+type Bots struct {
+	Count int
+	Delay time.Duration // longest pause before a bot answers or votes
+	AI    bool          // answers come from the session's AI provider instead of canned ones
+}
+
+// SetAutoBots makes every session created over the socket start with b's
+// bots, see --bots.
+func (srv *Server) SetAutoBots(b Bots) { srv.autoBots = b }
+
+// botPoll is how often bots look at their session's phase.
+const botPoll = 250 * time.Millisecond
+
+// AddBots joins b.Count bots to the session and returns their player IDs.
+// They play until the game ends, the session is removed or the server shuts
+// down.
+func (srv *Server) AddBots(code string, b Bots) ([]string, error) {
+	sess, err := srv.RM.Lookup(code)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, b.Count)
+	for i := 0; i < b.Count; i++ {
+		name := fmt.Sprintf("Bot %d", len(sess.Players())+1)
+		id, token, err := sess.Join(name)
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+		go srv.runBot(sess, demoBot{id: id, token: token}, b)
+	}
+	log.Info().Str("code", sess.Code).Int("bots", len(ids)).Msg("bots joined")
+	srv.emitStateTo(sess.Code)
+	return ids, nil
+}
+
+// runBot acts once per phase of every round it sees.
+func (srv *Server) runBot(sess *game.SessionCtx, bot demoBot, b Bots) {
+	t := time.NewTicker(botPoll)
+	defer t.Stop()
+	done := ""
+	for range t.C {
+		if srv.closing.Load() {
+			return
+		}
+		if cur, err := srv.RM.Get(sess.Code); err != nil || cur != sess {
+			return
+		}
+		st := sess.PublicState()
+		if st.Phase == game.PhaseEnd {
+			return
+		}
+		step := fmt.Sprintf("%s/%d", st.Phase, st.RoundIndex)
+		if step == done {
+			continue
+		}
+		switch st.Phase {
+		case game.PhaseAnswering:
+			srv.botPause(b.Delay)
+			srv.botSubmit(sess, bot, b.AI)
+		case game.PhaseVoting:
+			srv.botPause(b.Delay)
+			srv.botVote(sess, bot)
+		case game.PhaseLobby, game.PhaseScoreboard:
+			if !sess.Config.Hostless {
+				continue
+			}
+			if allReady, err := sess.SetReady(bot.token); err == nil {
+				srv.emitStateTo(sess.Code)
+				if allReady {
+					srv.hostlessStepAsync(sess, st.Phase, "ready")
+				}
+			}
+		default:
+			continue
+		}
+		done = step
+	}
+}
+
+func (srv *Server) botPause(limit time.Duration) {
+	if limit > 0 {
+		time.Sleep(time.Duration(rand.Int63n(int64(limit))))
+	}
+}
+
+// botSubmit answers the current round like the game:submit handler does.
+func (srv *Server) botSubmit(sess *game.SessionCtx, bot demoBot, useAI bool) {
+	text := demoAnswers[rand.Intn(len(demoAnswers))]
+	if r := currentRoundPtr(sess); useAI && r != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		if out, _, err := srv.generateAIAnswer(ctx, sess, sess.RoundModel(), aiPrompt(sess, r)); err == nil {
+			text = out
+		} else {
+			log.Warn().Err(err).Str("code", sess.Code).Msg("bot answer generation failed, using a canned one")
+		}
+		cancel()
+	}
+	id, err := sess.Submit(bot.token, text)
+	if err != nil {
+		return // the phase ended meanwhile, or the answer hit the wordlist
+	}
+	srv.translateSubmission(sess, id, sess.SubmissionText(id))
+	srv.notifySubmissions(sess)
+	srv.advanceWhenAnswered(sess)
+}
+
+// botVote votes for a random answer other than the bot's own.
+func (srv *Server) botVote(sess *game.SessionCtx, bot demoBot) {
+	var targets []string
+	for _, sub := range sess.ListVotingSubmissionsFor(bot.id) {
+		if sub.PlayerID != bot.id {
+			targets = append(targets, sub.ID)
+		}
+	}
+	if len(targets) == 0 || sess.Vote(bot.token, targets[rand.Intn(len(targets))]) != nil {
+		return
+	}
+	srv.notifyVotes(sess)
+	if sess.Config.Hostless && votingDone(sess) {
+		srv.hostlessStepAsync(sess, game.PhaseVoting, "voted")
+	}
+}
+
+// BotsHandler serves POST /api/debug/bots, adding bots to a session.
+func (srv *Server) BotsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			SessionCode string `json:"sessionCode" binding:"required"`
+			Count       int    `json:"count" binding:"min=1,max=100"`
+			Delay       int    `json:"delay" binding:"min=0,max=300"` // seconds
+			AI          bool   `json:"ai"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "message": err.Error()})
+			return
+		}
+		if _, err := srv.RM.Lookup(req.SessionCode); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session_not_found"})
+			return
+		}
+		ids, err := srv.AddBots(req.SessionCode, Bots{Count: req.Count, Delay: time.Duration(req.Delay) * time.Second, AI: req.AI})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "playerIds": ids})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"playerIds": ids})
+	}
+}
@@ -0,0 +1,63 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+func TestBots(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1})
+	sess, _ := rm.Get(code)
+	srv := New(rm, config.Config{})
+	r := gin.New()
+	srv.Mount(r)
+	r.POST("/api/debug/bots", srv.BotsHandler())
+
+	for body, want := range map[string]int{
+		`{"sessionCode": "nope", "count": 3}`:         http.StatusNotFound,
+		`{"sessionCode": "` + code + `"}`:             http.StatusBadRequest,
+		`{"sessionCode": "` + code + `", "count": 3}`: http.StatusOK,
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/debug/bots", strings.NewReader(body)))
+		if w.Code != want {
+			t.Fatalf("expected %d for %s, got %d: %s", want, body, w.Code, w.Body)
+		}
+	}
+	if n := len(sess.Players()); n != 3 {
+		t.Fatalf("expected 3 bots to join, got %d", n)
+	}
+
+	if err := sess.SetPrompt(hostToken, "Who are you?"); err != nil {
+		t.Fatalf("should be able to set the prompt: %v", err)
+	}
+	sess.SetAIAnswer(hostToken, "A human, obviously.")
+	waitUntil(t, "every bot to answer", func() bool { return answeringDone(sess) })
+	if err := sess.Advance(hostToken); err != nil {
+		t.Fatalf("should be able to open voting: %v", err)
+	}
+	waitUntil(t, "every bot to vote", func() bool { return votingDone(sess) })
+	if n := len(sess.Votes()); n != 3 {
+		t.Fatalf("expected a vote from every bot, got %d", n)
+	}
+}
+
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
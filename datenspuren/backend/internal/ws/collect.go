@@ -0,0 +1,59 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/rs/zerolog/log"
+)
+
+type Collector interface {
+	Send(ctx context.Context, doc any) error
+}
+
+func (srv *Server) SetCollector(c Collector) { srv.collector = c }
+
+// collectRound streams the just-scored round to the external collector.
+func (srv *Server) collectRound(sess *game.SessionCtx) {
+	last, ok := sess.LastRound()
+	if srv.collector == nil || !ok {
+		return
+	}
+	doc := map[string]any{
+		"type":        "round",
+		"sessionCode": sess.Code,
+		"exportedAt":  time.Now().UTC(),
+		"config":      sess.Config,
+		"round":       sess.RedactRound(last, srv.exportOptions(sess)),
+	}
+	if meta := sess.RoundAIMetadata(last.Index); meta != nil {
+		doc["aiMeta"] = meta
+	}
+	srv.collect(sess.Code, doc)
+}
+
+// collectGame streams the whole game narrative once the game has ended.
+func (srv *Server) collectGame(sess *game.SessionCtx) {
+	if srv.collector == nil {
+		return
+	}
+	srv.collect(sess.Code, map[string]any{
+		"type":        "game",
+		"sessionCode": sess.Code,
+		"exportedAt":  time.Now().UTC(),
+		"config":      sess.Config,
+		"summary":     sess.RedactSummary(sess.Summary(), srv.exportOptions(sess)),
+	})
+}
+
+// collect sends in the background so a slow collector never stalls the game.
+func (srv *Server) collect(code string, doc map[string]any) {
+	background("collect", code, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		if err := srv.collector.Send(ctx, doc); err != nil {
+			log.Error().Err(err).Str("code", code).Str("type", doc["type"].(string)).Msg("failed to stream export")
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package ws
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	errNoRoundsLeft = errors.New("all rounds have been played")
+	errNoPrompt     = errors.New("no prompt given and none queued")
+)
+
+// nextRound moves a fast-paced show on with a single host action: it
+// leaves the scoreboard, starts the next round with prompt, the queued
+// prompt queuedID or else the next queued prompt, and opens voting on its
+// own once everyone has answered. It holds the step lock, so a phase timer
+// firing meanwhile can't leave the scoreboard a second time.
+func (srv *Server) nextRound(sess *game.SessionCtx, token, prompt string, translations map[string]string, queuedID string, lg zerolog.Logger) error {
+	mu := srv.stepLock(sess.Code)
+	mu.Lock()
+	defer mu.Unlock()
+	return srv.nextRoundLocked(sess, token, prompt, translations, queuedID, lg)
+}
+
+// nextRoundLocked is nextRound for callers already holding the step lock.
+func (srv *Server) nextRoundLocked(sess *game.SessionCtx, token, prompt string, translations map[string]string, queuedID string, lg zerolog.Logger) error {
+	if token != sess.HostToken {
+		return game.ErrNotHost
+	}
+	if st := sess.PublicState(); st.Phase == game.PhaseScoreboard {
+		if st.RoundIndex >= st.RoundCount {
+			return errNoRoundsLeft
+		}
+		if err := srv.advanceLocked(sess, token, st.Phase, st.RoundIndex, lg); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(prompt) == "" && queuedID == "" {
+		queuedID = nextQueued(sess.PromptQueue())
+		if queuedID == "" {
+			return errNoPrompt
+		}
+	}
+	if err := srv.setPrompt(sess, token, prompt, translations, queuedID); err != nil {
+		return err
+	}
+	if r := currentRoundPtr(sess); r != nil {
+		srv.autoMu.Lock()
+		srv.autoVoting[sess.Code] = r.ID
+		srv.autoMu.Unlock()
+	}
+	return nil
+}
+
+// nextQueued picks the queued prompt to play next, preferring one whose AI
+// answer is already there so the round doesn't wait on generation.
+func nextQueued(queue []game.QueuedPrompt) string {
+	for _, q := range queue {
+		if q.AIReady {
+			return q.ID
+		}
+	}
+	if len(queue) > 0 {
+		return queue[0].ID
+	}
+	return ""
+}
+
+// answeringDone reports whether every player has answered and the AI answer
+// is in, or will be generated right before voting.
+func answeringDone(sess *game.SessionCtx) bool {
+	r := sess.CurrentRound()
+	if r == nil || r.AISubmissionID == "" && aiTrigger(sess) != game.AITriggerVoting {
+		return false
+	}
+	status := sess.PlayerSubmissionStatus()
+	for _, ok := range status {
+		if !ok {
+			return false
+		}
+	}
+	return len(status) > 0
+}
+
+// votingDone reports whether everyone allowed to vote, i.e. everyone who
+// answered, has voted.
+func votingDone(sess *game.SessionCtx) bool {
+	voters := 0
+	for _, ok := range sess.PlayerSubmissionStatus() {
+		if ok {
+			voters++
+		}
+	}
+	return len(sess.Votes()) >= voters
+}
+
+// advanceWhenAnswered opens voting for a round started by nextRound, or in
+// a hostless session, once answeringDone, warning the host about answers close
+// to the AI's first. Call it whenever an answer comes in.
+func (srv *Server) advanceWhenAnswered(sess *game.SessionCtx) {
+	if sess.GetPhase() != game.PhaseAnswering || !answeringDone(sess) {
+		return
+	}
+	srv.warnSimilar(sess)
+	if sess.Config.Hostless {
+		srv.hostlessStepAsync(sess, game.PhaseAnswering, "answered")
+		return
+	}
+	r := currentRoundPtr(sess)
+	srv.autoMu.Lock()
+	armed := r != nil && srv.autoVoting[sess.Code] == r.ID
+	if armed {
+		delete(srv.autoVoting, sess.Code)
+	}
+	srv.autoMu.Unlock()
+	if !armed {
+		return
+	}
+	// generating the AI answer before voting may take a while, don't hold
+	// up the answer that came in last
+	background("advanceWhenAnswered", sess.Code, func() {
+		if _, err := srv.stepFrom(sess, game.PhaseAnswering, r.Index, "answered"); err != nil {
+			log.Warn().Err(err).Str("code", sess.Code).Msg("failed to open voting after everyone answered")
+		}
+	})
+}
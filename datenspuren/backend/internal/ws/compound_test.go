@@ -0,0 +1,164 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/rs/zerolog/log"
+)
+
+func TestNextRound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 2})
+	sess, _ := rm.Get(code)
+	srv := New(rm, config.Config{})
+	srv.Mount(gin.New())
+	_, aliceToken, _ := sess.Join("Alice")
+	_, bobToken, _ := sess.Join("Bob")
+
+	if err := srv.nextRound(sess, hostToken, "", nil, "", log.Logger); err != errNoPrompt {
+		t.Fatalf("expected errNoPrompt without prompt or queue, got %v", err)
+	}
+	sess.QueuePrompt(hostToken, "Not ready yet?", nil)
+	sess.ImportPrompts(hostToken, []game.DeckPrompt{{Prompt: "Ready?", AIAnswer: "Canned"}})
+	if err := srv.nextRound(sess, hostToken, "", nil, "", log.Logger); err != nil {
+		t.Fatalf("should be able to start the next queued prompt: %v", err)
+	}
+	if r := sess.CurrentRound(); r.Prompt != "Ready?" || r.AISubmissionID == "" {
+		t.Fatalf("expected the queued prompt with its AI answer to start, got %+v", r)
+	}
+
+	waitPhase := func(want game.Phase) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for sess.GetPhase() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected phase %s, got %s", want, sess.GetPhase())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	sess.Submit(aliceToken, "Alice's answer")
+	srv.advanceWhenAnswered(sess)
+	if sess.GetPhase() != game.PhaseAnswering {
+		t.Fatal("expected answering to stay open until everyone answered")
+	}
+	sess.Submit(bobToken, "Bob's answer")
+	srv.advanceWhenAnswered(sess)
+	waitPhase(game.PhaseVoting)
+
+	if err := hostStep(srv, sess, hostToken); err != nil {
+		t.Fatalf("should be able to close voting: %v", err)
+	}
+	if err := srv.nextRound(sess, hostToken, "Second question?", nil, "", log.Logger); err != nil {
+		t.Fatalf("should be able to go from the scoreboard to the next round: %v", err)
+	}
+	if st := sess.PublicState(); st.Phase != game.PhaseAnswering || st.RoundIndex != 2 {
+		t.Fatalf("expected round 2 to be answering, got %+v", st)
+	}
+
+	// rounds started the usual way don't open voting on their own
+	sess.ResetRound(hostToken)
+	srv.setPrompt(sess, hostToken, "Manual question?", nil, "")
+	sess.SetAIAnswer(hostToken, "AI answer")
+	sess.Submit(aliceToken, "Alice's answer")
+	sess.Submit(bobToken, "Bob's answer")
+	srv.advanceWhenAnswered(sess)
+	time.Sleep(50 * time.Millisecond)
+	if sess.GetPhase() != game.PhaseAnswering {
+		t.Fatalf("expected answering to stay open, got %s", sess.GetPhase())
+	}
+
+	hostStep(srv, sess, hostToken) // To Voting
+	hostStep(srv, sess, hostToken) // To Scoreboard
+	if err := srv.nextRound(sess, hostToken, "One too many?", nil, "", log.Logger); err != errNoRoundsLeft {
+		t.Fatalf("expected errNoRoundsLeft, got %v", err)
+	}
+}
+
+func TestNextRoundTakesStepLock(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 3})
+	sess, _ := rm.Get(code)
+	srv := New(rm, config.Config{})
+	srv.Mount(gin.New())
+	_, aliceToken, _ := sess.Join("Alice")
+	srv.setPrompt(sess, hostToken, "First?", nil, "")
+	sess.SetAIAnswer(hostToken, "AI answer")
+	sess.Submit(aliceToken, "Alice's answer")
+	hostStep(srv, sess, hostToken) // To Voting
+	hostStep(srv, sess, hostToken) // To Scoreboard
+
+	// the scoreboard timer is stepping the session on right now
+	mu := srv.stepLock(code)
+	mu.Lock()
+	done := make(chan error, 1)
+	go func() { done <- srv.nextRound(sess, hostToken, "Second?", nil, "", log.Logger) }()
+	time.Sleep(50 * time.Millisecond)
+	if st := sess.PublicState(); st.Phase != game.PhaseScoreboard {
+		t.Fatalf("expected nextRound to wait for the running step, got %s", st.Phase)
+	}
+	mu.Unlock()
+	if err := <-done; err != nil {
+		t.Fatalf("should be able to start the next round: %v", err)
+	}
+	if st := sess.PublicState(); st.Phase != game.PhaseAnswering || st.RoundIndex != 2 {
+		t.Fatalf("expected round 2 to be answering, got %+v", st)
+	}
+}
+
+func TestAdvanceRejectsStaleStep(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 2})
+	sess, _ := rm.Get(code)
+	srv := New(rm, config.Config{})
+	srv.Mount(gin.New())
+	_, aliceToken, _ := sess.Join("Alice")
+	srv.setPrompt(sess, hostToken, "First?", nil, "")
+	sess.SetAIAnswer(hostToken, "AI answer")
+	sess.Submit(aliceToken, "Alice's answer")
+
+	host := newStreamConn(httptest.NewRequest("GET", "/api/session/"+code+"/events", nil), code)
+	host.SetContext(&ConnCtx{Code: code, Token: hostToken, Role: "host"})
+
+	// the answering timer is stepping the session on right now
+	mu := srv.stepLock(code)
+	mu.Lock()
+	done := make(chan map[string]any, 1)
+	go func() {
+		done <- srv.actions["game:advance"](host, json.RawMessage(`{"phase": "Answering", "round": 1}`))
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if err := sess.AdvanceFrom(hostToken, game.PhaseAnswering, 1); err != nil {
+		t.Fatal(err)
+	}
+	mu.Unlock()
+	if ack := <-done; ack["code"] != "stale_step" {
+		t.Fatalf("expected the host's click to be rejected as stale, got %v", ack)
+	}
+	if sess.GetPhase() != game.PhaseVoting {
+		t.Fatalf("expected the session to advance only once, got %s", sess.GetPhase())
+	}
+
+	// without a phase the host advances from wherever the session is
+	if ack := srv.actions["game:advance"](host, nil); ack["error"] != nil {
+		t.Fatalf("should be able to close voting: %v", ack)
+	}
+	if sess.GetPhase() != game.PhaseScoreboard {
+		t.Fatalf("expected the scoreboard, got %s", sess.GetPhase())
+	}
+}
+
+// hostStep advances sess from wherever it is, like a click of its host.
+func hostStep(srv *Server, sess *game.SessionCtx, token string) error {
+	st := sess.PublicState()
+	return srv.advanceFrom(sess, token, st.Phase, st.RoundIndex, log.Logger)
+}
@@ -0,0 +1,241 @@
+package ws
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	socketio "github.com/googollee/go-socket.io"
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/rs/zerolog/log"
+)
+
+// connInfo is what we know about a socket from its handshake.
+type connInfo struct {
+	ConnectedAt time.Time
+	IP          string
+	RTT         time.Duration // last round-trip time reported via net:ping
+	LastPing    time.Time
+}
+
+// Connection describes an active socket of a session for the GM API.
+type Connection struct {
+	SocketID    string    `json:"socketId"`
+	Role        string    `json:"role"`
+	PlayerID    string    `json:"playerId,omitempty"`
+	PlayerName  string    `json:"playerName,omitempty"`
+	ConnectedAt time.Time `json:"connectedAt"`
+	IP          string    `json:"ip"`
+	Quality     string    `json:"quality"`
+	RTTMs       int64     `json:"rttMs,omitempty"`
+}
+
+func (srv *Server) trackConn(s socketio.Conn) {
+	srv.connMu.Lock()
+	defer srv.connMu.Unlock()
+	srv.conns[s.ID()] = connInfo{ConnectedAt: time.Now().UTC(), IP: srv.remoteIP(s)}
+}
+
+// remoteIP is the client's address of a socket, see clientIP.
+func (srv *Server) remoteIP(s socketio.Conn) string {
+	addr := ""
+	if a := s.RemoteAddr(); a != nil {
+		addr = a.String()
+	}
+	return srv.clientIP(addr, s.RemoteHeader())
+}
+
+// clientIP is the address a request came from. X-Forwarded-For is only
+// believed when the peer is one of the trusted proxies, walking the chain
+// back to the first hop that isn't; gin's ClientIP does the same once
+// main passes it the same list, so sockets and HTTP routes agree on who a
+// client is.
+func (srv *Server) clientIP(remoteAddr string, header http.Header) string {
+	ip := remoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		ip = host
+	}
+	if !srv.trustedProxy(ip) {
+		return ip
+	}
+	hops := strings.Split(header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+		if !srv.trustedProxy(hop) {
+			break
+		}
+	}
+	return ip
+}
+
+func (srv *Server) trustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range srv.proxies {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseProxies reads TRUSTED_PROXIES entries, bare IPs or CIDRs. main
+// rejects invalid ones on startup, so they are skipped here.
+func parseProxies(entries []string) []*net.IPNet {
+	var out []*net.IPNet
+	for _, e := range entries {
+		if !strings.Contains(e, "/") {
+			if ip := net.ParseIP(e); ip != nil && ip.To4() != nil {
+				e += "/32"
+			} else {
+				e += "/128"
+			}
+		}
+		if _, n, err := net.ParseCIDR(e); err == nil {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// admit sheds new Socket.IO handshakes once MaxConnections sockets are
+// open, before they cost anything. Requests of established connections
+// carry their sid and always pass.
+func (srv *Server) admit(c *gin.Context) {
+	if srv.closing.Load() {
+		c.Header("Retry-After", "30")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "server_shutdown", "message": "Server is restarting, please try again shortly"})
+		return
+	}
+	if srv.joins != nil && c.Query("sid") == "" && srv.joins.isBlocked(srv.clientIP(c.Request.RemoteAddr, c.Request.Header)) {
+		c.Header("Retry-After", "60")
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate_limited", "message": "Too many join attempts, please try again later"})
+		return
+	}
+	if max := srv.config().MaxConnections; max > 0 && c.Query("sid") == "" && srv.connCount() >= max {
+		c.Header("Retry-After", "30")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "server_full", "message": "Server is at capacity, please try again later"})
+	}
+}
+
+func (srv *Server) connCount() int {
+	srv.connMu.Lock()
+	defer srv.connMu.Unlock()
+	return len(srv.conns)
+}
+
+// sessionFull reports whether a session has reached MaxSessionConns.
+func (srv *Server) sessionFull(code string) bool {
+	max := srv.config().MaxSessionConns
+	return max > 0 && len(srv.membersOf(code)) >= max
+}
+
+func (srv *Server) untrackConn(s socketio.Conn) {
+	srv.connMu.Lock()
+	defer srv.connMu.Unlock()
+	delete(srv.conns, s.ID())
+}
+
+// Connections lists the sockets currently joined to a session.
+func (srv *Server) Connections(code string) []Connection {
+	sess, err := srv.RM.Get(code)
+	if err != nil {
+		return nil
+	}
+	members := srv.membersOf(code)
+	srv.connMu.Lock()
+	defer srv.connMu.Unlock()
+	out := []Connection{}
+	now := time.Now().UTC()
+	for sid, c := range members {
+		ctx, _ := c.Context().(*ConnCtx)
+		if ctx == nil {
+			continue
+		}
+		info := srv.conns[sid]
+		conn := Connection{SocketID: sid, Role: ctx.Role, ConnectedAt: info.ConnectedAt, IP: info.IP, Quality: connQuality(info, now), RTTMs: info.RTT.Milliseconds()}
+		if ctx.Role == "player" {
+			if id := sess.GetPlayerIDByToken(ctx.Token); id != "" {
+				conn.PlayerID = id
+				conn.PlayerName = sess.PlayerName(id)
+			}
+		}
+		out = append(out, conn)
+	}
+	return out
+}
+
+// Disconnect force-closes a socket of a session, e.g. a ghost connection
+// that keeps a stale presence alive. It reports whether the socket existed.
+func (srv *Server) Disconnect(code, socketID string) bool {
+	c := srv.membersOf(code)[socketID]
+	if c == nil {
+		return false
+	}
+	c.Emit("error", map[string]any{"code": "disconnected", "message": "Disconnected by the host"})
+	c.Close()
+	srv.removeMember(code, c)
+	log.Info().Str("code", code).Str("sid", socketID).Msg("socket disconnected by GM")
+	return true
+}
+
+// ConnectionsHandler serves GET /api/host/sessions/:code/connections.
+func (srv *Server) ConnectionsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sess, err := srv.RM.Lookup(c.Param("code"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session_not_found"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"connections": srv.Connections(sess.Code)})
+	}
+}
+
+// DisconnectHandler serves DELETE /api/host/sessions/:code/connections/:sid.
+func (srv *Server) DisconnectHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sess, err := srv.RM.Lookup(c.Param("code"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session_not_found"})
+			return
+		}
+		if !srv.Disconnect(sess.Code, c.Param("sid")) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "connection_not_found"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+}
+
+// SessionStatus is a session in the admin listing with its open sockets.
+type SessionStatus struct {
+	game.SessionInfo
+	Connections int   `json:"connections"`
+	Age         int64 `json:"ageSeconds"` // since it was created
+}
+
+// SessionsHandler serves GET /api/sessions and GET /api/admin/sessions,
+// every session of this instance including ended ones, with its phase,
+// players, open sockets and age.
+func (srv *Server) SessionsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := time.Now()
+		out := []SessionStatus{}
+		for _, info := range srv.RM.Sessions() {
+			out = append(out, SessionStatus{
+				SessionInfo: info,
+				Connections: len(srv.membersOf(info.Code)),
+				Age:         int64(now.Sub(info.CreatedAt).Seconds()),
+			})
+		}
+		c.JSON(http.StatusOK, gin.H{"sessions": out})
+	}
+}
@@ -0,0 +1,177 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	socketio "github.com/googollee/go-socket.io"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+func TestAdmit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	srv := New(game.NewRoomManager(), config.Config{MaxConnections: 1, MaxSessionConns: 1})
+	r := gin.New()
+	r.GET("/socket.io/*any", srv.admit, func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	get := func(url string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		return w.Code
+	}
+	if code := get("/socket.io/?EIO=3&transport=polling"); code != http.StatusOK {
+		t.Fatalf("expected the handshake to be admitted, got %d", code)
+	}
+	srv.conns["a"] = connInfo{}
+	if code := get("/socket.io/?EIO=3&transport=polling"); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected new handshakes to be shed at capacity, got %d", code)
+	}
+	if code := get("/socket.io/?EIO=3&transport=polling&sid=a"); code != http.StatusOK {
+		t.Fatalf("expected established connections to pass, got %d", code)
+	}
+
+	if srv.sessionFull("ABCDE") {
+		t.Fatal("expected an empty session not to be full")
+	}
+	srv.members["ABCDE"] = map[string]socketio.Conn{"a": nil}
+	if !srv.sessionFull("ABCDE") {
+		t.Fatal("expected the session to be full")
+	}
+}
+
+func TestJoinFlood(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, _, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1})
+	srv := New(rm, config.Config{JoinRate: 2, JoinBlock: time.Minute})
+	r := gin.New()
+	srv.Mount(r)
+	join := func(ip, name string) map[string]any {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = ip + ":1234"
+		s := newStreamConn(req, code)
+		return srv.actions["game:join"](s, json.RawMessage(`{"sessionCode": "`+code+`", "name": "`+name+`"}`))
+	}
+
+	for i, want := range []string{"", "", "rate_limited", "rate_limited"} {
+		ack := join("10.0.0.1", fmt.Sprintf("Bot %d", i+1))
+		if got, _ := ack["code"].(string); got != want {
+			t.Fatalf("join %d: expected %q, got %v", i+1, want, ack)
+		}
+	}
+	if ack := join("10.0.0.2", "Alice"); ack["error"] != nil {
+		t.Fatalf("expected joins from other IPs to pass, got %v", ack)
+	}
+	if srv.joins.isBlocked("10.0.0.1") {
+		t.Fatal("expected the IP not to be blocked before it tried at twice the rate")
+	}
+	join("10.0.0.1", "Bot 5")
+	join("10.0.0.1", "Bot 6")
+	if !srv.joins.isBlocked("10.0.0.1") {
+		t.Fatal("expected the flooding IP to be blocked")
+	}
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/socket.io/?EIO=3&transport=polling", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected new sockets of the blocked IP to be turned away, got %d", w.Code)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	srv := New(game.NewRoomManager(), config.Config{TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1"}})
+	fwd := func(v string) http.Header { return http.Header{"X-Forwarded-For": []string{v}} }
+
+	if got := srv.clientIP("203.0.113.7:1234", fwd("1.2.3.4")); got != "203.0.113.7" {
+		t.Fatalf("expected X-Forwarded-For from untrusted peers to be ignored, got %s", got)
+	}
+	if got := srv.clientIP("10.1.2.3:1234", fwd("198.51.100.1")); got != "198.51.100.1" {
+		t.Fatalf("expected the client behind a trusted proxy, got %s", got)
+	}
+	// a client can prepend anything, only the hop our proxies appended counts
+	if got := srv.clientIP("192.168.1.1:1234", fwd("1.2.3.4, 198.51.100.1, 10.0.0.5")); got != "198.51.100.1" {
+		t.Fatalf("expected the first untrusted hop from the right, got %s", got)
+	}
+	if got := New(game.NewRoomManager(), config.Config{}).clientIP("10.1.2.3:1234", fwd("198.51.100.1")); got != "10.1.2.3" {
+		t.Fatalf("expected no proxy to be trusted by default, got %s", got)
+	}
+}
+
+func TestPinGuessing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, _, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1})
+	srv := New(rm, config.Config{})
+	profiles, _ := game.LoadProfiles("")
+	profiles.Claim("Alice", "123456")
+	srv.SetProfiles(profiles)
+	srv.Mount(gin.New())
+	join := func(ip, pin string) map[string]any {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = ip + ":1234"
+		s := newStreamConn(req, code)
+		return srv.actions["game:join"](s, json.RawMessage(`{"sessionCode": "`+code+`", "name": "Alice", "pin": "`+pin+`"}`))
+	}
+
+	if ack := join("10.0.0.1", "1234"); ack["code"] != "invalid_pin" {
+		t.Fatalf("expected a wrong PIN to be refused, got %v", ack)
+	}
+	for i := 1; i < pinAttempts; i++ {
+		join("10.0.0.1", fmt.Sprintf("%06d", i))
+	}
+	if ack := join("10.0.0.1", "123456"); ack["code"] != "rate_limited" {
+		t.Fatalf("expected the guessing IP to be turned away, got %v", ack)
+	}
+	if ack := join("10.0.0.2", "123456"); ack["error"] != nil {
+		t.Fatalf("expected the right PIN to work from other IPs, got %v", ack)
+	}
+	if ack := join("10.0.0.2", "12"); ack["code"] != "invalid_pin" {
+		t.Fatalf("expected a short PIN for a claimed name to count as wrong, got %v", ack)
+	}
+	srv2 := New(rm, config.Config{})
+	srv2.SetProfiles(profiles)
+	srv2.Mount(gin.New())
+	req := httptest.NewRequest("GET", "/", nil)
+	ack := srv2.actions["game:join"](newStreamConn(req, code), json.RawMessage(`{"sessionCode": "`+code+`", "name": "Bob", "pin": "12"}`))
+	if ack["code"] != "pin_too_short" {
+		t.Fatalf("expected new profiles to need a longer PIN, got %v", ack)
+	}
+}
+
+func TestResumeState(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(game.SessionConfig{Hostless: true, Provider: "manual", RoundCount: 1, AllowSelfVote: true, MaxAnswerLength: 80})
+	sess, _ := rm.Get(code)
+	srv := New(rm, config.Config{})
+	srv.Mount(gin.New())
+	_, aliceToken, _ := sess.Join("Alice")
+
+	resume := func(role, token string) map[string]any {
+		t.Helper()
+		s := newStreamConn(httptest.NewRequest("GET", "/", nil), "")
+		raw, _ := json.Marshal(map[string]any{"sessionCode": code, "role": role, "token": token})
+		if ack := srv.actions["game:resume"](s, raw); ack["error"] != nil {
+			t.Fatalf("%s should be able to resume: %v", role, ack)
+		}
+		ev := <-s.events
+		state, _ := ev.Data.(map[string]any)
+		if ev.Name != "game:state" || state["hostless"] != true || state["allowSelfVote"] != true || state["maxAnswerLength"] != 80 || state["ready"] == nil {
+			t.Fatalf("expected %s to get the full state on resume, got %s %v", role, ev.Name, state)
+		}
+		return state
+	}
+	if state := resume("player", aliceToken); state["connectivity"] != nil {
+		t.Fatal("expected connectivity to stay with the host")
+	}
+	if state := resume("host", hostToken); state["connectivity"] == nil {
+		t.Fatal("expected the host to get connectivity on resume")
+	}
+}
@@ -0,0 +1,101 @@
+package ws
+
+import (
+	"time"
+
+	socketio "github.com/googollee/go-socket.io"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+// Connection quality as shown to the host, judged from the round-trip times
+// clients measure with net:ping. It tells "Bob is still thinking" apart from
+// "Bob's phone is offline".
+const (
+	QualityGood    = "good"    // under 300ms
+	QualityFair    = "fair"    // under a second
+	QualityPoor    = "poor"    // slower, actions arrive late
+	QualityOffline = "offline" // no socket, or no ping for pingStale
+)
+
+// pingStale is how long a connection may go without a ping before it counts
+// as offline. Clients ping every 5 seconds.
+const pingStale = 15 * time.Second
+
+var qualityRank = map[string]int{QualityOffline: 0, QualityPoor: 1, QualityFair: 2, QualityGood: 3}
+
+// PlayerConnectivity is how well a player can currently be reached.
+type PlayerConnectivity struct {
+	Quality  string    `json:"quality"`
+	RTTMs    int64     `json:"rttMs,omitempty"`
+	LastSeen time.Time `json:"lastSeen,omitzero"`
+}
+
+func connQuality(info connInfo, now time.Time) string {
+	seen := info.LastPing
+	if seen.IsZero() {
+		seen = info.ConnectedAt
+	}
+	switch {
+	case now.Sub(seen) > pingStale:
+		return QualityOffline
+	case info.RTT < 300*time.Millisecond:
+		return QualityGood
+	case info.RTT < time.Second:
+		return QualityFair
+	default:
+		return QualityPoor
+	}
+}
+
+// recordPing notes that a socket is alive, with the round-trip time the
+// client measured for its previous ping (0 if unknown).
+func (srv *Server) recordPing(s socketio.Conn, rtt time.Duration) {
+	srv.connMu.Lock()
+	defer srv.connMu.Unlock()
+	info, ok := srv.conns[s.ID()]
+	if !ok {
+		return
+	}
+	if rtt > 0 {
+		info.RTT = rtt
+	}
+	info.LastPing = time.Now().UTC()
+	srv.conns[s.ID()] = info
+}
+
+// Connectivity reports for every player of a session how well their best
+// connection does. Players without a socket are offline, bots are left out.
+func (srv *Server) Connectivity(sess *game.SessionCtx) map[string]PlayerConnectivity {
+	out := map[string]PlayerConnectivity{}
+	for _, p := range sess.Players() {
+		if !p.IsBot {
+			out[p.ID] = PlayerConnectivity{Quality: QualityOffline}
+		}
+	}
+	members := srv.membersOf(sess.Code)
+	now := time.Now().UTC()
+	srv.connMu.Lock()
+	defer srv.connMu.Unlock()
+	for sid, c := range members {
+		ctx, _ := c.Context().(*ConnCtx)
+		if ctx == nil || ctx.Role != "player" {
+			continue
+		}
+		id := sess.GetPlayerIDByToken(ctx.Token)
+		info, ok := srv.conns[sid]
+		best, known := out[id]
+		if !ok || !known {
+			continue
+		}
+		if q := connQuality(info, now); qualityRank[q] > qualityRank[best.Quality] || q == best.Quality && info.LastPing.After(best.LastSeen) {
+			out[id] = PlayerConnectivity{Quality: q, RTTMs: info.RTT.Milliseconds(), LastSeen: info.LastPing}
+		}
+	}
+	return out
+}
+
+// notifyConnectivity sends the hosts of a session the current connectivity
+// of its players.
+func (srv *Server) notifyConnectivity(sess *game.SessionCtx) {
+	srv.emitToHosts(sess.Code, "game:connectivity", srv.Connectivity(sess))
+}
@@ -0,0 +1,25 @@
+package ws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConnQuality(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		info connInfo
+		want string
+	}{
+		{connInfo{ConnectedAt: now}, QualityGood},
+		{connInfo{ConnectedAt: now.Add(-time.Minute)}, QualityOffline},
+		{connInfo{ConnectedAt: now.Add(-time.Minute), LastPing: now, RTT: 80 * time.Millisecond}, QualityGood},
+		{connInfo{LastPing: now, RTT: 500 * time.Millisecond}, QualityFair},
+		{connInfo{LastPing: now, RTT: 3 * time.Second}, QualityPoor},
+		{connInfo{LastPing: now.Add(-pingStale - time.Second), RTT: 80 * time.Millisecond}, QualityOffline},
+	} {
+		if got := connQuality(tc.info, now); got != tc.want {
+			t.Fatalf("expected %s for %+v, got %s", tc.want, tc.info, got)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package ws
+
+import (
+	"time"
+
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+// scheduleCues arms "game:cue" events for the session's current phase timer,
+// one per configured remaining-time threshold above 0, replacing the cues of
+// the previous phase. Clients map them to sounds or vibration, so all devices
+// fire in sync with the server's timer.
+func (srv *Server) scheduleCues(sess *game.SessionCtx) {
+	srv.cueMu.Lock()
+	defer srv.cueMu.Unlock()
+	for _, t := range srv.cues[sess.Code] {
+		t.Stop()
+	}
+	delete(srv.cues, sess.Code)
+
+	deadline, ok := sess.PhaseDeadline()
+	if !ok {
+		return
+	}
+	phase := sess.GetPhase()
+	round := 0
+	if r := currentRoundPtr(sess); r != nil {
+		round = r.Index
+	}
+	for _, remaining := range srv.config().CueThresholds {
+		if remaining <= 0 {
+			// the phase timer sends the time-up cue as it ends the phase,
+			// see expireTimer
+			continue
+		}
+		wait := time.Until(deadline.Add(-time.Duration(remaining) * time.Second))
+		if wait < 0 {
+			// phase is shorter than this threshold
+			continue
+		}
+		remaining := remaining
+		srv.cues[sess.Code] = append(srv.cues[sess.Code], time.AfterFunc(wait, func() {
+			// the phase may have moved on while the timer fired
+			if sess.GetPhase() != phase {
+				return
+			}
+			if d, ok := sess.PhaseDeadline(); !ok || !d.Equal(deadline) {
+				return
+			}
+			srv.emitCue(sess.Code, cuePayload(phase, round, remaining, deadline))
+		}))
+	}
+}
+
+func cuePayload(phase game.Phase, round, remaining int, deadline time.Time) map[string]any {
+	return map[string]any{
+		"phase":      phase,
+		"roundIndex": round,
+		"remaining":  remaining, // seconds left, 0 when time is up
+		"deadline":   deadline.UnixMilli(),
+		"serverTime": time.Now().UnixMilli(),
+	}
+}
+
+// deadlineMillis is the phase deadline for state payloads, nil without a
+// timer.
+func deadlineMillis(sess *game.SessionCtx) any {
+	if d, ok := sess.PhaseDeadline(); ok {
+		return d.UnixMilli()
+	}
+	return nil
+}
+
+func (srv *Server) emitCue(code string, payload map[string]any) {
+	srv.broadcast(code, "game:cue", payload)
+	srv.overlay.publish(code, overlayEvent{Name: "cue", Data: payload})
+}
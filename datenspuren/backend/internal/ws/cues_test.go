@@ -0,0 +1,106 @@
+package ws
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+func TestCues(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1, AnswerTime: 1})
+	sess, _ := rm.Get(code)
+	srv := New(rm, config.Config{CueThresholds: []int{30, 0}})
+	srv.Mount(gin.New())
+	ch := srv.overlay.subscribe(code)
+	defer srv.overlay.unsubscribe(code, ch)
+
+	if err := srv.setPrompt(sess, hostToken, "Test question?", nil, ""); err != nil {
+		t.Fatalf("should be able to set the prompt: %v", err)
+	}
+	srv.cueMu.Lock()
+	n := len(srv.cues[code])
+	srv.cueMu.Unlock()
+	if n != 0 {
+		t.Fatalf("expected no scheduled cue for a 1s phase, the timer sends the time-up cue, got %d", n)
+	}
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case ev := <-ch:
+			if ev.Name != "cue" {
+				continue
+			}
+			data := ev.Data.(map[string]any)
+			if data["remaining"] != 0 || data["phase"] != game.PhaseAnswering {
+				t.Fatalf("expected the time-up cue for answering, got %+v", data)
+			}
+			return
+		case <-timeout:
+			t.Fatal("expected a cue when the answer time ran out")
+		}
+	}
+}
+
+func TestPhaseTimerAdvances(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1, AnswerTime: 1, VoteTime: 30})
+	sess, _ := rm.Get(code)
+	srv := New(rm, config.Config{})
+	srv.Mount(gin.New())
+	_, aliceToken, _ := sess.Join("Alice")
+	sess.Join("Bob") // disconnected, never answers
+
+	if err := srv.setPrompt(sess, hostToken, "Test question?", nil, ""); err != nil {
+		t.Fatalf("should be able to set the prompt: %v", err)
+	}
+	sess.Submit(aliceToken, "Alice's answer")
+	sess.SetAIAnswer(hostToken, "The AI's answer")
+	deadline := time.Now().Add(3 * time.Second)
+	for sess.GetPhase() == game.PhaseAnswering {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the answer timer to open voting")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if sess.GetPhase() != game.PhaseVoting {
+		t.Fatalf("expected voting after the answer time ran out, got %s", sess.GetPhase())
+	}
+	if !srv.timers.Running(code) {
+		t.Fatal("expected the vote timer to be running")
+	}
+}
+
+func TestOverlayDelaysInitialState(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, _, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1, StreamDelay: 1})
+	sess, _ := rm.Get(code)
+	srv := New(rm, config.Config{})
+	r := gin.New()
+	r.GET("/api/session/:code/overlay", srv.OverlayHandler())
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	start := time.Now()
+	res, err := http.Get(ts.URL + "/api/session/" + code + "/overlay?token=" + sess.OverlayToken)
+	if err != nil {
+		t.Fatalf("should be able to open the overlay feed: %v", err)
+	}
+	defer res.Body.Close()
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+	if err != nil || line != "event:state\n" {
+		t.Fatalf("expected the state snapshot first, got %q (%v)", line, err)
+	}
+	if waited := time.Since(start); waited < time.Second {
+		t.Fatalf("expected the snapshot to be held back by the stream delay, came after %v", waited)
+	}
+}
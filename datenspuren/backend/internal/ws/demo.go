@@ -0,0 +1,218 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/rs/zerolog/log"
+)
+
+// Demo scripts the games a --demo server plays on its own, so newcomers and
+// frontend devs see a full game loop without any configuration. Humans may
+// join the demo session and play along with the bots.
+type Demo struct {
+	Rounds     int
+	AnswerTime time.Duration // answering closes after this, or once everyone answered
+	VoteTime   time.Duration // voting closes after this, or once everyone voted
+	Pause      time.Duration // time spent in the lobby, on scoreboards and the end screen
+}
+
+// DemoProvider is the provider name demo sessions are created with; it must
+// be registered with SetProviders.
+const DemoProvider = "mock"
+
+var demoBots = []string{"Ada", "Grace", "Linus", "Margaret"}
+
+var demoPrompts = []string{
+	"Warum ist der Himmel blau?",
+	"Was ist das Geheimnis eines guten Kuchens?",
+	"Wie überzeugt man eine Katze, vom Tisch zu gehen?",
+	"Was macht ein Pinguin in seiner Freizeit?",
+	"Warum gibt es Montage?",
+	"Wofür wurde das Internet ursprünglich erfunden?",
+}
+
+var demoAnswers = []string{
+	"Weil es schon immer so war.",
+	"Das weiß niemand so genau, aber es hat mit Physik zu tun.",
+	"Mit sehr viel Butter.",
+	"Ganz klar: Verschwörung der Bäckereien.",
+	"Das ist eine Frage, die nur Oma beantworten kann.",
+	"Aus Versehen, wie die meisten guten Dinge.",
+	"Man muss es einfach oft genug versuchen.",
+}
+
+type demoBot struct {
+	id, token string
+}
+
+// RunDemo plays demo games back to back until ctx is done. Every game gets
+// a fresh session that replaces the previous one.
+func (srv *Server) RunDemo(ctx context.Context, d Demo) {
+	prev := ""
+	for ctx.Err() == nil {
+		sess, bots, err := srv.startDemo(d)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to create demo session")
+			if !wait(ctx, d.Pause) {
+				return
+			}
+			continue
+		}
+		if prev != "" {
+			srv.RM.Remove(prev)
+		}
+		prev = sess.Code
+		if err := srv.playDemo(ctx, sess, bots, d); err != nil {
+			log.Error().Err(err).Str("code", sess.Code).Msg("demo game failed")
+		}
+	}
+}
+
+// startDemo creates a session seeded with queued prompts and bot players.
+func (srv *Server) startDemo(d Demo) (*game.SessionCtx, []demoBot, error) {
+	rounds := min(d.Rounds, len(demoPrompts))
+	export := false
+	code, hostToken, err := srv.RM.CreateSession(game.SessionConfig{
+		Provider:   DemoProvider,
+		Model:      DemoProvider,
+		RoundCount: rounds,
+		AnswerTime: int(d.AnswerTime / time.Second),
+		VoteTime:   int(d.VoteTime / time.Second),
+		Public:     true,
+		Export:     &export,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	sess, err := srv.RM.Get(code)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, i := range rand.Perm(len(demoPrompts))[:rounds] {
+		if _, err := sess.QueuePrompt(hostToken, demoPrompts[i], nil); err != nil {
+			return nil, nil, err
+		}
+	}
+	bots := make([]demoBot, 0, len(demoBots))
+	for _, name := range demoBots {
+		id, token, err := sess.Join(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		bots = append(bots, demoBot{id: id, token: token})
+	}
+	log.Info().Str("code", code).Str("pin", sess.JoinPin).Msg("demo session ready")
+	return sess, bots, nil
+}
+
+// playDemo hosts a demo session from the lobby to the end screen.
+func (srv *Server) playDemo(ctx context.Context, sess *game.SessionCtx, bots []demoBot, d Demo) error {
+	srv.emitStateTo(sess.Code)
+	if !wait(ctx, d.Pause) {
+		return nil
+	}
+	for _, q := range sess.PromptQueue() {
+		if err := srv.setPrompt(sess, sess.HostToken, "", nil, q.ID); err != nil {
+			return err
+		}
+		round := sess.PublicState().RoundIndex
+		if !demoPhase(ctx, d.AnswerTime, srv.demoSubmits(sess, bots), func() bool { return answeringDone(sess) }) {
+			return nil
+		}
+		// the phase timer may have ended the phase already
+		if _, err := srv.stepFrom(sess, game.PhaseAnswering, round, "demo"); err != nil {
+			return err
+		}
+		if sess.GetPhase() == game.PhaseVoting {
+			if !demoPhase(ctx, d.VoteTime, srv.demoVotes(sess, bots), func() bool { return votingDone(sess) }) {
+				return nil
+			}
+			if _, err := srv.stepFrom(sess, game.PhaseVoting, round, "demo"); err != nil {
+				return err
+			}
+		}
+		if !wait(ctx, d.Pause) {
+			return nil
+		}
+		st := sess.PublicState()
+		if err := srv.advanceFrom(sess, sess.HostToken, st.Phase, st.RoundIndex, log.Logger); err != nil && !errors.Is(err, game.ErrStaleStep) {
+			return err
+		}
+	}
+	wait(ctx, d.Pause)
+	return nil
+}
+
+func (srv *Server) demoSubmits(sess *game.SessionCtx, bots []demoBot) []func() {
+	answers := rand.Perm(len(demoAnswers))
+	actions := make([]func(), 0, len(bots))
+	for i, b := range bots {
+		text := demoAnswers[answers[i%len(answers)]]
+		actions = append(actions, func() {
+			if id, err := sess.Submit(b.token, text); err == nil {
+				srv.translateSubmission(sess, id, sess.SubmissionText(id))
+				srv.notifySubmissions(sess)
+			}
+		})
+	}
+	rand.Shuffle(len(actions), func(i, j int) { actions[i], actions[j] = actions[j], actions[i] })
+	return actions
+}
+
+func (srv *Server) demoVotes(sess *game.SessionCtx, bots []demoBot) []func() {
+	actions := make([]func(), 0, len(bots))
+	for _, b := range bots {
+		actions = append(actions, func() {
+			var targets []string
+			for _, sub := range sess.ListVotingSubmissionsFor(b.id) {
+				if sub.PlayerID != b.id {
+					targets = append(targets, sub.ID)
+				}
+			}
+			if len(targets) == 0 {
+				return
+			}
+			if sess.Vote(b.token, targets[rand.Intn(len(targets))]) == nil {
+				srv.notifyVotes(sess)
+			}
+		})
+	}
+	rand.Shuffle(len(actions), func(i, j int) { actions[i], actions[j] = actions[j], actions[i] })
+	return actions
+}
+
+// demoPhase performs the bots' actions one by one, spread over the first
+// half of limit, and returns once done reports true or limit has passed.
+// It returns false if ctx was cancelled.
+func demoPhase(ctx context.Context, limit time.Duration, actions []func(), done func() bool) bool {
+	step := limit / time.Duration(2*len(actions)+2)
+	deadline := time.Now().Add(limit)
+	for time.Now().Before(deadline) {
+		if len(actions) > 0 {
+			actions[0]()
+			actions = actions[1:]
+		} else if done() {
+			return true
+		}
+		if !wait(ctx, step) {
+			return false
+		}
+	}
+	return ctx.Err() == nil
+}
+
+// wait sleeps for d and reports whether ctx is still alive afterwards.
+func wait(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,44 @@
+package ws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/ai/mock"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+func TestDemo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	srv := New(rm, config.Config{})
+	srv.SetProviders(map[string]AIProvider{DemoProvider: &mock.Client{}})
+	srv.Mount(gin.New())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.RunDemo(ctx, Demo{Rounds: 2, AnswerTime: 300 * time.Millisecond, VoteTime: 300 * time.Millisecond, Pause: 300 * time.Millisecond})
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, sess := rm.Active(); sess != nil && sess.GetPhase() == game.PhaseEnd {
+			summary := sess.Summary()
+			if len(summary.Rounds) != 2 {
+				t.Fatalf("expected the demo to play 2 rounds, got %d", len(summary.Rounds))
+			}
+			for _, r := range summary.Rounds {
+				if r.AISubmissionID == "" || len(r.Submissions) != len(demoBots)+1 {
+					t.Fatalf("expected every bot and the AI to answer, got %+v", r)
+				}
+				if r.TotalVotes != len(demoBots) {
+					t.Fatalf("expected every bot to vote, got %d votes", r.TotalVotes)
+				}
+			}
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("expected the demo game to reach the end screen")
+}
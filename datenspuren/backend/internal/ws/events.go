@@ -0,0 +1,284 @@
+package ws
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	socketio "github.com/googollee/go-socket.io"
+	"github.com/rs/zerolog/log"
+)
+
+// action is a socket event handler as registered by on, callable for
+// clients that don't speak Socket.IO.
+type action func(s socketio.Conn, raw json.RawMessage) map[string]any
+
+// streamEvent is one Socket.IO event queued for an event stream.
+type streamEvent struct {
+	Name string
+	Data any
+}
+
+// streamConn stands in for a socket when a client follows the game over
+// Server-Sent Events and acts via plain HTTP POSTs. It joins sessions like
+// a socket does, so everything emitted to a session's members reaches it.
+// Its ID is the only credential of the POST endpoint and therefore random.
+type streamConn struct {
+	id     string
+	code   string // session the stream was opened for
+	header http.Header
+	remote net.Addr
+	url    url.URL
+	events chan streamEvent
+	done   chan struct{}
+
+	mu    sync.Mutex
+	ctx   any
+	rooms map[string]struct{}
+	once  sync.Once
+}
+
+func newStreamConn(r *http.Request, code string) *streamConn {
+	c := &streamConn{
+		id:     "sse-" + newRequestID() + newRequestID(),
+		code:   code,
+		header: r.Header.Clone(),
+		url:    *r.URL,
+		// a burst like voting plus results plus state per player fits easily
+		events: make(chan streamEvent, 64),
+		done:   make(chan struct{}),
+		rooms:  map[string]struct{}{},
+	}
+	if addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+		c.remote = addr
+	}
+	return c
+}
+
+func (c *streamConn) ID() string                { return c.id }
+func (c *streamConn) URL() url.URL              { return c.url }
+func (c *streamConn) LocalAddr() net.Addr       { return nil }
+func (c *streamConn) RemoteAddr() net.Addr      { return c.remote }
+func (c *streamConn) RemoteHeader() http.Header { return c.header }
+func (c *streamConn) Namespace() string         { return "/" }
+
+func (c *streamConn) Context() any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ctx
+}
+
+func (c *streamConn) SetContext(ctx any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ctx = ctx
+}
+
+func (c *streamConn) Join(room string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rooms[room] = struct{}{}
+}
+
+func (c *streamConn) Leave(room string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.rooms, room)
+}
+
+func (c *streamConn) LeaveAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rooms = map[string]struct{}{}
+}
+
+func (c *streamConn) Close() error {
+	c.once.Do(func() { close(c.done) })
+	return nil
+}
+
+func (c *streamConn) Rooms() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]string, 0, len(c.rooms))
+	for room := range c.rooms {
+		out = append(out, room)
+	}
+	return out
+}
+
+func (c *streamConn) inRoom(room string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.rooms[room]
+	return ok
+}
+
+// Emit queues an event. A client too slow to keep up is disconnected
+// rather than silently missing state; EventSource reconnects on its own and
+// gets the current state again.
+func (c *streamConn) Emit(event string, v ...any) {
+	var data any
+	if len(v) > 0 {
+		data = v[0]
+	}
+	select {
+	case <-c.done:
+	case c.events <- streamEvent{Name: event, Data: data}:
+	default:
+		log.Warn().Str("sid", c.id).Str("event", event).Msg("event stream too slow, closing")
+		c.Close()
+	}
+}
+
+// broadcast emits an event to everyone in a session, over Socket.IO and
+// over event streams.
+func (srv *Server) broadcast(code, event string, payload any) {
+	srv.io.BroadcastToRoom("/", code, event, payload)
+	for _, c := range srv.membersOf(code) {
+		if sc, ok := c.(*streamConn); ok && sc.inRoom(code) {
+			sc.Emit(event, payload)
+		}
+	}
+}
+
+// dropConn forgets a socket or event stream that went away.
+func (srv *Server) dropConn(s socketio.Conn) {
+	if ctx, ok := s.Context().(*ConnCtx); ok && ctx.Code != "" {
+		srv.removeMember(ctx.Code, s)
+		if ctx.Role == "spectator" {
+			srv.emitStateTo(ctx.Code)
+		}
+		if sess, err := srv.RM.Get(ctx.Code); err == nil && ctx.Role == "player" {
+			srv.notifyConnectivity(sess)
+		}
+	}
+	srv.untrackConn(s)
+	if srv.eventLimit != nil {
+		srv.eventLimit.Forget(s.ID())
+	}
+}
+
+// EventsHandler streams a session's game events (game:state, game:voting,
+// game:results and the rest) as Server-Sent Events, for clients behind
+// proxies that break Socket.IO. With a host or player token in ?token= or a
+// Bearer header the stream resumes that role right away; without one it
+// starts unjoined, like a fresh socket, and can join or spectate via
+// ActionHandler. The first event, "connected", carries the stream's ID.
+func (srv *Server) EventsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sess, err := srv.RM.Lookup(c.Param("code"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session_not_found"})
+			return
+		}
+		token := c.Query("token")
+		if token == "" {
+			token = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		}
+		role, _ := srv.authenticate(sess, token)
+		if token != "" && role == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		if srv.closing.Load() {
+			c.Header("Retry-After", "30")
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "server_shutdown", "message": "Server is restarting, please try again shortly"})
+			return
+		}
+		if role == "" && srv.config().MaxConnections > 0 && srv.connCount() >= srv.config().MaxConnections {
+			c.Header("Retry-After", "30")
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "server_full", "message": "Server is at capacity, please try again later"})
+			return
+		}
+
+		s := newStreamConn(c.Request, sess.Code)
+		s.SetContext(&ConnCtx{})
+		srv.trackConn(s)
+		srv.streamMu.Lock()
+		srv.streams[s.ID()] = s
+		srv.streamMu.Unlock()
+		defer func() {
+			srv.streamMu.Lock()
+			delete(srv.streams, s.ID())
+			srv.streamMu.Unlock()
+			s.Close()
+			srv.dropConn(s)
+			log.Info().Str("sid", s.ID()).Msg("event stream disconnected")
+		}()
+		log.Info().Str("sid", s.ID()).Str("code", sess.Code).Str("ip", c.ClientIP()).Msg("event stream connected")
+
+		c.Header("Cache-Control", "no-cache")
+		c.Header("X-Accel-Buffering", "no")
+		c.SSEvent("connected", map[string]any{"sid": s.ID(), "sessionCode": sess.Code})
+		c.Writer.Flush()
+		if role != "" {
+			raw, _ := json.Marshal(map[string]any{"sessionCode": sess.Code, "role": role, "token": token})
+			if ack := srv.actions["game:resume"](s, raw); ack["error"] != nil {
+				return
+			}
+		}
+
+		heartbeat := time.NewTicker(15 * time.Second)
+		defer heartbeat.Stop()
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case <-c.Request.Context().Done():
+				return false
+			case <-s.done:
+				// pass on what was sent before closing, like game:closed
+				for {
+					select {
+					case ev := <-s.events:
+						c.SSEvent(ev.Name, ev.Data)
+					default:
+						return false
+					}
+				}
+			case ev := <-s.events:
+				c.SSEvent(ev.Name, ev.Data)
+			case <-heartbeat.C:
+				if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+					return false
+				}
+				// the write went through, so the client is still there
+				srv.recordPing(s, 0)
+			}
+			return true
+		})
+	}
+}
+
+// ActionHandler serves POST /api/session/:code/events/:sid/:event, the
+// client-to-server half of EventsHandler: the JSON body is the event's
+// payload and the response is what a socket would get as acknowledgement.
+func (srv *Server) ActionHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		srv.streamMu.Lock()
+		s := srv.streams[c.Param("sid")]
+		srv.streamMu.Unlock()
+		sess, err := srv.RM.Lookup(c.Param("code"))
+		if s == nil || err != nil || sess.Code != s.code {
+			c.JSON(http.StatusNotFound, gin.H{"error": "stream_not_found"})
+			return
+		}
+		h := srv.actions[c.Param("event")]
+		if h == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "unknown_event"})
+			return
+		}
+		raw, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, 1<<20))
+		if err != nil {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "payload_too_large"})
+			return
+		}
+		srv.recordPing(s, 0)
+		c.JSON(http.StatusOK, h(s, raw))
+	}
+}
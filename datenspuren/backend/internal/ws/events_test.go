@@ -0,0 +1,171 @@
+package ws
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+func TestEventStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1})
+	sess, _ := rm.Get(code)
+	srv := New(rm, config.Config{})
+	r := gin.New()
+	srv.Mount(r)
+	r.GET("/api/session/:code/events", srv.EventsHandler())
+	r.POST("/api/session/:code/events/:sid/:event", srv.ActionHandler())
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	if res, err := http.Get(ts.URL + "/api/session/" + code + "/events?token=wrong"); err != nil || res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected an unknown token to be rejected, got %v %v", res, err)
+	}
+
+	res, err := http.Get(ts.URL + "/api/session/" + code + "/events")
+	if err != nil {
+		t.Fatalf("should be able to open the event stream: %v", err)
+	}
+	defer res.Body.Close()
+	events := make(chan [2]string, 16)
+	go func() {
+		sc := bufio.NewScanner(res.Body)
+		name := ""
+		for sc.Scan() {
+			line := sc.Text()
+			switch {
+			case strings.HasPrefix(line, "event:"):
+				name = strings.TrimPrefix(line, "event:")
+			case strings.HasPrefix(line, "data:"):
+				events <- [2]string{name, strings.TrimPrefix(line, "data:")}
+			}
+		}
+	}()
+	next := func(name string) map[string]any {
+		t.Helper()
+		timeout := time.After(3 * time.Second)
+		for {
+			select {
+			case ev := <-events:
+				if ev[0] != name {
+					continue
+				}
+				var data map[string]any
+				if err := json.Unmarshal([]byte(ev[1]), &data); err != nil {
+					t.Fatalf("should be able to decode %s: %v", name, err)
+				}
+				return data
+			case <-timeout:
+				t.Fatalf("expected a %s event", name)
+			}
+		}
+	}
+	post := func(sid, event, body string) map[string]any {
+		t.Helper()
+		res, err := http.Post(ts.URL+"/api/session/"+code+"/events/"+sid+"/"+event, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("should be able to post %s: %v", event, err)
+		}
+		defer res.Body.Close()
+		var ack map[string]any
+		json.NewDecoder(res.Body).Decode(&ack)
+		return ack
+	}
+
+	sid, _ := next("connected")["sid"].(string)
+	if sid == "" {
+		t.Fatal("expected the stream ID in the connected event")
+	}
+	if ack := post("sse-unknown", "game:join", `{}`); ack["error"] != "stream_not_found" {
+		t.Fatalf("expected actions on an unknown stream to fail, got %+v", ack)
+	}
+	ack := post(sid, "game:join", `{"sessionCode":"`+code+`","name":"Alice"}`)
+	playerID, _ := ack["playerId"].(string)
+	if playerID == "" {
+		t.Fatalf("should be able to join over HTTP, got %+v", ack)
+	}
+	if you, _ := next("game:state")["you"].(map[string]any); you["playerId"] != playerID {
+		t.Fatalf("expected the personal state after joining, got %+v", you)
+	}
+
+	if err := srv.setPrompt(sess, hostToken, "Test question?", nil, ""); err != nil {
+		t.Fatalf("should be able to set the prompt: %v", err)
+	}
+	for state := next("game:state"); state["phase"] != string(game.PhaseAnswering); state = next("game:state") {
+	}
+	if ack := post(sid, "game:submit", `{"text":"Alice's answer"}`); ack["submissionId"] == nil {
+		t.Fatalf("should be able to submit over HTTP, got %+v", ack)
+	}
+	if got := next("game:submissions"); got["count"] != float64(1) {
+		t.Fatalf("expected the broadcast submission count, got %+v", got)
+	}
+
+	// with a token the stream resumes the role right away
+	host, err := http.Get(ts.URL + "/api/session/" + code + "/events?token=" + hostToken)
+	if err != nil {
+		t.Fatalf("should be able to stream as host: %v", err)
+	}
+	defer host.Body.Close()
+	sc := bufio.NewScanner(host.Body)
+	for sc.Scan() && sc.Text() != "event:game:state" {
+	}
+	sc.Scan()
+	var state struct{ You map[string]any }
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(sc.Text(), "data:")), &state); err != nil || state.You["role"] != "host" {
+		t.Fatalf("expected the host's state, got %q", sc.Text())
+	}
+}
+
+func TestSpectatorCount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, _, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1})
+	srv := New(rm, config.Config{})
+	srv.Mount(gin.New())
+	spectate := func() *streamConn {
+		t.Helper()
+		c := newStreamConn(httptest.NewRequest(http.MethodGet, "/", nil), code)
+		if ack := srv.actions["game:spectate"](c, json.RawMessage(`{"sessionCode": "`+code+`"}`)); ack["error"] != nil {
+			t.Fatalf("should be able to spectate: %v", ack)
+		}
+		return c
+	}
+	// lastCount drains c and returns the spectator count of the last state
+	lastCount := func(c *streamConn) any {
+		t.Helper()
+		var n any
+		for {
+			select {
+			case ev := <-c.events:
+				if ev.Name == "game:state" {
+					n = ev.Data.(map[string]any)["spectators"]
+				}
+			default:
+				return n
+			}
+		}
+	}
+
+	first := spectate()
+	if n := lastCount(first); n != 1 {
+		t.Fatalf("expected one spectator, got %v", n)
+	}
+	second := spectate()
+	if n := lastCount(first); n != 2 {
+		t.Fatalf("expected the others to learn about a new spectator, got %v", n)
+	}
+	lastCount(second)
+	srv.dropConn(second)
+	if n := lastCount(first); n != 1 {
+		t.Fatalf("expected a leaving spectator to be counted out, got %v", n)
+	}
+}
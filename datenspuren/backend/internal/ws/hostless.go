@@ -0,0 +1,68 @@
+package ws
+
+import (
+	"time"
+
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/rs/zerolog/log"
+)
+
+// hostlessPause is how long a hostless session shows the scoreboard before
+// the next round, unless everyone is ready sooner.
+const hostlessPause = 20 * time.Second
+
+// driveHostless arms the step that ends a hostless session's scoreboard
+// pause when not everyone gets ready sooner. Answering and voting end with
+// their phase timer like in every session. Call it after every transition.
+func (srv *Server) driveHostless(sess *game.SessionCtx) {
+	if !sess.Config.Hostless {
+		return
+	}
+	srv.autoMu.Lock()
+	defer srv.autoMu.Unlock()
+	if t := srv.autoTimers[sess.Code]; t != nil {
+		t.Stop()
+		delete(srv.autoTimers, sess.Code)
+	}
+	if sess.GetPhase() != game.PhaseScoreboard {
+		return
+	}
+	step := srv.hostlessStepFunc(sess, "timeout")
+	srv.autoTimers[sess.Code] = time.AfterFunc(hostlessPause, func() { background("hostlessStep", sess.Code, step) })
+}
+
+// hostlessStepAsync moves a hostless session on from phase in the
+// background, e.g. because the last player answered, so that player's
+// request doesn't wait for the AI answer generated before voting.
+func (srv *Server) hostlessStepAsync(sess *game.SessionCtx, from game.Phase, reason string) {
+	st := sess.PublicState()
+	if st.Phase != from {
+		return
+	}
+	background("hostlessStep", sess.Code, func() { srv.hostlessStep(sess, from, st.RoundIndex, reason) })
+}
+
+// hostlessStepFunc captures the session's phase and round now and returns
+// the step that moves on from there. The step does nothing if the session
+// has moved on in the meantime, so racing triggers advance only once.
+func (srv *Server) hostlessStepFunc(sess *game.SessionCtx, reason string) func() {
+	st := sess.PublicState()
+	return func() { srv.hostlessStep(sess, st.Phase, st.RoundIndex, reason) }
+}
+
+func (srv *Server) hostlessStep(sess *game.SessionCtx, phase game.Phase, round int, reason string) {
+	if _, err := srv.stepFrom(sess, phase, round, reason); err != nil {
+		log.Warn().Err(err).Str("code", sess.Code).Str("reason", reason).Msg("hostless session failed to move on")
+	}
+}
+
+// drawPrompt picks a prompt not played in the session yet from the prompt
+// library and the built-in prompts, in the session's language if possible.
+func (srv *Server) drawPrompt(sess *game.SessionCtx) string {
+	played := sess.PlayedPrompts()
+	p, err := game.RandomPrompt(srv.library, "", sess.Config.Language, played)
+	if err != nil {
+		p, _ = game.RandomPrompt(srv.library, "", "", played)
+	}
+	return p.Prompt
+}
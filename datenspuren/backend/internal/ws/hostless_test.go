@@ -0,0 +1,64 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+func TestHostlessSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, _, _ := rm.CreateSession(game.SessionConfig{Hostless: true, Provider: "manual", AITrigger: game.AITriggerVoting, RoundCount: 1})
+	sess, _ := rm.Get(code)
+	srv := New(rm, config.Config{})
+	srv.Mount(gin.New())
+	_, aliceToken, _ := sess.Join("Alice")
+	_, bobToken, _ := sess.Join("Bob")
+
+	waitPhase := func(want game.Phase) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for sess.GetPhase() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected phase %s, got %s", want, sess.GetPhase())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	sess.SetReady(aliceToken)
+	sess.SetReady(bobToken)
+	srv.hostlessStepAsync(sess, game.PhaseLobby, "ready")
+	waitPhase(game.PhaseAnswering)
+	if r := sess.CurrentRound(); r == nil || r.Prompt == "" {
+		t.Fatalf("expected a round with a drawn prompt, got %+v", r)
+	}
+	if !srv.timers.Running(code) {
+		t.Fatal("expected the answer timer to be running")
+	}
+
+	aliceSub, _ := sess.Submit(aliceToken, "Alice's answer")
+	bobSub, _ := sess.Submit(bobToken, "Bob's answer")
+	srv.advanceWhenAnswered(sess)
+	waitPhase(game.PhaseVoting)
+
+	sess.Vote(aliceToken, bobSub)
+	sess.Vote(bobToken, aliceSub)
+	srv.hostlessStepAsync(sess, game.PhaseVoting, "voted")
+	waitPhase(game.PhaseScoreboard)
+
+	// a late trigger for a phase the session already left is a no-op
+	srv.hostlessStepAsync(sess, game.PhaseVoting, "voted")
+	time.Sleep(50 * time.Millisecond)
+	if sess.GetPhase() != game.PhaseScoreboard {
+		t.Fatalf("expected the session to stay on the scoreboard, got %s", sess.GetPhase())
+	}
+	srv.hostlessStepFunc(sess, "timeout")()
+	if sess.GetPhase() != game.PhaseEnd {
+		t.Fatalf("expected the last scoreboard to end the game, got %s", sess.GetPhase())
+	}
+}
@@ -0,0 +1,85 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/ai"
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/rs/zerolog/log"
+)
+
+// maxImages is how many generated images are kept in memory. Images don't
+// survive a restart; their rounds show the prompt without the picture.
+const maxImages = 32
+
+// SetImageProvider enables image rounds, generating with model.
+func (srv *Server) SetImageProvider(p ai.ImageProvider, model string) {
+	srv.imageGen, srv.imageModel = p, model
+}
+
+// imageURL is where the generated image of a round is served, see
+// ImageHandler.
+func imageURL(code, roundID string) string {
+	return "/api/session/" + code + "/images/" + roundID
+}
+
+// storeImage keeps an image for ImageHandler, dropping the oldest one
+// beyond maxImages.
+func (srv *Server) storeImage(code, roundID string, img ai.Image) {
+	srv.imageMu.Lock()
+	defer srv.imageMu.Unlock()
+	if srv.images == nil {
+		srv.images = make(map[string]ai.Image)
+	}
+	key := code + "/" + roundID
+	if _, ok := srv.images[key]; !ok {
+		srv.imageOrder = append(srv.imageOrder, key)
+	}
+	srv.images[key] = img
+	if len(srv.imageOrder) > maxImages {
+		delete(srv.images, srv.imageOrder[0])
+		srv.imageOrder = srv.imageOrder[1:]
+	}
+}
+
+// generateImage creates the picture of the current image round in the
+// background and shows it as the round's attachment once it is there.
+func (srv *Server) generateImage(sess *game.SessionCtx) {
+	r := currentRoundPtr(sess)
+	if r == nil || srv.imageGen == nil {
+		return
+	}
+	roundID, prompt := r.ID, r.Prompt
+	background("generateImage", sess.Code, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		img, err := srv.imageGen.GenerateImage(ctx, srv.imageModel, prompt)
+		if err != nil {
+			log.Warn().Err(err).Str("code", sess.Code).Msg("image generation failed")
+			srv.emitToHosts(sess.Code, "game:imageFailed", map[string]any{"error": err.Error()})
+			return
+		}
+		srv.storeImage(sess.Code, roundID, img)
+		sess.SetRoundImage(roundID, imageURL(sess.Code, roundID))
+		srv.emitStateTo(sess.Code)
+		srv.publishPhase(sess)
+	})
+}
+
+// ImageHandler serves the generated images of image rounds.
+func (srv *Server) ImageHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		srv.imageMu.Lock()
+		img, ok := srv.images[c.Param("code")+"/"+c.Param("roundId")]
+		srv.imageMu.Unlock()
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "image_not_found"})
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=86400, immutable")
+		c.Data(http.StatusOK, img.ContentType, img.Data)
+	}
+}
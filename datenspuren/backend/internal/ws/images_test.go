@@ -0,0 +1,56 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/ai"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+type fakeImages struct{}
+
+func (fakeImages) GenerateImage(_ context.Context, model string, prompt string) (ai.Image, error) {
+	return ai.Image{Data: []byte(model + ":" + prompt), ContentType: "image/png"}, nil
+}
+
+func TestImageRound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1, Language: "en"})
+	sess, _ := rm.Get(code)
+	srv := New(rm, config.Config{})
+	srv.SetImageProvider(fakeImages{}, "painter")
+	r := gin.New()
+	r.GET("/api/session/:code/images/:roundId", srv.ImageHandler())
+
+	sess.SetImagePrompt(hostToken, "A cat on the moon")
+	round := sess.CurrentRound()
+	if got, want := aiPrompt(sess, round), "Write a short, witty caption for a picture showing: A cat on the moon"; got != want {
+		t.Fatalf("expected the AI to caption the picture, got %q", got)
+	}
+	srv.generateImage(sess)
+	deadline := time.Now().Add(2 * time.Second)
+	for sess.CurrentRound().Attachment == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the generated image to be attached")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, sess.CurrentRound().Attachment.URL, nil))
+	if w.Code != http.StatusOK || w.Body.String() != "painter:A cat on the moon" {
+		t.Fatalf("expected the generated image, got %d %q", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/session/"+code+"/images/nope", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown image, got %d", w.Code)
+	}
+}
@@ -0,0 +1,43 @@
+package ws
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/i18n"
+)
+
+// joinURL is the short link players open to join a session, see
+// JoinHandler. Without PUBLIC_URL it is guessed from the request, which
+// works unless a proxy rewrites the host.
+func (srv *Server) joinURL(r *http.Request, code string) string {
+	base := strings.TrimSuffix(srv.config().PublicURL, "/")
+	if base == "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		if p := r.Header.Get("X-Forwarded-Proto"); p != "" {
+			scheme = p
+		}
+		base = scheme + "://" + r.Host
+	}
+	return base + "/j/" + code
+}
+
+// JoinHandler serves the short join links at /j/:code by sending players to
+// the join page with the code filled in. Codes and PINs of sessions that
+// ended get a 404 instead of a join page that can't join anything.
+func (srv *Server) JoinHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sess, err := srv.RM.Lookup(c.Param("code"))
+		if err != nil {
+			lang := i18n.Negotiate(c.GetHeader("Accept-Language"))
+			c.String(http.StatusNotFound, i18n.T(lang, "This game doesn't exist (anymore)."))
+			return
+		}
+		c.Header("Cache-Control", "no-store")
+		c.Redirect(http.StatusFound, "/?join="+sess.Code)
+	}
+}
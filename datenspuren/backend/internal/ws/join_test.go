@@ -0,0 +1,42 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+func TestJoinHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	srv := New(rm, config.Config{})
+	r := gin.New()
+	r.GET("/j/:code", srv.JoinHandler())
+	code, _, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/j/"+strings.ToLower(code), nil))
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/?join="+code {
+		t.Fatalf("expected a redirect to the join page, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/j/ZZZZZ", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown session, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Host = "play.local:8080"
+	if got, want := srv.joinURL(req, code), "http://play.local:8080/j/"+code; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	srv.config().PublicURL = "https://play.example/"
+	if got, want := srv.joinURL(req, code), "https://play.example/j/"+code; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,77 @@
+package ws
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/ratelimit"
+	"github.com/rs/zerolog/log"
+)
+
+// joinGuard throttles join attempts per remote IP. A source that keeps
+// trying at twice the limit is blocked altogether for a while, which also
+// turns away its new sockets, see admit.
+type joinGuard struct {
+	limit   *ratelimit.Limiter // joins let through
+	strikes *ratelimit.Limiter // joins turned away before the source is blocked
+	block   time.Duration
+
+	mu      sync.Mutex
+	blocked map[string]time.Time // IP -> blocked until
+}
+
+// joinEvents are the events that put a new player into a session.
+var joinEvents = map[string]bool{
+	"game:join":           true,
+	"game:createHostless": true,
+}
+
+func newJoinGuard(perMinute int, block time.Duration) *joinGuard {
+	return &joinGuard{
+		limit:   ratelimit.New(perMinute, time.Minute),
+		strikes: ratelimit.New(perMinute, time.Minute),
+		block:   block,
+		blocked: make(map[string]time.Time),
+	}
+}
+
+// allow records a join attempt from ip and reports whether it may go ahead.
+func (g *joinGuard) allow(ip string) bool {
+	if g.isBlocked(ip) {
+		return false
+	}
+	if g.limit.Allow(ip) {
+		return true
+	}
+	if !g.strikes.Allow(ip) && g.block > 0 {
+		g.mu.Lock()
+		g.blocked[ip] = time.Now().Add(g.block)
+		g.mu.Unlock()
+		log.Warn().Str("ip", ip).Dur("for", g.block).Msg("blocking join flood")
+	}
+	return false
+}
+
+func (g *joinGuard) isBlocked(ip string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	until, ok := g.blocked[ip]
+	if ok && time.Now().After(until) {
+		delete(g.blocked, ip)
+		return false
+	}
+	return ok
+}
+
+// JoinGuard turns away requests from sources blocked for flooding joins,
+// for routes that let players in without a socket.
+func (srv *Server) JoinGuard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if srv.joins != nil && srv.joins.isBlocked(srv.clientIP(c.Request.RemoteAddr, c.Request.Header)) {
+			c.Header("Retry-After", "60")
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate_limited", "message": "Too many join attempts, please try again later"})
+		}
+	}
+}
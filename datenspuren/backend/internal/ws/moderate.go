@@ -0,0 +1,34 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"github.com/kiliankoe/gptdash/internal/ai"
+)
+
+type Moderator interface {
+	Moderate(ctx context.Context, text string) (ai.Verdict, error)
+}
+
+func (srv *Server) SetModerator(m Moderator) { srv.moderator = m }
+
+// moderate screens a prompt before it is accepted and returns the error ack
+// if the moderator flagged it, nil otherwise. Without a moderator, or if it
+// fails, the prompt goes through so an outage doesn't stop the game.
+func (srv *Server) moderate(req *request, text string) map[string]any {
+	if srv.moderator == nil || text == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	v, err := srv.moderator.Moderate(ctx, text)
+	if err != nil {
+		req.log.Warn().Err(err).Msg("moderation failed, accepting the prompt")
+		return nil
+	}
+	if !v.Flagged {
+		return nil
+	}
+	return req.flagged(v.Categories)
+}
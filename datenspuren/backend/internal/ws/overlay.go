@@ -0,0 +1,155 @@
+package ws
+
+import (
+	"crypto/subtle"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/rs/zerolog/log"
+)
+
+type overlayEvent struct {
+	Name string
+	Data any
+	At   time.Time
+}
+
+// overlayHub fans out overlay events to SSE subscribers per session.
+type overlayHub struct {
+	mu   sync.Mutex
+	subs map[string]map[chan overlayEvent]struct{} // sessionCode -> subscribers
+}
+
+func newOverlayHub() *overlayHub {
+	return &overlayHub{subs: make(map[string]map[chan overlayEvent]struct{})}
+}
+
+func (h *overlayHub) subscribe(code string) chan overlayEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// roomy buffer since delayed subscribers hold events back for a while
+	ch := make(chan overlayEvent, 256)
+	if h.subs[code] == nil {
+		h.subs[code] = make(map[chan overlayEvent]struct{})
+	}
+	h.subs[code][ch] = struct{}{}
+	return ch
+}
+
+func (h *overlayHub) unsubscribe(code string, ch chan overlayEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subs[code], ch)
+	if len(h.subs[code]) == 0 {
+		delete(h.subs, code)
+	}
+}
+
+func (h *overlayHub) publish(code string, ev overlayEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if ev.At.IsZero() {
+		ev.At = time.Now()
+	}
+	for ch := range h.subs[code] {
+		select {
+		case ch <- ev:
+		default:
+			// slow overlay; drop rather than block the game
+		}
+	}
+}
+
+// publishReveal sends the scored round to overlays, as revealed on stage.
+func (srv *Server) publishReveal(sess *game.SessionCtx) {
+	if last, ok := sess.LastRound(); ok {
+		srv.overlay.publish(sess.Code, overlayEvent{Name: "reveal", Data: last.Revealed()})
+	}
+}
+
+// publishPhase notifies overlays of the session's current phase and round.
+func (srv *Server) publishPhase(sess *game.SessionCtx) {
+	data := map[string]any{"phase": sess.GetPhase()}
+	if r := currentRoundPtr(sess); r != nil {
+		data["roundIndex"] = r.Index
+		data["prompt"] = r.Prompt
+		if r.Attachment != nil {
+			data["attachment"] = r.Attachment
+		}
+	}
+	srv.overlay.publish(sess.Code, overlayEvent{Name: "phase", Data: data})
+}
+
+// OverlayHandler streams overlay events for a session as Server-Sent Events.
+// Requires the session's overlay token via ?token= or a Bearer header.
+// Events, including the initial state snapshot, are delayed by the session's
+// StreamDelay, or by ?delay= seconds.
+func (srv *Server) OverlayHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sess, err := srv.RM.Lookup(c.Param("code"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session_not_found"})
+			return
+		}
+		token := c.Query("token")
+		if token == "" {
+			token = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(sess.OverlayToken)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		delay := time.Duration(sess.Config.StreamDelay) * time.Second
+		if d, err := strconv.Atoi(c.Query("delay")); err == nil && d >= 0 {
+			delay = time.Duration(d) * time.Second
+		}
+
+		ch := srv.overlay.subscribe(sess.Code)
+		defer srv.overlay.unsubscribe(sess.Code, ch)
+		log.Info().Str("code", sess.Code).Str("ip", c.ClientIP()).Msg("overlay connected")
+
+		c.Header("Cache-Control", "no-cache")
+		c.Header("X-Accel-Buffering", "no")
+		c.Writer.Flush()
+
+		// the snapshot is held back like any other event so the overlay
+		// never shows what the delayed stream hasn't caught up to yet
+		initial := &overlayEvent{Name: "state", Data: sess.PublicState(), At: time.Now()}
+		heartbeat := time.NewTicker(15 * time.Second)
+		defer heartbeat.Stop()
+		c.Stream(func(w io.Writer) bool {
+			var ev overlayEvent
+			if initial != nil {
+				ev, initial = *initial, nil
+			} else {
+				select {
+				case <-c.Request.Context().Done():
+					return false
+				case ev = <-ch:
+				case <-heartbeat.C:
+					_, _ = io.WriteString(w, ": ping\n\n")
+					return true
+				}
+			}
+			// hold back until the delayed stream catches up; the channel
+			// is FIFO so ordering is preserved
+			if wait := time.Until(ev.At.Add(delay)); wait > 0 {
+				select {
+				case <-c.Request.Context().Done():
+					return false
+				case <-time.After(wait):
+				}
+			}
+			c.SSEvent(ev.Name, ev.Data)
+			return true
+		})
+		log.Info().Str("code", sess.Code).Msg("overlay disconnected")
+	}
+}
@@ -0,0 +1,126 @@
+package ws
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/rs/zerolog/log"
+)
+
+// maxDeckSize caps the body of a prompt deck upload.
+const maxDeckSize = 1 << 20
+
+// deckFormat picks the deck format from ?format= or the Content-Type,
+// defaulting to JSON.
+func deckFormat(c *gin.Context) string {
+	if f := strings.ToLower(c.Query("format")); f != "" {
+		return f
+	}
+	if ct := c.ContentType(); ct == "text/csv" || ct == "application/csv" {
+		return "csv"
+	}
+	return "json"
+}
+
+// queueDeck queues deck prompts into a session and starts generating AI
+// answers for those without a canned one, as with game:queuePrompt.
+func (srv *Server) queueDeck(sess *game.SessionCtx, hostToken string, deck []game.DeckPrompt) ([]game.QueuedPrompt, error) {
+	queued, err := sess.ImportPrompts(hostToken, deck)
+	if err != nil {
+		return nil, err
+	}
+	if aiTrigger(sess) == game.AITriggerQueue {
+		for _, q := range queued {
+			if !q.AIReady {
+				srv.generateForQueuedPrompt(sess, q)
+			}
+		}
+	}
+	srv.emitQueueTo(sess)
+	return queued, nil
+}
+
+// promptStats looks up the historical stats of prompts keyed by ID, leaving
+// out prompts that haven't been played yet.
+func (srv *Server) promptStats(prompts map[string]string) map[string]game.PromptStats {
+	out := map[string]game.PromptStats{}
+	if srv.stats == nil {
+		return out
+	}
+	for id, prompt := range prompts {
+		if st, ok := srv.stats.Get(prompt); ok {
+			out[id] = st
+		}
+	}
+	return out
+}
+
+// recordPromptStats books the round just scored onto its prompt's stats.
+func (srv *Server) recordPromptStats(sess *game.SessionCtx) error {
+	if srv.stats == nil || !srv.recording(sess) {
+		return nil
+	}
+	rs, ok := sess.LastRound()
+	if !ok {
+		return nil
+	}
+	return srv.stats.RecordRound(rs)
+}
+
+// PromptStatsHandler serves GET /api/gm/prompts/stats, the prompts played so
+// far with the ones the AI fools the fewest people with first.
+func (srv *Server) PromptStatsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stats := []game.PromptStats{}
+		if srv.stats != nil {
+			stats = srv.stats.List()
+		}
+		c.JSON(http.StatusOK, gin.H{"prompts": stats})
+	}
+}
+
+// PromptImportHandler serves POST /api/gm/prompts/import. The deck goes into
+// the prompt library shared by all sessions, or with ?session=CODE straight
+// into that session's prompt queue.
+func (srv *Server) PromptImportHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deck, err := game.ParseDeck(http.MaxBytesReader(c.Writer, c.Request.Body, maxDeckSize), deckFormat(c))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_deck", "message": err.Error()})
+			return
+		}
+		if code := c.Query("session"); code != "" {
+			sess, err := srv.RM.Lookup(code)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "session_not_found"})
+				return
+			}
+			queued, err := srv.queueDeck(sess, sess.HostToken, deck)
+			if errors.Is(err, game.ErrStorageFull) {
+				c.JSON(http.StatusConflict, gin.H{"error": "queue_full"})
+				return
+			} else if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			log.Info().Str("code", sess.Code).Int("prompts", len(queued)).Msg("imported prompt deck")
+			c.JSON(http.StatusOK, gin.H{"imported": len(queued), "prompts": queued})
+			return
+		}
+		if srv.library == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "library_unavailable"})
+			return
+		}
+		added, err := srv.library.Add(deck)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to save prompt library")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		log.Info().Int("prompts", len(added)).Int("duplicates", len(deck)-len(added)).Msg("imported prompt deck into library")
+		c.JSON(http.StatusOK, gin.H{"imported": len(added), "duplicates": len(deck) - len(added), "prompts": added})
+	}
+}
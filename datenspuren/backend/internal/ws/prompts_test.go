@@ -0,0 +1,84 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+func TestPromptImport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, _, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1})
+	sess, _ := rm.Get(code)
+	srv := New(rm, config.Config{})
+	lib, _ := game.LoadPromptLibrary("")
+	srv.SetPromptLibrary(lib)
+	r := gin.New()
+	r.POST("/api/gm/prompts/import", srv.PromptImportHandler())
+
+	post := func(url, contentType, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", url, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("/api/gm/prompts/import", "text/csv", "prompt,category\nQ1,Food\nQ2,Tech\n")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	if got := lib.List(""); len(got) != 2 {
+		t.Fatalf("expected 2 prompts in the library, got %+v", got)
+	}
+
+	w = post("/api/gm/prompts/import?session="+code, "application/json", `[{"prompt":"Q3","aiAnswer":"Canned"}]`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+	if queue := sess.PromptQueue(); len(queue) != 1 || queue[0].Prompt != "Q3" || !queue[0].AIReady {
+		t.Fatalf("expected Q3 queued with its AI answer, got %+v", queue)
+	}
+	if got := lib.List(""); len(got) != 2 {
+		t.Fatalf("expected a session import to leave the library alone, got %+v", got)
+	}
+
+	if w := post("/api/gm/prompts/import", "application/json", "{"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a broken deck, got %d", w.Code)
+	}
+	if w := post("/api/gm/prompts/import?session=NOPE", "text/csv", "prompt\nQ\n"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown session, got %d", w.Code)
+	}
+}
+
+func TestPromptStatsOnlyFromRecordedSessions(t *testing.T) {
+	rm := game.NewRoomManager()
+	srv := New(rm, config.Config{ExportEnabled: true})
+	srv.Mount(gin.New())
+	stats, _ := game.LoadPromptStats("")
+	srv.SetPromptStats(stats)
+	off := false
+	for _, cfg := range []game.SessionConfig{
+		{Provider: "manual", RoundCount: 1},
+		{Provider: "manual", RoundCount: 1, Export: &off},
+		{Provider: "manual", RoundCount: 1, Rehearsal: true},
+	} {
+		code, hostToken, _ := rm.CreateSession(cfg)
+		sess, _ := rm.Get(code)
+		_, playerToken, _ := sess.Join("Alice")
+		srv.setPrompt(sess, hostToken, "Same question?", nil, "")
+		sess.SetAIAnswer(hostToken, "AI answer")
+		sess.Submit(playerToken, "Alice's answer")
+		hostStep(srv, sess, hostToken) // To Voting
+		hostStep(srv, sess, hostToken) // To Scoreboard
+	}
+	if st, ok := stats.Get("Same question?"); !ok || st.Rounds != 1 {
+		t.Fatalf("expected only the recorded session's round to count, got %+v", st)
+	}
+}
@@ -0,0 +1,43 @@
+package ws
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const (
+	qrDefaultSize = 256
+	qrMaxSize     = 1024
+)
+
+// QRHandler renders a QR code of the session's join URL as PNG, for the
+// projector and host screens. The optional size query parameter sets the
+// width in pixels.
+func (srv *Server) QRHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sess, err := srv.RM.Lookup(c.Param("code"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session_not_found"})
+			return
+		}
+		size := qrDefaultSize
+		if v := c.Query("size"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 64 || n > qrMaxSize {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_size"})
+				return
+			}
+			size = n
+		}
+		png, err := qrcode.Encode(srv.joinURL(c.Request, sess.Code), qrcode.Medium, size)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "qr_failed"})
+			return
+		}
+		c.Header("Cache-Control", "private, max-age=300")
+		c.Data(http.StatusOK, "image/png", png)
+	}
+}
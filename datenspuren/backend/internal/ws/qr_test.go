@@ -0,0 +1,46 @@
+package ws
+
+import (
+	"bytes"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+func TestQRHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	srv := New(rm, config.Config{})
+	r := gin.New()
+	r.GET("/api/session/:code/qr.png", srv.QRHandler())
+	code, _, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/session/"+code+"/qr.png?size=128", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("expected a PNG, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	img, err := png.Decode(bytes.NewReader(w.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("should be able to decode the QR code: %v", err)
+	}
+	if img.Bounds().Dx() != 128 {
+		t.Fatalf("expected a 128px QR code, got %d", img.Bounds().Dx())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/session/"+code+"/qr.png?size=99999", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an oversized QR code, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/session/ZZZZZ/qr.png", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown session, got %d", w.Code)
+	}
+}
@@ -0,0 +1,62 @@
+package ws
+
+import (
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/rs/zerolog/log"
+)
+
+// ClientCount is how many sockets and event streams are joined to a
+// session, for the session reaper.
+func (srv *Server) ClientCount(code string) int {
+	return len(srv.membersOf(code))
+}
+
+// CloseExpired tells everyone still in a session the reaper removed that it
+// is gone, with a game:closed event, and frees what the server kept for it.
+func (srv *Server) CloseExpired(sess *game.SessionCtx) {
+	srv.closeSession(sess, "expired")
+	log.Info().Str("code", sess.Code).Msg("session expired")
+}
+
+// closeSession sends game:closed with reason to a removed session's clients
+// and drops them, its timers and its overlay feed.
+func (srv *Server) closeSession(sess *game.SessionCtx, reason string) {
+	code := sess.Code
+	srv.stopTimers(code)
+
+	payload := map[string]any{"sessionCode": code, "reason": reason}
+	srv.broadcast(code, "game:closed", payload)
+	srv.overlay.publish(code, overlayEvent{Name: "closed", Data: payload})
+	for _, c := range srv.membersOf(code) {
+		c.Leave(code)
+		if sc, ok := c.(*streamConn); ok {
+			// nothing will come down the stream anymore
+			sc.Close()
+		}
+	}
+	srv.memberMu.Lock()
+	delete(srv.members, code)
+	srv.memberMu.Unlock()
+	srv.notifySignage()
+}
+
+// stopTimers cancels a session's phase timer, pending cues and automatic
+// steps.
+func (srv *Server) stopTimers(code string) {
+	srv.timers.Stop(code)
+	srv.cueMu.Lock()
+	for _, t := range srv.cues[code] {
+		t.Stop()
+	}
+	delete(srv.cues, code)
+	srv.cueMu.Unlock()
+	srv.autoMu.Lock()
+	if t := srv.autoTimers[code]; t != nil {
+		t.Stop()
+	}
+	delete(srv.autoTimers, code)
+	delete(srv.autoVoting, code)
+	srv.autoMu.Unlock()
+	srv.stepLocks.Delete(code)
+	srv.votingAI.Delete(code)
+}
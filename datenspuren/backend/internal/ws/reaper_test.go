@@ -0,0 +1,37 @@
+package ws
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+func TestCloseExpired(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, _, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1, AnswerTime: 60})
+	sess, _ := rm.Get(code)
+	srv := New(rm, config.Config{})
+	srv.Mount(gin.New())
+	s := newStreamConn(httptest.NewRequest("GET", "/api/session/"+code+"/events", nil), code)
+	s.SetContext(&ConnCtx{Code: code, Role: "spectator"})
+	s.Join(code)
+	srv.addMember(code, s)
+
+	// a connected client doesn't keep a session alive in which nothing happens
+	expired := rm.Reap(sess.CreatedAt.Add(2*time.Hour), time.Hour, srv.ClientCount)
+	if len(expired) != 1 {
+		t.Fatalf("expected the session to expire, got %d", len(expired))
+	}
+	srv.CloseExpired(expired[0])
+	if ev := <-s.events; ev.Name != "game:closed" {
+		t.Fatalf("expected game:closed, got %s", ev.Name)
+	}
+	if n := srv.ClientCount(code); n != 0 {
+		t.Fatalf("expected the session's members to be dropped, got %d", n)
+	}
+}
@@ -0,0 +1,26 @@
+package ws
+
+import (
+	"runtime/debug"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// logPanic records a recovered panic together with its stack.
+func logPanic(lg zerolog.Logger, r any) {
+	lg.Error().Interface("panic", r).Str("stack", string(debug.Stack())).Msg("recovered from panic")
+}
+
+// background runs fn in its own goroutine. A panic is logged instead of
+// taking down the server and every game running on it.
+func background(task, code string, fn func()) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				logPanic(log.With().Str("task", task).Str("code", code).Logger(), r)
+			}
+		}()
+		fn()
+	}()
+}
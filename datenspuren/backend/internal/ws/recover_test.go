@@ -0,0 +1,21 @@
+package ws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackgroundRecoversPanic(t *testing.T) {
+	done := make(chan struct{})
+	background("test", "ABCDE", func() {
+		defer close(done)
+		panic("boom")
+	})
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("background task did not run")
+	}
+	// reaching this point means the panic didn't crash the test binary
+	time.Sleep(10 * time.Millisecond)
+}
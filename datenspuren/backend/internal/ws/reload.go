@@ -0,0 +1,15 @@
+package ws
+
+import "github.com/kiliankoe/gptdash/internal/config"
+
+// Reload swaps the configuration the server reads, e.g. on SIGHUP. Running
+// sessions carry on; handlers see the new values from their next event on.
+// What is only read at startup, like the port, WAL and providers in use,
+// needs a restart.
+func (srv *Server) Reload(cfg config.Config) {
+	srv.conf.Store(&cfg)
+}
+
+func (srv *Server) config() *config.Config {
+	return srv.conf.Load()
+}
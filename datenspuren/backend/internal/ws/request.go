@@ -0,0 +1,85 @@
+package ws
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	socketio "github.com/googollee/go-socket.io"
+	"github.com/kiliankoe/gptdash/internal/i18n"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// request is a single inbound socket event. Its ID shows up in the logs, in
+// error payloads and in acks so a failed action reported by a player can be
+// found in the server output.
+type request struct {
+	ID   string
+	s    socketio.Conn
+	log  zerolog.Logger
+	lang string // of the connection's session, for error messages
+}
+
+func (srv *Server) begin(s socketio.Conn, event string) *request {
+	id := newRequestID()
+	return &request{
+		ID:   id,
+		s:    s,
+		log:  log.With().Str("sid", s.ID()).Str("event", event).Str("requestId", id).Logger(),
+		lang: srv.connLanguage(s),
+	}
+}
+
+// connLanguage returns the language of the session a connection belongs
+// to, i18n.Default before it joined one.
+func (srv *Server) connLanguage(s socketio.Conn) string {
+	ctx, _ := s.Context().(*ConnCtx)
+	if ctx == nil || ctx.Code == "" {
+		return i18n.Default
+	}
+	sess, err := srv.RM.Get(ctx.Code)
+	if err != nil {
+		return i18n.Default
+	}
+	return i18n.Normalize(sess.Config.Language)
+}
+
+// ack adds the request ID to a handler's acknowledgement.
+func (req *request) ack(m map[string]any) map[string]any {
+	m["requestId"] = req.ID
+	return m
+}
+
+// err reports a failed event to the client, both as an "error" event and
+// as the ack. Messages with a translation are sent in the session's
+// language.
+func (req *request) err(code, message string) map[string]any {
+	req.log.Warn().Str("error", code).Msg(message)
+	message = i18n.T(req.lang, message)
+	req.s.Emit("error", map[string]any{"code": code, "message": message, "requestId": req.ID})
+	return map[string]any{"error": message, "code": code, "requestId": req.ID}
+}
+
+// invalid rejects a malformed payload, naming the offending field.
+func (req *request) invalid(field, message string) map[string]any {
+	req.log.Warn().Str("field", field).Msg(message)
+	req.s.Emit("error", map[string]any{"code": "invalid_payload", "message": message, "field": field, "requestId": req.ID})
+	return map[string]any{"error": message, "code": "invalid_payload", "field": field, "requestId": req.ID}
+}
+
+// flagged rejects text the moderator flagged, naming its categories.
+func (req *request) flagged(categories []string) map[string]any {
+	if categories == nil {
+		categories = []string{}
+	}
+	req.log.Warn().Strs("categories", categories).Msg("content flagged by moderation")
+	message := i18n.T(req.lang, "Content was flagged by moderation")
+	req.s.Emit("error", map[string]any{"code": "content_flagged", "message": message, "categories": categories, "requestId": req.ID})
+	return map[string]any{"error": message, "code": "content_flagged", "categories": categories, "requestId": req.ID}
+}
+
+func newRequestID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
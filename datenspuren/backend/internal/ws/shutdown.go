@@ -0,0 +1,84 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/rs/zerolog/log"
+)
+
+// shutdownFlush is how long Shutdown waits for server:shutdown to reach the
+// sockets before closing them.
+const shutdownFlush = 500 * time.Millisecond
+
+// Shutdown prepares the server for going away: new sockets and event streams
+// are turned away, everyone connected gets a server:shutdown notice, phase
+// timers stop so no round moves on unattended, and all sockets and event
+// streams are closed. Clients reconnect and resume once the server is back.
+func (srv *Server) Shutdown() {
+	srv.closing.Store(true)
+	payload := map[string]any{"reason": "shutdown"}
+	for _, sess := range srv.RM.Running() {
+		srv.stopTimers(sess.Code)
+		srv.overlay.publish(sess.Code, overlayEvent{Name: "shutdown", Data: payload})
+	}
+	srv.streamMu.Lock()
+	streams := make([]*streamConn, 0, len(srv.streams))
+	for _, s := range srv.streams {
+		streams = append(streams, s)
+	}
+	srv.streamMu.Unlock()
+	for _, s := range streams {
+		s.Emit("server:shutdown", payload)
+		s.Close()
+	}
+	if srv.io != nil {
+		srv.io.BroadcastToNamespace("/", "server:shutdown", payload)
+		// emits are written asynchronously, give them a moment to go out
+		// before the connections close
+		time.Sleep(shutdownFlush)
+		if err := srv.io.Close(); err != nil {
+			log.Error().Err(err).Msg("failed to close socket server")
+		}
+	}
+}
+
+// ExportRunning exports every session that hasn't ended yet, marked as
+// terminated and including the round in progress, to the export directory
+// and the collector. It's run on graceful shutdown so a redeploy during an
+// event doesn't lose the evening's results; it waits for the collector
+// until ctx is done.
+func (srv *Server) ExportRunning(ctx context.Context) {
+	now := time.Now().UTC()
+	for _, sess := range srv.RM.Running() {
+		unfinished, inRound := sess.UnfinishedRound()
+		summary := sess.Summary()
+		if !srv.recording(sess) || !inRound && len(summary.Rounds) == 0 {
+			continue
+		}
+		opts := srv.exportOptions(sess)
+		opts.Terminated = now
+		if file, err := game.ExportSession(sess, srv.config().ExportDir, opts); err != nil {
+			log.Error().Err(err).Str("code", sess.Code).Msg("failed to export terminated session")
+		} else {
+			log.Info().Str("code", sess.Code).Str("file", file).Msg("exported terminated session")
+		}
+		if srv.collector == nil {
+			continue
+		}
+		doc := map[string]any{
+			"type":        "terminated",
+			"sessionCode": sess.Code,
+			"exportedAt":  now,
+			"config":      sess.Config,
+			"summary":     sess.RedactSummary(summary, opts),
+		}
+		if inRound {
+			doc["unfinishedRound"] = sess.RedactRound(unfinished, opts)
+		}
+		if err := srv.collector.Send(ctx, doc); err != nil {
+			log.Error().Err(err).Str("code", sess.Code).Msg("failed to stream terminated session")
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+func TestShutdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, hostToken, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1, AnswerTime: 60})
+	sess, _ := rm.Get(code)
+	srv := New(rm, config.Config{})
+	r := gin.New()
+	srv.Mount(r)
+	r.GET("/api/session/:code/events", srv.EventsHandler())
+	sess.SetPrompt(hostToken, "Still running?")
+	srv.timers.Sync(sess)
+	s := newStreamConn(httptest.NewRequest("GET", "/api/session/"+code+"/events", nil), code)
+	srv.streams[s.ID()] = s
+
+	srv.Shutdown()
+	if ev := <-s.events; ev.Name != "server:shutdown" {
+		t.Fatalf("expected server:shutdown, got %s", ev.Name)
+	}
+	select {
+	case <-s.done:
+	default:
+		t.Fatal("expected the event stream to be closed")
+	}
+	if srv.timers.Running(code) {
+		t.Fatal("expected the answer timer to be stopped")
+	}
+	for _, path := range []string{"/socket.io/?EIO=3&transport=polling", "/api/session/" + code + "/events"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("expected %s to be turned away, got %d", path, w.Code)
+		}
+	}
+}
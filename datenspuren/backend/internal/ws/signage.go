@@ -0,0 +1,156 @@
+package ws
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/kiliankoe/gptdash/internal/i18n"
+	"github.com/rs/zerolog/log"
+)
+
+// Signage is what digital signage at the venue shows about the running
+// game, e.g. "Spiel läuft – mitmachen auf … – Runde 3 von 5 – 57 Mitspielende".
+type Signage struct {
+	Running     bool       `json:"running"`
+	SessionCode string     `json:"sessionCode,omitempty"`
+	JoinPin     string     `json:"joinPin,omitempty"`
+	JoinURL     string     `json:"joinUrl,omitempty"`
+	Phase       game.Phase `json:"phase,omitempty"`
+	RoundIndex  int        `json:"roundIndex,omitempty"`
+	RoundCount  int        `json:"roundCount,omitempty"`
+	PlayerCount int        `json:"playerCount"`
+	Text        string     `json:"text"`
+}
+
+// signageDelay coalesces bursts of state changes, like a crowd joining at
+// once, into one webhook call.
+const signageDelay = 2 * time.Second
+
+// signageHook pushes Signage to a webhook whenever it changes.
+type signageHook struct {
+	sink    Collector
+	mu      sync.Mutex
+	pending bool
+	last    Signage
+}
+
+// SetSignageHook makes the server POST Signage to sink whenever it changes.
+func (srv *Server) SetSignageHook(sink Collector) { srv.signage = &signageHook{sink: sink} }
+
+// Signage describes the game running in the given session, or in the
+// active one if code is empty.
+func (srv *Server) Signage(code string) Signage {
+	return srv.signageFor(srv.signageSession(code))
+}
+
+// signageSession resolves the session Signage describes. Codes only, since
+// signage shows the join PIN and must not turn into a way to check PINs.
+func (srv *Server) signageSession(code string) *game.SessionCtx {
+	if code == "" {
+		_, sess := srv.RM.Active()
+		return sess
+	}
+	sess, _ := srv.RM.Get(strings.ToUpper(code))
+	return sess
+}
+
+func (srv *Server) signageFor(sess *game.SessionCtx) Signage {
+	if sess == nil || sess.GetPhase() == game.PhaseEnd {
+		return Signage{Text: i18n.T(i18n.Default, "No game running right now")}
+	}
+	st := sess.PublicState()
+	out := Signage{
+		Running:     true,
+		SessionCode: sess.Code,
+		JoinPin:     sess.JoinPin,
+		Phase:       st.Phase,
+		RoundIndex:  st.RoundIndex,
+		RoundCount:  st.RoundCount,
+		PlayerCount: st.PlayerCount,
+	}
+	lang := sess.Config.Language
+	parts := []string{i18n.T(lang, "Game running")}
+	if base := strings.TrimSuffix(srv.config().PublicURL, "/"); base != "" {
+		out.JoinURL = base + "/j/" + sess.Code
+		parts = append(parts, i18n.T(lang, "join at %s", out.JoinURL))
+	} else {
+		parts = append(parts, i18n.T(lang, "join with code %s", sess.Code))
+	}
+	if st.RoundIndex > 0 {
+		parts = append(parts, i18n.T(lang, "Round %d of %d", st.RoundIndex, st.RoundCount))
+	}
+	parts = append(parts, i18n.T(lang, "%d players", st.PlayerCount))
+	out.Text = strings.Join(parts, " – ")
+	return out
+}
+
+// notifySignage schedules a webhook call with the current Signage, unless
+// one is already pending.
+func (srv *Server) notifySignage() {
+	h := srv.signage
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.pending {
+		return
+	}
+	h.pending = true
+	time.AfterFunc(signageDelay, srv.sendSignage)
+}
+
+func (srv *Server) sendSignage() {
+	h := srv.signage
+	st := srv.Signage("")
+	h.mu.Lock()
+	h.pending = false
+	if st == h.last {
+		h.mu.Unlock()
+		return
+	}
+	h.last = st
+	h.mu.Unlock()
+	background("signage", st.SessionCode, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		if err := h.sink.Send(ctx, st); err != nil {
+			log.Error().Err(err).Msg("failed to send signage webhook")
+		}
+	})
+}
+
+// SignageHandler serves GET /api/signage for signage that polls, optionally
+// for a specific session via ?code=. Signage shows the session's code and
+// join PIN, so sessions that aren't public need the GM's credentials; an
+// unknown code answers the same, so the endpoint doesn't tell which exist.
+func (srv *Server) SignageHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "no-store")
+		code := c.Query("code")
+		sess := srv.signageSession(code)
+		if (sess != nil && !sess.Config.Public || sess == nil && code != "") && !srv.gmAuthorized(c.Request) {
+			c.Header("WWW-Authenticate", `Basic realm="Authorization Required"`)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		c.JSON(http.StatusOK, srv.signageFor(sess))
+	}
+}
+
+// gmAuthorized reports whether r carries the GM's basic auth credentials.
+func (srv *Server) gmAuthorized(r *http.Request) bool {
+	cfg := srv.config()
+	user, pass, ok := r.BasicAuth()
+	if !ok || cfg.GMUser == "" || cfg.GMPass == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(user), []byte(cfg.GMUser)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(pass), []byte(cfg.GMPass)) == 1
+}
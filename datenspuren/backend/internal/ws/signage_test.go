@@ -0,0 +1,101 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+type signageSink chan any
+
+func (s signageSink) Send(_ context.Context, doc any) error {
+	s <- doc
+	return nil
+}
+
+func TestSignage(t *testing.T) {
+	rm := game.NewRoomManager()
+	srv := New(rm, config.Config{PublicURL: "https://play.example/"})
+	if st := srv.Signage(""); st.Running {
+		t.Fatalf("expected no running game, got %+v", st)
+	}
+
+	code, hostToken, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 5})
+	sess, _ := rm.Get(code)
+	sess.Join("Alice")
+	sess.Join("Bob")
+	sess.SetPrompt(hostToken, "Test question?")
+	st := srv.Signage("")
+	want := "Spiel läuft – mitmachen auf https://play.example/j/" + code + " – Runde 1 von 5 – 2 Mitspielende"
+	if !st.Running || st.Text != want {
+		t.Fatalf("expected %q, got %+v", want, st)
+	}
+
+	sink := make(signageSink, 4)
+	srv.SetSignageHook(sink)
+	srv.notifySignage()
+	srv.notifySignage() // coalesced with the first
+	select {
+	case doc := <-sink:
+		if doc.(Signage) != st {
+			t.Fatalf("expected the webhook to receive %+v, got %+v", st, doc)
+		}
+	case <-time.After(2 * signageDelay):
+		t.Fatal("expected a webhook call")
+	}
+	srv.notifySignage() // nothing changed
+	select {
+	case doc := <-sink:
+		t.Fatalf("expected no webhook call without changes, got %+v", doc)
+	case <-time.After(signageDelay + 500*time.Millisecond):
+	}
+
+	enCode, _, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 5, Language: "en"})
+	if got, want := srv.Signage(enCode).Text, "Game running – join at https://play.example/j/"+enCode+" – 0 players"; got != want {
+		t.Fatalf("expected English signage for an English session, got %q", got)
+	}
+}
+
+func TestSignageHandlerNeedsAuthForPrivateSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	srv := New(rm, config.Config{GMUser: "gm", GMPass: "secret"})
+	r := gin.New()
+	r.GET("/api/signage", srv.SignageHandler())
+	private, _, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1})
+	public, _, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1, Public: true})
+	privateSess, _ := rm.Get(private)
+
+	get := func(query string, auth bool) (int, Signage) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/signage"+query, nil)
+		if auth {
+			req.SetBasicAuth("gm", "secret")
+		}
+		r.ServeHTTP(w, req)
+		var st Signage
+		json.Unmarshal(w.Body.Bytes(), &st)
+		return w.Code, st
+	}
+	if status, st := get("?code="+public, false); status != http.StatusOK || st.SessionCode != public {
+		t.Fatalf("expected a public session without auth, got %d %+v", status, st)
+	}
+	for _, query := range []string{"?code=" + private, "?code=" + privateSess.JoinPin, "?code=NOPE0"} {
+		if status, st := get(query, false); status != http.StatusUnauthorized || st.JoinPin != "" {
+			t.Fatalf("expected %s to need auth, got %d %+v", query, status, st)
+		}
+	}
+	if status, st := get("?code="+private, true); status != http.StatusOK || st.JoinPin != privateSess.JoinPin {
+		t.Fatalf("expected the GM to see a private session, got %d %+v", status, st)
+	}
+	if status, st := get("?code="+privateSess.JoinPin, true); status != http.StatusOK || st.Running {
+		t.Fatalf("expected PINs not to resolve, got %d %+v", status, st)
+	}
+}
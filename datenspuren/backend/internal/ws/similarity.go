@@ -0,0 +1,13 @@
+package ws
+
+import "github.com/kiliankoe/gptdash/internal/game"
+
+// warnSimilar tells the host(s) once all answers are in whether someone
+// wrote nearly what the AI did, so they can regenerate the AI answer before
+// voting turns into a coin toss.
+func (srv *Server) warnSimilar(sess *game.SessionCtx) {
+	if r := sess.CurrentRound(); r == nil || r.AISubmissionID == "" {
+		return
+	}
+	srv.emitToHosts(sess.Code, "game:similarity", map[string]any{"answers": sess.SimilarToAI()})
+}
@@ -0,0 +1,138 @@
+package ws
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+// simpleHostPage is a minimal host remote for phones, independent of the
+// frontend bundle. The buttons call the Connect API with the host token the
+// frontend left in localStorage, or the one given as #token=... in the URL.
+var simpleHostPage = template.Must(template.New("simple").Parse(`<!doctype html>
+<html lang="de">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>GPTDash – Steuerung</title>
+<style>
+body { font-family: system-ui, sans-serif; background: #111; color: #eee; margin: 0; padding: 16px; }
+h1 { font-size: 1.2em; margin: 0 0 12px; }
+#status { font-size: 1.4em; margin-bottom: 16px; }
+#error { color: #f66; min-height: 1.2em; margin-bottom: 12px; }
+button { display: block; width: 100%; font-size: 1.6em; padding: 24px 0; margin-bottom: 16px; border: 0; border-radius: 12px; color: #111; }
+#advance { background: #fc3; }
+#reveal { background: #6cf; }
+#extend { background: #9e9; }
+form { display: grid; gap: 8px; }
+input { font-size: 1.2em; padding: 8px; }
+</style>
+</head>
+<body>
+<h1>GPTDash – Steuerung</h1>
+{{if .Code}}
+<div id="status">{{.Status}}</div>
+<div id="error"></div>
+<button id="advance" onclick="call('Advance')">Weiter</button>
+<button id="reveal" onclick="call('RevealScores')">Punkte aufdecken</button>
+<button id="extend" onclick="call('ExtendTimer', {seconds: 30})">+30 Sekunden</button>
+<script>
+const code = {{.Code}};
+const api = "/" + {{.Service}} + "/";
+const phases = {{.Phases}};
+let token = new URLSearchParams(location.hash.slice(1)).get("token");
+if (!token && localStorage.getItem("sessionCode") === code) token = localStorage.getItem("hostToken");
+
+async function post(method, body) {
+  const res = await fetch(api + method, {
+    method: "POST",
+    headers: { "Content-Type": "application/json", "Authorization": "Bearer " + token },
+    body: JSON.stringify(Object.assign({ sessionCode: code }, body)),
+  });
+  const j = await res.json();
+  if (!res.ok) throw new Error(j.message || res.statusText);
+  return j;
+}
+
+function show(state) {
+  let text = phases[state.phase] || state.phase;
+  if (state.roundIndex > 0) text += " – Runde " + state.roundIndex + "/" + state.roundCount;
+  if (state.deadline) {
+    const left = Math.max(0, Math.round((new Date(state.deadline) - Date.now()) / 1000));
+    text += " – " + left + " s";
+  }
+  document.getElementById("status").textContent = text + " – " + state.playerCount + " Mitspielende";
+}
+
+async function refresh() {
+  try {
+    show(await post("GetState"));
+  } catch (e) {}
+}
+
+async function call(method, body) {
+  const error = document.getElementById("error");
+  error.textContent = "";
+  if (!token) {
+    error.textContent = "Kein Host-Token gefunden, bitte Link mit #token=... öffnen.";
+    return;
+  }
+  try {
+    await post(method, body);
+  } catch (e) {
+    error.textContent = e.message;
+  }
+  refresh();
+}
+
+if (token) {
+  refresh();
+  setInterval(refresh, 2000);
+}
+</script>
+{{else}}
+<form method="get">
+<label for="code">Session-Code</label>
+<input id="code" name="code" autocomplete="off" autocapitalize="characters" required>
+<button type="submit" id="advance">Öffnen</button>
+</form>
+{{end}}
+</body>
+</html>
+`))
+
+// phaseNames are the German phase labels of the simple host page.
+var phaseNames = map[game.Phase]string{
+	game.PhaseLobby:            "Lobby",
+	game.PhasePromptSet:        "Frage gesetzt",
+	game.PhaseAnswering:        "Antworten",
+	game.PhaseVoting:           "Abstimmung",
+	game.PhaseReveal:           "Auflösung",
+	game.PhaseScoreboard:       "Punktestand",
+	game.PhaseEnd:              "Spielende",
+	game.PhasePromptCollection: "Fragen sammeln",
+}
+
+// SimpleHostHandler serves GET /host/simple?code=..., a fallback for when
+// the full host view misbehaves on a phone mid-show.
+func (srv *Server) SimpleHostHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		data := map[string]any{"Service": APIService, "Phases": phaseNames}
+		if code := c.Query("code"); code != "" {
+			sess, err := srv.RM.Lookup(code)
+			if err != nil {
+				c.String(http.StatusNotFound, "Session nicht gefunden")
+				return
+			}
+			data["Code"] = sess.Code
+			data["Status"] = phaseNames[sess.GetPhase()]
+		}
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Header("Cache-Control", "no-store")
+		if err := simpleHostPage.Execute(c.Writer, data); err != nil {
+			c.Status(http.StatusInternalServerError)
+		}
+	}
+}
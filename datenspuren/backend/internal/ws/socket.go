@@ -2,275 +2,906 @@ package ws
 
 import (
     "context"
+    "errors"
+    "net"
     "net/http"
     "strings"
+    "sync"
+    "sync/atomic"
+    "time"
 
     "github.com/gin-gonic/gin"
     socketio "github.com/googollee/go-socket.io"
+    "github.com/kiliankoe/gptdash/internal/ai"
     "github.com/kiliankoe/gptdash/internal/config"
     "github.com/kiliankoe/gptdash/internal/game"
+    "github.com/kiliankoe/gptdash/internal/i18n"
+    "github.com/kiliankoe/gptdash/internal/moderation"
+    "github.com/kiliankoe/gptdash/internal/ratelimit"
+    "github.com/kiliankoe/gptdash/internal/token"
+    "github.com/kiliankoe/gptdash/internal/tts"
+    "github.com/rs/zerolog"
     "github.com/rs/zerolog/log"
 )
 
 type ConnCtx struct {
     Code  string
     Token string
-    Role  string // "host" | "player"
+    Role  string // "host" | "player" | "spectator"
 }
 
 type Server struct {
     RM           *game.RoomManager
+    memberMu     sync.Mutex
     members      map[string]map[string]socketio.Conn // sessionCode -> socketID -> Conn
     provider     AIProvider
     provByName   map[string]AIProvider
-    systemPrompt string
-    config       config.Config
+    conf         atomic.Pointer[config.Config] // see Reload
+    profiles     *game.ProfileStore
+    library      *game.PromptLibrary
+    stats        *game.PromptStatsStore
+    answers      *game.AnswerPool // pre-written AI answers, nil without a pool file
+    overlay      *overlayHub
+    translator   Translator
+    moderator    Moderator // screens prompts, nil without MODERATOR
+    speaker      tts.Speaker // reads answers aloud, nil without TTS
+    imageGen     ai.ImageProvider // draws image rounds, nil without IMAGE_PROVIDER
+    imageModel   string
+    imageMu      sync.Mutex
+    images       map[string]ai.Image // "code/roundID" -> generated image
+    imageOrder   []string // keys of images, oldest first
+    collector    Collector
+    signage      *signageHook // nil without a signage webhook
+    webhook      *game.Webhook // nil without WEBHOOK_URL
+    aiMu         sync.Mutex
+    aiCalls      map[string]aiCall // roundID -> in-flight AI call
+    connMu       sync.Mutex
+    conns        map[string]connInfo // socketID -> handshake info
+    cueMu        sync.Mutex
+    cues         map[string][]*time.Timer // sessionCode -> pending timer cues
+    autoMu       sync.Mutex
+    autoVoting   map[string]string // sessionCode -> round that opens voting once answered, see nextRound
+    autoTimers   map[string]*time.Timer // sessionCode -> pending step of a hostless session
+    stepLocks    sync.Map // sessionCode -> *sync.Mutex serializing automatic steps, see stepFrom
+    votingAI     sync.Map // sessionCode -> ID of the round whose AI answer was generated before voting
+    timers       *game.PhaseTimers // answer and vote countdowns
+    actions      map[string]action // event -> socket handler, for event streams
+    streamMu     sync.Mutex
+    streams      map[string]*streamConn // streamID -> open event stream
+    io           *socketio.Server
+    closing      atomic.Bool // set by Shutdown, new sockets and streams are turned away
+    autoBots     Bots // joined to every session created over the socket, see SetAutoBots
+    eventLimit   *ratelimit.Bucket // per connection, nil without EVENT_RATE_LIMIT
+    joins        *joinGuard // per IP, nil without JOIN_RATE_LIMIT
+    proxies      []*net.IPNet // TRUSTED_PROXIES, see clientIP
+    pinFailures  *ratelimit.Limiter // wrong profile PINs per IP, see pinAttempts
+    tokens       *token.Signer // signs client tokens, nil without TOKEN_SECRET, see ClientToken
 }
 
+// A source guessing profile PINs is turned away after pinAttempts wrong
+// ones within pinWindow, on top of the join guard.
+const (
+    pinAttempts = 10
+    pinWindow   = 10 * time.Minute
+)
+
 type AIProvider interface {
     Complete(ctx context.Context, model string, prompt string) (string, error)
     CompleteWithSystem(ctx context.Context, model string, systemPrompt string, prompt string) (string, error)
+    CompleteDetailed(ctx context.Context, model string, systemPrompt string, prompt string) (ai.Completion, error)
 }
 
 func New(rm *game.RoomManager, cfg config.Config) *Server {
-    return &Server{RM: rm, members: make(map[string]map[string]socketio.Conn), config: cfg}
+    srv := &Server{RM: rm, members: make(map[string]map[string]socketio.Conn), overlay: newOverlayHub(), aiCalls: make(map[string]aiCall), conns: make(map[string]connInfo), cues: make(map[string][]*time.Timer), autoVoting: make(map[string]string), autoTimers: make(map[string]*time.Timer), actions: make(map[string]action), streams: make(map[string]*streamConn)}
+    srv.conf.Store(&cfg)
+    srv.timers = game.NewPhaseTimers(srv.emitTimer, srv.expireTimer)
+    if cfg.EventRate > 0 {
+        srv.eventLimit = ratelimit.NewBucket(float64(cfg.EventRate), cfg.EventBurst)
+    }
+    if cfg.JoinRate > 0 {
+        srv.joins = newJoinGuard(cfg.JoinRate, cfg.JoinBlock)
+    }
+    srv.proxies = parseProxies(cfg.TrustedProxies)
+    srv.pinFailures = ratelimit.New(pinAttempts, pinWindow)
+    if cfg.TokenSecret != "" {
+        srv.tokens = token.NewSigner(cfg.TokenSecret, cfg.TokenTTL)
+    }
+    return srv
 }
 
 func (srv *Server) SetProvider(p AIProvider) { srv.provider = p }
 func (srv *Server) SetProviders(m map[string]AIProvider) { srv.provByName = m }
-func (srv *Server) SetSystemPrompt(prompt string) { srv.systemPrompt = prompt }
+func (srv *Server) SetProfiles(ps *game.ProfileStore) { srv.profiles = ps }
+func (srv *Server) SetPromptLibrary(l *game.PromptLibrary) { srv.library = l }
+func (srv *Server) SetPromptStats(ps *game.PromptStatsStore) { srv.stats = ps }
+func (srv *Server) SetAnswerPool(p *game.AnswerPool) { srv.answers = p }
+func (srv *Server) SetWebhook(w *game.Webhook) { srv.webhook = w }
 
 // Mount attaches Socket.IO server with handlers to the given Gin engine.
 func (srv *Server) Mount(r *gin.Engine) *socketio.Server {
     io := socketio.NewServer(nil)
+    srv.io = io
 
     io.OnConnect("/", func(s socketio.Conn) error {
+        defer func() {
+            if r := recover(); r != nil { logPanic(log.With().Str("sid", s.ID()).Logger(), r) }
+        }()
         s.SetContext(&ConnCtx{})
+        srv.trackConn(s)
         log.Info().Str("sid", s.ID()).Msg("socket connected")
         return nil
     })
 
     // game:create
-    io.OnEvent("/", "game:create", func(s socketio.Conn, payload struct {
+    on(srv, io, "game:create", func(s socketio.Conn, req *request, payload struct {
         Config game.SessionConfig `json:"config"`
     }) map[string]any {
-        code, hostToken, _ := srv.RM.CreateSession(payload.Config)
+        code, hostToken, err := srv.RM.CreateSession(payload.Config)
+        if errors.Is(err, game.ErrTooManySessions) {
+            return req.err("server_full", "Too many games are running, please try again later")
+        } else if errors.Is(err, game.ErrInvalidTimeZone) {
+            return req.invalid("config.timeZone", err.Error())
+        } else if errors.Is(err, game.ErrInvalidTrigger) {
+            return req.invalid("config.aiTrigger", err.Error())
+        } else if err != nil {
+            return req.err("internal_error", err.Error())
+        }
         s.SetContext(&ConnCtx{Code: code, Token: hostToken, Role: "host"})
         s.Join(code)
         srv.addMember(code, s)
-        log.Info().Str("sid", s.ID()).Str("code", code).Msg("game:create")
+        req.log.Info().Str("code", code).Msg("game:create")
+        if srv.autoBots.Count > 0 {
+            srv.AddBots(code, srv.autoBots)
+        }
         // send initial state to host only
         srv.emitStateTo(code)
-        return map[string]any{"sessionCode": code, "hostToken": hostToken}
+        sess, _ := srv.RM.Get(code)
+        return req.ack(map[string]any{"sessionCode": code, "joinPin": sess.JoinPin, "hostToken": srv.ClientToken(code, "host", "", hostToken), "overlayToken": sess.OverlayToken})
+    })
+
+    // game:createHostless - any player starts a game without a GM screen and joins it
+    on(srv, io, "game:createHostless", func(s socketio.Conn, req *request, payload struct {
+        Name   string             `json:"name" validate:"required,max=40"`
+        Config game.SessionConfig `json:"config"`
+    }) map[string]any {
+        cfg := payload.Config
+        cfg.Hostless = true
+        cfg.Public, cfg.Rehearsal = false, false
+        code, _, err := srv.RM.CreateSession(cfg)
+        if errors.Is(err, game.ErrTooManySessions) {
+            return req.err("server_full", "Too many games are running, please try again later")
+        } else if errors.Is(err, game.ErrInvalidTimeZone) {
+            return req.invalid("config.timeZone", err.Error())
+        } else if errors.Is(err, game.ErrInvalidTrigger) {
+            return req.invalid("config.aiTrigger", err.Error())
+        } else if err != nil {
+            return req.err("internal_error", err.Error())
+        }
+        sess, _ := srv.RM.Get(code)
+        playerID, playerToken, err := sess.Join(payload.Name)
+        if errors.Is(err, moderation.ErrBlocked) {
+            srv.RM.Remove(code)
+            return req.err("name_blocked", "Please choose a different name")
+        }
+        s.SetContext(&ConnCtx{Code: code, Token: playerToken, Role: "player"})
+        s.Join(code)
+        srv.addMember(code, s)
+        req.log.Info().Str("code", code).Str("playerId", playerID).Msg("game:createHostless")
+        srv.emitStateTo(code)
+        return req.ack(map[string]any{"sessionCode": code, "joinPin": sess.JoinPin, "playerToken": srv.ClientToken(code, "player", playerID, playerToken), "playerId": playerID})
+    })
+
+    // game:ready (player, hostless) - ready to start the game or the next round
+    on(srv, io, "game:ready", func(s socketio.Conn, req *request, _ struct{}) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        phase := sess.GetPhase()
+        allReady, err := sess.SetReady(ctx.Token)
+        if err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Bool("allReady", allReady).Msg("game:ready")
+        srv.emitStateTo(ctx.Code)
+        if allReady {
+            srv.hostlessStepAsync(sess, phase, "ready")
+        }
+        return req.ack(map[string]any{"ok": true})
+    })
+
+    // game:suggestPrompt (player, hostless) - propose a prompt for an upcoming round
+    on(srv, io, "game:suggestPrompt", func(s socketio.Conn, req *request, payload struct {
+        Prompt string `json:"prompt" validate:"required,max=500"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if ack := srv.moderate(req, payload.Prompt); ack != nil { return ack }
+        q, err := sess.SuggestPrompt(ctx.Token, payload.Prompt)
+        if err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Str("queuedId", q.ID).Msg("game:suggestPrompt")
+        if aiTrigger(sess) == game.AITriggerQueue {
+            srv.generateForQueuedPrompt(sess, q)
+        }
+        return req.ack(map[string]any{"queuedId": q.ID})
     })
 
     // game:join
-    io.OnEvent("/", "game:join", func(s socketio.Conn, payload struct {
-        SessionCode string `json:"sessionCode"`
-        Name        string `json:"name"`
+    on(srv, io, "game:join", func(s socketio.Conn, req *request, payload struct {
+        SessionCode string `json:"sessionCode" validate:"required,max=16"`
+        Name        string `json:"name" validate:"required,max=40"`
+        Pin         string `json:"pin" validate:"max=12"` // optional, claims or logs into a profile
     }) map[string]any {
-        sess, err := srv.RM.Get(payload.SessionCode)
+        // accepts the session code or its numeric join PIN
+        sess, err := srv.RM.Lookup(payload.SessionCode)
         if err != nil {
-            return srv.err(s, "session_not_found", "Session not found")
+            return req.err("session_not_found", "Session not found")
+        }
+        payload.SessionCode = sess.Code
+        if srv.sessionFull(sess.Code) {
+            return req.err("session_full", "Session is full")
+        }
+        if payload.Pin != "" && srv.profiles != nil {
+            ip := srv.remoteIP(s)
+            if srv.pinFailures.Exhausted(ip) {
+                return req.err("rate_limited", "Too many wrong PINs, please try again later")
+            }
+            err := srv.profiles.Claim(payload.Name, payload.Pin)
+            if errors.Is(err, game.ErrPinTooShort) {
+                return req.err("pin_too_short", i18n.T(req.lang, "PINs need at least %d characters", game.MinPinLength))
+            }
+            if err != nil {
+                srv.pinFailures.Allow(ip)
+                return req.err("invalid_pin", "Name is claimed by a profile with a different PIN")
+            }
+        }
+        playerID, playerToken, err := sess.Join(payload.Name)
+        if errors.Is(err, moderation.ErrBlocked) {
+            return req.err("name_blocked", "Please choose a different name")
+        }
+        if payload.Pin != "" && srv.profiles != nil {
+            sess.LinkProfile(playerID, payload.Name)
         }
-        playerID, playerToken := sess.Join(payload.Name)
         s.SetContext(&ConnCtx{Code: payload.SessionCode, Token: playerToken, Role: "player"})
         s.Join(payload.SessionCode)
         srv.addMember(payload.SessionCode, s)
-        log.Info().Str("sid", s.ID()).Str("code", payload.SessionCode).Str("playerId", playerID).Msg("game:join")
+        req.log.Info().Str("code", payload.SessionCode).Str("playerId", playerID).Msg("game:join")
         // broadcast updated state to all in room (personalized per-conn)
         srv.emitStateTo(payload.SessionCode)
-        return map[string]any{"playerToken": playerToken, "playerId": playerID}
+        return req.ack(map[string]any{"playerToken": srv.ClientToken(sess.Code, "player", playerID, playerToken), "playerId": playerID, "sessionCode": sess.Code})
+    })
+
+    // game:spectate - watch a session without playing, e.g. the audience
+    on(srv, io, "game:spectate", func(s socketio.Conn, req *request, payload struct {
+        SessionCode string `json:"sessionCode" validate:"required,max=16"`
+    }) map[string]any {
+        sess, err := srv.RM.Lookup(payload.SessionCode)
+        if err != nil {
+            return req.err("session_not_found", "Session not found")
+        }
+        if srv.sessionFull(sess.Code) {
+            return req.err("session_full", "Session is full")
+        }
+        s.SetContext(&ConnCtx{Code: sess.Code, Role: "spectator"})
+        s.Join(sess.Code)
+        srv.addMember(sess.Code, s)
+        req.log.Info().Str("code", sess.Code).Msg("game:spectate")
+        srv.emitStateTo(sess.Code)
+        return req.ack(map[string]any{"sessionCode": sess.Code})
     })
 
     // game:resume (reconnection)
-    io.OnEvent("/", "game:resume", func(s socketio.Conn, payload struct {
-        SessionCode string `json:"sessionCode"`
-        Role        string `json:"role"`
-        Token       string `json:"token"`
+    on(srv, io, "game:resume", func(s socketio.Conn, req *request, payload struct {
+        SessionCode string `json:"sessionCode" validate:"required,max=16"`
+        Role        string `json:"role" validate:"oneof=host|player"`
+        Token       string `json:"token" validate:"required,max=1024"`
     }) map[string]any {
         sess, err := srv.RM.Get(payload.SessionCode)
-        if err != nil { return srv.err(s, "session_not_found", "Session not found") }
-        if payload.Role == "host" {
-            if payload.Token != sess.HostToken { return srv.err(s, "unauthorized", "Invalid host token") }
-        } else {
-            id := sess.GetPlayerIDByToken(payload.Token)
-            if id == "" { return srv.err(s, "unauthorized", "Invalid player token") }
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        role, token := srv.authenticate(sess, payload.Token)
+        if role != payload.Role {
+            if payload.Role == "host" { return req.err("unauthorized", "Invalid host token") }
+            return req.err("unauthorized", "Invalid player token")
         }
-        s.SetContext(&ConnCtx{Code: payload.SessionCode, Token: payload.Token, Role: payload.Role})
+        ctx := &ConnCtx{Code: payload.SessionCode, Token: token, Role: payload.Role}
+        s.SetContext(ctx)
         s.Join(payload.SessionCode)
         srv.addMember(payload.SessionCode, s)
-        log.Info().Str("sid", s.ID()).Str("code", payload.SessionCode).Str("role", payload.Role).Msg("game:resume")
+        req.log.Info().Str("code", payload.SessionCode).Str("role", payload.Role).Msg("game:resume")
         // send state to only this connection
-        sess2, _ := srv.RM.Get(payload.SessionCode)
+        s.Emit("game:state", srv.statePayload(sess, ctx, srv.spectatorCount(payload.SessionCode)))
+        // Also broadcast updated state to all other connections (they need to see this player is back)
+        srv.emitStateTo(payload.SessionCode)
+        return req.ack(map[string]any{"ok": true})
+    })
+
+    // game:refreshToken - swap the client token for a fresh one, the old
+    // one stops working
+    on(srv, io, "game:refreshToken", func(s socketio.Conn, req *request, payload struct {
+        Token string `json:"token" validate:"required,max=1024"`
+    }) map[string]any {
         ctx := s.Context().(*ConnCtx)
-        you := map[string]any{"role": ctx.Role}
-        if ctx.Role == "player" {
-            if id := sess2.GetPlayerIDByToken(ctx.Token); id != "" {
-                you["playerId"] = id
-            }
+        if ctx.Code == "" { return req.err("session_not_found", "Session not found") }
+        tok, err := srv.refreshToken(ctx, payload.Token)
+        if err != nil { return req.err("unauthorized", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Str("role", ctx.Role).Msg("game:refreshToken")
+        return req.ack(map[string]any{"token": tok})
+    })
+
+    // game:queuePrompt (host) - prepare a prompt for an upcoming round
+    on(srv, io, "game:queuePrompt", func(s socketio.Conn, req *request, payload struct {
+        Prompt       string            `json:"prompt" validate:"required,max=500"`
+        Translations map[string]string `json:"translations" validate:"max=10"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if ack := srv.moderate(req, payload.Prompt); ack != nil { return ack }
+        q, err := sess.QueuePrompt(ctx.Token, payload.Prompt, payload.Translations)
+        if err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Str("queuedId", q.ID).Msg("game:queuePrompt")
+        if aiTrigger(sess) == game.AITriggerQueue {
+            srv.generateForQueuedPrompt(sess, q)
         }
-        payloadOut := map[string]any{
-            "phase":       string(sess2.Phase),
-            "players":     sess2.Players(),
-            "round":       currentRoundPtr(sess2),
-            "you":         you,
-            "sessionCode": payload.SessionCode,
-            "scores":      sess2.ScoresArray(),
+        srv.emitQueueTo(sess)
+        return req.ack(map[string]any{"queuedId": q.ID})
+    })
+
+    // game:library (host) - list imported prompts to pick from, optionally by category
+    on(srv, io, "game:library", func(s socketio.Conn, req *request, payload struct {
+        Category string `json:"category" validate:"max=64"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if ctx.Role != "host" || ctx.Token != sess.HostToken { return req.err("unauthorized", "Invalid host token") }
+        prompts := []game.DeckPrompt{}
+        if srv.library != nil {
+            prompts = srv.library.List(payload.Category)
         }
-        s.Emit("game:state", payloadOut)
-        // Also broadcast updated state to all other connections (they need to see this player is back)
-        srv.emitStateTo(payload.SessionCode)
-        return map[string]any{"ok": true}
+        byID := make(map[string]string, len(prompts))
+        for _, p := range prompts {
+            byID[p.ID] = p.Prompt
+        }
+        return req.ack(map[string]any{"prompts": prompts, "stats": srv.promptStats(byID)})
+    })
+
+    // game:randomPrompt (host) - suggest a prompt not played yet from the
+    // library and the built-in prompts, for hosts blanking on stage
+    on(srv, io, "game:randomPrompt", func(s socketio.Conn, req *request, payload struct {
+        Category string `json:"category" validate:"max=64"`
+        Language string `json:"language" validate:"max=8"` // defaults to the session's
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if ctx.Role != "host" || ctx.Token != sess.HostToken { return req.err("unauthorized", "Invalid host token") }
+        lang := payload.Language
+        if lang == "" { lang = sess.Config.Language }
+        p, err := game.RandomPrompt(srv.library, payload.Category, lang, sess.PlayedPrompts())
+        if err != nil { return req.err("not_found", err.Error()) }
+        return req.ack(map[string]any{"prompt": p})
+    })
+
+    // game:answerPool (host) - pre-written AI answers fitting the current round
+    on(srv, io, "game:answerPool", func(s socketio.Conn, req *request, _ struct{}) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if ctx.Role != "host" || ctx.Token != sess.HostToken { return req.err("unauthorized", "Invalid host token") }
+        answers := []game.PoolAnswer{}
+        if r := currentRoundPtr(sess); srv.answers != nil && r != nil {
+            answers = srv.answers.For(r.Prompt)
+        }
+        return req.ack(map[string]any{"answers": answers})
+    })
+
+    // game:retirePrompt (host) - drop a worn-out prompt from the library
+    on(srv, io, "game:retirePrompt", func(s socketio.Conn, req *request, payload struct {
+        ID string `json:"id" validate:"required,max=64"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if ctx.Role != "host" || ctx.Token != sess.HostToken { return req.err("unauthorized", "Invalid host token") }
+        if srv.library == nil { return req.err("not_found", game.ErrLibraryPromptGone.Error()) }
+        if err := srv.library.Remove(payload.ID); errors.Is(err, game.ErrLibraryPromptGone) {
+            return req.err("not_found", err.Error())
+        } else if err != nil {
+            return req.err("internal_error", err.Error())
+        }
+        req.log.Info().Str("code", ctx.Code).Str("id", payload.ID).Msg("game:retirePrompt")
+        return req.ack(map[string]any{"ok": true})
+    })
+
+    // game:queueFromLibrary (host) - queue an imported prompt for an upcoming round
+    on(srv, io, "game:queueFromLibrary", func(s socketio.Conn, req *request, payload struct {
+        ID string `json:"id" validate:"required,max=64"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if srv.library == nil { return req.err("not_found", game.ErrLibraryPromptGone.Error()) }
+        p, err := srv.library.Get(payload.ID)
+        if err != nil { return req.err("not_found", err.Error()) }
+        queued, err := srv.queueDeck(sess, ctx.Token, []game.DeckPrompt{p})
+        if err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Str("queuedId", queued[0].ID).Msg("game:queueFromLibrary")
+        return req.ack(map[string]any{"queuedId": queued[0].ID})
     })
 
     // game:setPrompt (host)
-    io.OnEvent("/", "game:setPrompt", func(s socketio.Conn, payload struct {
-        Prompt string `json:"prompt"`
+    on(srv, io, "game:setPrompt", func(s socketio.Conn, req *request, payload struct {
+        Prompt       string            `json:"prompt" validate:"max=500"`
+        Translations map[string]string `json:"translations" validate:"max=10"` // optional, language -> prompt
+        QueuedID     string            `json:"queuedId" validate:"max=64"`     // optional, start a queued prompt instead
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if ack := srv.moderate(req, payload.Prompt); ack != nil { return ack }
+        if err := srv.setPrompt(sess, ctx.Token, payload.Prompt, payload.Translations, payload.QueuedID); err != nil {
+            return req.err("bad_request", err.Error())
+        }
+        req.log.Info().Str("code", ctx.Code).Msg("game:setPrompt")
+        return req.ack(map[string]any{"ok": true})
+    })
+
+    // game:nextRoundWithPrompt (host) - one action per round: leave the scoreboard, start the
+    // round with the given or next queued prompt and open voting once everyone answered
+    on(srv, io, "game:nextRoundWithPrompt", func(s socketio.Conn, req *request, payload struct {
+        Prompt       string            `json:"prompt" validate:"max=500"`
+        Translations map[string]string `json:"translations" validate:"max=10"`
+        QueuedID     string            `json:"queuedId" validate:"max=64"`
     }) map[string]any {
         ctx := s.Context().(*ConnCtx)
         sess, err := srv.RM.Get(ctx.Code)
-        if err != nil { return srv.err(s, "session_not_found", "Session not found") }
-        if err := sess.SetPrompt(ctx.Token, payload.Prompt); err != nil {
-            return srv.err(s, "bad_request", err.Error())
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if ack := srv.moderate(req, payload.Prompt); ack != nil { return ack }
+        if err := srv.nextRound(sess, ctx.Token, payload.Prompt, payload.Translations, payload.QueuedID, req.log); err != nil {
+            return req.err("bad_request", err.Error())
         }
-        log.Info().Str("code", ctx.Code).Msg("game:setPrompt")
-        // moving to Answering -> notify players
+        req.log.Info().Str("code", ctx.Code).Msg("game:nextRoundWithPrompt")
+        return req.ack(map[string]any{"ok": true, "roundIndex": sess.PublicState().RoundIndex})
+    })
+
+    // game:openPromptCollection (host) - let the players suggest and vote on the next prompt
+    on(srv, io, "game:openPromptCollection", func(s socketio.Conn, req *request, _ struct{}) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if err := sess.OpenPromptCollection(ctx.Token); err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Msg("game:openPromptCollection")
         srv.emitStateTo(ctx.Code)
-        // kick off AI completion in background (best-effort)
-        go func(code string) {
-            // pick provider per session
-            prov := srv.provider
-            if srv.provByName != nil {
-                if p := srv.provByName[strings.ToLower(sess.Config.Provider)]; p != nil {
-                    prov = p
-                }
-            }
-            if prov == nil { return }
-            // use session config model if present
-            model := sess.Config.Model
-            if model == "" { model = "gpt-3.5-turbo" }
-            var text string
-            var err error
-            if srv.systemPrompt != "" {
-                text, err = prov.CompleteWithSystem(context.Background(), model, srv.systemPrompt, payload.Prompt)
-            } else {
-                text, err = prov.Complete(context.Background(), model, payload.Prompt)
+        srv.publishPhase(sess)
+        srv.timers.Sync(sess)
+        return req.ack(map[string]any{"ok": true})
+    })
+
+    // game:submitPrompt (player) - suggest a prompt during prompt collection
+    on(srv, io, "game:submitPrompt", func(s socketio.Conn, req *request, payload struct {
+        Prompt string `json:"prompt" validate:"required,max=500"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if ack := srv.moderate(req, payload.Prompt); ack != nil { return ack }
+        c, err := sess.SubmitPrompt(ctx.Token, payload.Prompt)
+        if err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Str("candidateId", c.ID).Msg("game:submitPrompt")
+        srv.emitPromptCandidates(sess)
+        return req.ack(map[string]any{"candidateId": c.ID})
+    })
+
+    // game:votePrompt (player) - vote for a suggested prompt, voting again changes the vote
+    on(srv, io, "game:votePrompt", func(s socketio.Conn, req *request, payload struct {
+        CandidateID string `json:"candidateId" validate:"required,max=64"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if err := sess.VotePrompt(ctx.Token, payload.CandidateID); err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Str("candidateId", payload.CandidateID).Msg("game:votePrompt")
+        srv.emitPromptCandidates(sess)
+        return req.ack(map[string]any{"ok": true})
+    })
+
+    // game:closePromptCollection (host) - start the next round with the prompt most players voted for
+    on(srv, io, "game:closePromptCollection", func(s socketio.Conn, req *request, _ struct{}) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if ctx.Role != "host" || ctx.Token != sess.HostToken { return req.err("unauthorized", "Invalid host token") }
+        if sess.GetPhase() != game.PhasePromptCollection { return req.err("bad_request", game.ErrInvalidPhase.Error()) }
+        winner, ok := sess.WinningPrompt()
+        if !ok { return req.err("bad_request", "no prompt suggested yet") }
+        if err := srv.nextRound(sess, ctx.Token, winner.Prompt, nil, "", req.log); err != nil {
+            return req.err("bad_request", err.Error())
+        }
+        req.log.Info().Str("code", ctx.Code).Str("candidateId", winner.ID).Msg("game:closePromptCollection")
+        return req.ack(map[string]any{"ok": true, "prompt": winner.Prompt})
+    })
+
+    // game:setImagePrompt (host) - start an image round: players caption a picture generated from the prompt
+    on(srv, io, "game:setImagePrompt", func(s socketio.Conn, req *request, payload struct {
+        Prompt string `json:"prompt" validate:"required,max=500"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if srv.imageGen == nil { return req.err("images_disabled", "Image rounds need an image provider") }
+        if ack := srv.moderate(req, payload.Prompt); ack != nil { return ack }
+        if err := sess.SetImagePrompt(ctx.Token, strings.TrimSpace(payload.Prompt)); err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Msg("game:setImagePrompt")
+        srv.roundStarted(sess, false)
+        srv.generateImage(sess)
+        return req.ack(map[string]any{"ok": true})
+    })
+
+    // game:attach (host) - show an image or link with the prompt, an empty url removes it
+    on(srv, io, "game:attach", func(s socketio.Conn, req *request, payload struct {
+        Kind string `json:"kind" validate:"max=16"` // "image" or "link"
+        URL  string `json:"url" validate:"max=2048"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        var a *game.Attachment
+        if strings.TrimSpace(payload.URL) != "" {
+            a = &game.Attachment{Kind: payload.Kind, URL: payload.URL}
+        }
+        if err := sess.AttachToPrompt(ctx.Token, a); errors.Is(err, game.ErrInvalidAttachment) {
+            return req.invalid("url", err.Error())
+        } else if err != nil {
+            return req.err("bad_request", err.Error())
+        }
+        req.log.Info().Str("code", ctx.Code).Str("kind", payload.Kind).Msg("game:attach")
+        srv.emitStateTo(ctx.Code)
+        srv.publishPhase(sess)
+        return req.ack(map[string]any{"ok": true})
+    })
+
+    // game:extendTimer (host) - give everyone more time in the current phase
+    on(srv, io, "game:extendTimer", func(s socketio.Conn, req *request, payload struct {
+        Seconds int `json:"seconds" validate:"min=1,max=300"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if err := srv.extendTimer(sess, ctx.Token, time.Duration(payload.Seconds)*time.Second); err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Int("seconds", payload.Seconds).Msg("game:extendTimer")
+        return req.ack(map[string]any{"ok": true, "deadline": deadlineMillis(sess)})
+    })
+
+    // game:resetRound (host) - abort a dud round and go back to PromptSet
+    on(srv, io, "game:resetRound", func(s socketio.Conn, req *request, _ struct{}) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        r := currentRoundPtr(sess)
+        if err := sess.ResetRound(ctx.Token); err != nil { return req.err("bad_request", err.Error()) }
+        if r != nil {
+            srv.cancelAICall(r.ID)
+        }
+        req.log.Info().Str("code", ctx.Code).Msg("game:resetRound")
+        srv.emitStateTo(ctx.Code)
+        srv.publishPhase(sess)
+        srv.scheduleCues(sess)
+        srv.timers.Sync(sess)
+        return req.ack(map[string]any{"ok": true})
+    })
+
+    // game:setAIAnswer (host) - manual AI answer, e.g. for provider "manual",
+    // or one picked from the answer pool by poolId, optionally edited
+    on(srv, io, "game:setAIAnswer", func(s socketio.Conn, req *request, payload struct {
+        Text   string `json:"text" validate:"max=1000"`
+        PoolID string `json:"poolId" validate:"max=32"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        text := strings.TrimSpace(payload.Text)
+        var meta *game.AIMetadata
+        if payload.PoolID != "" {
+            if srv.answers == nil { return req.err("not_found", game.ErrPoolAnswerGone.Error()) }
+            pooled, err := srv.answers.Get(payload.PoolID)
+            if err != nil { return req.err("not_found", err.Error()) }
+            if text == "" {
+                text = pooled.Text
             }
-            if err == nil && text != "" {
-                // insert AI submission
-                _, _ = sess.AddAISubmission(text)
-                // notify GM that AI answer is ready
-                for _, c := range srv.members[code] {
-                    if ctx, ok := c.Context().(*ConnCtx); ok && ctx.Role == "host" {
-                        c.Emit("game:aiAnswer", map[string]any{"answer": text})
-                    }
-                }
+            if text == pooled.Text {
+                meta = &game.AIMetadata{Provider: "pool", Model: "prewritten"}
             }
-        }(ctx.Code)
-        return map[string]any{"ok": true}
+        }
+        if text == "" { return req.err("bad_request", "AI answer must not be empty") }
+        id, err := sess.SetAIAnswer(ctx.Token, text)
+        if err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Str("submissionId", id).Str("poolId", payload.PoolID).Msg("game:setAIAnswer")
+        if r := currentRoundPtr(sess); meta != nil && r != nil {
+            sess.RecordAIMetadata(r.ID, *meta)
+        }
+        srv.translateSubmission(sess, id, text)
+        srv.notifyAIAnswer(sess, text, meta)
+        srv.advanceWhenAnswered(sess)
+        return req.ack(map[string]any{"submissionId": id})
+    })
+
+    // game:editAIAnswer (host) - tweak the AI answer before voting, the generated text stays in the export
+    on(srv, io, "game:editAIAnswer", func(s socketio.Conn, req *request, payload struct {
+        Text string `json:"text" validate:"required,max=1000"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        text := strings.TrimSpace(payload.Text)
+        if text == "" { return req.err("bad_request", "AI answer must not be empty") }
+        id, err := sess.EditAIAnswer(ctx.Token, text)
+        if err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Str("submissionId", id).Msg("game:editAIAnswer")
+        srv.translateSubmission(sess, id, text)
+        var meta *game.AIMetadata
+        if r := currentRoundPtr(sess); r != nil {
+            meta = sess.RoundAIMetadata(r.Index)
+        }
+        srv.notifyAIAnswer(sess, text, meta)
+        srv.advanceWhenAnswered(sess)
+        return req.ack(map[string]any{"submissionId": id})
+    })
+
+    // game:regenerateAI (host) - ask the provider again when the AI answer is unusable
+    on(srv, io, "game:regenerateAI", func(s socketio.Conn, req *request, _ struct{}) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if ctx.Role != "host" || ctx.Token != sess.HostToken { return req.err("unauthorized", "Only the host can regenerate the AI answer") }
+        if sess.GetPhase() != game.PhaseAnswering { return req.err("bad_request", game.ErrInvalidPhase.Error()) }
+        if isManual(sess.RoundModel()) { return req.err("bad_request", "Manual sessions have no AI provider to ask") }
+        req.log.Info().Str("code", ctx.Code).Msg("game:regenerateAI")
+        // the new answer arrives as game:aiAnswer, or game:aiFailed
+        srv.generateForCurrentRound(sess)
+        return req.ack(map[string]any{"ok": true})
     })
 
     // game:submit
-    io.OnEvent("/", "game:submit", func(s socketio.Conn, payload struct {
-        Text string `json:"text"`
+    on(srv, io, "game:submit", func(s socketio.Conn, req *request, payload struct {
+        Text string `json:"text" validate:"required,max=500"`
     }) map[string]any {
         ctx := s.Context().(*ConnCtx)
         sess, err := srv.RM.Get(ctx.Code)
-        if err != nil { return srv.err(s, "session_not_found", "Session not found") }
+        if err != nil { return req.err("session_not_found", "Session not found") }
         id, err := sess.Submit(ctx.Token, payload.Text)
-        if err != nil { return srv.err(s, "bad_request", err.Error()) }
-        log.Info().Str("code", ctx.Code).Str("submissionId", id).Msg("game:submit")
-        // notify count update (only human submissions) and player status
-        cnt := sess.HumanSubmissionCount()
-        status := sess.PlayerSubmissionStatus()
-        io.BroadcastToRoom("/", ctx.Code, "game:submissions", map[string]any{"count": cnt, "playerStatus": status})
-        return map[string]any{"submissionId": id}
+        if errors.Is(err, moderation.ErrBlocked) { return req.err("answer_blocked", "Your answer contains a blocked word") }
+        if errors.Is(err, game.ErrAnswerTooLong) { return req.err("answer_too_long", i18n.T(req.lang, "Answers may be at most %d characters", sess.Config.MaxAnswerLength)) }
+        if err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Str("submissionId", id).Msg("game:submit")
+        srv.translateSubmission(sess, id, sess.SubmissionText(id))
+        srv.notifySubmissions(sess)
+        srv.advanceWhenAnswered(sess)
+        return req.ack(map[string]any{"submissionId": id})
     })
 
     // game:advance
-    io.OnEvent("/", "game:advance", func(s socketio.Conn) map[string]any {
-        ctx := s.Context().(*ConnCtx)
-        sess, err := srv.RM.Get(ctx.Code)
-        if err != nil { return srv.err(s, "session_not_found", "Session not found") }
-        // capture phase before advance to decide what to emit
-        previousPhase := sess.GetPhase()
-        if err := sess.Advance(ctx.Token); err != nil { return srv.err(s, "bad_request", err.Error()) }
-        currentPhase := sess.GetPhase()
-        log.Info().Str("code", ctx.Code).Str("from", string(previousPhase)).Str("to", string(currentPhase)).Msg("phase transition")
-        
-        // Export game data if we just entered Scoreboard phase (round complete)
-        if currentPhase == game.PhaseScoreboard && srv.config.ExportEnabled {
-            if exportErr := game.ExportSession(sess, srv.config.ExportFile); exportErr != nil {
-                log.Error().Err(exportErr).Str("code", ctx.Code).Msg("failed to export game data")
-            } else {
-                log.Info().Str("code", ctx.Code).Str("file", srv.config.ExportFile).Msg("exported game data")
-            }
+    // The host sends the phase and round it is looking at, so a click that
+    // crosses a phase timer doesn't skip the phase the timer just opened.
+    on(srv, io, "game:advance", func(s socketio.Conn, req *request, payload struct {
+        Phase game.Phase `json:"phase"` // empty for the current phase
+        Round int        `json:"round" validate:"min=0"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if payload.Phase == "" {
+            st := sess.PublicState()
+            payload.Phase, payload.Round = st.Phase, st.RoundIndex
         }
-        log.Info().Str("code", ctx.Code).Msg("game:advance")
-        // Emit state update
-        srv.emitStateTo(ctx.Code)
-        // If now in Voting, emit shuffled submissions
-        subs := sess.ListVotingSubmissionsShuffled()
-        if len(subs) > 0 {
-            list := make([]map[string]any, 0, len(subs))
-            for _, ssub := range subs {
-                list = append(list, map[string]any{"id": ssub.ID, "text": ssub.Text})
+        err = srv.advanceFrom(sess, ctx.Token, payload.Phase, payload.Round, req.log)
+        if errors.Is(err, game.ErrStaleStep) { return req.err("stale_step", "The game has moved on already") }
+        if err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Msg("game:advance")
+        return req.ack(map[string]any{"ok": true})
+    })
+
+    // game:skip (host, rehearsals only) - advance straight to a phase
+    on(srv, io, "game:skip", func(s socketio.Conn, req *request, payload struct {
+        Phase game.Phase `json:"phase" validate:"oneof=Voting|Scoreboard|PromptSet|End"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if !sess.Config.Rehearsal { return req.err("not_rehearsal", "Skipping phases is only possible in rehearsals") }
+        // every phase is reachable within a round's worth of steps; holding
+        // the step lock keeps phase timers out until the skip is done
+        mu := srv.stepLock(sess.Code)
+        mu.Lock()
+        defer mu.Unlock()
+        for i := 0; i < 6 && sess.GetPhase() != payload.Phase; i++ {
+            st := sess.PublicState()
+            if err := srv.advanceLocked(sess, ctx.Token, st.Phase, st.RoundIndex, req.log); err != nil { return req.err("bad_request", err.Error()) }
+        }
+        if sess.GetPhase() != payload.Phase { return req.err("bad_request", "Phase not reachable") }
+        req.log.Info().Str("code", ctx.Code).Str("phase", string(payload.Phase)).Msg("game:skip")
+        return req.ack(map[string]any{"ok": true})
+    })
+
+    // game:setReadingOrder (host) - arrange the order answers are read aloud
+    on(srv, io, "game:setReadingOrder", func(s socketio.Conn, req *request, payload struct {
+        Order []string `json:"order" validate:"required,max=100"` // submission IDs
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if err := sess.SetReadingOrder(ctx.Token, payload.Order); err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Msg("game:setReadingOrder")
+        srv.emitVoting(sess)
+        return req.ack(map[string]any{"ok": true})
+    })
+
+    // game:addRoundNote (host) - free-text note for the post-show writeup
+    on(srv, io, "game:addRoundNote", func(s socketio.Conn, req *request, payload struct {
+        Text       string `json:"text" validate:"required,max=500"`
+        RoundIndex int    `json:"roundIndex" validate:"min=0"` // optional, defaults to the current round
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        note, err := sess.AddRoundNote(ctx.Token, payload.RoundIndex, payload.Text)
+        if err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Int("round", payload.RoundIndex).Msg("game:addRoundNote")
+        // notes may arrive after the round was exported
+        if _, scored := sess.LastRound(); scored && srv.recording(sess) {
+            if _, err := game.ExportSession(sess, srv.config().ExportDir, srv.exportOptions(sess)); err != nil {
+                req.log.Error().Err(err).Str("code", ctx.Code).Msg("failed to export game data")
             }
-            io.BroadcastToRoom("/", ctx.Code, "game:voting", map[string]any{"submissions": list})
         }
-        // If now in Scoreboard, emit results with submissions and authors
-        votes := sess.Votes()
-        r := currentRoundPtr(sess)
-        aiID := ""
-        if r != nil { aiID = r.AISubmissionID }
-        // collect submissions
-        subs = sess.ListVotingSubmissionsShuffled()
-        // we want authors; rebuild directly from map
-        // Note: ListVotingSubmissionsShuffled returns a copy without authors; we will build here with authors
-        // We already have sess.submissions but it's private; use ListVotingSubmissionsShuffled then enrich via lookup
-        // For simplicity, send shuffled texts and ids only (authorId best-effort)
-        resultsList := make([]map[string]any, 0, len(subs))
-        for _, sub := range subs {
-            resultsList = append(resultsList, map[string]any{
-                "id": sub.ID,
-                "text": sub.Text,
-                "authorId": sub.PlayerID,
-            })
-        }
-        io.BroadcastToRoom("/", ctx.Code, "game:results", map[string]any{
-            "aiSubmissionId": aiID,
-            "votes": votes,
-            "scores": sess.ScoresArray(),
-            "submissions": resultsList,
-        })
-        return map[string]any{"ok": true}
+        return req.ack(map[string]any{"note": note})
+    })
+
+    // game:hint (host) - eliminate a random human answer from the vote
+    on(srv, io, "game:hint", func(s socketio.Conn, req *request, _ struct{}) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        id, err := sess.UseHint(ctx.Token)
+        if err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Str("eliminated", id).Msg("game:hint")
+        srv.broadcast(ctx.Code, "game:hint", map[string]any{"eliminatedId": id})
+        srv.emitVoting(sess)
+        srv.overlay.publish(ctx.Code, overlayEvent{Name: "hint", Data: map[string]any{"eliminatedId": id}})
+        return req.ack(map[string]any{"eliminatedId": id})
+    })
+
+    // game:hideSubmission (host) - take an offensive or duplicate answer out of the vote
+    on(srv, io, "game:hideSubmission", func(s socketio.Conn, req *request, payload struct {
+        SubmissionID string `json:"submissionId" validate:"required,max=64"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if err := sess.HideSubmission(ctx.Token, payload.SubmissionID); err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Str("submission", payload.SubmissionID).Msg("game:hideSubmission")
+        if sess.GetPhase() == game.PhaseVoting {
+            srv.emitVoting(sess)
+            srv.notifyVotes(sess)
+        }
+        return req.ack(map[string]any{"ok": true})
+    })
+
+    // game:setCheats (host) - unlock the cheat panel
+    on(srv, io, "game:setCheats", func(s socketio.Conn, req *request, payload struct {
+        Enabled bool `json:"enabled"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if err := sess.SetCheats(ctx.Token, payload.Enabled); err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Bool("enabled", payload.Enabled).Msg("game:setCheats")
+        return req.ack(map[string]any{"ok": true})
+    })
+
+    // game:cheat (host) - bonus points, fake AI reveal or vote override
+    on(srv, io, "game:cheat", func(s socketio.Conn, req *request, payload struct {
+        Action string `json:"action" validate:"required,oneof=bonus|markAI|votes"`
+        Target string `json:"target" validate:"required,max=64"` // player ID for bonus, submission ID otherwise
+        Value  int    `json:"value" validate:"min=-1000,max=1000"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        entry, err := sess.Cheat(ctx.Token, payload.Action, payload.Target, payload.Value)
+        if err != nil { return req.err("bad_request", err.Error()) }
+        // audit trail; cheats are part of the show but must stay traceable
+        req.log.Warn().Str("code", ctx.Code).Str("action", entry.Action).Str("target", entry.Target).Int("value", entry.Value).Int("round", entry.RoundIndex).Msg("host cheat")
+        if entry.Action == game.CheatBonus {
+            srv.emitStateTo(ctx.Code)
+        }
+        return req.ack(map[string]any{"cheat": entry})
+    })
+
+    // game:freezeScores (host) - withhold scores from players until revealed
+    on(srv, io, "game:freezeScores", func(s socketio.Conn, req *request, payload struct {
+        Frozen bool `json:"frozen"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if err := sess.SetScoreFreeze(ctx.Token, payload.Frozen); err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Bool("frozen", payload.Frozen).Msg("game:freezeScores")
+        return req.ack(map[string]any{"ok": true})
+    })
+
+    // game:revealScores (host) - release withheld scores to players
+    on(srv, io, "game:revealScores", func(s socketio.Conn, req *request, _ struct{}) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if err := srv.revealScores(sess, ctx.Token); err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Msg("game:revealScores")
+        return req.ack(map[string]any{"ok": true})
     })
 
     // game:vote
-    io.OnEvent("/", "game:vote", func(s socketio.Conn, payload struct {
-        SubmissionID string `json:"submissionId"`
+    on(srv, io, "game:vote", func(s socketio.Conn, req *request, payload struct {
+        SubmissionID string `json:"submissionId" validate:"required,max=64"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if err := sess.Vote(ctx.Token, payload.SubmissionID); err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Str("submissionId", payload.SubmissionID).Msg("game:vote")
+        srv.notifyVotes(sess)
+        if sess.Config.Hostless && votingDone(sess) {
+            srv.hostlessStepAsync(sess, game.PhaseVoting, "voted")
+        }
+        return req.ack(map[string]any{"ok": true})
+    })
+
+    // game:audienceVote - spectators pick their favourite answer; the
+    // audience's majority adds AudienceWeight votes to it. Voting again
+    // changes the vote.
+    on(srv, io, "game:audienceVote", func(s socketio.Conn, req *request, payload struct {
+        SubmissionID string `json:"submissionId" validate:"required,max=64"`
+    }) map[string]any {
+        ctx := s.Context().(*ConnCtx)
+        sess, err := srv.RM.Get(ctx.Code)
+        if err != nil { return req.err("session_not_found", "Session not found") }
+        if ctx.Role != "spectator" { return req.err("unauthorized", "Only spectators can cast audience votes") }
+        if err := sess.AudienceVote(s.ID(), payload.SubmissionID); err != nil { return req.err("bad_request", err.Error()) }
+        req.log.Info().Str("code", ctx.Code).Str("submissionId", payload.SubmissionID).Msg("game:audienceVote")
+        srv.emitToHosts(ctx.Code, "game:audienceVotes", sess.AudienceTally())
+        return req.ack(map[string]any{"ok": true})
+    })
+
+    // net:ping - keeps the connection quality shown to the host up to date;
+    // clients report the round-trip time of their previous ping. The host's
+    // own pings are answered with everyone's quality, which catches players
+    // who silently stopped pinging.
+    on(srv, io, "net:ping", func(s socketio.Conn, req *request, payload struct {
+        RTT int `json:"rtt" validate:"min=0,max=600000"` // milliseconds
     }) map[string]any {
         ctx := s.Context().(*ConnCtx)
         sess, err := srv.RM.Get(ctx.Code)
-        if err != nil { return srv.err(s, "session_not_found", "Session not found") }
-        if err := sess.Vote(ctx.Token, payload.SubmissionID); err != nil { return srv.err(s, "bad_request", err.Error()) }
-        log.Info().Str("code", ctx.Code).Str("submissionId", payload.SubmissionID).Msg("game:vote")
-        // notify GM of vote count update
-        voteCount := len(sess.Votes())
-        io.BroadcastToRoom("/", ctx.Code, "game:votes", map[string]any{"count": voteCount})
-        return map[string]any{"ok": true}
+        if err != nil || ctx.Role != "player" {
+            srv.recordPing(s, time.Duration(payload.RTT)*time.Millisecond)
+            if err == nil && ctx.Role == "host" && ctx.Token == sess.HostToken {
+                return req.ack(map[string]any{"connectivity": srv.Connectivity(sess)})
+            }
+            return req.ack(map[string]any{})
+        }
+        id := sess.GetPlayerIDByToken(ctx.Token)
+        before := srv.Connectivity(sess)[id].Quality
+        srv.recordPing(s, time.Duration(payload.RTT)*time.Millisecond)
+        if srv.Connectivity(sess)[id].Quality != before {
+            srv.notifyConnectivity(sess)
+        }
+        return req.ack(map[string]any{})
     })
 
     io.OnError("/", func(s socketio.Conn, e error) {
         log.Error().Str("sid", s.ID()).Err(e).Msg("socket error")
     })
     io.OnDisconnect("/", func(s socketio.Conn, reason string) {
-        if ctx, ok := s.Context().(*ConnCtx); ok {
-            if ctx.Code != "" {
-                srv.removeMember(ctx.Code, s)
-            }
-        }
+        defer func() {
+            if r := recover(); r != nil { logPanic(log.With().Str("sid", s.ID()).Logger(), r) }
+        }()
+        srv.dropConn(s)
         log.Info().Str("sid", s.ID()).Str("reason", reason).Msg("socket disconnected")
         _ = reason
     })
@@ -278,8 +909,8 @@ func (srv *Server) Mount(r *gin.Engine) *socketio.Server {
     go io.Serve()
 
     // Mount to router
-    r.GET("/socket.io/*any", gin.WrapH(io))
-    r.POST("/socket.io/*any", gin.WrapH(io))
+    r.GET("/socket.io/*any", srv.admit, gin.WrapH(io))
+    r.POST("/socket.io/*any", srv.admit, gin.WrapH(io))
 
     // Basic CORS preflight for Socket.IO POST
     r.OPTIONS("/socket.io/*any", func(c *gin.Context) {
@@ -292,7 +923,172 @@ func (srv *Server) Mount(r *gin.Engine) *socketio.Server {
     return io
 }
 
+// setPrompt starts the next round, either with prompt or with a queued
+// prompt, and notifies everyone.
+func (srv *Server) setPrompt(sess *game.SessionCtx, token, prompt string, translations map[string]string, queuedID string) error {
+    aiReady := false
+    if queuedID != "" {
+        q, err := sess.StartQueuedPrompt(token, queuedID)
+        if err != nil { return err }
+        aiReady = q.AIReady
+        if aiReady {
+            srv.notifyAIAnswer(sess, q.AIAnswer, q.AIMeta)
+        }
+        srv.emitQueueTo(sess)
+    } else if err := sess.SetPromptTranslated(token, prompt, translations); err != nil {
+        return err
+    }
+    srv.roundStarted(sess, aiReady)
+    return nil
+}
+
+// roundStarted notifies everyone of a round that just started and kicks off
+// its AI answer, unless it is ready already.
+func (srv *Server) roundStarted(sess *game.SessionCtx, aiReady bool) {
+    // moving to Answering -> notify players
+    srv.emitStateTo(sess.Code)
+    srv.publishPhase(sess)
+    srv.scheduleCues(sess)
+    srv.timers.Sync(sess)
+    srv.driveHostless(sess)
+    srv.translatePrompt(sess)
+    // lazy sessions generate right before voting; queued prompts may
+    // still need an answer if generation didn't finish in time
+    if !aiReady && aiTrigger(sess) != game.AITriggerVoting {
+        srv.generateForCurrentRound(sess)
+    }
+}
+
+// advanceFrom moves the session on from phase in the given round. It holds
+// the step lock, so a phase timer firing meanwhile can't step a second time,
+// and returns game.ErrStaleStep if the session has left that phase already.
+func (srv *Server) advanceFrom(sess *game.SessionCtx, token string, phase game.Phase, round int, lg zerolog.Logger) error {
+    mu := srv.stepLock(sess.Code)
+    mu.Lock()
+    defer mu.Unlock()
+    return srv.advanceLocked(sess, token, phase, round, lg)
+}
+
+// advanceLocked is advanceFrom for callers already holding the step lock. It
+// takes care of everything hanging off a transition: exports, profiles and
+// notifying everyone.
+func (srv *Server) advanceLocked(sess *game.SessionCtx, token string, phase game.Phase, round int, lg zerolog.Logger) error {
+    code := sess.Code
+    if st := sess.PublicState(); st.Phase != phase || st.RoundIndex != round { return game.ErrStaleStep }
+    previousPhase := phase
+    if previousPhase == game.PhaseAnswering && token == sess.HostToken && aiTrigger(sess) == game.AITriggerVoting && srv.generateBeforeVoting(sess) {
+        // voting opens once the AI answer is in, see generateBeforeVoting
+        return nil
+    }
+    if err := sess.AdvanceFrom(token, phase, round); err != nil { return err }
+    currentPhase := sess.GetPhase()
+    lg.Info().Str("code", code).Str("from", string(previousPhase)).Str("to", string(currentPhase)).Msg("phase transition")
+
+    // Export game data if a round completed or the game ended
+    if (currentPhase == game.PhaseScoreboard || currentPhase == game.PhaseEnd) && currentPhase != previousPhase && srv.recording(sess) {
+        if file, exportErr := game.ExportSession(sess, srv.config().ExportDir, srv.exportOptions(sess)); exportErr != nil {
+            lg.Error().Err(exportErr).Str("code", code).Msg("failed to export game data")
+        } else {
+            lg.Info().Str("code", code).Str("file", file).Msg("exported game data")
+        }
+    }
+    if currentPhase != previousPhase && srv.recording(sess) {
+        switch currentPhase {
+        case game.PhaseScoreboard:
+            srv.collectRound(sess)
+            if srv.webhook != nil {
+                srv.webhook.RoundCompleted(sess, srv.exportOptions(sess))
+            }
+        case game.PhaseEnd:
+            srv.collectGame(sess)
+            if srv.webhook != nil {
+                srv.webhook.GameEnded(sess, srv.exportOptions(sess))
+            }
+        }
+    }
+    if srv.profiles != nil && !sess.Config.Rehearsal {
+        var profileErr error
+        switch currentPhase {
+        case game.PhaseScoreboard:
+            profileErr = srv.profiles.RecordRound(sess)
+        case game.PhaseEnd:
+            profileErr = srv.profiles.RecordGame(sess)
+        }
+        if profileErr != nil {
+            lg.Error().Err(profileErr).Str("code", code).Msg("failed to update profiles")
+        }
+    }
+    if currentPhase == game.PhaseScoreboard && currentPhase != previousPhase {
+        if err := srv.recordPromptStats(sess); err != nil {
+            lg.Error().Err(err).Str("code", code).Msg("failed to update prompt stats")
+        }
+    }
+    // Emit state update
+    srv.emitStateTo(code)
+    srv.publishPhase(sess)
+    srv.scheduleCues(sess)
+    srv.timers.Sync(sess)
+    srv.driveHostless(sess)
+    withheld := sess.ScoresWithheld()
+    if currentPhase == game.PhaseScoreboard && previousPhase != game.PhaseScoreboard && !withheld {
+        srv.publishReveal(sess)
+    }
+    // If now in Voting, emit submissions in reading order
+    if currentPhase == game.PhaseVoting {
+        srv.emitVoting(sess)
+    }
+    // If now in Scoreboard, emit results with submissions and authors;
+    // with frozen scores only the host's stage view gets them for now
+    if withheld {
+        srv.emitToHosts(code, "game:results", resultsPayload(sess))
+    } else {
+        srv.emitResults(sess)
+    }
+    // Final screen gets the whole game narrative at once
+    if currentPhase == game.PhaseEnd && previousPhase != game.PhaseEnd {
+        srv.broadcast(code, "game:summary", sess.Summary())
+        srv.broadcast(code, "game:awards", map[string]any{"awards": game.ComputeAwards(sess)})
+    }
+    return nil
+}
+
+// notifySubmissions sends the answer count (only human submissions) and
+// who has answered yet.
+func (srv *Server) notifySubmissions(sess *game.SessionCtx) {
+    cnt := sess.HumanSubmissionCount()
+    status := sess.PlayerSubmissionStatus()
+    srv.broadcast(sess.Code, "game:submissions", map[string]any{"count": cnt, "playerStatus": status})
+}
+
+// notifyVotes sends the vote count to the GM and overlays.
+func (srv *Server) notifyVotes(sess *game.SessionCtx) {
+    voteCount := len(sess.Votes())
+    srv.broadcast(sess.Code, "game:votes", map[string]any{"count": voteCount})
+    srv.overlay.publish(sess.Code, overlayEvent{Name: "votes", Data: map[string]any{"count": voteCount}})
+}
+
+// extendTimer gives everyone more time in the current phase and moves the
+// cues and the phase timer along with the new deadline.
+func (srv *Server) extendTimer(sess *game.SessionCtx, token string, d time.Duration) error {
+    if _, err := sess.ExtendTimer(token, d); err != nil { return err }
+    srv.emitStateTo(sess.Code)
+    srv.scheduleCues(sess)
+    srv.timers.Sync(sess)
+    return nil
+}
+
+// revealScores releases withheld scores to players and overlays.
+func (srv *Server) revealScores(sess *game.SessionCtx, token string) error {
+    if err := sess.RevealScores(token); err != nil { return err }
+    srv.emitStateTo(sess.Code)
+    srv.emitResults(sess)
+    srv.publishReveal(sess)
+    return nil
+}
+
 func (srv *Server) addMember(code string, c socketio.Conn) {
+    srv.memberMu.Lock()
+    defer srv.memberMu.Unlock()
     if srv.members[code] == nil {
         srv.members[code] = make(map[string]socketio.Conn)
     }
@@ -300,44 +1096,279 @@ func (srv *Server) addMember(code string, c socketio.Conn) {
 }
 
 func (srv *Server) removeMember(code string, c socketio.Conn) {
+    srv.memberMu.Lock()
+    defer srv.memberMu.Unlock()
     if m := srv.members[code]; m != nil {
         delete(m, c.ID())
     }
 }
 
+// membersOf returns a snapshot of the sockets joined to a session, to emit
+// to without holding memberMu.
+func (srv *Server) membersOf(code string) map[string]socketio.Conn {
+    srv.memberMu.Lock()
+    defer srv.memberMu.Unlock()
+    out := make(map[string]socketio.Conn, len(srv.members[code]))
+    for sid, c := range srv.members[code] {
+        out[sid] = c
+    }
+    return out
+}
+
 func (srv *Server) emitStateTo(code string) {
     sess, err := srv.RM.Get(code)
     if err != nil {
         return
     }
-    for _, c := range srv.members[code] {
+    srv.notifySignage()
+    spectators := srv.spectatorCount(code)
+    for _, c := range srv.membersOf(code) {
+        ctx, _ := c.Context().(*ConnCtx)
+        c.Emit("game:state", srv.statePayload(sess, ctx, spectators))
+        if sess.GetPhase() == game.PhasePromptCollection {
+            c.Emit("game:promptCandidates", promptCandidatesFor(sess, ctx))
+        }
+    }
+}
+
+// statePayload is the game:state a member of the session gets, depending on
+// their role.
+func (srv *Server) statePayload(sess *game.SessionCtx, ctx *ConnCtx, spectators int) map[string]any {
+    you := map[string]any{"role": ctx.Role}
+    if ctx.Role == "player" {
+        if id := sess.GetPlayerIDByToken(ctx.Token); id != "" {
+            you["playerId"] = id
+        }
+    }
+    payload := map[string]any{
+        "phase":       string(sess.GetPhase()),
+        "players":     sess.Players(),
+        "round":       sess.PlayerRound(),
+        "you":         you,
+        "sessionCode": sess.Code,
+        "scores":      sess.PlayerScores(),
+        "aiScore":     sess.PlayerAIScore(),
+        "metaScore":   sess.PlayerMetaScore(),
+        "spectators":  spectators,
+        "recording":   srv.recording(sess),
+        "anonymized":  srv.exportOptions(sess).Anonymize,
+        "deadline":    deadlineMillis(sess),
+        "rehearsal":   sess.Config.Rehearsal,
+        "hostless":    sess.Config.Hostless,
+        "allowSelfVote": sess.Config.AllowSelfVote,
+        "maxAnswerLength": sess.Config.MaxAnswerLength,
+        "serverTime":  time.Now().UnixMilli(),
+    }
+    if ctx.Role == "host" {
+        payload["round"] = currentRoundPtr(sess)
+        payload["scores"] = sess.ScoresArray()
+        payload["aiScore"] = sess.AIScore()
+        payload["cheats"] = sess.CheatsEnabled()
+        payload["seed"] = sess.ShuffleSeed()
+        payload["metaScore"] = sess.MetaScore()
+        payload["scoresWithheld"] = sess.ScoresWithheld()
+        payload["pacing"] = sess.Pacing()
+        payload["connectivity"] = srv.Connectivity(sess)
+    }
+    if sess.Config.Hostless {
+        payload["ready"] = sess.ReadyPlayers()
+    }
+    return payload
+}
+
+// emitPromptCandidates sends everyone the prompts suggested so far; players
+// also learn which one is theirs and which one they voted for.
+func (srv *Server) emitPromptCandidates(sess *game.SessionCtx) {
+    for _, c := range srv.membersOf(sess.Code) {
+        ctx, _ := c.Context().(*ConnCtx)
+        if ctx == nil { continue }
+        c.Emit("game:promptCandidates", promptCandidatesFor(sess, ctx))
+    }
+}
+
+func promptCandidatesFor(sess *game.SessionCtx, ctx *ConnCtx) map[string]any {
+    payload := map[string]any{"candidates": sess.PromptCandidates()}
+    if ctx.Role == "player" {
+        payload["own"], payload["vote"] = sess.PromptChoice(ctx.Token)
+    }
+    return payload
+}
+
+// recording reports whether the session's answers end up in exports or the
+// live collector stream.
+func (srv *Server) recording(sess *game.SessionCtx) bool {
+    return sess.Config.Recording(srv.config().ExportEnabled)
+}
+
+// exportOptions decides how much of the session's results leave the server.
+func (srv *Server) exportOptions(sess *game.SessionCtx) game.ExportOptions {
+    opts := game.ExportOptions{
+        Anonymize: sess.Config.Anonymize || srv.config().ExportAnonymize,
+        Sensitive: srv.config().ExportRedact,
+    }
+    if tz := srv.config().ExportTimeZone; tz != "" {
+        opts.Location, _ = time.LoadLocation(tz) // checked at startup
+    }
+    return opts
+}
+
+// Lobby is the session's pre-join info including the consent notice.
+func (srv *Server) Lobby(sess *game.SessionCtx) game.LobbyInfo {
+    info := sess.Lobby()
+    info.Recording = srv.recording(sess)
+    info.Anonymized = info.Recording && srv.exportOptions(sess).Anonymize
+    return info
+}
+
+func (srv *Server) spectatorCount(code string) int {
+    n := 0
+    for _, c := range srv.membersOf(code) {
+        if ctx, _ := c.Context().(*ConnCtx); ctx != nil && ctx.Role == "spectator" {
+            n++
+        }
+    }
+    return n
+}
+
+func (srv *Server) emitToHosts(code string, event string, payload any) {
+    for _, c := range srv.membersOf(code) {
+        if ctx, _ := c.Context().(*ConnCtx); ctx != nil && ctx.Role == "host" {
+            c.Emit(event, payload)
+        }
+    }
+}
+
+func votingPayload(sess *game.SessionCtx, subs []*game.Submission) map[string]any {
+    list := make([]map[string]any, 0, len(subs))
+    for _, sub := range subs {
+        entry := map[string]any{"id": sub.ID, "text": sub.Text, "translations": sub.Translations}
+        // lets players spot the option their own merged answer went into
+        if members := sess.ClusterMembers(sub.ID); len(members) > 0 {
+            ids := make([]string, 0, len(members))
+            for _, m := range members {
+                ids = append(ids, m.ID)
+            }
+            entry["mergedIds"] = ids
+        }
+        list = append(list, entry)
+    }
+    return map[string]any{"submissions": list}
+}
+
+// emitVoting sends the answers to vote on. When voting is split into groups
+// players only get their group's answers; hosts and spectators get all of
+// them along with the groups.
+func (srv *Server) emitVoting(sess *game.SessionCtx) {
+    full := votingPayload(sess, sess.ListVotingSubmissions())
+    r := currentRoundPtr(sess)
+    if r == nil || len(r.Groups) == 0 {
+        srv.broadcast(sess.Code, "game:voting", full)
+        return
+    }
+    full["groups"] = r.Groups
+    for _, c := range srv.membersOf(sess.Code) {
         ctx, _ := c.Context().(*ConnCtx)
-        you := map[string]any{"role": ctx.Role}
-        if ctx.Role == "player" {
-            if id := sess.GetPlayerIDByToken(ctx.Token); id != "" {
-                you["playerId"] = id
+        if ctx == nil || ctx.Role != "player" {
+            c.Emit("game:voting", full)
+            continue
+        }
+        id := sess.GetPlayerIDByToken(ctx.Token)
+        payload := votingPayload(sess, sess.ListVotingSubmissionsFor(id))
+        if group, count := sess.VotingGroup(id); group >= 0 {
+            payload["group"] = group + 1
+            payload["groupCount"] = count
+        }
+        c.Emit("game:voting", payload)
+    }
+}
+
+// resultsPayload lists the current round's submissions with their authors
+// resolved to names, so clients don't need their own ID -> name map.
+func resultsPayload(sess *game.SessionCtx) map[string]any {
+    aiID := sess.RevealedAISubmission()
+    subs := sess.ListVotingSubmissions()
+    // a host cheat may reveal a player's answer as the AI's; swap the
+    // authors so the real AI answer doesn't give it away
+    fakedAuthor := ""
+    for _, sub := range subs {
+        if sub.ID == aiID && sub.PlayerID != "AI" { fakedAuthor = sub.PlayerID }
+    }
+    list := make([]map[string]any, 0, len(subs))
+    for _, sub := range subs {
+        author := sub.PlayerID
+        if sub.ID == aiID {
+            author = "AI"
+        } else if sub.PlayerID == "AI" && fakedAuthor != "" {
+            author = fakedAuthor
+        }
+        entry := map[string]any{
+            "id": sub.ID,
+            "text": sub.Text,
+            "authorId": author,
+            "authorName": sess.PlayerName(author),
+        }
+        // near-duplicates merged into this answer share its points
+        if members := sess.ClusterMembers(sub.ID); len(members) > 0 {
+            authors := []map[string]any{{"id": author, "name": sess.PlayerName(author)}}
+            for _, m := range members {
+                authors = append(authors, map[string]any{"id": m.PlayerID, "name": sess.PlayerName(m.PlayerID)})
             }
+            entry["authors"] = authors
         }
-        payload := map[string]any{
-            "phase":       string(sess.Phase),
-            "players":     sess.Players(),
-            "round":       currentRoundPtr(sess),
-            "you":         you,
-            "sessionCode": code,
-            "scores":      sess.ScoresArray(),
+        list = append(list, entry)
+    }
+    out := map[string]any{
+        "aiSubmissionId": aiID,
+        "votes": sess.Votes(),
+        "voteCounts": sess.VoteCounts(),
+        "scores": sess.ScoresArray(),
+        "aiScore": sess.AIScore(),
+        "submissions": list,
+        "awards": sess.RoundAwards(),
+    }
+    if t := sess.AudienceTally(); t != nil { out["audience"] = t }
+    return out
+}
+
+// emitResults sends the round results to everyone in the session. With
+// HideVoteCounts players only get their own vote and whether it found the AI.
+func (srv *Server) emitResults(sess *game.SessionCtx) {
+    full := resultsPayload(sess)
+    if !sess.Config.HideVoteCounts {
+        srv.broadcast(sess.Code, "game:results", full)
+        return
+    }
+    for _, c := range srv.membersOf(sess.Code) {
+        ctx, _ := c.Context().(*ConnCtx)
+        if ctx == nil || ctx.Role != "player" {
+            c.Emit("game:results", full)
+            continue
         }
-        c.Emit("game:state", payload)
+        c.Emit("game:results", personalResults(full, sess.GetPlayerIDByToken(ctx.Token)))
     }
 }
 
-func (srv *Server) err(s socketio.Conn, code, message string) map[string]any {
-    s.Emit("error", map[string]any{"code": code, "message": message})
-    return map[string]any{"error": message}
+// personalResults strips everyone else's votes and the vote counts from a
+// results payload, leaving the player's own vote and whether it found the AI.
+func personalResults(full map[string]any, playerID string) map[string]any {
+    out := make(map[string]any, len(full)+2)
+    for k, v := range full {
+        out[k] = v
+    }
+    aiID, _ := full["aiSubmissionId"].(string)
+    votes, _ := full["votes"].([]*game.Vote)
+    own := []*game.Vote{}
+    for _, v := range votes {
+        if playerID != "" && v.VoterID == playerID { own = append(own, v) }
+    }
+    delete(out, "voteCounts")
+    delete(out, "audience")
+    out["votes"] = own
+    out["foundAI"] = len(own) > 0 && aiID != "" && own[0].TargetSubmissionID == aiID
+    out["voteCountsHidden"] = true
+    return out
 }
 
 func currentRoundPtr(s *game.SessionCtx) *game.Round {
-    if s.RoundIx == 0 || len(s.Rounds) < s.RoundIx {
-        return nil
-    }
-    return s.Rounds[s.RoundIx-1]
+    return s.CurrentRound()
 }
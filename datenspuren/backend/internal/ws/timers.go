@@ -0,0 +1,90 @@
+package ws
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/rs/zerolog/log"
+)
+
+// emitTimer broadcasts the time left in the current phase as "game:timer",
+// so clients can show a countdown that stays in sync with the server.
+func (srv *Server) emitTimer(sess *game.SessionCtx, phase game.Phase, remaining int, deadline time.Time) {
+	round := 0
+	if r := currentRoundPtr(sess); r != nil {
+		round = r.Index
+	}
+	srv.broadcast(sess.Code, "game:timer", cuePayload(phase, round, remaining, deadline))
+}
+
+// expireTimer ends a phase whose timer ran out, so players who disconnected
+// or never answer can't stall the game.
+func (srv *Server) expireTimer(sess *game.SessionCtx, phase game.Phase) {
+	round := sess.PublicState().RoundIndex
+	if slices.Contains(srv.config().CueThresholds, 0) {
+		if deadline, ok := sess.PhaseDeadline(); ok {
+			srv.emitCue(sess.Code, cuePayload(phase, round, 0, deadline))
+		}
+	}
+	background("phaseTimer", sess.Code, func() {
+		if _, err := srv.stepFrom(sess, phase, round, "timeout"); err != nil {
+			log.Warn().Err(err).Str("code", sess.Code).Msg("failed to end phase after its timer ran out")
+		}
+	})
+}
+
+// ResumeTimers restarts the phase timers of sessions recovered from the WAL.
+func (srv *Server) ResumeTimers() {
+	for _, sess := range srv.RM.Running() {
+		srv.timers.Sync(sess)
+		srv.driveHostless(sess)
+	}
+}
+
+// stepLock returns the lock serializing the session's steps.
+func (srv *Server) stepLock(code string) *sync.Mutex {
+	mu, _ := srv.stepLocks.LoadOrStore(code, &sync.Mutex{})
+	return mu.(*sync.Mutex)
+}
+
+// stepFrom moves the session on from phase in the given round, unless
+// another trigger got there first: the phase timer, the last player answering
+// or voting, everyone being ready in a hostless session, or the demo. It
+// reports whether it moved the session.
+func (srv *Server) stepFrom(sess *game.SessionCtx, phase game.Phase, round int, reason string) (bool, error) {
+	mu := srv.stepLock(sess.Code)
+	mu.Lock()
+	defer mu.Unlock()
+	st := sess.PublicState()
+	if st.Phase != phase || st.RoundIndex != round {
+		return false, nil
+	}
+	lg := log.With().Str("code", sess.Code).Str("reason", reason).Logger()
+	var err error
+	switch phase {
+	case game.PhaseAnswering, game.PhaseVoting:
+		err = srv.advanceLocked(sess, sess.HostToken, phase, round, lg)
+	case game.PhaseScoreboard:
+		if st.RoundIndex >= st.RoundCount {
+			err = srv.advanceLocked(sess, sess.HostToken, phase, round, lg)
+			break
+		}
+		fallthrough
+	case game.PhaseLobby, game.PhasePromptSet:
+		queuedID, prompt := nextQueued(sess.PromptQueue()), ""
+		if queuedID == "" {
+			prompt = srv.drawPrompt(sess)
+		}
+		err = srv.nextRoundLocked(sess, sess.HostToken, prompt, nil, queuedID, lg)
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	lg.Info().Str("phase", string(sess.GetPhase())).Msg("session moved on")
+	srv.broadcast(sess.Code, "game:advance", map[string]any{"phase": sess.GetPhase(), "reason": reason})
+	return true, nil
+}
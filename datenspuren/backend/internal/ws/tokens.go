@@ -0,0 +1,78 @@
+package ws
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+// Clients get their host or player token from ClientToken and present it
+// on resume, on event streams and to the API; authenticate maps it back to
+// the session's own token. Without TOKEN_SECRET both are the same bare
+// token. With it clients get signed JWTs naming the session, role and
+// player, which expire and can be refreshed and revoked without the game
+// state knowing about them. Either is checked when a socket resumes, an
+// event stream opens or an API call comes in: a live socket or stream keeps
+// its role for as long as it stays connected, and only has to show a valid
+// token again after reconnecting.
+
+// ClientToken returns the token handed to a client for a session role,
+// given the session's own token for it.
+func (srv *Server) ClientToken(code, role, playerID, internal string) string {
+	if srv.tokens == nil {
+		return internal
+	}
+	return srv.tokens.Issue(code, role, playerID)
+}
+
+// authenticate checks a client's token against sess and returns its role
+// and the session's own token for it, or "" for both if it's invalid.
+func (srv *Server) authenticate(sess *game.SessionCtx, tok string) (role, internal string) {
+	if tok == "" {
+		return "", ""
+	}
+	if srv.tokens == nil {
+		switch {
+		case subtle.ConstantTimeCompare([]byte(tok), []byte(sess.HostToken)) == 1:
+			return "host", tok
+		case sess.GetPlayerIDByToken(tok) != "":
+			return "player", tok
+		}
+		return "", ""
+	}
+	c, err := srv.tokens.Verify(tok)
+	if err != nil || c.Session != sess.Code {
+		return "", ""
+	}
+	switch c.Role {
+	case "host":
+		return "host", sess.HostToken
+	case "player":
+		if internal := sess.PlayerToken(c.PlayerID); internal != "" {
+			return "player", internal
+		}
+	}
+	return "", ""
+}
+
+// refreshToken swaps a connection's client token for a fresh one and
+// revokes the old one.
+func (srv *Server) refreshToken(ctx *ConnCtx, tok string) (string, error) {
+	sess, err := srv.RM.Get(ctx.Code)
+	if err != nil {
+		return "", err
+	}
+	if role, internal := srv.authenticate(sess, tok); role != ctx.Role || internal != ctx.Token {
+		return "", errors.New("token doesn't belong to this connection")
+	}
+	if srv.tokens == nil {
+		return tok, nil
+	}
+	c, err := srv.tokens.Verify(tok)
+	if err != nil {
+		return "", err
+	}
+	srv.tokens.Revoke(c)
+	return srv.tokens.Issue(c.Session, c.Role, c.PlayerID), nil
+}
@@ -0,0 +1,68 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+func TestSignedTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	srv := New(rm, config.Config{TokenSecret: "secret", TokenTTL: time.Hour})
+	srv.Mount(gin.New())
+	conn := func() *streamConn { return newStreamConn(httptest.NewRequest("GET", "/", nil), "") }
+	emit := func(s *streamConn, event string, payload map[string]any) map[string]any {
+		raw, _ := json.Marshal(payload)
+		return srv.actions[event](s, raw)
+	}
+
+	host := conn()
+	ack := emit(host, "game:create", map[string]any{"config": game.SessionConfig{Provider: "manual", RoundCount: 1}})
+	code, _ := ack["sessionCode"].(string)
+	hostToken, _ := ack["hostToken"].(string)
+	sess, _ := rm.Get(code)
+	if hostToken == sess.HostToken {
+		t.Fatal("expected the host to get a signed token instead of the session's own")
+	}
+	ack = emit(conn(), "game:join", map[string]any{"sessionCode": code, "name": "Alice"})
+	aliceToken, _ := ack["playerToken"].(string)
+	if role, internal := srv.authenticate(sess, aliceToken); role != "player" || sess.GetPlayerIDByToken(internal) != ack["playerId"] {
+		t.Fatalf("expected Alice's token to identify her, got %q", role)
+	}
+
+	if ack := emit(conn(), "game:resume", map[string]any{"sessionCode": code, "role": "host", "token": aliceToken}); ack["error"] == nil {
+		t.Fatal("expected a player token not to resume the host")
+	}
+	if ack := emit(conn(), "game:resume", map[string]any{"sessionCode": code, "role": "host", "token": sess.HostToken}); ack["error"] == nil {
+		t.Fatal("expected the bare session token to be rejected once tokens are signed")
+	}
+	other := New(rm, config.Config{TokenSecret: "other", TokenTTL: time.Hour})
+	if role, _ := other.authenticate(sess, hostToken); role != "" {
+		t.Fatal("expected a token signed with another secret to be rejected")
+	}
+
+	alice := conn()
+	if ack := emit(alice, "game:resume", map[string]any{"sessionCode": code, "role": "player", "token": aliceToken}); ack["error"] != nil {
+		t.Fatalf("Alice should be able to resume: %v", ack)
+	}
+	ack = emit(alice, "game:refreshToken", map[string]any{"token": aliceToken})
+	fresh, _ := ack["token"].(string)
+	if fresh == "" || fresh == aliceToken {
+		t.Fatalf("expected a fresh token, got %v", ack)
+	}
+	if ack := emit(conn(), "game:resume", map[string]any{"sessionCode": code, "role": "player", "token": aliceToken}); ack["error"] == nil {
+		t.Fatal("expected the refreshed token to be revoked")
+	}
+	if ack := emit(conn(), "game:resume", map[string]any{"sessionCode": code, "role": "player", "token": fresh}); ack["error"] != nil {
+		t.Fatalf("Alice should be able to resume with the fresh token: %v", ack)
+	}
+	if ack := emit(host, "game:refreshToken", map[string]any{"token": fresh}); ack["error"] == nil {
+		t.Fatal("expected the host not to be able to refresh Alice's token")
+	}
+}
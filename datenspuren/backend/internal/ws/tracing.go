@@ -0,0 +1,41 @@
+package ws
+
+import (
+	"context"
+
+	socketio "github.com/googollee/go-socket.io"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/kiliankoe/gptdash/internal/ws")
+
+// traceEvent starts the span of a socket event. Spans are only recorded
+// once tracing.Setup ran.
+func traceEvent(s socketio.Conn, req *request, event string) trace.Span {
+	_, span := tracer.Start(context.Background(), event,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("socket.id", s.ID()), attribute.String("request.id", req.ID)))
+	return span
+}
+
+// endEvent finishes an event's span with the session it ended up in, which
+// a join only just set, and the error code of the ack.
+func (srv *Server) endEvent(span trace.Span, s socketio.Conn, ack map[string]any) {
+	defer span.End()
+	if !span.IsRecording() {
+		return
+	}
+	if ctx, ok := s.Context().(*ConnCtx); ok && ctx.Code != "" {
+		span.SetAttributes(attribute.String("session.code", ctx.Code), attribute.String("session.role", ctx.Role))
+		if sess, err := srv.RM.Get(ctx.Code); err == nil {
+			phase, round := sess.Progress()
+			span.SetAttributes(attribute.String("game.phase", string(phase)), attribute.Int("game.round_index", round))
+		}
+	}
+	if code, _ := ack["code"].(string); code != "" {
+		span.SetStatus(codes.Error, code)
+	}
+}
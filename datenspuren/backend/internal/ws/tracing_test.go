@@ -0,0 +1,44 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTraceEvents(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, _, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1})
+	srv := New(rm, config.Config{})
+	srv.Mount(gin.New())
+	s := newStreamConn(httptest.NewRequest("GET", "/", nil), code)
+
+	srv.actions["game:join"](s, json.RawMessage(`{"sessionCode": "`+code+`", "name": "Alice"}`))
+	srv.actions["game:vote"](s, json.RawMessage(`{"submissionId": "nope"}`))
+
+	spans := rec.Ended()
+	if len(spans) != 2 || spans[0].Name() != "game:join" || spans[1].Name() != "game:vote" {
+		t.Fatalf("expected a span per event, got %v", spans)
+	}
+	attrs := map[string]string{}
+	for _, kv := range spans[0].Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs["session.code"] != code || attrs["session.role"] != "player" || attrs["game.round_index"] == "" {
+		t.Fatalf("expected the join to be traced with the session it joined, got %v", attrs)
+	}
+	if spans[1].Status().Code != codes.Error {
+		t.Fatalf("expected the failed vote to be marked as an error, got %v", spans[1].Status())
+	}
+}
@@ -0,0 +1,61 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/rs/zerolog/log"
+)
+
+type Translator interface {
+	Translate(ctx context.Context, text string, targetLang string) (string, error)
+}
+
+func (srv *Server) SetTranslator(t Translator) { srv.translator = t }
+
+// translatePrompt translates the current round's prompt into the session's
+// secondary language in the background and re-broadcasts state when done.
+func (srv *Server) translatePrompt(sess *game.SessionCtx) {
+	lang := sess.Config.SecondaryLanguage
+	r := currentRoundPtr(sess)
+	if srv.translator == nil || lang == "" || r == nil {
+		return
+	}
+	if _, ok := r.Translations[lang]; ok {
+		return // host provided it
+	}
+	roundID, prompt := r.ID, r.Prompt
+	background("translatePrompt", sess.Code, func() {
+		text, err := srv.translate(prompt, lang)
+		if err != nil {
+			log.Warn().Err(err).Str("code", sess.Code).Msg("prompt translation failed")
+			return
+		}
+		sess.SetRoundTranslation(roundID, lang, text)
+		srv.emitStateTo(sess.Code)
+	})
+}
+
+// translateSubmission translates an answer in the background so the
+// translation is ready by the time voting starts.
+func (srv *Server) translateSubmission(sess *game.SessionCtx, submissionID, text string) {
+	lang := sess.Config.SecondaryLanguage
+	if srv.translator == nil || lang == "" {
+		return
+	}
+	background("translateAnswer", sess.Code, func() {
+		translated, err := srv.translate(text, lang)
+		if err != nil {
+			log.Warn().Err(err).Str("code", sess.Code).Str("submissionId", submissionID).Msg("answer translation failed")
+			return
+		}
+		sess.SetSubmissionTranslation(submissionID, text, lang, translated)
+	})
+}
+
+func (srv *Server) translate(text, lang string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	return srv.translator.Translate(ctx, text, lang)
+}
@@ -0,0 +1,57 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/i18n"
+	"github.com/kiliankoe/gptdash/internal/ratelimit"
+	"github.com/kiliankoe/gptdash/internal/tts"
+	"github.com/rs/zerolog/log"
+)
+
+func (srv *Server) SetSpeaker(s tts.Speaker) { srv.speaker = s }
+
+// TTSHandler reads an answer aloud for the host view. Only answers already
+// shown for voting can be fetched, so nobody listens in on the answers
+// still coming in.
+func (srv *Server) TTSHandler() gin.HandlerFunc {
+	limit := ratelimit.New(30, time.Minute)
+	return func(c *gin.Context) {
+		if srv.speaker == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "tts_disabled"})
+			return
+		}
+		if !limit.Allow(c.ClientIP()) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "rate_limited"})
+			return
+		}
+		sess, err := srv.RM.Lookup(c.Param("code"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session_not_found"})
+			return
+		}
+		text := ""
+		for _, sub := range sess.ListVotingSubmissions() {
+			if sub.ID == c.Param("submissionId") {
+				text = sub.Text
+			}
+		}
+		if text == "" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "submission_not_found"})
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+		audio, err := srv.speaker.Speak(ctx, text, i18n.Normalize(sess.Config.Language))
+		if err != nil {
+			log.Error().Err(err).Str("code", sess.Code).Msg("text-to-speech failed")
+			c.JSON(http.StatusBadGateway, gin.H{"error": "tts_failed"})
+			return
+		}
+		c.Header("Cache-Control", "private, max-age=3600")
+		c.Data(http.StatusOK, audio.ContentType, audio.Data)
+	}
+}
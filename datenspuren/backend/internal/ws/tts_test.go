@@ -0,0 +1,51 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+	"github.com/kiliankoe/gptdash/internal/tts"
+)
+
+type echoSpeaker struct{}
+
+func (echoSpeaker) Speak(_ context.Context, text, lang string) (tts.Audio, error) {
+	return tts.Audio{Data: []byte(lang + ":" + text), ContentType: "audio/wav"}, nil
+}
+
+func TestTTSHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	srv := New(rm, config.Config{})
+	r := gin.New()
+	r.GET("/api/session/:code/tts/:submissionId", srv.TTSHandler())
+	code, hostToken, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1, Language: "en"})
+	sess, _ := rm.Get(code)
+	_, aliceToken, _ := sess.Join("Alice")
+	sess.SetPrompt(hostToken, "Q")
+	id, _ := sess.Submit(aliceToken, "Alice's answer")
+
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/session/"+code+"/tts/"+id, nil))
+		return w
+	}
+	if w := get(); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 without TTS, got %d", w.Code)
+	}
+	srv.SetSpeaker(echoSpeaker{})
+	if w := get(); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 while answers are still coming in, got %d", w.Code)
+	}
+	sess.SetAIAnswer(hostToken, "AI answer")
+	sess.Advance(hostToken) // To Voting
+	w := get()
+	if w.Code != http.StatusOK || w.Body.String() != "en:Alice's answer" || w.Header().Get("Content-Type") != "audio/wav" {
+		t.Fatalf("expected the answer spoken in English, got %d %q", w.Code, w.Body.String())
+	}
+}
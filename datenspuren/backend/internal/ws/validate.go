@@ -0,0 +1,149 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	socketio "github.com/googollee/go-socket.io"
+)
+
+// limitedEvents are the events players send, which a single connection
+// may only send at Config.EventRate.
+var limitedEvents = map[string]bool{
+	"game:join":          true,
+	"game:submit":        true,
+	"game:vote":          true,
+	"game:audienceVote":  true,
+	"game:suggestPrompt": true,
+}
+
+// on registers an event handler behind the validation layer: the payload is
+// decoded into T and checked against its `validate` tags before h runs, so
+// handlers only ever see well-formed input. Unknown fields are ignored.
+// Panics in h are turned into an internal_error ack instead of a silently
+// dropped event.
+//
+// Supported rules, comma separated: required, min=N, max=N (characters for
+// strings, entries for slices and maps, value for ints) and oneof=a|b.
+//
+// The handler is also kept in srv.actions for event streams, see ActionHandler.
+func on[T any](srv *Server, io *socketio.Server, event string, h func(s socketio.Conn, req *request, payload T) map[string]any) {
+	handler := func(s socketio.Conn, raw json.RawMessage) (ack map[string]any) {
+		req := srv.begin(s, event)
+		span := traceEvent(s, req, event)
+		defer func() { srv.endEvent(span, s, ack) }()
+		defer func() {
+			if r := recover(); r != nil {
+				logPanic(req.log, r)
+				ack = req.err("internal_error", "Internal error")
+			}
+		}()
+		if limitedEvents[event] && srv.eventLimit != nil && !srv.eventLimit.Allow(s.ID()) {
+			return req.err("rate_limited", "Too many requests, please slow down")
+		}
+		if joinEvents[event] && srv.joins != nil && !srv.joins.allow(srv.remoteIP(s)) {
+			return req.err("rate_limited", "Too many join attempts, please try again later")
+		}
+		var payload T
+		if len(raw) > 0 && string(raw) != "null" {
+			if err := json.NewDecoder(bytes.NewReader(raw)).Decode(&payload); err != nil {
+				field, message := describeDecodeError(err)
+				return req.invalid(field, message)
+			}
+		}
+		if err := validatePayload(payload); err != nil {
+			return req.invalid(err.Field, err.Message)
+		}
+		if _, ok := s.Context().(*ConnCtx); !ok {
+			s.SetContext(&ConnCtx{})
+		}
+		return h(s, req, payload)
+	}
+	srv.actions[event] = handler
+	io.OnEvent("/", event, handler)
+}
+
+type fieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *fieldError) Error() string { return e.Field + ": " + e.Message }
+
+func describeDecodeError(err error) (field, message string) {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return typeErr.Field, fmt.Sprintf("must be of type %s", typeErr.Type)
+	}
+	return "", "payload must be a JSON object"
+}
+
+// validatePayload checks the top-level fields of a payload struct against
+// their `validate` tags.
+func validatePayload(payload any) *fieldError {
+	v := reflect.ValueOf(payload)
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		for _, rule := range strings.Split(tag, ",") {
+			if msg := checkRule(v.Field(i), rule); msg != "" {
+				return &fieldError{Field: name, Message: msg}
+			}
+		}
+	}
+	return nil
+}
+
+func checkRule(v reflect.Value, rule string) string {
+	key, arg, _ := strings.Cut(rule, "=")
+	switch key {
+	case "required":
+		if v.Kind() == reflect.String && strings.TrimSpace(v.String()) == "" || v.IsZero() {
+			return "is required"
+		}
+	case "min", "max":
+		n, _ := strconv.Atoi(arg)
+		size, unit := 0, ""
+		switch v.Kind() {
+		case reflect.String:
+			size, unit = utf8.RuneCountInString(v.String()), " characters"
+		case reflect.Slice, reflect.Map:
+			size, unit = v.Len(), " entries"
+		case reflect.Int:
+			size = int(v.Int())
+		}
+		if key == "min" && size < n {
+			return fmt.Sprintf("must be at least %d%s", n, unit)
+		}
+		if key == "max" && size > n {
+			return fmt.Sprintf("must be at most %d%s", n, unit)
+		}
+	case "oneof":
+		if v.Kind() == reflect.String {
+			for _, opt := range strings.Split(arg, "|") {
+				if v.String() == opt {
+					return ""
+				}
+			}
+			return "must be one of " + strings.ReplaceAll(arg, "|", ", ")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	socketio "github.com/googollee/go-socket.io"
+	"github.com/kiliankoe/gptdash/internal/config"
+	"github.com/kiliankoe/gptdash/internal/game"
+)
+
+func TestValidatePayload(t *testing.T) {
+	type payload struct {
+		Name  string            `json:"name" validate:"required,max=5"`
+		Role  string            `json:"role" validate:"oneof=host|player"`
+		Order []string          `json:"order" validate:"max=2"`
+		Tr    map[string]string `json:"translations" validate:"max=1"`
+		Index int               `json:"roundIndex" validate:"min=0"`
+	}
+	cases := []struct {
+		p     payload
+		field string
+	}{
+		{payload{Name: "Alice", Role: "host"}, ""},
+		{payload{Name: "  ", Role: "host"}, "name"},
+		{payload{Name: "Alexander", Role: "host"}, "name"},
+		{payload{Name: "Älöü", Role: "player"}, ""}, // counts characters, not bytes
+		{payload{Name: "Bob", Role: "admin"}, "role"},
+		{payload{Name: "Bob", Role: "host", Order: []string{"a", "b", "c"}}, "order"},
+		{payload{Name: "Bob", Role: "host", Tr: map[string]string{"de": "x", "en": "y"}}, "translations"},
+		{payload{Name: "Bob", Role: "host", Index: -1}, "roundIndex"},
+	}
+	for _, c := range cases {
+		err := validatePayload(c.p)
+		switch {
+		case c.field == "" && err != nil:
+			t.Errorf("expected %+v to be valid, got %v", c.p, err)
+		case c.field != "" && (err == nil || err.Field != c.field):
+			t.Errorf("expected %+v to fail on %q, got %v", c.p, c.field, err)
+		}
+	}
+}
+
+func TestEventRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rm := game.NewRoomManager()
+	code, _, _ := rm.CreateSession(game.SessionConfig{Provider: "manual", RoundCount: 1})
+	srv := New(rm, config.Config{EventRate: 1, EventBurst: 2})
+	srv.Mount(gin.New())
+	join := func(s socketio.Conn, name string) map[string]any {
+		return srv.actions["game:join"](s, json.RawMessage(`{"sessionCode": "`+code+`", "name": "`+name+`"}`))
+	}
+
+	spammer := newStreamConn(httptest.NewRequest("GET", "/", nil), code)
+	for _, name := range []string{"Alice", "Bob"} {
+		if ack := join(spammer, name); ack["error"] != nil {
+			t.Fatalf("expected the burst to be allowed, got %v", ack)
+		}
+	}
+	if ack := join(spammer, "Carol"); ack["code"] != "rate_limited" {
+		t.Fatalf("expected the third join in a row to be rate limited, got %v", ack)
+	}
+	if ack := srv.actions["game:ready"](spammer, nil); ack["code"] == "rate_limited" {
+		t.Fatal("expected events other than joins, answers and votes not to be limited")
+	}
+	if ack := join(newStreamConn(httptest.NewRequest("GET", "/", nil), code), "Dave"); ack["error"] != nil {
+		t.Fatalf("expected other connections not to be limited, got %v", ack)
+	}
+}
@@ -58,13 +58,17 @@
             cp -r ${frontend}/dist/* static/dist/
           '';
 
-          ldflags = [ "-X main.version=${version}" ];
+          ldflags = [
+            "-X github.com/kiliankoe/gptdash/internal/buildinfo.Version=${version}"
+            "-X github.com/kiliankoe/gptdash/internal/buildinfo.Commit=${self.sourceInfo.rev or ""}"
+          ];
 
-          vendorHash = "sha256-hGx/bQNL6BXEGVYZTqivt0bpTai2/PdxEGdc6TKMZMA=";
+          vendorHash = "sha256-a2RLViumPRv01IB94n9JUXGvp1ah+v5v65RwsJMhg7c=";
 
           go = pkgs.go_1_24 or pkgs.go;
 
-          env.CGO_ENABLED = "0";
+          # go-sqlite3 (SESSION_DB) needs cgo; without it the driver is a stub
+          env.CGO_ENABLED = "1";
           doCheck = false;
 
           postInstall = ''
@@ -212,10 +216,10 @@
                   default = true;
                   description = "Enable exporting game results to a file.";
                 };
-                file = lib.mkOption {
+                dir = lib.mkOption {
                   type = lib.types.str;
-                  default = "/var/lib/gptdash/gptdash-results.txt";
-                  description = "Path to export game results file (should reside under stateDir).";
+                  default = "/var/lib/gptdash/results";
+                  description = "Directory for per-session game result files (should reside under stateDir).";
                 };
               };
 
@@ -236,7 +240,7 @@
 
               systemd.tmpfiles.rules = [
                 "d ${cfg.stateDir} 0750 ${cfg.user} ${cfg.group} - -"
-                "f ${cfg.export.file} 0640 ${cfg.user} ${cfg.group} - -"
+                "d ${cfg.export.dir} 0750 ${cfg.user} ${cfg.group} - -"
               ];
 
               systemd.services.gptdash =
@@ -248,7 +252,7 @@
                     OLLAMA_HOST = cfg.ollamaHost;
                     SINGLE_SESSION = lib.boolToString cfg.singleSession;
                     EXPORT_ENABLED = lib.boolToString cfg.export.enabled;
-                    EXPORT_FILE = toString cfg.export.file;
+                    EXPORT_DIR = toString cfg.export.dir;
                   };
                   optionalEnv = lib.mkMerge [
                     cfg.environment
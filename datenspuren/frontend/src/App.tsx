@@ -7,6 +7,7 @@ import { VERSION } from "./version";
 
 export default function App() {
   const setState = useGameStore((s) => s.setState);
+  const restarting = useGameStore((s) => s.restarting);
   useEffect(() => {
     const s = getSocket();
     const onState = (payload: any) => {
@@ -19,7 +20,7 @@ export default function App() {
       });
     };
     const onError = (e: any) => {
-      console.warn("Server error:", e?.code, e?.message);
+      console.warn("Server error:", e?.code, e?.message, e?.requestId);
     };
     s.on("game:state", onState);
     s.on("error", onError);
@@ -43,6 +44,12 @@ export default function App() {
           GPTdash
         </h1>
       </header>
+      {restarting && (
+        <div className="card" style={{ background: "var(--yellow)", color: "var(--bg)", padding: 16, marginBottom: 16 }}>
+          <strong>🔄 Der Server startet neu…</strong>
+          <div style={{ fontSize: "0.9em", marginTop: 4 }}>Das Spiel geht gleich an derselben Stelle weiter.</div>
+        </div>
+      )}
       <Outlet />
       <div
         style={{
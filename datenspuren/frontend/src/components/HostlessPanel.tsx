@@ -0,0 +1,63 @@
+import { useState } from "react";
+import { getSocket } from "../lib/socket";
+import { useGameStore } from "../store/useGameStore";
+
+// Between rounds of a game without a GM screen: players mark themselves
+// ready and suggest prompts; the server starts the next round once everyone
+// is ready or the pause is over.
+export default function HostlessPanel() {
+  const { players, you, ready, phase } = useGameStore((s) => ({
+    players: s.players,
+    you: s.you,
+    ready: s.ready ?? [],
+    phase: s.phase,
+  }));
+  const [suggestion, setSuggestion] = useState("");
+  const [msg, setMsg] = useState<string | null>(null);
+  const isReady = !!you?.playerId && ready.includes(you.playerId);
+
+  const onReady = () => {
+    getSocket().emit("game:ready", {}, (res: any) => {
+      if (res?.error) setMsg("Fehler: " + res.error);
+    });
+  };
+  const onSuggest = (e: React.FormEvent<HTMLFormElement>) => {
+    e.preventDefault();
+    getSocket().emit("game:suggestPrompt", { prompt: suggestion }, (res: any) => {
+      if (res?.code === "content_flagged") {
+        setMsg("Diese Frage können wir leider nicht zeigen.");
+      } else if (res?.error) {
+        setMsg("Fehler: " + res.error);
+      } else {
+        setMsg("Danke! Deine Frage kommt in eine der nächsten Runden.");
+        setSuggestion("");
+      }
+    });
+  };
+
+  return (
+    <div className="card">
+      <h3>{phase === "Lobby" ? "Spiel ohne Spielleitung" : "Nächste Runde"}</h3>
+      <p className="subtle">
+        {ready.length} von {players.length} bereit
+        {phase === "Lobby" ? " – los geht's, sobald alle (mindestens zwei) bereit sind." : " – sonst geht's gleich von selbst weiter."}
+      </p>
+      <button type="button" onClick={onReady} disabled={isReady}>
+        {isReady ? "Bereit ✓" : "Bereit"}
+      </button>
+      <form onSubmit={onSuggest} className="row" style={{ marginTop: 12 }}>
+        <input
+          style={{ flex: 1 }}
+          value={suggestion}
+          onChange={(e) => setSuggestion(e.target.value)}
+          placeholder="Frage vorschlagen (optional)"
+          maxLength={500}
+        />
+        <button type="submit" disabled={!suggestion.trim()}>
+          Vorschlagen
+        </button>
+      </form>
+      {msg && <p className="subtle">{msg}</p>}
+    </div>
+  );
+}
@@ -0,0 +1,22 @@
+// Plays the server's timer cues ("game:cue") as a short beep and vibration,
+// so every device signals at the same moment regardless of its own clock.
+let audio: AudioContext | null = null;
+
+export function playCue(remaining: number) {
+  const timeUp = remaining === 0;
+  if ("vibrate" in navigator) {
+    navigator.vibrate(timeUp ? [300, 100, 300] : 150);
+  }
+  try {
+    audio = audio || new AudioContext();
+    const osc = audio.createOscillator();
+    const gain = audio.createGain();
+    osc.frequency.value = timeUp ? 440 : 880;
+    gain.gain.value = 0.1;
+    osc.connect(gain).connect(audio.destination);
+    osc.start();
+    osc.stop(audio.currentTime + (timeUp ? 0.6 : 0.15));
+  } catch {
+    // audio may be blocked until the user interacted with the page
+  }
+}
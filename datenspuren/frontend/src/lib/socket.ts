@@ -1,20 +1,60 @@
 import io from "socket.io-client";
+import { useGameStore } from "../store/useGameStore";
 
 // Using 'any' to avoid TS type friction between client versions
 let socket: any = null;
 
 export function getSocket() {
   if (!socket) {
+    // lets multi-instance deployments route us to the instance owning the session
+    const code =
+      window.location.pathname.match(/^\/(?:lobby|host|play)\/([A-Z0-9]+)/i)?.[1] ||
+      localStorage.getItem("sessionCode") ||
+      undefined;
     socket = io(import.meta.env.VITE_API_URL || window.location.origin, {
       transports: ["websocket", "polling"],
       autoConnect: true,
+      query: code ? { code } : undefined,
     });
     socket.on("connect", () => console.log("[socket] connected", socket!.id));
     socket.on("disconnect", (reason: any) => console.log("[socket] disconnect", reason));
     socket.on("connect_error", (err: any) => console.warn("[socket] connect_error", (err as any)?.message || err));
     socket.on("reconnect_attempt", (n: number) => console.log("[socket] reconnect_attempt", n));
     socket.on("reconnect_error", (err: any) => console.warn("[socket] reconnect_error", (err as any)?.message || err));
+    // report round-trip times so the host sees how well everyone is connected;
+    // the host's pings come back with everyone's connection quality
+    let rtt = 0;
+    setInterval(() => {
+      if (!socket.connected) return;
+      const sent = performance.now();
+      socket.emit("net:ping", { rtt: Math.round(rtt) }, (res: any) => {
+        rtt = performance.now() - sent;
+        if (res?.connectivity) useGameStore.getState().setState({ connectivity: res.connectivity });
+      });
+    }, 5000);
+    socket.on("game:connectivity", (connectivity: any) => useGameStore.getState().setState({ connectivity }));
+    // the server closed the session after it sat idle; start over
+    socket.on("game:closed", () => {
+      localStorage.removeItem("sessionCode");
+      localStorage.removeItem("hostToken");
+      localStorage.removeItem("playerToken");
+      localStorage.removeItem("playerId");
+      window.location.href = "/";
+    });
+    // the server is restarting; it closes our socket, so keep knocking until
+    // it's back and resume there
+    socket.on("server:shutdown", () => useGameStore.getState().setState({ restarting: true }));
+    socket.on("disconnect", () => {
+      if (!useGameStore.getState().restarting) return;
+      const retry = () => {
+        if (socket.connected) return;
+        socket.connect();
+        setTimeout(retry, 3000);
+      };
+      setTimeout(retry, 3000);
+    });
     socket.on("connect", () => {
+      useGameStore.getState().setState({ restarting: false });
       // try to resume if we have tokens
       const sessionCode = localStorage.getItem("sessionCode");
       const hostToken = localStorage.getItem("hostToken");
@@ -34,6 +74,10 @@ export function getSocket() {
             window.location.href = "/";
           } else {
             console.log("[socket] successfully resumed session");
+            // swap the token for a fresh one so long games don't outlive it
+            socket!.emit("game:refreshToken", { token }, (res: any) => {
+              if (res?.token) localStorage.setItem(hostToken ? "hostToken" : "playerToken", res.token);
+            });
           }
         });
       }
@@ -41,3 +85,23 @@ export function getSocket() {
   }
   return socket;
 }
+
+// socketFor returns the socket for joining the given session. A socket opened
+// before the code was known (e.g. on the home page) may sit on an instance that
+// doesn't own the session, so ask where it lives and reconnect with the code,
+// which routes us to its owner.
+export async function socketFor(code: string) {
+  const sock = getSocket();
+  const r = await fetch(`/api/route/${encodeURIComponent(code)}`).catch(() => null);
+  // routing is off (or unreachable): there's only one instance
+  if (!r?.ok) return sock;
+  const route = await r.json();
+  sock.io.opts.query = { code: route.code };
+  if (route.local) return sock;
+  await new Promise<void>((resolve) => {
+    sock.once("connect", () => resolve());
+    sock.disconnect();
+    sock.connect();
+  });
+  return sock;
+}
@@ -1,12 +1,22 @@
 import { useEffect, useState } from "react";
 import { useNavigate, useSearchParams } from "react-router-dom";
-import { getSocket } from "../lib/socket";
+import { getSocket, socketFor } from "../lib/socket";
 
 export default function Home() {
   const nav = useNavigate();
   const [searchParams] = useSearchParams();
   const [name, setName] = useState("");
   const [activeCode, setActiveCode] = useState<string | null>(null);
+  // with several games running none is offered, players type its code or PIN
+  const [codeInput, setCodeInput] = useState("");
+  const [joinError, setJoinError] = useState<string | null>(null);
+  const [lobby, setLobby] = useState<{
+    playerCount: number;
+    roundIndex: number;
+    roundCount: number;
+    recording: boolean;
+    anonymized: boolean;
+  } | null>(null);
   useEffect(() => {
     // Check if there's a join parameter in the URL
     const joinCode = searchParams.get("join");
@@ -34,14 +44,33 @@ export default function Home() {
     return () => clearInterval(id);
   }, [searchParams, nav]);
 
+  // Show who's already in, so people know they're joining the right game
+  useEffect(() => {
+    if (!activeCode) {
+      setLobby(null);
+      return;
+    }
+    const tick = () => {
+      fetch(`/api/session/${activeCode}/lobby`)
+        .then(async (r) => setLobby(r.ok ? await r.json() : null))
+        .catch(() => {});
+    };
+    tick();
+    const id = setInterval(tick, 5000);
+    return () => clearInterval(id);
+  }, [activeCode]);
+
   const onJoin = async (e: React.FormEvent<HTMLFormElement>) => {
     e.preventDefault();
-    // always fetch latest active session just in case page loaded before GM created it
-    const r = await fetch("/api/session/active");
-    if (!r.ok) return alert("Noch keine aktive Session. Bitte warte kurz.");
-    const j = await r.json();
-    const code: string = j.sessionCode;
-    const sock = getSocket();
+    let code = codeInput.trim();
+    if (!code) {
+      // always fetch latest active session just in case page loaded before GM created it
+      const r = await fetch("/api/session/active");
+      if (!r.ok) return alert("Noch keine aktive Session. Bitte warte kurz oder gib den Code des Spiels ein.");
+      const j = await r.json();
+      code = j.sessionCode;
+    }
+    const sock = await socketFor(code);
     let done = false;
     const to = setTimeout(() => {
       if (!done) console.warn("join ack timeout");
@@ -50,13 +79,45 @@ export default function Home() {
       done = true;
       clearTimeout(to);
       if (res?.playerToken) {
+        // a typed PIN resolves to the session's code
+        const sessionCode = res.sessionCode ?? code;
         localStorage.setItem("playerToken", res.playerToken);
         localStorage.setItem("playerId", res.playerId);
-        localStorage.setItem("sessionCode", code);
+        localStorage.setItem("sessionCode", sessionCode);
         localStorage.setItem("role", "player");
-        nav(`/lobby/${code}`);
+        nav(`/lobby/${sessionCode}`);
       } else if (res?.error) {
         console.warn("join error", res.error);
+        setJoinError(
+          res.code === "session_full"
+            ? "Die Session ist voll, bitte versuch es später noch einmal."
+            : res.code === "name_blocked"
+              ? "Bitte wähle einen anderen Namen."
+              : res.error,
+        );
+      }
+    });
+  };
+
+  // Own game without a GM screen: the creator joins as a player and the server
+  // moves the game on by itself
+  const onCreateHostless = () => {
+    if (!name.trim()) return setJoinError("Bitte gib zuerst deinen Namen ein.");
+    getSocket().emit("game:createHostless", { name }, (res: any) => {
+      if (res?.playerToken) {
+        localStorage.setItem("playerToken", res.playerToken);
+        localStorage.setItem("playerId", res.playerId);
+        localStorage.setItem("sessionCode", res.sessionCode);
+        localStorage.setItem("role", "player");
+        nav(`/lobby/${res.sessionCode}`);
+      } else if (res?.error) {
+        setJoinError(
+          res.code === "server_full"
+            ? "Gerade laufen zu viele Spiele, bitte versuch es später."
+            : res.code === "name_blocked"
+              ? "Bitte wähle einen anderen Namen."
+              : res.error,
+        );
       }
     });
   };
@@ -66,6 +127,18 @@ export default function Home() {
       <div className="card">
         <div className="title">Spiel beitreten</div>
         <p className="subtle">Gib deinen Namen ein, um der aktuellen Session beizutreten.</p>
+        {activeCode && lobby && (
+          <p className="subtle">
+            Session {activeCode}: {lobby.playerCount} Mitspielende
+            {lobby.roundIndex > 0 ? `, Runde ${lobby.roundIndex} von ${lobby.roundCount}` : ", noch nicht gestartet"}
+          </p>
+        )}
+        {activeCode && lobby?.recording && (
+          <p className="subtle">
+            Mit dem Beitritt stimmst du zu, dass deine Antworten und Stimmen gespeichert und ausgewertet werden
+            {lobby.anonymized ? " (ohne deinen Namen)." : "."}
+          </p>
+        )}
         <form onSubmit={onJoin} className="row" style={{ marginTop: 12 }}>
           <input
             style={{ flex: 1 }}
@@ -76,14 +149,34 @@ export default function Home() {
             required
             maxLength={40}
           />
+          {!activeCode && (
+            <input
+              style={{ width: 120 }}
+              value={codeInput}
+              onChange={(e) => setCodeInput(e.target.value)}
+              name="code"
+              placeholder="Code oder PIN"
+              maxLength={16}
+            />
+          )}
           <button
             type="submit"
-            disabled={!activeCode}
-            title={!activeCode ? "Warte auf Spielleiter..." : "Spiel beitreten"}
+            disabled={!activeCode && !codeInput.trim()}
+            title={!activeCode && !codeInput.trim() ? "Warte auf Spielleiter..." : "Spiel beitreten"}
           >
-            {activeCode ? "Beitreten" : "Warte auf Spiel..."}
+            {activeCode || codeInput.trim() ? "Beitreten" : "Warte auf Spiel..."}
           </button>
         </form>
+        {joinError && <p className="subtle">{joinError}</p>}
+      </div>
+      <div className="card">
+        <div className="title">Eigenes Spiel</div>
+        <p className="subtle">
+          Ohne Spielleitung: Ihr schlagt Fragen vor oder bekommt welche gestellt, weiter geht's, sobald alle fertig sind.
+        </p>
+        <button type="button" onClick={onCreateHostless}>
+          Spiel ohne Spielleitung starten
+        </button>
       </div>
     </div>
   );
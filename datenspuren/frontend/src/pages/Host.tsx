@@ -1,23 +1,58 @@
 import { useEffect, useState } from "react";
 import { useNavigate, useParams } from "react-router-dom";
 import { getSocket } from "../lib/socket";
-import { useGameStore } from "../store/useGameStore";
+import { type Connectivity, useGameStore } from "../store/useGameStore";
 
 export default function Host() {
   const { code } = useParams();
   const navigate = useNavigate();
-  const { phase, players, round, you } = useGameStore((s) => ({
+  const { phase, players, round, you, connectivity, sessionCode } = useGameStore((s) => ({
     phase: s.phase,
     players: s.players,
     round: s.round,
     you: s.you,
+    connectivity: s.connectivity,
+    sessionCode: s.sessionCode,
   }));
   const [prompt, setPrompt] = useState("");
   const [msg, setMsg] = useState<string | null>(null);
   const [submissionCount, setSubmissionCount] = useState(0);
   const [voteCount, setVoteCount] = useState(0);
+  const [audienceVotes, setAudienceVotes] = useState(0);
   const [aiAnswer, setAiAnswer] = useState<string | null>(null);
+  // human answers nearly identical to the AI's, see game:similarity
+  const [similar, setSimilar] = useState<{ submissionId: string; playerName: string; text: string; similarity: number }[]>(
+    [],
+  );
+  const [aiProvider, setAiProvider] = useState<string | null>(null); // who answered, may be a fallback
+  const [aiError, setAiError] = useState<string | null>(null); // every provider failed
+  const [manualAiAnswer, setManualAiAnswer] = useState("");
+  const [editingAI, setEditingAI] = useState(false); // manualAiAnswer edits the current AI answer
+  // pre-written answers from ANSWER_POOL_FILE fitting this round, and the one being edited
+  const [answerPool, setAnswerPool] = useState<{ id: string; prompt?: string; text: string }[]>([]);
+  const [poolId, setPoolId] = useState<string | null>(null);
   const [playerSubmissionStatus, setPlayerSubmissionStatus] = useState<Record<string, boolean>>({});
+  const [freezeScores, setFreezeScores] = useState(false);
+  const [scoresWithheld, setScoresWithheld] = useState(false);
+  const [spectators, setSpectators] = useState(0);
+  const [note, setNote] = useState("");
+  const [attachmentUrl, setAttachmentUrl] = useState("");
+  const [attachmentKind, setAttachmentKind] = useState<"image" | "link">("image");
+  const [readingOrder, setReadingOrder] = useState<{ id: string; text: string }[]>([]);
+  const [cheats, setCheats] = useState(false);
+  const [imageRound, setImageRound] = useState(false); // next prompt is drawn as a picture
+  const [rehearsal, setRehearsal] = useState(false);
+  const [seed, setSeed] = useState("");
+  const [bonusPlayer, setBonusPlayer] = useState("");
+  const [bonusPoints, setBonusPoints] = useState(1);
+  type QueuedPrompt = { id: string; prompt: string; category?: string; aiReady: boolean };
+  type LibraryPrompt = { id: string; prompt: string; category?: string; language?: string };
+  const [promptQueue, setPromptQueue] = useState<QueuedPrompt[]>([]);
+  const [library, setLibrary] = useState<LibraryPrompt[]>([]);
+  const [libraryCategory, setLibraryCategory] = useState("");
+  type PromptStats = { rounds: number; foolRate: number; avgAnswers: number };
+  const [queueStats, setQueueStats] = useState<Record<string, PromptStats>>({});
+  const [libraryStats, setLibraryStats] = useState<Record<string, PromptStats>>({});
 
   // GM form state (for session creation)
   const [showCreateForm, setShowCreateForm] = useState(false);
@@ -26,6 +61,19 @@ export default function Host() {
     (import.meta.env.VITE_DEFAULT_MODEL as string) || (provider === "ollama" ? "mistral" : "gpt-3.5-turbo"),
   );
   const [roundCount, setRoundCount] = useState(3);
+  const [answerTime, setAnswerTime] = useState(0);
+  const [voteTime, setVoteTime] = useState(0);
+  const [exportResults, setExportResults] = useState(true);
+  const [anonymize, setAnonymize] = useState(false);
+  const [hideVoteCounts, setHideVoteCounts] = useState(false);
+  const [groupSize, setGroupSize] = useState(0);
+  const [clusterAnswers, setClusterAnswers] = useState(false);
+  const [audienceWeight, setAudienceWeight] = useState(0);
+  const [allowSelfVote, setAllowSelfVote] = useState(false);
+  const [fooledBonus, setFooledBonus] = useState(0);
+  const [maxAnswerLength, setMaxAnswerLength] = useState(0);
+  const [language, setLanguage] = useState("de");
+  const [promptCandidates, setPromptCandidates] = useState<{ id: string; prompt: string; votes: number }[]>([]);
 
   // Check if host has valid session token
   useEffect(() => {
@@ -55,6 +103,12 @@ export default function Host() {
     sock.on("game:state", (payload: any) => {
       const { phase, players, round, you, sessionCode } = payload;
       useGameStore.getState().setState({ phase, players, round, you, sessionCode });
+      setScoresWithheld(!!payload.scoresWithheld);
+      setSpectators(payload.spectators || 0);
+      setCheats(!!payload.cheats);
+      setRehearsal(!!payload.rehearsal);
+      setSeed(payload.seed || "");
+      if (payload.connectivity) useGameStore.getState().setState({ connectivity: payload.connectivity });
     });
     sock.on("game:submissions", (payload: any) => {
       setSubmissionCount(payload.count || 0);
@@ -73,36 +127,137 @@ export default function Host() {
       // Set AI answer as soon as it's ready
       if (payload.answer) {
         setAiAnswer(payload.answer);
+        setAiProvider(payload.meta?.provider ?? null);
+        setAiError(null);
+        setSimilar([]); // a new answer gets checked again
       }
     });
+    sock.on("game:aiFailed", (payload: any) => {
+      // queued prompts just stay without a pre-generated answer
+      if (!payload.queuedId) setAiError(payload.error);
+    });
+    sock.on("game:imageFailed", (payload: any) => {
+      setMsg("Bild konnte nicht erzeugt werden: " + payload.error);
+    });
+    sock.on("game:similarity", (payload: any) => {
+      setSimilar(payload.answers || []);
+    });
     sock.on("game:votes", (payload: any) => {
       setVoteCount(payload.count || 0);
     });
+    sock.on("game:audienceVotes", (payload: any) => {
+      setAudienceVotes(payload?.total || 0);
+    });
+    sock.on("game:voting", (payload: any) => {
+      setReadingOrder(payload.submissions || []);
+    });
+    sock.on("game:promptQueue", (payload: any) => {
+      setPromptQueue(payload.prompts || []);
+      setQueueStats(payload.stats || {});
+    });
+    sock.on("game:promptCandidates", (payload: any) => {
+      setPromptCandidates(payload.candidates || []);
+    });
     // Reset vote count when entering new phases
     if (phase === "Answering") {
       setVoteCount(0);
       setAiAnswer(null); // Reset AI answer for new round
+      setAiProvider(null);
+      setAiError(null);
+      setSimilar([]);
+      setEditingAI(false);
       setSubmissionCount(0);
       setPlayerSubmissionStatus({});
     }
     if (phase === "Voting") {
       setVoteCount(0);
+      setAudienceVotes(0);
     }
     return () => {
       sock.off("game:state");
       sock.off("game:submissions");
       sock.off("game:results");
       sock.off("game:aiAnswer");
+      sock.off("game:similarity");
+      sock.off("game:aiFailed");
+      sock.off("game:imageFailed");
       sock.off("game:votes");
+      sock.off("game:audienceVotes");
+      sock.off("game:voting");
+      sock.off("game:promptQueue");
+      sock.off("game:promptCandidates");
     };
   }, [phase]);
 
+  // Imported prompt decks to pick from, see POST /api/gm/prompts/import
+  const loadLibrary = () => {
+    getSocket().emit("game:library", {}, (res: any) => {
+      if (res?.error) return;
+      setLibrary(res.prompts || []);
+      setLibraryStats(res.stats || {});
+    });
+  };
+  useEffect(() => {
+    if (phase === "Lobby" || phase === "PromptSet" || phase === "Scoreboard") loadLibrary();
+  }, [phase]);
+
+  // Suggestion from the library and the built-in prompts, for when nothing comes to mind
+  const onRandomPrompt = () => {
+    getSocket().emit("game:randomPrompt", { category: libraryCategory }, (res: any) => {
+      if (res?.error) {
+        setMsg("Keine passende Frage gefunden");
+        return;
+      }
+      setPrompt(res.prompt.prompt);
+    });
+  };
+
+  // Ask the provider again when the AI answer is unusable; the new one arrives as game:aiAnswer
+  const onRegenerateAI = () => {
+    getSocket().emit("game:regenerateAI", {}, (res: any) => {
+      if (res?.error) setMsg("Fehler: " + res.error);
+      else setMsg("KI-Antwort wird neu erzeugt …");
+    });
+  };
+
+  // Players suggest and vote on the next prompt instead of the host picking it
+  const onOpenPromptCollection = () => {
+    setPromptCandidates([]);
+    getSocket().emit("game:openPromptCollection", {}, (res: any) => {
+      if (res?.error) setMsg(res.error);
+    });
+  };
+  const onClosePromptCollection = () => {
+    getSocket().emit("game:closePromptCollection", {}, (res: any) => {
+      if (res?.error) setMsg("Noch keine Frage vorgeschlagen");
+    });
+  };
+
   const onCreate = async () => {
     const res = await fetch("/api/host/create", {
       method: "POST",
       headers: { "Content-Type": "application/json" },
       body: JSON.stringify({
-        config: { provider, model, roundCount, answerTime: 0, voteTime: 0 },
+        config: {
+          provider,
+          model,
+          roundCount,
+          answerTime,
+          voteTime,
+          export: exportResults,
+          anonymize,
+          rehearsal,
+          hideVoteCounts,
+          groupSize,
+          clusterAnswers,
+          audienceWeight,
+          allowSelfVote,
+          fooledBonus,
+          maxAnswerLength,
+          language,
+          // export timestamps in the host's time zone rather than the server's
+          timeZone: Intl.DateTimeFormat().resolvedOptions().timeZone,
+        },
       }),
     });
     if (!res.ok) {
@@ -127,13 +282,50 @@ export default function Host() {
     }
   };
 
+  const onSetAIAnswer = () => {
+    const sock = getSocket();
+    sock.emit("game:setAIAnswer", { text: manualAiAnswer, poolId: poolId ?? "" }, (res: any) => {
+      if (res?.error) {
+        setMsg(res.error);
+        return;
+      }
+      setManualAiAnswer("");
+      setPoolId(null);
+    });
+  };
+
+  // Tweak the generated answer; the export keeps what the AI wrote
+  const onEditAIAnswer = () => {
+    getSocket().emit("game:editAIAnswer", { text: manualAiAnswer }, (res: any) => {
+      if (res?.error) {
+        setMsg(res.error);
+        return;
+      }
+      setManualAiAnswer("");
+      setEditingAI(false);
+    });
+  };
+
+  const onPickPoolAnswer = (id: string) => {
+    getSocket().emit("game:setAIAnswer", { poolId: id }, (res: any) => {
+      if (res?.error) setMsg(res.error);
+    });
+  };
+
+  useEffect(() => {
+    if (phase !== "Answering") return;
+    getSocket().emit("game:answerPool", {}, (res: any) => {
+      if (!res?.error) setAnswerPool(res.answers || []);
+    });
+  }, [phase, round?.id]);
+
   const onSetPrompt = () => {
     const sock = getSocket();
     let done = false;
     const to = setTimeout(() => {
       if (!done) console.warn("setPrompt ack timeout");
     }, 5000);
-    sock.emit("game:setPrompt", { prompt }, (res: any) => {
+    sock.emit(imageRound ? "game:setImagePrompt" : "game:setPrompt", { prompt }, (res: any) => {
       done = true;
       clearTimeout(to);
       if (res?.error) {
@@ -146,6 +338,19 @@ export default function Host() {
       }
     });
   };
+  // One action per round: leaves the scoreboard, starts the typed or next
+  // queued prompt and opens voting once everyone answered
+  const onNextRound = () => {
+    getSocket().emit("game:nextRoundWithPrompt", { prompt }, (res: any) => {
+      if (res?.error) {
+        setMsg("Fehler: " + res.error);
+      } else {
+        setMsg(`Runde ${res.roundIndex} läuft, Abstimmung startet sobald alle geantwortet haben.`);
+        setPrompt("");
+        setSubmissionCount(0);
+      }
+    });
+  };
   const onAdvance = () => {
     const sock = getSocket();
 
@@ -174,7 +379,7 @@ export default function Host() {
       const to = setTimeout(() => {
         if (!done) console.warn("advance ack timeout");
       }, 5000);
-      sock.emit("game:advance", (res: any) => {
+      sock.emit("game:advance", { phase, round: round?.index ?? 0 }, (res: any) => {
         done = true;
         clearTimeout(to);
         if (res?.error) {
@@ -186,6 +391,115 @@ export default function Host() {
       });
     }
   };
+  const onQueueFromLibrary = (id: string) => {
+    getSocket().emit("game:queueFromLibrary", { id }, (res: any) => {
+      if (res?.error) setMsg("Fehler: " + res.error);
+    });
+  };
+  const onRetirePrompt = (id: string) => {
+    getSocket().emit("game:retirePrompt", { id }, (res: any) => {
+      if (res?.error) setMsg("Fehler: " + res.error);
+      else loadLibrary();
+    });
+  };
+  // how a prompt fared in earlier games, so worn-out prompts can be retired
+  const statsLabel = (st?: PromptStats) =>
+    st
+      ? ` · ${st.rounds}× gespielt, KI täuscht ${Math.round(st.foolRate * 100)} %, Ø ${st.avgAnswers.toFixed(1)} Antworten`
+      : "";
+  const onStartQueued = (queuedId: string) => {
+    getSocket().emit("game:setPrompt", { queuedId }, (res: any) => {
+      if (res?.error) {
+        setMsg("Fehler: " + res.error);
+      } else {
+        setMsg("Frage gesetzt.");
+        setSubmissionCount(0);
+      }
+    });
+  };
+  const onToggleFreeze = (frozen: boolean) => {
+    getSocket().emit("game:freezeScores", { frozen }, (res: any) => {
+      if (res?.error) {
+        setMsg("Fehler: " + res.error);
+      } else {
+        setFreezeScores(frozen);
+      }
+    });
+  };
+  const onAddNote = () => {
+    getSocket().emit("game:addRoundNote", { text: note }, (res: any) => {
+      if (res?.error) {
+        setMsg("Fehler: " + res.error);
+      } else {
+        setNote("");
+        setMsg("Notiz gespeichert.");
+      }
+    });
+  };
+  const onAttach = (url: string) => {
+    getSocket().emit("game:attach", { kind: attachmentKind, url }, (res: any) => {
+      if (res?.error) {
+        setMsg("Fehler: " + res.error);
+      } else {
+        setAttachmentUrl("");
+        setMsg(url ? "Anhang hinzugefügt." : "Anhang entfernt.");
+      }
+    });
+  };
+  const onMoveAnswer = (from: number, to: number) => {
+    if (to < 0 || to >= readingOrder.length) return;
+    const next = [...readingOrder];
+    const [moved] = next.splice(from, 1);
+    next.splice(to, 0, moved);
+    getSocket().emit("game:setReadingOrder", { order: next.map((s) => s.id) }, (res: any) => {
+      if (res?.error) setMsg("Fehler: " + res.error);
+    });
+  };
+  const onHideSubmission = (submissionId: string) => {
+    getSocket().emit("game:hideSubmission", { submissionId }, (res: any) => {
+      if (res?.error) setMsg("Fehler: " + res.error);
+      else {
+        setSimilar((prev) => prev.filter((s) => s.submissionId !== submissionId));
+        setMsg("Antwort ausgeblendet.");
+      }
+    });
+  };
+  const onSpeak = (submissionId: string) => {
+    const audio = new Audio(`/api/session/${sessionCode}/tts/${submissionId}`);
+    audio.onerror = () => setMsg("Vorlesen nicht möglich (TTS nicht eingerichtet?)");
+    audio.play().catch(() => {});
+  };
+  const onHint = () => {
+    getSocket().emit("game:hint", (res: any) => {
+      setMsg(res?.error ? "Fehler: " + res.error : "Eine Antwort wurde gestrichen.");
+    });
+  };
+  const onSkip = (target: string) => {
+    getSocket().emit("game:skip", { phase: target }, (res: any) => {
+      if (res?.error) setMsg("Fehler: " + res.error);
+    });
+  };
+  const onToggleCheats = (enabled: boolean) => {
+    getSocket().emit("game:setCheats", { enabled }, (res: any) => {
+      if (res?.error) setMsg("Fehler: " + res.error);
+      else setCheats(enabled);
+    });
+  };
+  const onCheat = (action: "bonus" | "markAI" | "votes", target: string, value = 0) => {
+    getSocket().emit("game:cheat", { action, target, value }, (res: any) => {
+      setMsg(res?.error ? "Fehler: " + res.error : "Geschummelt. (Steht im Protokoll.)");
+    });
+  };
+  const onRevealScores = () => {
+    getSocket().emit("game:revealScores", (res: any) => {
+      if (res?.error) {
+        setMsg("Fehler: " + res.error);
+      } else {
+        setScoresWithheld(false);
+        setMsg("Punkte enthüllt.");
+      }
+    });
+  };
   const getPhaseDisplayName = (phase: string) => {
     switch (phase) {
       case "Lobby":
@@ -200,6 +514,8 @@ export default function Host() {
         return "Ergebnisse";
       case "End":
         return "Spiel beendet";
+      case "PromptCollection":
+        return "Fragen sammeln";
       default:
         return phase;
     }
@@ -207,6 +523,20 @@ export default function Host() {
 
   const shouldShowPromptInput = phase === "Lobby" || phase === "PromptSet" || phase === "Scoreboard";
 
+  // Hotkeys: N outside text fields, or Ctrl/Cmd+Enter anywhere, for the next round
+  useEffect(() => {
+    if (!shouldShowPromptInput) return;
+    const onKey = (e: KeyboardEvent) => {
+      const typing = e.target instanceof HTMLInputElement || e.target instanceof HTMLTextAreaElement;
+      if ((e.key === "Enter" && (e.ctrlKey || e.metaKey)) || (e.key === "n" && !typing && !e.ctrlKey && !e.metaKey)) {
+        e.preventDefault();
+        onNextRound();
+      }
+    };
+    window.addEventListener("keydown", onKey);
+    return () => window.removeEventListener("keydown", onKey);
+  }, [shouldShowPromptInput, prompt]);
+
   // Show create form if no valid session
   if (showCreateForm) {
     return (
@@ -218,6 +548,7 @@ export default function Host() {
             <select value={provider} onChange={(e) => setProvider(e.target.value)} style={{ marginLeft: 8 }}>
               <option value="openai">OpenAI</option>
               <option value="ollama">Ollama</option>
+              <option value="manual">Manuell (KI-Antwort selbst eingeben)</option>
             </select>
           </label>
           <label>
@@ -240,6 +571,97 @@ export default function Host() {
               style={{ marginLeft: 8, width: 100 }}
             />
           </label>
+          <label>
+            Antwortzeit (s, 0 = aus)
+            <input
+              type="number"
+              min={0}
+              value={answerTime}
+              onChange={(e) => setAnswerTime(parseInt(e.target.value || "0"))}
+              style={{ marginLeft: 8, width: 100 }}
+            />
+          </label>
+          <label>
+            Abstimmzeit (s, 0 = aus)
+            <input
+              type="number"
+              min={0}
+              value={voteTime}
+              onChange={(e) => setVoteTime(parseInt(e.target.value || "0"))}
+              style={{ marginLeft: 8, width: 100 }}
+            />
+          </label>
+          <label>
+            Abstimmgruppen (Antworten pro Gruppe, 0 = alle gemeinsam)
+            <input
+              type="number"
+              min={0}
+              value={groupSize}
+              onChange={(e) => setGroupSize(parseInt(e.target.value || "0"))}
+              style={{ marginLeft: 8, width: 100 }}
+            />
+          </label>
+          <label>
+            <input type="checkbox" checked={exportResults} onChange={(e) => setExportResults(e.target.checked)} />
+            Ergebnisse exportieren
+          </label>
+          <label>
+            <input type="checkbox" checked={anonymize} onChange={(e) => setAnonymize(e.target.checked)} />
+            Namen im Export anonymisieren
+          </label>
+          <label>
+            <input type="checkbox" checked={rehearsal} onChange={(e) => setRehearsal(e.target.checked)} />
+            Probelauf (keine Exporte, Bots füllen Antworten auf)
+          </label>
+          <label>
+            <input type="checkbox" checked={hideVoteCounts} onChange={(e) => setHideVoteCounts(e.target.checked)} />
+            Stimmen verbergen (Spieler:innen sehen nur, ob sie die KI erkannt haben)
+          </label>
+          <label>
+            <input type="checkbox" checked={clusterAnswers} onChange={(e) => setClusterAnswers(e.target.checked)} />
+            Fast gleiche Antworten zusammenfassen (Punkte werden geteilt)
+          </label>
+          <label>
+            <input type="checkbox" checked={allowSelfVote} onChange={(e) => setAllowSelfVote(e.target.checked)} />
+            Für eigene Antwort stimmen erlauben
+          </label>
+          <label>
+            Maximale Antwortlänge in Zeichen (0 = unbegrenzt)
+            <input
+              type="number"
+              min={0}
+              value={maxAnswerLength}
+              onChange={(e) => setMaxAnswerLength(parseInt(e.target.value || "0"))}
+              style={{ marginLeft: 8, width: 100 }}
+            />
+          </label>
+          <label>
+            Bonus für Antworten mit mehr Stimmen als die KI (0 = aus)
+            <input
+              type="number"
+              min={0}
+              value={fooledBonus}
+              onChange={(e) => setFooledBonus(parseInt(e.target.value || "0"))}
+              style={{ marginLeft: 8, width: 100 }}
+            />
+          </label>
+          <label>
+            Sprache der KI und der Server-Meldungen
+            <select value={language} onChange={(e) => setLanguage(e.target.value)} style={{ marginLeft: 8 }}>
+              <option value="de">Deutsch</option>
+              <option value="en">English</option>
+            </select>
+          </label>
+          <label>
+            Publikumsstimme (zählt wie so viele Spieler:innen-Stimmen, 0 = aus)
+            <input
+              type="number"
+              min={0}
+              value={audienceWeight}
+              onChange={(e) => setAudienceWeight(parseInt(e.target.value || "0"))}
+              style={{ marginLeft: 8, width: 100 }}
+            />
+          </label>
           <button type="button" onClick={onCreate}>
             Session erstellen
           </button>
@@ -252,6 +674,25 @@ export default function Host() {
     <div style={{ display: "flex", flexDirection: "column", gap: 20 }}>
       <div className="card">
         <h2>Host Übersicht</h2>
+        {sessionCode && (
+          <a className="subtle" href={`/host/simple?code=${sessionCode}`} style={{ display: "block", marginBottom: 8 }}>
+            Einfache Steuerung (falls diese Ansicht hakt)
+          </a>
+        )}
+        {rehearsal && (
+          <div className="row" style={{ gap: 8, marginBottom: 8 }}>
+            <strong>Probelauf – nichts wird gespeichert.</strong>
+            <button type="button" onClick={() => onSkip("Voting")}>
+              Zur Abstimmung
+            </button>
+            <button type="button" onClick={() => onSkip("Scoreboard")}>
+              Zur Punktetafel
+            </button>
+            <button type="button" onClick={() => onSkip("End")}>
+              Zum Ende
+            </button>
+          </div>
+        )}
         {msg && (
           <div className="subtle" style={{ marginBottom: 8 }}>
             {msg}
@@ -274,12 +715,72 @@ export default function Host() {
             <strong>Mitspielende:</strong>
             <div>{players.length}</div>
           </div>
+          <div>
+            <strong>Zuschauende:</strong>
+            <div>{spectators}</div>
+          </div>
+          {seed && (
+            <div title="Bestimmt die Reihenfolge der Antworten in jeder Runde, steht auch im Export">
+              <strong>Mischungs-Seed:</strong>
+              <div style={{ fontFamily: "monospace" }}>{seed}</div>
+            </div>
+          )}
         </div>
+        {sessionCode && phase === "Lobby" && (
+          <div style={{ textAlign: "center", marginBottom: 16 }}>
+            <img
+              src={`/api/session/${sessionCode}/qr.png?size=384`}
+              alt={`QR-Code zum Beitreten von ${sessionCode}`}
+              width={192}
+              height={192}
+              style={{ background: "white", padding: 8, borderRadius: 8 }}
+            />
+            <div className="subtle">Zum Mitmachen scannen oder Code {sessionCode} eingeben</div>
+          </div>
+        )}
+        {players.length > 0 && (
+          <div style={{ display: "flex", flexWrap: "wrap", gap: 12, marginBottom: 16 }}>
+            {players.map((p) => (
+              <span key={p.id} title={connectivityTitle(connectivity?.[p.id])}>
+                {connectivityIcon(connectivity?.[p.id])} {p.name}
+              </span>
+            ))}
+          </div>
+        )}
 
         {round && (
           <div style={{ marginBottom: 16 }}>
             <strong>Aktuelle Frage:</strong>
             <div style={{ fontStyle: "italic", color: "var(--subtle)" }}>{round.prompt}</div>
+            {round.attachment && (
+              <div style={{ fontSize: "0.9em" }}>
+                Anhang ({round.attachment.kind === "image" ? "Bild" : "Link"}):{" "}
+                <a href={round.attachment.url} target="_blank" rel="noopener noreferrer">
+                  {round.attachment.url}
+                </a>{" "}
+                <button type="button" onClick={() => onAttach("")}>
+                  Entfernen
+                </button>
+              </div>
+            )}
+            {(phase === "Answering" || phase === "Voting") && (
+              <div style={{ display: "flex", gap: 8, marginTop: 8 }}>
+                <select value={attachmentKind} onChange={(e) => setAttachmentKind(e.target.value as "image" | "link")}>
+                  <option value="image">Bild</option>
+                  <option value="link">Link</option>
+                </select>
+                <input
+                  value={attachmentUrl}
+                  onChange={(e) => setAttachmentUrl(e.target.value)}
+                  placeholder="https://..."
+                  maxLength={2048}
+                  style={{ flex: 1 }}
+                />
+                <button type="button" onClick={() => onAttach(attachmentUrl)} disabled={!attachmentUrl.trim()}>
+                  Anhängen
+                </button>
+              </div>
+            )}
           </div>
         )}
       </div>
@@ -323,10 +824,109 @@ export default function Host() {
                 borderRadius: 8,
               }}
             >
-              <strong>🤖 KI-Antwort bereit:</strong>
+              <strong>🤖 KI-Antwort bereit{aiProvider ? ` (${aiProvider})` : ""}:</strong>
               <div style={{ marginTop: 8, fontStyle: "italic" }}>"{aiAnswer}"</div>
+              {phase === "Answering" && (
+                <div style={{ marginTop: 8 }}>
+                  <button type="button" onClick={onRegenerateAI} style={{ marginRight: 8 }}>
+                    🔄 Neu erzeugen
+                  </button>
+                  <button
+                    type="button"
+                    onClick={() => {
+                      setEditingAI(true);
+                      setManualAiAnswer(aiAnswer);
+                    }}
+                  >
+                    ✏️ Bearbeiten
+                  </button>
+                </div>
+              )}
+            </div>
+          )}
+          {aiAnswer && similar.length > 0 && (
+            <div
+              style={{
+                background: "var(--yellow)",
+                color: "var(--bg)",
+                padding: 12,
+                borderRadius: 8,
+                marginTop: 8,
+              }}
+            >
+              <strong>⚠️ Fast gleich wie die KI-Antwort:</strong>
+              {similar.map((s) => (
+                <div key={s.submissionId} style={{ marginTop: 4 }}>
+                  {s.playerName}: "{s.text}" ({Math.round(s.similarity * 100)} %){" "}
+                  <button type="button" onClick={() => onHideSubmission(s.submissionId)}>
+                    Ausblenden
+                  </button>
+                </div>
+              ))}
             </div>
           )}
+          {!aiAnswer && aiError && (
+            <div
+              style={{
+                background: "var(--accent)",
+                color: "white",
+                padding: 12,
+                borderRadius: 8,
+              }}
+            >
+              <strong>⚠️ Keine KI-Antwort:</strong> Alle KI-Anbieter sind fehlgeschlagen. Bitte gib die Antwort manuell
+              ein.
+              <div style={{ marginTop: 8, fontSize: "0.85em" }}>{aiError}</div>
+            </div>
+          )}
+          {answerPool.length > 0 && (
+            <div style={{ marginTop: 12 }}>
+              <strong>Vorbereitete Antworten:</strong>
+              <ul style={{ listStyle: "none", padding: 0, margin: "8px 0 0" }}>
+                {answerPool.map((a) => (
+                  <li key={a.id} style={{ display: "flex", gap: 8, alignItems: "center", marginBottom: 6 }}>
+                    <span style={{ flex: 1, fontStyle: "italic" }}>
+                      "{a.text}"{!a.prompt && <span className="subtle"> (passt immer)</span>}
+                    </span>
+                    <button type="button" onClick={() => onPickPoolAnswer(a.id)}>
+                      Übernehmen
+                    </button>
+                    <button
+                      type="button"
+                      onClick={() => {
+                        setManualAiAnswer(a.text);
+                        setPoolId(a.id);
+                      }}
+                    >
+                      Bearbeiten
+                    </button>
+                  </li>
+                ))}
+              </ul>
+            </div>
+          )}
+          <div style={{ marginTop: 12 }}>
+            <label htmlFor="ai-answer-textarea" style={{ display: "block", marginBottom: 8, fontWeight: "bold" }}>
+              {editingAI ? "KI-Antwort bearbeiten:" : aiAnswer ? "KI-Antwort ersetzen:" : "KI-Antwort manuell eingeben:"}
+            </label>
+            <textarea
+              id="ai-answer-textarea"
+              value={manualAiAnswer}
+              onChange={(e) => setManualAiAnswer(e.target.value)}
+              placeholder="Antwort der KI..."
+              rows={2}
+              style={{ width: "100%", boxSizing: "border-box", marginBottom: 8, resize: "vertical" }}
+            />
+            {editingAI ? (
+              <button type="button" onClick={onEditAIAnswer} disabled={!manualAiAnswer.trim()}>
+                Änderung speichern
+              </button>
+            ) : (
+              <button type="button" onClick={onSetAIAnswer} disabled={!manualAiAnswer.trim()}>
+                KI-Antwort setzen
+              </button>
+            )}
+          </div>
         </div>
       )}
 
@@ -335,17 +935,72 @@ export default function Host() {
           <h3>Abstimmung läuft</h3>
           <div style={{ marginBottom: 12 }}>
             <strong>Abgegebene Stimmen:</strong> {voteCount} / {players.length}
+            {audienceVotes > 0 && <span> (Publikum: {audienceVotes})</span>}
           </div>
           <p className="subtle">
             {voteCount >= players.length && players.length > 0
               ? "Alle Spieler:innen haben abgestimmt."
               : "Die Spieler:innen stimmen gerade über die Antworten ab..."}
           </p>
+          <button type="button" onClick={onHint} style={{ marginBottom: 12 }}>
+            Hinweis: eine menschliche Antwort streichen
+          </button>
+          {round?.groups && round.groups.length > 0 && (
+            <p className="subtle">
+              Abstimmung in {round.groups.length} parallelen Gruppen, jede stimmt zusätzlich über die KI-Antwort ab.
+            </p>
+          )}
+          {readingOrder.length > 0 && (
+            <>
+              <h4>Vorlesereihenfolge</h4>
+              <ol>
+                {readingOrder.map((sub, i) => (
+                  <li key={sub.id} style={{ marginBottom: 6 }}>
+                    {sub.text}{" "}
+                    <button type="button" onClick={() => onMoveAnswer(i, i - 1)} disabled={i === 0}>
+                      ↑
+                    </button>
+                    <button type="button" onClick={() => onMoveAnswer(i, i + 1)} disabled={i === readingOrder.length - 1}>
+                      ↓
+                    </button>
+                    <button type="button" onClick={() => onSpeak(sub.id)} title="Vorlesen">
+                      🔊
+                    </button>
+                    <button type="button" onClick={() => onHideSubmission(sub.id)} title="Aus der Abstimmung nehmen">
+                      🙈
+                    </button>
+                  </li>
+                ))}
+              </ol>
+            </>
+          )}
         </div>
       )}
 
       <div className="card">
         <h3>Aktionen</h3>
+        {phase === "PromptCollection" && (
+          <div style={{ marginBottom: 16 }}>
+            <strong>Vorgeschlagene Fragen</strong>
+            {promptCandidates.length === 0 && <p className="subtle">Noch keine Vorschläge.</p>}
+            {[...promptCandidates]
+              .sort((a, b) => b.votes - a.votes)
+              .map((c) => (
+                <div key={c.id} className="row" style={{ gap: 8, marginTop: 4 }}>
+                  <span style={{ flex: 1 }}>{c.prompt}</span>
+                  <span>{c.votes} 🗳</span>
+                </div>
+              ))}
+            <button
+              type="button"
+              onClick={onClosePromptCollection}
+              disabled={promptCandidates.length === 0}
+              style={{ marginTop: 12 }}
+            >
+              Gewinnerfrage starten ⏭
+            </button>
+          </div>
+        )}
         {shouldShowPromptInput && (
           <div style={{ marginBottom: 16 }}>
             <label htmlFor="prompt-textarea" style={{ display: "block", marginBottom: 8, fontWeight: "bold" }}>
@@ -370,12 +1025,150 @@ export default function Host() {
               }}
             />
             {phase === "PromptSet" && (
-              <button type="button" onClick={onSetPrompt} disabled={!prompt.trim()} style={{ marginRight: 12 }}>
-                Frage setzen
-              </button>
+              <>
+                <label style={{ marginRight: 12 }}>
+                  <input type="checkbox" checked={imageRound} onChange={(e) => setImageRound(e.target.checked)} />{" "}
+                  Bildrunde
+                </label>
+                <button type="button" onClick={onSetPrompt} disabled={!prompt.trim()} style={{ marginRight: 12 }}>
+                  {imageRound ? "Bild erzeugen" : "Frage setzen"}
+                </button>
+              </>
+            )}
+            <button
+              type="button"
+              onClick={onNextRound}
+              disabled={!prompt.trim() && promptQueue.length === 0}
+              title="N oder Strg+Enter"
+              style={{ marginRight: 12 }}
+            >
+              Nächste Runde ⏭
+            </button>
+            <button type="button" onClick={onRandomPrompt} title="Vorschlag aus Fragenkatalog und mitgelieferten Fragen">
+              🎲 Zufällige Frage
+            </button>
+            <button type="button" onClick={onOpenPromptCollection} style={{ marginLeft: 12 }}>
+              Spieler:innen Fragen vorschlagen lassen
+            </button>
+            {promptQueue.length > 0 && (
+              <div style={{ marginTop: 12 }}>
+                <strong>Vorbereitete Fragen</strong>
+                {promptQueue.map((q) => (
+                  <div key={q.id} className="row" style={{ gap: 8, marginTop: 4 }}>
+                    <span style={{ flex: 1 }}>
+                      {q.category && <small>[{q.category}] </small>}
+                      {q.prompt} {q.aiReady ? "🤖" : ""}
+                      <small>{statsLabel(queueStats[q.id])}</small>
+                    </span>
+                    <button type="button" onClick={() => onStartQueued(q.id)}>
+                      Starten
+                    </button>
+                  </div>
+                ))}
+              </div>
+            )}
+            {library.length > 0 && (
+              <details style={{ marginTop: 12 }}>
+                <summary>Fragenkatalog ({library.length})</summary>
+                <select value={libraryCategory} onChange={(e) => setLibraryCategory(e.target.value)}>
+                  <option value="">Alle Kategorien</option>
+                  {[...new Set(library.map((p) => p.category).filter(Boolean))].map((c) => (
+                    <option key={c} value={c}>
+                      {c}
+                    </option>
+                  ))}
+                </select>
+                {library
+                  .filter((p) => !libraryCategory || p.category === libraryCategory)
+                  .map((p) => (
+                    <div key={p.id} className="row" style={{ gap: 8, marginTop: 4 }}>
+                      <span style={{ flex: 1 }}>
+                        {p.prompt}
+                        {p.language && <small> ({p.language})</small>}
+                        <small>{statsLabel(libraryStats[p.id])}</small>
+                      </span>
+                      <button type="button" onClick={() => setPrompt(p.prompt)}>
+                        Übernehmen
+                      </button>
+                      <button type="button" onClick={() => onQueueFromLibrary(p.id)}>
+                        Einreihen
+                      </button>
+                      <button type="button" onClick={() => onRetirePrompt(p.id)}>
+                        Aussortieren
+                      </button>
+                    </div>
+                  ))}
+              </details>
             )}
           </div>
         )}
+        {round && (
+          <div style={{ display: "flex", gap: 8, marginBottom: 12 }}>
+            <input
+              value={note}
+              onChange={(e) => setNote(e.target.value)}
+              placeholder={`Notiz zu Runde ${round.index}`}
+              style={{ flex: 1 }}
+            />
+            <button type="button" onClick={onAddNote} disabled={!note.trim()}>
+              Notiz speichern
+            </button>
+          </div>
+        )}
+        <label style={{ display: "block", marginBottom: 12 }}>
+          <input type="checkbox" checked={cheats} onChange={(e) => onToggleCheats(e.target.checked)} /> Schummel-Modus
+        </label>
+        {cheats && (
+          <div className="card" style={{ marginBottom: 12 }}>
+            <h4>Schummeln (wird protokolliert)</h4>
+            <div className="row" style={{ gap: 8, marginBottom: 8 }}>
+              <select value={bonusPlayer} onChange={(e) => setBonusPlayer(e.target.value)}>
+                <option value="">Spieler:in wählen</option>
+                {players.map((p) => (
+                  <option key={p.id} value={p.id}>
+                    {p.name}
+                  </option>
+                ))}
+              </select>
+              <input
+                type="number"
+                value={bonusPoints}
+                onChange={(e) => setBonusPoints(parseInt(e.target.value || "0"))}
+                style={{ width: 80 }}
+              />
+              <button type="button" onClick={() => onCheat("bonus", bonusPlayer, bonusPoints)} disabled={!bonusPlayer}>
+                Punkte vergeben
+              </button>
+            </div>
+            {phase === "Voting" &&
+              readingOrder.map((sub) => (
+                <div key={sub.id} className="row" style={{ gap: 8, marginBottom: 6 }}>
+                  <span style={{ flex: 1 }}>{sub.text}</span>
+                  <button type="button" onClick={() => onCheat("markAI", sub.id)}>
+                    Als KI enthüllen
+                  </button>
+                  <button
+                    type="button"
+                    onClick={() => {
+                      const n = parseInt(window.prompt("Stimmen für diese Antwort:") || "", 10);
+                      if (!isNaN(n)) onCheat("votes", sub.id, n);
+                    }}
+                  >
+                    Stimmen setzen
+                  </button>
+                </div>
+              ))}
+          </div>
+        )}
+        <label style={{ display: "block", marginBottom: 12 }}>
+          <input type="checkbox" checked={freezeScores} onChange={(e) => onToggleFreeze(e.target.checked)} /> Punkte
+          erst nach Enthüllung an Handys senden
+        </label>
+        {scoresWithheld && (
+          <button type="button" onClick={onRevealScores} style={{ marginRight: 12 }}>
+            Punkte enthüllen
+          </button>
+        )}
         <button
           type="button"
           onClick={onAdvance}
@@ -398,3 +1191,24 @@ export default function Host() {
     </div>
   );
 }
+
+function connectivityIcon(c?: Connectivity): string {
+  switch (c?.quality) {
+    case "good":
+      return "🟢";
+    case "fair":
+      return "🟡";
+    case "poor":
+      return "🟠";
+    case "offline":
+      return "🔴";
+    default:
+      return "⚪";
+  }
+}
+
+function connectivityTitle(c?: Connectivity): string {
+  if (!c) return "Verbindung unbekannt";
+  if (c.quality === "offline") return "Offline";
+  return c.rttMs ? `Latenz: ${c.rttMs} ms` : "Verbunden";
+}
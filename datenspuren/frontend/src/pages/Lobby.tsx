@@ -1,5 +1,6 @@
 import { useEffect } from "react";
 import { useNavigate, useParams } from "react-router-dom";
+import HostlessPanel from "../components/HostlessPanel";
 import { getSocket } from "../lib/socket";
 import { useGameStore } from "../store/useGameStore";
 
@@ -8,6 +9,9 @@ export default function Lobby() {
   const nav = useNavigate();
   const players = useGameStore((s) => s.players);
   const phase = useGameStore((s) => s.phase);
+  const recording = useGameStore((s) => s.recording);
+  const anonymized = useGameStore((s) => s.anonymized);
+  const hostless = useGameStore((s) => s.hostless);
 
   // Check if player has valid session token
   useEffect(() => {
@@ -50,7 +54,7 @@ export default function Lobby() {
     });
 
     sock.on("game:state", (payload: any) => {
-      const { phase, players, round, you, sessionCode } = payload;
+      const { phase, players, round, you, sessionCode, recording, anonymized, hostless, ready } = payload;
       console.log("[Lobby] Received game:state:", {
         phase,
         playersCount: players?.length,
@@ -71,7 +75,9 @@ export default function Lobby() {
         console.warn("[Lobby] Received invalid players data:", players);
       }
 
-      useGameStore.getState().setState({ phase, players: players || [], round, you, sessionCode });
+      useGameStore
+        .getState()
+        .setState({ phase, players: players || [], round, you, sessionCode, recording, anonymized, hostless, ready });
     });
 
     // Request initial state if connected
@@ -146,7 +152,24 @@ export default function Lobby() {
         )}
       </div>
 
-      {phase === "PromptSet" && (
+      {hostless && (
+        <>
+          <p className="subtle">
+            Lade andere mit dem Code <strong>{code}</strong> ein.
+          </p>
+          <HostlessPanel />
+        </>
+      )}
+
+      {recording !== undefined && (
+        <p style={{ color: "var(--subtle)", marginTop: 16 }}>
+          {recording
+            ? `Hinweis: Antworten und Abstimmungen dieser Session werden gespeichert${anonymized ? ", Namen dabei pseudonymisiert" : ""}.`
+            : "Antworten dieser Session werden nicht gespeichert."}
+        </p>
+      )}
+
+      {phase === "PromptSet" && !hostless && (
         <p style={{ color: "var(--yellow)", marginTop: 16 }}>Spielleiter:in bereitet eine neue Runde vor...</p>
       )}
     </div>
@@ -1,33 +1,66 @@
 import { useEffect, useState } from "react";
 import { useNavigate, useParams } from "react-router-dom";
+import { playCue } from "../lib/cues";
+import HostlessPanel from "../components/HostlessPanel";
 import { getSocket } from "../lib/socket";
-import { useGameStore } from "../store/useGameStore";
+import { localizedPrompt, useGameStore } from "../store/useGameStore";
 
 type ResultPayload = {
   aiSubmissionId: string;
   votes: { id: string; voterId: string; targetSubmissionId: string }[];
-  scores: { PlayerID: string; Points: number }[];
-  submissions: { id: string; text: string; authorId?: string | null }[];
+  voteCounts?: Record<string, number>; // may differ from votes when the host cheated
+  scores: { playerId: string; name: string; points: number; rank: number }[];
+  aiScore: number;
+  submissions: {
+    id: string;
+    text: string;
+    authorId?: string | null;
+    authors?: { id: string; name: string }[]; // near-duplicate answers merged into this one, sharing its points
+  }[];
+  voteCountsHidden?: boolean; // the host only lets players know whether they found the AI
+  foundAI?: boolean;
+  awards?: { name: string; title: string; playerId: string; playerName: string; submissionId: string; points: number }[];
+};
+
+// title is translated into the session's language by the server
+type Award = { name: string; title: string; playerId: string; playerName: string; value: number };
+
+type PromptCandidates = {
+  candidates: { id: string; prompt: string; votes: number }[];
+  own: string; // ID of the player's own suggestion
+  vote: string;
 };
 
 export default function Play() {
   const { code } = useParams();
   const navigate = useNavigate();
-  const { phase, players, round, you } = useGameStore((s) => ({
+  const { phase, players, round, you, metaScore, hostless, allowSelfVote, maxAnswerLength } = useGameStore((s) => ({
     phase: s.phase,
     players: s.players,
     round: s.round,
     you: s.you,
+    metaScore: s.metaScore,
+    hostless: s.hostless,
+    allowSelfVote: s.allowSelfVote,
+    maxAnswerLength: s.maxAnswerLength,
   }));
   const [text, setText] = useState("");
   const [currentRound, setCurrentRound] = useState<number | null>(null);
-  const [submissions, setSubmissions] = useState<{ id: string; text: string }[]>([]);
+  const [submissions, setSubmissions] = useState<{ id: string; text: string; mergedIds?: string[] }[]>([]);
+  const [votingGroup, setVotingGroup] = useState<{ group: number; count: number } | null>(null);
   const [results, setResults] = useState<ResultPayload | null>(null);
+  const [awards, setAwards] = useState<Award[]>([]);
+  const [promptCandidates, setPromptCandidates] = useState<PromptCandidates | null>(null);
+  const [promptSuggestion, setPromptSuggestion] = useState("");
+  const [promptRejected, setPromptRejected] = useState(false);
   const [mySubmissionId, setMySubmissionId] = useState<string | null>(null);
   const [hasVoted, setHasVoted] = useState(false);
   const [votedFor, setVotedFor] = useState<string | null>(null);
   const [showSubmitFeedback, setShowSubmitFeedback] = useState(false);
   const [isSubmitting, setIsSubmitting] = useState(false);
+  const [answerError, setAnswerError] = useState<string | null>(null);
+  // seconds left in the current phase, from the server's "game:timer"
+  const [timeLeft, setTimeLeft] = useState<{ phase: string; remaining: number } | null>(null);
 
   // Check if player has valid session token and handle reconnection
   useEffect(() => {
@@ -83,10 +116,25 @@ export default function Play() {
 
   useEffect(() => {
     const sock = getSocket();
-    sock.on("game:voting", (payload: any) => setSubmissions(payload.submissions || []));
+    sock.on("game:voting", (payload: any) => {
+      setSubmissions(payload.submissions || []);
+      setVotingGroup(payload.group ? { group: payload.group, count: payload.groupCount } : null);
+    });
     sock.on("game:results", (payload: any) => setResults(payload));
+    sock.on("game:awards", (payload: any) => setAwards(payload.awards || []));
+    sock.on("game:promptCandidates", (payload: any) => setPromptCandidates(payload));
+    sock.on("game:cue", (payload: any) => playCue(payload.remaining));
+    sock.on("game:timer", (payload: any) => setTimeLeft({ phase: payload.phase, remaining: payload.remaining }));
+    // a hint hands back votes for the eliminated answer
+    sock.on("game:hint", (payload: any) =>
+      setVotedFor((prev) => {
+        if (prev !== payload.eliminatedId) return prev;
+        setHasVoted(false);
+        return null;
+      }),
+    );
     sock.on("game:state", (payload: any) => {
-      const { phase, players, round, you } = payload;
+      const { phase, players, round, you, metaScore, hostless, ready, allowSelfVote, maxAnswerLength } = payload;
       console.log("[Play] Received game:state:", {
         phase,
         playersCount: players?.length,
@@ -112,11 +160,18 @@ export default function Play() {
         }
       }
 
-      useGameStore.getState().setState({ phase, players, round, you });
+      useGameStore
+        .getState()
+        .setState({ phase, players, round, you, metaScore, hostless, ready, allowSelfVote, maxAnswerLength });
     });
     return () => {
       sock.off("game:voting");
+      sock.off("game:hint");
       sock.off("game:results");
+      sock.off("game:awards");
+      sock.off("game:promptCandidates");
+      sock.off("game:cue");
+      sock.off("game:timer");
       sock.off("game:state");
     };
   }, [code, navigate]);
@@ -151,6 +206,13 @@ export default function Play() {
       done = true;
       clearTimeout(to);
       setIsSubmitting(false);
+      setAnswerError(
+        res?.code === "answer_blocked"
+          ? "Deine Antwort enthält ein gesperrtes Wort, bitte formuliere sie um."
+          : res?.code === "answer_too_long"
+            ? "Deine Antwort ist zu lang, bitte kürze sie."
+            : null,
+      );
       if (res?.submissionId) {
         setMySubmissionId(res.submissionId);
         setShowSubmitFeedback(true);
@@ -183,6 +245,19 @@ export default function Play() {
     });
   };
 
+  const onSuggestPrompt = () => {
+    getSocket().emit("game:submitPrompt", { prompt: promptSuggestion }, (res: any) => {
+      setPromptRejected(res?.code === "content_flagged");
+      if (res?.error) console.warn("Prompt suggestion error:", res.error);
+    });
+  };
+
+  const onVotePrompt = (id: string) => {
+    getSocket().emit("game:votePrompt", { candidateId: id }, (res: any) => {
+      if (res?.error) console.warn("Prompt vote error:", res.error);
+    });
+  };
+
   return (
     <div>
       {/* Debug info */}
@@ -204,7 +279,28 @@ export default function Play() {
         {round?.prompt ? (
           <div className="card" style={{ background: "var(--purple)", color: "white", padding: 16 }}>
             <h3 style={{ margin: "0 0 8px 0" }}>Frage</h3>
-            <div style={{ fontSize: "1.1em", fontWeight: "normal" }}>{round.prompt}</div>
+            <div style={{ fontSize: "1.1em", fontWeight: "normal" }}>{localizedPrompt(round)}</div>
+            {round.attachment?.kind === "image" && (
+              <img
+                src={round.attachment.url}
+                alt="Bild zur Frage"
+                referrerPolicy="no-referrer"
+                style={{ display: "block", maxWidth: "100%", maxHeight: 320, marginTop: 12, borderRadius: 8 }}
+              />
+            )}
+            {round.type === "image" && !round.attachment && (
+              <p style={{ margin: "12px 0 0 0" }}>🎨 Bild wird erzeugt…</p>
+            )}
+            {round.attachment?.kind === "link" && (
+              <a
+                href={round.attachment.url}
+                target="_blank"
+                rel="noopener noreferrer"
+                style={{ display: "block", marginTop: 12, color: "white", wordBreak: "break-all" }}
+              >
+                🔗 {round.attachment.url}
+              </a>
+            )}
           </div>
         ) : phase === "Answering" || phase === "Voting" ? (
           <div
@@ -223,6 +319,7 @@ export default function Play() {
       {phase === "Answering" && (
         <div className="card">
           <h3>Deine Antwort</h3>
+          {timeLeft?.phase === phase && <p className="subtle">⏱ Noch {timeLeft.remaining} s</p>}
           <textarea
             value={text}
             onChange={(e) => setText(e.target.value)}
@@ -234,8 +331,14 @@ export default function Play() {
               marginBottom: 12,
               resize: "vertical",
             }}
-            placeholder="Schreibe deine Antwort hier..."
+            placeholder={round?.type === "image" ? "Deine Bildunterschrift..." : "Schreibe deine Antwort hier..."}
+            maxLength={maxAnswerLength || undefined}
           />
+          {!!maxAnswerLength && (
+            <p className="subtle">
+              {text.trim().length} / {maxAnswerLength} Zeichen
+            </p>
+          )}
           <button
             type="button"
             onClick={onSubmit}
@@ -256,12 +359,14 @@ export default function Play() {
                   ? "Antwort aktualisieren"
                   : "Antwort senden"}
           </button>
+          {answerError && <p className="subtle">{answerError}</p>}
         </div>
       )}
       {phase === "Voting" && (
         <div className="card">
           <h3>Stimme für eine Antwort ab</h3>
           <p className="subtle">Welche Antwort stammt wohl von der KI?</p>
+          {timeLeft?.phase === phase && <p className="subtle">⏱ Noch {timeLeft.remaining} s</p>}
           {!mySubmissionId && (
             <div
               style={{
@@ -275,17 +380,23 @@ export default function Play() {
               ⚠️ Du kannst leider nicht abstimmen, da du in dieser Runde keine Antwort abgegeben hast.
             </div>
           )}
+          {votingGroup && (
+            <p className="subtle">
+              Gruppe {votingGroup.group} von {votingGroup.count}: du stimmst über die Antworten deiner Gruppe ab.
+            </p>
+          )}
           {submissions.map((s) => {
-            // Check if this is the current player's submission (prevent self-voting)
-            const isOwnSubmission = mySubmissionId === s.id;
+            // Check if this is the current player's submission (prevent self-voting unless the host allows it)
+            const isOwnSubmission = mySubmissionId === s.id || (!!mySubmissionId && !!s.mergedIds?.includes(mySubmissionId));
+            const selfVoteBlocked = isOwnSubmission && !allowSelfVote;
             const isVotedFor = votedFor === s.id;
-            const canVote = mySubmissionId && !hasVoted && !isOwnSubmission;
+            const canVote = mySubmissionId && !hasVoted && !selfVoteBlocked;
             return (
               <button
                 type="button"
                 key={s.id}
                 onClick={() => canVote && onVote(s.id)}
-                disabled={!mySubmissionId || isOwnSubmission || hasVoted}
+                disabled={!mySubmissionId || selfVoteBlocked || hasVoted}
                 style={{
                   display: "block",
                   marginBottom: 8,
@@ -293,7 +404,7 @@ export default function Play() {
                   textAlign: "left",
                   padding: 12,
                   opacity: !canVote || isOwnSubmission ? 0.6 : 1,
-                  cursor: !canVote || isOwnSubmission ? "not-allowed" : "pointer",
+                  cursor: !canVote || selfVoteBlocked ? "not-allowed" : "pointer",
                   border: isVotedFor ? "3px solid var(--green)" : undefined,
                   background: isVotedFor ? "var(--green)" : undefined,
                   color: isVotedFor ? "white" : undefined,
@@ -318,6 +429,79 @@ export default function Play() {
           })}
         </div>
       )}
+      {phase === "PromptCollection" && (
+        <div className="card">
+          <h3>Welche Frage kommt als Nächstes?</h3>
+          <p className="subtle">Schlag eine Frage vor und stimme für deinen Favoriten.</p>
+          <textarea
+            value={promptSuggestion}
+            onChange={(e) => setPromptSuggestion(e.target.value)}
+            rows={2}
+            style={{ width: "100%", maxWidth: "100%", boxSizing: "border-box", marginBottom: 12, resize: "vertical" }}
+            placeholder="Deine Frage..."
+          />
+          <button type="button" onClick={onSuggestPrompt} disabled={!promptSuggestion.trim()}>
+            {promptCandidates?.own ? "Vorschlag aktualisieren" : "Frage vorschlagen"}
+          </button>
+          {promptRejected && <p className="subtle">Diese Frage können wir leider nicht zeigen.</p>}
+          {promptCandidates?.candidates.map((c) => {
+            const isOwn = c.id === promptCandidates.own;
+            const isVotedFor = c.id === promptCandidates.vote;
+            const blocked = isOwn && !allowSelfVote;
+            return (
+              <button
+                type="button"
+                key={c.id}
+                onClick={() => !blocked && onVotePrompt(c.id)}
+                disabled={blocked}
+                style={{
+                  display: "block",
+                  marginTop: 8,
+                  width: "100%",
+                  textAlign: "left",
+                  padding: 12,
+                  opacity: blocked ? 0.6 : 1,
+                  cursor: blocked ? "not-allowed" : "pointer",
+                  background: isVotedFor ? "var(--green)" : undefined,
+                  color: isVotedFor ? "white" : undefined,
+                }}
+              >
+                {c.prompt}
+                <span style={{ marginLeft: 8, fontSize: "0.9em" }}>
+                  {isOwn && "(Dein Vorschlag) "}
+                  {isVotedFor && "✓ Gewählt "}({c.votes})
+                </span>
+              </button>
+            );
+          })}
+        </div>
+      )}
+      {(phase === "Scoreboard" || phase === "End") && metaScore && (
+        <div className="card" style={{ textAlign: "center" }}>
+          <h3>
+            Menschen {metaScore.humans} : {metaScore.ai} KI
+          </h3>
+          {phase === "End" && (
+            <p style={{ fontSize: "1.2em", fontWeight: "bold" }}>
+              {metaScore.leader === "humans"
+                ? "🎉 Die Menschen gewinnen den Abend!"
+                : metaScore.leader === "ai"
+                  ? "🤖 Die KI gewinnt den Abend!"
+                  : "Unentschieden zwischen Menschen und KI!"}
+            </p>
+          )}
+          {phase === "End" &&
+            awards.map((a) => (
+              <div key={`${a.name}-${a.playerId}`}>
+                🏆 {a.title || a.name}: <strong>{a.playerName}</strong>
+                {a.name === "fastestWriter" ? ` (Ø ${Math.round(a.value)} s)` : ` (${a.value})`}
+              </div>
+            ))}
+        </div>
+      )}
+
+      {phase === "Scoreboard" && hostless && <HostlessPanel />}
+
       {phase === "Scoreboard" && results && (
         <div>
           {/* Debug info for results */}
@@ -339,8 +523,8 @@ export default function Play() {
               Scores:{" "}
               {JSON.stringify(
                 results.scores.map((s) => ({
-                  id: s.PlayerID,
-                  points: s.Points,
+                  id: s.playerId,
+                  points: s.points,
                 })),
               )}
             </div>
@@ -364,16 +548,29 @@ export default function Play() {
                   ?.text || "(unbekannt)"}
               </blockquote>
             </div>
+            {results.voteCountsHidden && (
+              <div style={{ fontWeight: "bold", color: results.foundAI ? "var(--green)" : "var(--subtle)" }}>
+                {results.foundAI ? "✓ Du hast die KI erkannt!" : "Diesmal hast du die KI nicht erkannt."}
+              </div>
+            )}
+            {results.awards?.map((a) => (
+              <div key={`${a.name}-${a.playerId}`} style={{ fontWeight: "bold", color: "var(--yellow)" }}>
+                🏆 {a.title || a.name}: {a.playerName} (+{a.points})
+              </div>
+            ))}
           </div>
 
           <div className="card" style={{ marginBottom: 16 }}>
-            <h3>Wer hat was gewählt?</h3>
+            <h3>{results.voteCountsHidden ? "Wer hat was geschrieben?" : "Wer hat was gewählt?"}</h3>
             <div style={{ display: "grid", gap: 12 }}>
               {results.submissions?.map((submission) => {
                 const isAI = submission.id === results.aiSubmissionId;
                 const authorPlayer = players.find((p) => p.id === submission.authorId);
-                const author = isAI ? "KI" : authorPlayer?.name || submission.authorId || "Unbekannt";
+                const author = isAI
+                  ? "KI"
+                  : submission.authors?.map((a) => a.name).join(", ") || authorPlayer?.name || submission.authorId || "Unbekannt";
                 const votesForThis = results.votes.filter((v) => v.targetSubmissionId === submission.id);
+                const voteCount = results.voteCounts?.[submission.id] ?? votesForThis.length;
 
                 return (
                   <div
@@ -389,25 +586,27 @@ export default function Play() {
                       {isAI ? "🤖 " : "👤 "}
                       {author}: "{submission.text}"
                     </div>
-                    <div style={{ fontSize: "0.9em", color: "var(--subtle)" }}>
-                      {votesForThis.length > 0 ? (
-                        <>
-                          <strong>
-                            {votesForThis.length} Stimme
-                            {votesForThis.length !== 1 ? "n" : ""} von:
-                          </strong>{" "}
-                          {votesForThis
-                            .map((vote) => {
-                              const voter = players.find((p) => p.id === vote.voterId);
-                              return voter?.name || vote.voterId || "Unbekannt";
-                            })
-                            .join(", ")}
-                          {isAI && <span style={{ color: "var(--green)", marginLeft: 8 }}>✓ KI richtig erkannt!</span>}
-                        </>
-                      ) : (
-                        <em>Keine Stimmen erhalten</em>
-                      )}
-                    </div>
+                    {!results.voteCountsHidden && (
+                      <div style={{ fontSize: "0.9em", color: "var(--subtle)" }}>
+                        {voteCount > 0 ? (
+                          <>
+                            <strong>
+                              {voteCount} Stimme
+                              {voteCount !== 1 ? "n" : ""} von:
+                            </strong>{" "}
+                            {votesForThis
+                              .map((vote) => {
+                                const voter = players.find((p) => p.id === vote.voterId);
+                                return voter?.name || vote.voterId || "Unbekannt";
+                              })
+                              .join(", ")}
+                            {isAI && <span style={{ color: "var(--green)", marginLeft: 8 }}>✓ KI richtig erkannt!</span>}
+                          </>
+                        ) : (
+                          <em>Keine Stimmen erhalten</em>
+                        )}
+                      </div>
+                    )}
                   </div>
                 );
               })}
@@ -418,14 +617,14 @@ export default function Play() {
             <h3>Aktuelle Punkte</h3>
             <div style={{ display: "grid", gap: 12 }}>
               {(() => {
-                const sortedScores = results.scores.sort((a, b) => b.Points - a.Points);
-                const maxScore = Math.max(...sortedScores.map((s) => s.Points), 1);
+                // already sorted and ranked by the server
+                const sortedScores = results.scores;
+                const maxScore = Math.max(...sortedScores.map((s) => s.points), results.aiScore ?? 0, 1);
                 return sortedScores.map((s, index) => {
-                  const player = players.find((p) => p.id === s.PlayerID);
-                  const displayName = player?.name || s.PlayerID || `Spieler:in ${index + 1}`;
-                  const barWidth = (s.Points / maxScore) * 100;
+                  const displayName = s.name || s.playerId || `Spieler:in ${index + 1}`;
+                  const barWidth = (s.points / maxScore) * 100;
                   return (
-                    <div key={s.PlayerID} style={{ display: "grid", gap: 4 }}>
+                    <div key={s.playerId} style={{ display: "grid", gap: 4 }}>
                       <div
                         style={{
                           display: "flex",
@@ -434,19 +633,8 @@ export default function Play() {
                         }}
                       >
                         <span>
-                          {index === 0 ? "🥇 " : `${index + 1}. `}
+                          {s.rank === 1 ? "🥇 " : `${s.rank}. `}
                           <strong>{displayName}</strong>
-                          {!player && (
-                            <span
-                              style={{
-                                color: "var(--muted)",
-                                marginLeft: 4,
-                                fontSize: "0.8em",
-                              }}
-                            >
-                              (ID: {s.PlayerID})
-                            </span>
-                          )}
                         </span>
                         <span
                           style={{
@@ -455,7 +643,7 @@ export default function Play() {
                             textAlign: "right",
                           }}
                         >
-                          {s.Points} {s.Points > 1 ? "Punkte" : "Punkt"}
+                          {s.points} {s.points > 1 ? "Punkte" : "Punkt"}
                         </span>
                       </div>
                       <div
@@ -480,6 +668,12 @@ export default function Play() {
                   );
                 });
               })()}
+              <div style={{ display: "flex", justifyContent: "space-between", color: "var(--subtle)" }}>
+                <span>🤖 Die KI</span>
+                <span style={{ fontWeight: "bold", minWidth: "80px", textAlign: "right" }}>
+                  {results.aiScore ?? 0} {results.aiScore === 1 ? "Punkt" : "Punkte"}
+                </span>
+              </div>
             </div>
           </div>
         </div>
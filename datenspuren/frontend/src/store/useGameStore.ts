@@ -1,24 +1,43 @@
 import { create } from "zustand";
 
-type Phase = "Lobby" | "PromptSet" | "Answering" | "Voting" | "Reveal" | "Scoreboard" | "End";
+type Phase = "Lobby" | "PromptSet" | "Answering" | "Voting" | "Reveal" | "Scoreboard" | "End" | "PromptCollection";
 
 type Player = { id: string; name: string; isHost: boolean; joinedAt: string };
 type Round = {
   id: string;
   index: number;
   prompt: string;
+  translations?: Record<string, string>;
+  type?: "image"; // image rounds: players caption a generated picture
+  attachment?: { kind: "image" | "link"; url: string } | null; // shown with the prompt
+  groups?: string[][]; // submission IDs per parallel voting group
   aiSubmissionId?: string | null;
   status: Phase;
 };
 
+// Running humans-vs-AI tally: rounds where a majority found the AI vs was fooled.
+type MetaScore = { humans: number; ai: number; draws: number; leader: "humans" | "ai" | "" };
+
 type You = { role: "host" | "player"; playerId?: string };
 
+// How well the server currently reaches a player, from their pings.
+export type Connectivity = { quality: "good" | "fair" | "poor" | "offline"; rttMs?: number };
+
 type State = {
   sessionCode?: string;
   phase: Phase;
   players: Player[];
   round?: Round;
   you?: You;
+  recording?: boolean; // whether this session's results are exported
+  anonymized?: boolean; // whether exports pseudonymize player names
+  metaScore?: MetaScore;
+  connectivity?: Record<string, Connectivity>; // host only, player ID -> connection quality
+  hostless?: boolean; // no GM screen, the server moves the game on
+  ready?: string[]; // hostless only, IDs of players ready for the next round
+  allowSelfVote?: boolean; // players may vote for their own answer
+  maxAnswerLength?: number; // characters, 0 = no cap
+  restarting?: boolean; // the server announced a shutdown, we reconnect once it's back
   setState: (s: Partial<State>) => void;
 };
 
@@ -27,3 +46,9 @@ export const useGameStore = create<State>((set) => ({
   players: [],
   setState: (s) => set(s),
 }));
+
+// Picks the prompt translation matching the browser locale, if the host provided one.
+export function localizedPrompt(round: Round): string {
+  const lang = navigator.language.slice(0, 2).toLowerCase();
+  return round.translations?.[lang] || round.prompt;
+}